@@ -0,0 +1,27 @@
+// drop-legacy-room-status ลบคอลัมน์ rooms.legacy_status (ค่าสถานะห้องแบบเดิม) แบบ one-off
+//
+//	go run ./cmd/drop-legacy-room-status
+//
+// รันหลังตรวจแล้วว่า clean_status / service_status ที่ย้ายตอนบูตถูกต้อง
+package main
+
+import (
+	"log"
+
+	"github.com/joho/godotenv"
+
+	"hotel-backend/config"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("⚠️  .env not found or couldn't load it; continuing with environment variables")
+	}
+	if err := config.ConnectDatabase(); err != nil {
+		log.Fatalf("❌ Database connect failed: %v", err)
+	}
+	if err := config.DropLegacyRoomStatus(config.DB); err != nil {
+		log.Fatalf("❌ drop rooms.legacy_status failed: %v", err)
+	}
+	log.Println("✅ rooms.legacy_status dropped")
+}
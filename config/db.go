@@ -122,6 +122,21 @@ func SeedDatabase() {
 		"rolesAndPermissions.create",
 		"rolesAndPermissions.edit",
 		"rolesAndPermissions.delete",
+		"housekeeping.view",
+		"housekeeping.update",
+		"housekeeping.assign",
+		"housekeeping.inspect",
+		"maintenance.view",
+		"maintenance.create",
+		"maintenance.update",
+		"maintenance.assign",
+		"settings.view",
+		"settings.edit",
+	}
+
+	// สิทธิ์เริ่มต้นของ role อื่น (ให้เฉพาะ role ที่ยังไม่มีสิทธิ์ใดเลย)
+	defaultRolePerms := map[string][]string{
+		"cleaner": {"housekeeping.view", "housekeeping.update", "maintenance.view", "maintenance.create"},
 	}
 
 	rolesByKey := map[string]models.Role{}
@@ -151,6 +166,25 @@ func SeedDatabase() {
 		rolesByKey[key] = role
 	}
 
+	for key, defaults := range defaultRolePerms {
+		role, ok := rolesByKey[key]
+		if !ok || role.ID == 0 {
+			continue
+		}
+		var permCount int64
+		DB.Model(&models.RolePermission{}).Where("role_id = ?", role.ID).Count(&permCount)
+		if permCount > 0 {
+			continue
+		}
+		perms := make([]models.RolePermission, 0, len(defaults))
+		for _, p := range defaults {
+			perms = append(perms, models.RolePermission{RoleID: role.ID, Permission: p})
+		}
+		if err := DB.Create(&perms).Error; err != nil {
+			log.Printf("warning: failed to create %s permissions: %v", role.Name, err)
+		}
+	}
+
 	ownerRole, ok := rolesByKey["owner"]
 	if ok && ownerRole.ID != 0 {
 		var permCount int64
@@ -165,6 +199,24 @@ func SeedDatabase() {
 					log.Printf("warning: failed to create owner permissions: %v", err)
 				}
 			}
+		} else {
+			// ฐานข้อมูลที่ seed ก่อนมีโมดูลใหม่: owner ยังไม่มีสิทธิ์ของโมดูลนั้น
+			for _, module := range []string{"housekeeping", "maintenance", "settings"} {
+				var moduleCount int64
+				DB.Model(&models.RolePermission{}).Where("role_id = ? AND permission LIKE ?", ownerRole.ID, module+".%").Count(&moduleCount)
+				if moduleCount > 0 {
+					continue
+				}
+				perms := []models.RolePermission{}
+				for _, p := range allPerms {
+					if strings.HasPrefix(p, module+".") {
+						perms = append(perms, models.RolePermission{RoleID: ownerRole.ID, Permission: p})
+					}
+				}
+				if err := DB.Create(&perms).Error; err != nil {
+					log.Printf("warning: failed to create owner %s permissions: %v", module, err)
+				}
+			}
 		}
 
 		var memberCount int64
@@ -284,6 +336,89 @@ WHERE TABLE_SCHEMA = ? AND TABLE_NAME = 'consents' AND REFERENCED_TABLE_NAME = '
 	}
 }
 
+// ensureSearchIndexes สร้าง FULLTEXT index ที่ /api/search ใช้ (ถ้าสร้างไม่ได้ search จะใช้ LIKE แทน)
+func ensureSearchIndexes(db *gorm.DB) {
+	if db.Dialector.Name() != "mysql" {
+		return
+	}
+	indexes := []struct {
+		model   interface{}
+		table   string
+		name    string
+		columns string
+	}{
+		{&models.Customer{}, "customers", "ft_customers_search", "full_name, email, phone"},
+		{&models.Guest{}, "guests", "ft_guests_search", "full_name, id_number, email"},
+	}
+	for _, idx := range indexes {
+		if db.Migrator().HasIndex(idx.model, idx.name) {
+			continue
+		}
+		stmt := fmt.Sprintf("ALTER TABLE `%s` ADD FULLTEXT INDEX `%s` (%s)", idx.table, idx.name, idx.columns)
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Printf("warning: failed to create FULLTEXT index %s: %v", idx.name, err)
+		} else {
+			log.Printf("info: created FULLTEXT index %s", idx.name)
+		}
+	}
+}
+
+// legacyRoomStatusColumn ชื่อคอลัมน์ rooms.status เดิมหลังย้ายค่าแล้ว (เก็บไว้จนกว่าจะสั่ง DropLegacyRoomStatus)
+const legacyRoomStatusColumn = "legacy_status"
+
+// migrateLegacyRoomStatus ย้ายค่าจากคอลัมน์ rooms.status เดิม (ข้อความอิสระ) ไป clean_status / service_status
+// แล้วเปลี่ยนชื่อคอลัมน์เป็น legacy_status (ไม่ลบ — ย้อนกลับได้ และรอบบูตถัดไปไม่ย้ายทับค่าที่แก้ไปแล้ว)
+// Reserved / Available / Occupied ไม่ต้องย้ายเพราะการเข้าพักคำนวณจาก booking แล้ว
+func migrateLegacyRoomStatus(db *gorm.DB) {
+	if !db.Migrator().HasColumn(&models.Room{}, "status") {
+		return
+	}
+	now := time.Now().UTC()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		cleanMoves := []struct {
+			to     string
+			values []string
+		}{
+			{"dirty", []string{"dirty", "vacant dirty", "vd", "occupied dirty", "od", "needs cleaning", "unclean"}},
+			{"cleaning", []string{"cleaning", "in cleaning", "being cleaned", "housekeeping"}},
+			{"inspected", []string{"inspected", "vacant inspected", "vi"}},
+		}
+		for _, m := range cleanMoves {
+			if err := tx.Exec("UPDATE rooms SET clean_status = ?, clean_status_at = ? WHERE LOWER(TRIM(status)) IN ?", m.to, now, m.values).Error; err != nil {
+				return err
+			}
+		}
+		serviceMoves := []struct {
+			to     string
+			values []string
+		}{
+			{"out_of_order", []string{"maintenance", "out of order", "out_of_order", "ooo", "broken"}},
+			{"out_of_service", []string{"out of service", "out_of_service", "oos", "closed", "blocked"}},
+		}
+		for _, m := range serviceMoves {
+			if err := tx.Exec("UPDATE rooms SET service_status = ?, service_reason = status WHERE LOWER(TRIM(status)) IN ?", m.to, m.values).Error; err != nil {
+				return err
+			}
+		}
+		// CHANGE ใช้ได้ทั้ง MySQL 5.7 / 8 (RENAME COLUMN มีเฉพาะ 8 ขึ้นไป); เดิมเป็น Status string -> longtext
+		return tx.Exec("ALTER TABLE rooms CHANGE `status` `" + legacyRoomStatusColumn + "` LONGTEXT NULL").Error
+	})
+	if err != nil {
+		log.Printf("warning: failed to migrate legacy room status: %v", err)
+		return
+	}
+	log.Printf("info: migrated legacy rooms.status to clean_status / service_status (old values kept in rooms.%s)", legacyRoomStatusColumn)
+}
+
+// DropLegacyRoomStatus ลบคอลัมน์ rooms.legacy_status ทิ้ง (one-off: go run ./cmd/drop-legacy-room-status)
+// เรียกเองหลังตรวจแล้วว่าค่าที่ย้ายถูกต้อง ไม่รันตอนบูต
+func DropLegacyRoomStatus(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.Room{}, legacyRoomStatusColumn) {
+		return nil
+	}
+	return db.Migrator().DropColumn(&models.Room{}, legacyRoomStatusColumn)
+}
+
 func ConnectDatabase() error {
 	dsn, dbName, err := resolveMySQLDSN()
 	if err != nil {
@@ -333,9 +468,34 @@ func ConnectDatabase() error {
 		&models.Consent{},    // parent (consents)
 		&models.ConsentLog{}, // child (consent_logs)
 		&models.BookingRoom{},
+		&models.CapturedEmail{},
+		&models.ScheduleSetting{},
+		&models.JobRun{},
+		&models.AuditLog{},
+		&models.RateLimitEvent{},
+		&models.RateLimitLock{},
+		&models.CheckInDraft{},
+		&models.KioskDevice{},
+		&models.VerificationCase{},
+		&models.AdminSession{},
+		&models.CustomerMerge{},
+		&models.CustomerDuplicateDismissal{},
+		&models.ExportLog{},
+		&models.BookingImport{},
+		&models.ChannelRoomMapping{},
+		&models.ChannelSyncLog{},
+		&models.ChannelReservationRetry{},
+		&models.RoomBlock{},
+		&models.RoomCalendarFeed{},
+		&models.RoomCalendarImport{},
+		&models.HousekeepingTask{},
+		&models.RoomStatusChange{},
+		&models.MaintenanceTicket{},
 	); err != nil {
 		return err
 	}
+	ensureSearchIndexes(DB)
+	migrateLegacyRoomStatus(DB)
 
 	SeedDatabase()
 	return nil
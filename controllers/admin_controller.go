@@ -9,7 +9,9 @@ import (
 	"regexp"
 
 	"hotel-backend/config"
+	"hotel-backend/middleware"
 	"hotel-backend/models"
+	"hotel-backend/services"
 	"hotel-backend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -24,9 +26,10 @@ type createAdminPayload struct {
 }
 
 type inviteAdminPayload struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	Language string `json:"language"`
 }
 
 type activateAdminPayload struct {
@@ -53,19 +56,37 @@ func normalizeInviteRole(raw string) (string, bool) {
 	}
 }
 
+// adminListSpec: GET /api/admins
+var adminListSpec = services.ListSpec{
+	IDColumn: "id",
+	Sorts: map[string]string{
+		"id":        "id",
+		"username":  "username",
+		"fullName":  "full_name",
+		"createdAt": "created_at",
+	},
+	DefaultSort: "id",
+	DateColumn:  "created_at",
+}
+
 func GetAdmins(c *gin.Context) {
-	var admins []models.Admin
-	if err := config.DB.Find(&admins).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	q, ok := bindListQuery(c, adminListSpec)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, admins)
+	admins := []models.Admin{}
+	page, err := services.FindPage(config.DB, adminListSpec, q, &admins)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	respondPage(c, page, admins)
 }
 
 func CreateAdmin(c *gin.Context) {
 	var payload createAdminPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
 		return
 	}
 
@@ -82,7 +103,7 @@ func CreateAdmin(c *gin.Context) {
 	}
 
 	if err := config.DB.Create(&admin).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 	c.JSON(http.StatusOK, admin)
@@ -91,25 +112,33 @@ func CreateAdmin(c *gin.Context) {
 func InviteAdmin(c *gin.Context) {
 	var payload inviteAdminPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
 		return
 	}
 
 	name := strings.TrimSpace(payload.Name)
 	email := strings.TrimSpace(payload.Email)
 	roleName, ok := normalizeInviteRole(payload.Role)
-	if name == "" || email == "" || !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "name, email, and role are required"})
+	if name == "" {
+		respondValidationError(c, fieldError("name", "required", ""))
+		return
+	}
+	if email == "" {
+		respondValidationError(c, fieldError("email", "required", ""))
+		return
+	}
+	if !ok {
+		respondValidationError(c, fieldError("role", "required", ""))
 		return
 	}
 	if !inviteEmailRegex.MatchString(strings.ToLower(email)) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid email"})
+		respondValidationError(c, fieldError("email", "email", ""))
 		return
 	}
 
 	token, err := utils.GenerateSecureToken(24)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		middleware.Abort(c, services.ErrInternal.Wrapf("generate token: %w", err))
 		return
 	}
 	expiry := time.Now().Add(24 * time.Hour)
@@ -119,11 +148,11 @@ func InviteAdmin(c *gin.Context) {
 	if err := config.DB.Unscoped().Where("username = ?", email).First(&admin).Error; err == nil {
 		exists = true
 		if !admin.DeletedAt.Valid {
-			c.JSON(http.StatusConflict, gin.H{"error": "email already exists"})
+			middleware.Abort(c, services.ErrEmailExists)
 			return
 		}
 	} else if err != nil && err != gorm.ErrRecordNotFound {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -134,7 +163,7 @@ func InviteAdmin(c *gin.Context) {
 			"reset_token_expires": expiry,
 			"deleted_at":          nil,
 		}).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			middleware.Abort(c, services.ErrInternal.Wrap(err))
 			return
 		}
 	} else {
@@ -146,7 +175,7 @@ func InviteAdmin(c *gin.Context) {
 		}
 
 		if err := config.DB.Create(&admin).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			middleware.Abort(c, services.ErrInternal.Wrap(err))
 			return
 		}
 	}
@@ -159,22 +188,22 @@ func InviteAdmin(c *gin.Context) {
 				Description: "",
 			}
 			if err := config.DB.Create(&role).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				middleware.Abort(c, services.ErrInternal.Wrap(err))
 				return
 			}
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			middleware.Abort(c, services.ErrInternal.Wrap(err))
 			return
 		}
 	}
 
 	if err := config.DB.Unscoped().Where("admin_id = ?", admin.ID).Delete(&models.RoleMember{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
 	if err := config.DB.Create(&models.RoleMember{RoleID: role.ID, AdminID: admin.ID}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -185,14 +214,19 @@ func InviteAdmin(c *gin.Context) {
 	adminFrontendURL = strings.TrimRight(adminFrontendURL, "/")
 	inviteLink := fmt.Sprintf("%s/#/setup-account?token=%s&email=%s", adminFrontendURL, token, url.QueryEscape(email))
 
-	if err := utils.SendAdminInviteEmail(email, inviteLink, name, roleName); err != nil {
+	if err := utils.SendAdminInviteEmail(email, payload.Language, utils.AdminInviteEmailData{
+		Hotel:      services.LoadHotelBrand(config.DB),
+		Name:       name,
+		Role:       roleName,
+		InviteLink: inviteLink,
+	}); err != nil {
 		_ = config.DB.Unscoped().Where("admin_id = ?", admin.ID).Delete(&models.RoleMember{}).Error
 		if exists {
 			_ = config.DB.Unscoped().Model(&admin).Update("deleted_at", time.Now()).Error
 		} else {
 			_ = config.DB.Unscoped().Delete(&admin).Error
 		}
-		c.JSON(http.StatusBadGateway, gin.H{"error": "email send failed"})
+		middleware.Abort(c, services.ErrEmailSendFailed.Wrap(err))
 		return
 	}
 
@@ -209,36 +243,38 @@ func InviteAdmin(c *gin.Context) {
 func ActivateAdmin(c *gin.Context) {
 	var payload activateAdminPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
 		return
 	}
 
 	email := strings.TrimSpace(payload.Email)
 	token := strings.TrimSpace(payload.Token)
 	password := strings.TrimSpace(payload.Password)
-	if email == "" || token == "" || password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "email, token, and password are required"})
-		return
+	for _, f := range []struct{ name, value string }{{"email", email}, {"token", token}, {"password", password}} {
+		if f.value == "" {
+			respondValidationError(c, fieldError(f.name, "required", ""))
+			return
+		}
 	}
 	if len(password) < 8 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "password must be at least 8 characters"})
+		respondValidationError(c, &ValidationError{Fields: []FieldError{{Field: "password", Rule: "min", key: "minLen", param: "8"}}})
 		return
 	}
 
 	var admin models.Admin
 	if err := config.DB.Unscoped().Where("username = ? AND reset_token = ?", email, token).First(&admin).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "invalid token"})
+		middleware.Abort(c, services.ErrInvalidInvite)
 		return
 	}
 
 	if admin.ResetTokenExpires != nil && time.Now().After(*admin.ResetTokenExpires) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "token expired"})
+		middleware.Abort(c, services.ErrInviteExpired)
 		return
 	}
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
+		middleware.Abort(c, services.ErrInternal.Wrapf("hash password: %w", err))
 		return
 	}
 
@@ -253,7 +289,7 @@ func ActivateAdmin(c *gin.Context) {
 	}
 
 	if err := config.DB.Unscoped().Model(&admin).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to activate account"})
+		middleware.Abort(c, services.ErrInternal.Wrapf("activate account: %w", err))
 		return
 	}
 
@@ -4,11 +4,14 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	"hotel-backend/config"
+	"hotel-backend/middleware"
 	"hotel-backend/models"
+	"hotel-backend/services"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -35,112 +38,119 @@ func generateTokenHex(length int) (string, error) {
 	return hex.EncodeToString(b), nil
 }
 
-func Login(c *gin.Context) {
-	var payload loginPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
-		return
-	}
-
-	username := strings.TrimSpace(payload.Username)
-	password := payload.Password
-	if username == "" || password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "username and password required"})
-		return
-	}
+// POST /api/auth/login (ใช้ AdminSessionService ตัวเดียวกับ RequireAuth)
+func Login(sessions *services.AdminSessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload loginPayload
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
+			return
+		}
 
-	var admin models.Admin
-	if err := config.DB.Unscoped().Where("username = ?", username).First(&admin).Error; err != nil {
-		if username == "admin@hotel.local" {
-			hash, hErr := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-			if hErr == nil {
-				admin = models.Admin{
-					FullName: "Admin User",
-					Username: username,
-					Password: string(hash),
-				}
-				if cErr := config.DB.Create(&admin).Error; cErr == nil {
-					// continue with freshly created admin
-				} else {
-					// if admin already exists, fetch and continue
-					var existing models.Admin
-					if fErr := config.DB.Unscoped().Where("username = ?", username).First(&existing).Error; fErr == nil {
-						admin = existing
-					} else {
-						c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create default admin"})
-						return
-					}
-				}
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
-				return
-			}
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		username := strings.TrimSpace(payload.Username)
+		password := payload.Password
+		if username == "" {
+			respondValidationError(c, fieldError("username", "required", ""))
+			return
+		}
+		if password == "" {
+			respondValidationError(c, fieldError("password", "required", ""))
 			return
 		}
-	}
 
-	if admin.DeletedAt.Valid {
-		_ = config.DB.Unscoped().Model(&admin).Update("deleted_at", nil).Error
-	}
+		// admin ที่ถูกลบ (soft delete) login ไม่ได้ / admin เริ่มต้นสร้างตอน seed ใน config/db.go เท่านั้น
+		var admin models.Admin
+		if err := config.DB.Where("username = ?", username).First(&admin).Error; err != nil {
+			middleware.Abort(c, services.ErrInvalidCredentials)
+			return
+		}
 
-	stored := admin.Password
-	valid := false
-	if stored != "" {
+		stored := admin.Password
+		valid := false
 		if isBcryptHash(stored) {
-			if bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil {
-				valid = true
-			} else if username == "admin@hotel.local" && password == "admin123" {
-				valid = true
-				if hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost); err == nil {
-					config.DB.Model(&admin).Update("password", string(hash))
-				}
-			}
-		} else if stored == password {
+			valid = bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+		} else if stored != "" && stored == password {
+			// รหัสผ่านเก่าที่ยังเก็บเป็น plain text -> hash ใหม่ตอน login สำเร็จ
 			valid = true
 			if hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost); err == nil {
 				config.DB.Model(&admin).Update("password", string(hash))
 			}
 		}
-	} else {
-		valid = true
-		if hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost); err == nil {
-			config.DB.Model(&admin).Update("password", string(hash))
+
+		if !valid {
+			middleware.Abort(c, services.ErrInvalidCredentials)
+			return
 		}
-	}
 
-	if !valid {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
-		return
+		session, token, err := sessions.Create(admin.ID, c.ClientIP(), c.Request.UserAgent())
+		if err != nil {
+			middleware.Abort(c, services.ErrInternal.Wrapf("create session: %w", err))
+			return
+		}
+		perms, err := sessions.Permissions(admin.ID)
+		if err != nil {
+			middleware.Abort(c, services.ErrInternal.Wrapf("load permissions: %w", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"token":     token,
+			"expiresAt": session.ExpiresAt,
+			"admin": gin.H{
+				"id":        admin.ID,
+				"full_name": admin.FullName,
+				"username":  admin.Username,
+			},
+			"permissions": permissionList(perms),
+		})
 	}
+}
 
-	token, err := generateTokenHex(32)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
-		return
+// POST /api/auth/logout (Authorization: Bearer <token>)
+func Logout(sessions *services.AdminSessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := middleware.BearerToken(c); token != "" {
+			if err := sessions.Revoke(token); err != nil {
+				middleware.Abort(c, services.ErrInternal.Wrapf("revoke session: %w", err))
+				return
+			}
+		}
+		c.Status(http.StatusNoContent)
 	}
+}
 
+// GET /api/auth/me (ผ่าน RequireAuth)
+func Me(c *gin.Context) {
+	admin := middleware.CurrentAdmin(c)
 	c.JSON(http.StatusOK, gin.H{
-		"token": token,
 		"admin": gin.H{
 			"id":        admin.ID,
 			"full_name": admin.FullName,
 			"username":  admin.Username,
 		},
+		"permissions": permissionList(middleware.AdminPermissions(c)),
 	})
 }
 
+func permissionList(perms map[string]bool) []string {
+	out := make([]string, 0, len(perms))
+	for p := range perms {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
 func ForgotPassword(c *gin.Context) {
 	var payload forgotPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
 		return
 	}
 
 	email := strings.TrimSpace(payload.Email)
 	if email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "email required"})
+		respondValidationError(c, fieldError("email", "required", ""))
 		return
 	}
 
@@ -2,14 +2,14 @@
 package controllers
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	mysql "github.com/go-sql-driver/mysql"
 	"hotel-backend/config"
+	"hotel-backend/middleware"
 	"hotel-backend/models"
 	"hotel-backend/services"
-	"io/ioutil"
+	"hotel-backend/utils"
 	"log"
 	"net/http"
 	"strconv"
@@ -25,42 +25,63 @@ import (
 // ---------------------------
 
 type InitiateCheckInPayload struct {
-	BookingID uint `json:"bookingId" binding:"required"`
+	BookingID uint `json:"bookingId" alias:"booking_id" binding:"required,gt=0"`
+}
+
+type NotificationChannelsPayload struct {
+	Channels []string `json:"channels" binding:"required,min=1"`
 }
 
 type ValidateCodePayload struct {
-	CheckinCode string `json:"checkinCode" binding:"required"`
+	CheckinCode string `json:"checkinCode" alias:"checkin_code" binding:"required"`
 	Query       string `json:"query" binding:"required"` // lastName หรือ bookingRef
 }
 
 type ConfirmCheckInPayload struct {
-	Token    string           `json:"token" binding:"required"`
-	Guests   []models.Guest   `json:"guests"`
-	Consents []models.Consent `json:"consents"`
+	Token    string         `json:"token" binding:"required"`
+	Guests   []GuestRequest `json:"guests" binding:"omitempty,dive"`
+	Consents []ConsentRef   `json:"consents" binding:"omitempty,dive"`
 }
 
 // RoomItem รองรับ per-room details ที่ frontend อาจส่งมา
 type RoomItem struct {
-	RoomID uint     `json:"room_id" binding:"required"`
-	Price  *float64 `json:"price,omitempty"`
-	Nights *int     `json:"nights,omitempty"`
-	Hours  *int     `json:"hours,omitempty"`
+	RoomID uint     `json:"room_id" alias:"roomId" binding:"required,gt=0"`
+	Price  *float64 `json:"price,omitempty" binding:"omitempty,gte=0"`
+	Nights *int     `json:"nights,omitempty" binding:"omitempty,gt=0"`
+	Hours  *int     `json:"hours,omitempty" binding:"omitempty,gt=0"`
+}
+
+// AccompanyingGuestItem ผู้เข้าพักร่วมใน guest_list
+type AccompanyingGuestItem struct {
+	Name string `json:"name" alias:"fullName,full_name" binding:"required,max=255"`
+	Type string `json:"type" alias:"guestType,guest_type" binding:"omitempty,oneof=Adult Child"`
 }
 
 // CreateBookingRequest รองรับหลายรูปแบบ:
 type CreateBookingRequest struct {
-	CustomerID int                      `json:"customer_id" binding:"required"`
-	CheckIn    string                   `json:"check_in" binding:"required"`
-	CheckOut   string                   `json:"check_out" binding:"required"`
-	RoomID     uint                     `json:"room_id"`
-	RoomIDs    []uint                   `json:"room_ids"`
-	Rooms      []RoomItem               `json:"rooms"`
-	GuestList  []map[string]interface{} `json:"guest_list,omitempty"`
-	SendEmail  bool                     `json:"send_email,omitempty"`
+	CustomerID int                     `json:"customer_id" alias:"customerId" binding:"required,gt=0"`
+	CheckIn    string                  `json:"check_in" alias:"checkIn" binding:"required,dateonly"`
+	CheckOut   string                  `json:"check_out" alias:"checkOut" binding:"required,dateonly"`
+	RoomID     uint                    `json:"room_id" alias:"roomId"`
+	RoomIDs    []uint                  `json:"room_ids" alias:"roomIds"`
+	Rooms      []RoomItem              `json:"rooms" binding:"omitempty,dive"`
+	GuestList  []AccompanyingGuestItem `json:"guest_list,omitempty" alias:"guestList" binding:"omitempty,dive"`
+	SendEmail  bool                    `json:"send_email,omitempty" alias:"sendEmail"`
+
+	// ช่องทางส่งลิงก์เช็คอิน: "email", "sms", "line" (default: email)
+	NotificationChannels []string `json:"notification_channels,omitempty" alias:"notificationChannels"`
 
 	// ✅ รองรับจำนวนแขก
-	Adults   int `json:"adults"`
-	Children int `json:"children"`
+	Adults   int `json:"adults" binding:"gte=0"`
+	Children int `json:"children" binding:"gte=0"`
+}
+
+func (r CreateBookingRequest) accompanyingGuests() []services.AccompanyingGuest {
+	out := make([]services.AccompanyingGuest, 0, len(r.GuestList))
+	for _, g := range r.GuestList {
+		out = append(out, services.AccompanyingGuest{FullName: g.Name, Type: g.Type})
+	}
+	return out
 }
 
 // ---------------------------
@@ -104,12 +125,7 @@ func getBookingIDString(c *gin.Context) (string, bool) {
 // Helper: คืน structured error
 // ---------------------------
 func respondErrorMissingBookingID(c *gin.Context) {
-	c.JSON(http.StatusBadRequest, gin.H{
-		"error": gin.H{
-			"code":    "error.missingBookingId",
-			"message": "ไม่พบหมายเลขการจอง (bookingId) กรุณาตรวจสอบและลองใหม่",
-		},
-	})
+	middleware.Abort(c, services.ErrMissingBookingID)
 }
 
 // ---------------------------
@@ -143,72 +159,24 @@ func parseAccompanyingGuests(raw []byte) any {
 
 func (ctrl *BookingController) InitiateCheckIn(c *gin.Context) {
 	var payload InitiateCheckInPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"code":    "error.invalidPayload",
-				"message": "payload ไม่ถูกต้อง: ต้องมี bookingId",
-				"details": err.Error(),
-			},
-		})
+	if !bindJSON(c, &payload) {
 		return
 	}
 
 	bookingInfo, err := ctrl.BookingSvc.InitiateCheckInProcess(payload.BookingID)
 	if err != nil {
 		log.Printf("InitiateCheckIn error for booking %d: %v", payload.BookingID, err)
-
-		switch {
-		case strings.Contains(err.Error(), "booking_not_found"):
-			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": "error.bookingNotFound", "message": "ไม่พบการจอง (Booking) ที่ระบุ"}})
-			return
-
-		case strings.Contains(err.Error(), "already_checked_in"):
-			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": "error.alreadyCheckedIn", "message": "การจองนี้ถูกเช็คอินแล้ว"}})
-			return
-
-		case strings.Contains(err.Error(), "checkin_already_initiated"):
-			c.JSON(http.StatusConflict, gin.H{"error": gin.H{"code": "error.checkinAlreadyInitiated", "message": "มี session การเช็คอินที่กำลังใช้งานอยู่แล้ว"}})
-			return
-
-		case strings.Contains(err.Error(), "booking_checked_out"):
-			c.JSON(http.StatusGone, gin.H{
-				"error": gin.H{
-					"code":    "error.bookingCheckedOut",
-					"message": "การจองนี้เช็คเอาท์แล้ว ไม่สามารถเริ่มเช็คอินได้",
-				},
-			})
-			return
-
-		case strings.Contains(err.Error(), "email_send_failed"):
-			// bookingInfo created but email sending failed -> return 206 with token & checkin_code
-			c.JSON(http.StatusPartialContent, gin.H{
-				"status": "warning",
-				"data": gin.H{
-					"id":           bookingInfo.ID,
-					"token":        bookingInfo.Token,
-					"checkin_code": bookingInfo.CheckinCode,
-				},
-				"error": gin.H{
-					"code":    "error.emailSendFailed",
-					"message": "สร้าง session การเช็คอินสำเร็จ แต่ส่งอีเมลไม่สำเร็จ",
-					"details": err.Error(),
-				},
-			})
-			return
-
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": gin.H{
-					"code":    "error.internal",
-					"message": "เกิดข้อผิดพลาดภายในระบบ",
-					"details": err.Error(),
-				},
-			})
-			return
-		}
+		respondInitiateCheckInError(c, bookingInfo, err)
+		return
 	}
 
+	respondInitiateCheckInOK(c, bookingInfo)
+}
+
+// ---------------------------
+// Helper: response ของการเริ่มเช็คอิน (ใช้ร่วมกันทุก entry point)
+// ---------------------------
+func respondInitiateCheckInOK(c *gin.Context, bookingInfo models.BookingInfo) {
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 		"data": gin.H{
@@ -219,37 +187,47 @@ func (ctrl *BookingController) InitiateCheckIn(c *gin.Context) {
 	})
 }
 
+func respondInitiateCheckInError(c *gin.Context, bookingInfo models.BookingInfo, err error) {
+	code, message := "", ""
+	switch {
+	case errors.Is(err, services.ErrNotificationSendFailed):
+		code, message = "error.notificationSendFailed", middleware.Message(c, utils.MsgCheckinNotifyPartial)
+	case errors.Is(err, services.ErrNotificationNotSent):
+		code, message = "error.notificationNotSent", middleware.Message(c, "error.notificationNotSent")
+	}
+	if code != "" {
+		// bookingInfo created but the link did not reach every channel -> 206 with token & checkin_code
+		log.Printf("initiate check-in %d: notification not fully delivered: %v", bookingInfo.ID, err)
+		c.JSON(http.StatusPartialContent, gin.H{
+			"status": "warning",
+			"data": gin.H{
+				"id":           bookingInfo.ID,
+				"token":        bookingInfo.Token,
+				"checkin_code": bookingInfo.CheckinCode,
+			},
+			"error": gin.H{
+				"code":    code,
+				"message": message,
+			},
+		})
+		return
+	}
+	middleware.Abort(c, err)
+}
+
 // ---------------------------
 // 2) Manual Code Entry (/validate)
 // ---------------------------
 
 func (ctrl *BookingController) ValidateCheckinCode(c *gin.Context) {
 	var p ValidateCodePayload
-	if err := c.ShouldBindJSON(&p); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "error.invalidPayload", "message": "ต้องระบุ checkinCode และ query (lastName หรือ bookingRef)"}})
+	if !bindJSON(c, &p) {
 		return
 	}
 
 	bi, err := ctrl.BookingSvc.ValidateCheckinCodeByBooking(p.CheckinCode, p.Query)
 	if err != nil {
-
-		// ✅ เพิ่มตรงนี้
-		if strings.Contains(err.Error(), "booking_checked_out") {
-			c.JSON(http.StatusGone, gin.H{
-				"error": gin.H{
-					"code":    "error.bookingCheckedOut",
-					"message": "การจองนี้เช็คเอาท์แล้ว ไม่สามารถใช้รหัสนี้ได้",
-				},
-			})
-			return
-		}
-
-		if strings.Contains(err.Error(), "invalid_or_expired_code") || strings.Contains(err.Error(), "invalid_or_expired") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": gin.H{"code": "error.invalidOrExpiredCode", "message": "รหัสยืนยันไม่ถูกต้องหรือหมดอายุ"}})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": "error.internal", "message": "เกิดข้อผิดพลาดขณะตรวจสอบรหัส", "details": err.Error()}})
+		middleware.Abort(c, err)
 		return
 	}
 
@@ -283,12 +261,7 @@ func (ctrl *BookingController) VerifyToken(c *gin.Context) {
 	}
 
 	if token == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"code":    "error.missingToken",
-				"message": "ไม่พบ token กรุณาตรวจสอบลิงก์",
-			},
-		})
+		middleware.Abort(c, services.ErrMissingToken)
 		return
 	}
 
@@ -305,21 +278,10 @@ func (ctrl *BookingController) VerifyToken(c *gin.Context) {
 		First(&bi).Error; err != nil {
 
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": gin.H{
-					"code":    "error.invalidOrExpiredToken",
-					"message": "ลิงก์ยืนยันไม่ถูกต้องหรือหมดอายุ",
-				},
-			})
+			middleware.Abort(c, services.ErrInvalidOrExpiredToken)
 			return
 		}
-
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"code":    "error.internal",
-				"message": "เกิดข้อผิดพลาดภายในระบบ",
-			},
-		})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -335,23 +297,14 @@ func (ctrl *BookingController) VerifyToken(c *gin.Context) {
 		Preload("Rooms.Room.RoomType").
 		First(&booking).Error; err != nil {
 
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"code":    "error.bookingFetchFailed",
-				"message": "ไม่สามารถโหลดข้อมูลการจองได้",
-			},
-		})
+		middleware.Abort(c, services.ErrInternal.Wrapf("load booking for token: %w", err))
 		return
 	}
+	middleware.PreferLocale(c, booking.Customer.PreferredLanguage)
 
 	// ✅ Block if booking already Checked-Out
 	if strings.EqualFold(strings.TrimSpace(booking.Status), "Checked-Out") {
-		c.JSON(http.StatusGone, gin.H{
-			"error": gin.H{
-				"code":    "error.bookingCheckedOut",
-				"message": "การจองนี้เช็คเอาท์แล้ว ลิงก์นี้ใช้ไม่ได้",
-			},
-		})
+		middleware.Abort(c, services.ErrBookingCheckedOut)
 		return
 	}
 
@@ -359,7 +312,7 @@ func (ctrl *BookingController) VerifyToken(c *gin.Context) {
 	// 4) Build rooms list
 	// --------------------
 	rooms := make([]map[string]interface{}, 0, len(booking.Rooms))
-	for _, br := range booking.Rooms {
+	for _, br := range services.CurrentBookingRooms(booking.Rooms) {
 		roomNumber := ""
 		roomType := ""
 
@@ -472,60 +425,60 @@ func formatStayDuration(checkIn, checkOut *time.Time) string {
 
 func (ctrl *BookingController) ConfirmCheckIn(c *gin.Context) {
 	var payload ConfirmCheckInPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		log.Printf("ConfirmCheckIn bind error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "error.invalidPayload", "message": "payload ไม่ถูกต้องหรือขาดฟิลด์ที่จำเป็น", "details": err.Error()}})
+	if !bindJSON(c, &payload) {
 		return
 	}
 
-	var guestModels []models.Guest
+	guestModels := make([]models.Guest, 0, len(payload.Guests))
 	for _, g := range payload.Guests {
-		guestModels = append(guestModels, g)
+		guestModels = append(guestModels, g.toModel())
+	}
+	consents := make([]models.Consent, 0, len(payload.Consents))
+	for _, ref := range payload.Consents {
+		consents = append(consents, models.Consent{ID: ref.ID})
 	}
 
-	if err := ctrl.BookingSvc.FinalizeCheckInTransaction(payload.Token, guestModels, payload.Consents); err != nil {
+	if err := ctrl.BookingSvc.FinalizeCheckInTransaction(payload.Token, guestModels, consents); err != nil {
 		log.Printf("FinalizeCheckInTransaction error (token=%s): %v", payload.Token, err)
-		if strings.Contains(err.Error(), "invalid_or_expired_token") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": gin.H{"code": "error.invalidOrExpiredToken", "message": "ลิงก์การเช็คอินไม่ถูกต้องหรือหมดอายุ"}})
-			return
+		if services.AsError(err) == nil {
+			err = services.ErrFinalizeFailed.Wrap(err)
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": "error.finalizeFailed", "message": "ไม่สามารถยืนยันการเช็คอินได้", "details": err.Error()}})
+		middleware.Abort(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "เช็คอินเสร็จสิ้นและบันทึกข้อมูลแล้ว"})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": middleware.Message(c, utils.MsgCheckinCompleted)})
 }
 
 // ---------------------------
 // CRUD: Bookings
 // ---------------------------
 
+// GET /api/bookings?status=&from=&to=&roomId=&customerId=&nationality=&sort=&limit=&offset=|cursor=
 func (ctrl *BookingController) GetBookings(c *gin.Context) {
-	bookings, err := ctrl.BookingSvc.GetAllWithRelations()
+	q, ok := bindListQuery(c, services.BookingListSpec)
+	if !ok {
+		return
+	}
+	bookings, page, err := ctrl.BookingSvc.List(q)
 	if err != nil {
-		log.Printf("GetBookings error: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": "error.fetchBookings", "message": "ไม่สามารถดึงรายการการจองได้"}})
+		respondListError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, bookings)
+	respondPage(c, page, bookings)
 }
 
 func (ctrl *BookingController) CreateBooking(c *gin.Context) {
 	var payload CreateBookingRequest
-
-	bodyBytes, _ := ioutil.ReadAll(c.Request.Body)
-	if len(bodyBytes) > 0 {
-		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
-		log.Printf("Incoming CreateBooking body: %s", string(bodyBytes))
-	} else {
-		log.Printf("Incoming request: %#v", c.Request)
-	}
-
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		log.Printf("Validation error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+	if !bindJSON(c, &payload) {
 		return
 	}
+	for _, ch := range payload.NotificationChannels {
+		if !services.IsKnownChannel(strings.ToLower(strings.TrimSpace(ch))) {
+			respondValidationError(c, fieldError("notification_channels", "oneof", "email, sms, line"))
+			return
+		}
+	}
 
 	var roomIDs []uint
 	if payload.RoomID != 0 {
@@ -557,8 +510,7 @@ func (ctrl *BookingController) CreateBooking(c *gin.Context) {
 	roomIDs = deduped
 
 	if len(roomIDs) == 0 {
-		log.Printf("CreateBooking: no room id provided in payload")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "room_id, room_ids or rooms is required"})
+		respondValidationError(c, requiredOneOf("room_id", "room_ids", "rooms"))
 		return
 	}
 
@@ -566,18 +518,10 @@ func (ctrl *BookingController) CreateBooking(c *gin.Context) {
 		var r models.Room
 		if err := config.DB.First(&r, rid).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				log.Printf("CreateBooking: room_id not found: %d", rid)
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error":   "room_id not found",
-					"details": gin.H{"room_id": rid},
-				})
+				respondValidationError(c, fieldError("room_id", "exists", strconv.FormatUint(uint64(rid), 10)))
 				return
 			}
-			log.Printf("CreateBooking: DB error checking room %d: %v", rid, err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "internal error",
-				"details": err.Error(),
-			})
+			middleware.Abort(c, services.ErrInternal.Wrapf("check room %d: %w", rid, err))
 			return
 		}
 	}
@@ -589,25 +533,38 @@ func (ctrl *BookingController) CreateBooking(c *gin.Context) {
 		roomIDs,
 		payload.Adults,
 		payload.Children,
-		payload.GuestList,
+		payload.accompanyingGuests(),
+		payload.NotificationChannels,
 		payload.SendEmail,
 	)
 
-	if err != nil {
-		log.Printf("Service error creating booking: %v", err)
-		if strings.Contains(err.Error(), "validation") || strings.Contains(err.Error(), "invalid") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create booking", "details": err.Error()})
-			return
+	if err != nil && booking.ID != 0 {
+		// booking ถูกสร้างแล้ว แต่เริ่มเช็คอิน/ส่งลิงก์ไม่สำเร็จ -> ไม่ถือว่าสร้าง booking ล้มเหลว
+		log.Printf("CreateBooking: booking %d created, check-in initiation failed: %v", booking.ID, err)
+		code := "error.checkinInitiationFailed"
+		switch {
+		case errors.Is(err, services.ErrNotificationSendFailed):
+			code = "error.notificationSendFailed"
+		case errors.Is(err, services.ErrNotificationNotSent):
+			code = "error.notificationNotSent"
 		}
+		c.JSON(http.StatusCreated, gin.H{
+			"message": middleware.Message(c, utils.MsgBookingCreated),
+			"data":    booking,
+			"warning": gin.H{"code": code},
+		})
+		return
+	}
+
+	if err != nil {
 		if isForeignKeyError(err) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "foreign key constraint", "details": err.Error()})
-			return
+			err = services.ErrInvalidBooking.Wrap(err)
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking", "details": err.Error()})
+		middleware.Abort(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Booking created successfully", "data": booking})
+	c.JSON(http.StatusCreated, gin.H{"message": middleware.Message(c, utils.MsgBookingCreated), "data": booking})
 }
 
 func (ctrl *BookingController) DeleteBooking(c *gin.Context) {
@@ -619,15 +576,14 @@ func (ctrl *BookingController) DeleteBooking(c *gin.Context) {
 
 	if err := ctrl.BookingSvc.DeleteByStringID(idStr); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": "error.bookingNotFound", "message": "ไม่พบการจองที่ต้องการลบ"}})
+			middleware.Abort(c, services.ErrBookingNotFound)
 			return
 		}
-		log.Printf("DeleteBooking error: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": "error.deleteBookingFailed", "message": "ไม่สามารถลบการจองได้"}})
+		middleware.Abort(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "ลบการจองเรียบร้อยแล้ว"})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": middleware.Message(c, utils.MsgBookingDeleted)})
 }
 
 // ---------------------------
@@ -658,15 +614,16 @@ func (ctrl *BookingController) GetBookingDetails(c *gin.Context) {
 
 	if queryErr != nil {
 		if errors.Is(queryErr, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": "error.bookingNotFound", "message": "ไม่พบการจอง"}})
+			middleware.Abort(c, services.ErrBookingNotFound)
 			return
 		}
-		log.Printf("GetBookingDetails DB error: %v", queryErr)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": gin.H{"code": "error.fetchBookingFailed", "message": "ไม่สามารถดึงข้อมูลการจองได้"}})
+		middleware.Abort(c, services.ErrInternal.Wrap(queryErr))
 		return
 	}
 
+	// rooms = ห้องที่ใช้อยู่, movedRooms = segment ที่ย้ายออกไปแล้ว (ประวัติการย้ายห้อง)
 	rooms := make([]map[string]interface{}, 0, len(booking.Rooms))
+	movedRooms := []map[string]interface{}{}
 	for _, br := range booking.Rooms {
 		num := ""
 		rtype := ""
@@ -682,14 +639,23 @@ func (ctrl *BookingController) GetBookingDetails(c *gin.Context) {
 			desc = strings.TrimSpace(br.Room.Description)
 		}
 
-		rooms = append(rooms, map[string]interface{}{
+		entry := map[string]interface{}{
 			"bookingInfoId": br.ID,
 			"roomNumber":    num,
 			"roomType":      rtype,
 			"roomDetails": map[string]interface{}{
 				"description": desc,
 			},
-		})
+			// ย้ายห้องระหว่างพัก: startDate / endDate = ช่วงที่พักห้องนี้ (nil = ตามวันเข้า / ออกของ booking)
+			"status":    br.Status,
+			"startDate": br.StartDate,
+			"endDate":   br.EndDate,
+		}
+		if br.Status == services.BookingRoomStatusMoved {
+			movedRooms = append(movedRooms, entry)
+			continue
+		}
+		rooms = append(rooms, entry)
 	}
 
 	nights := calculateNights(booking.CheckIn, booking.CheckOut)
@@ -706,6 +672,7 @@ func (ctrl *BookingController) GetBookingDetails(c *gin.Context) {
 		"roomType":     "",
 		"roomNumber":   "",
 		"rooms":        rooms,
+		"movedRooms":   movedRooms,
 
 		"accompanyingGuests": accompanyingGuests,
 		"adults":             booking.Adults,
@@ -722,11 +689,8 @@ func isForeignKeyError(err error) bool {
 	if err == nil {
 		return false
 	}
-	if merr, ok := err.(*mysql.MySQLError); ok {
-		return merr.Number == 1452
-	}
-	lower := strings.ToLower(err.Error())
-	return strings.Contains(lower, "foreign key") || strings.Contains(lower, "1452")
+	var merr *mysql.MySQLError
+	return errors.As(err, &merr) && merr.Number == 1452
 }
 
 // ---------------------------
@@ -741,40 +705,105 @@ func (ctrl *BookingController) CheckoutBooking(c *gin.Context) {
 
 	bookingID, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"code":    "error.invalidBookingId",
-				"message": "bookingId ไม่ถูกต้อง",
-			},
-		})
+		middleware.Abort(c, services.ErrInvalidBookingID)
 		return
 	}
 
 	if err := ctrl.BookingSvc.CheckoutBooking(uint(bookingID)); err != nil {
-		log.Printf("CheckoutBooking error: %v", err)
+		middleware.Abort(c, err)
+		return
+	}
 
-		if strings.Contains(err.Error(), "not_checked_in") {
-			c.JSON(http.StatusConflict, gin.H{
-				"error": gin.H{
-					"code":    "error.notCheckedIn",
-					"message": "ไม่สามารถ checkout ได้ เนื่องจากยังไม่ check-in",
-				},
-			})
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": middleware.Message(c, utils.MsgBookingCheckedOut),
+	})
+}
+
+// ---------------------------
+// 7) Notification channels (per booking)
+// ---------------------------
+func (ctrl *BookingController) UpdateNotificationChannels(c *gin.Context) {
+	bookingID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookingID == 0 {
+		middleware.Abort(c, services.ErrInvalidBookingID)
+		return
+	}
+
+	var payload NotificationChannelsPayload
+	if !bindJSON(c, &payload) {
+		return
+	}
+	for _, ch := range payload.Channels {
+		if !services.IsKnownChannel(strings.ToLower(strings.TrimSpace(ch))) {
+			middleware.Abort(c, services.ErrInvalidChannel.WithDetails(ch))
 			return
 		}
+	}
 
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"code":    "error.checkoutFailed",
-				"message": "Checkout ไม่สำเร็จ",
-				"details": err.Error(),
-			},
-		})
+	channels, err := ctrl.BookingSvc.UpdateNotificationChannels(uint(bookingID), payload.Channels)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			middleware.Abort(c, services.ErrBookingNotFound)
+			return
+		}
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "success",
-		"message": "Checkout สำเร็จ",
-	})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"bookingId": bookingID, "channels": channels}})
+}
+
+// ---------------------------
+// 8) Room move (ย้ายห้องระหว่างพัก)
+// ---------------------------
+
+// RoomMoveRequest body ของ POST /api/bookings/:id/rooms/:bookingRoomId/move
+// date = คืนแรกที่พักห้องใหม่ (ไม่ส่ง = วันนี้)
+type RoomMoveRequest struct {
+	ToRoomID uint    `json:"toRoomId" alias:"to_room_id" binding:"required,gt=0"`
+	Date     *string `json:"date" binding:"omitempty,dateonly"`
+	Reason   string  `json:"reason" binding:"required,max=255"`
+}
+
+// POST /api/bookings/:id/rooms/:bookingRoomId/move {toRoomId, date?, reason}
+func (ctrl *BookingController) MoveRoom(c *gin.Context) {
+	bookingID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || bookingID == 0 {
+		middleware.Abort(c, services.ErrInvalidBookingID)
+		return
+	}
+	bookingRoomID, err := strconv.ParseUint(c.Param("bookingRoomId"), 10, 64)
+	if err != nil || bookingRoomID == 0 {
+		middleware.Abort(c, services.ErrInvalidID)
+		return
+	}
+	var req RoomMoveRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		respondValidationError(c, fieldError("reason", "required", ""))
+		return
+	}
+	in := services.RoomMoveInput{ToRoomID: req.ToRoomID, Reason: req.Reason}
+	if req.Date != nil {
+		if d, ok := parseDateOnly(*req.Date); ok {
+			in.Date = &d
+		}
+	}
+
+	res, err := ctrl.BookingSvc.MoveRoom(uint(bookingID), uint(bookingRoomID), in, middleware.CurrentActor(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRoomNotFound):
+			respondValidationError(c, fieldError("toRoomId", "exists", ""))
+		case errors.Is(err, services.ErrRoomMoveSameRoom):
+			respondValidationError(c, fieldError("toRoomId", "invalid", ""))
+		default:
+			middleware.Abort(c, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": res})
 }
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"hotel-backend/middleware"
+	"hotel-backend/models"
+	"hotel-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFile ขนาดไฟล์ import สูงสุด (10 MB)
+const maxImportFile = 10 << 20
+
+type BookingImportController struct {
+	ImportSvc *services.BookingImportService
+}
+
+func NewBookingImportController(svc *services.BookingImportService) *BookingImportController {
+	return &BookingImportController{ImportSvc: svc}
+}
+
+// POST /api/bookings/imports (multipart)
+//
+//	file:      .csv หรือ .xlsx
+//	source:    booking.com | agoda | spreadsheet (default)
+//	dateOrder: dmy (default) | mdy — ใช้กับวันที่แบบ 01/02/2025
+//	mapping:   JSON {"checkIn": "Arrival", ...} (ไม่ส่ง = เดาจากหัวคอลัมน์)
+//
+// dry run: ตรวจทุกแถวแล้วเก็บเป็น preview ยังไม่สร้าง booking (commit ด้วย POST /imports/:id/commit)
+func (ctrl *BookingImportController) Preview(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		middleware.Abort(c, services.ErrMissingFile.WithDetails(gin.H{"field": "file"}))
+		return
+	}
+	defer file.Close()
+	if header.Size > maxImportFile {
+		respondValidationError(c, fieldError("file", "max", strconv.Itoa(maxImportFile>>20)+" MB"))
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFile+1))
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrapf("read import file: %w", err))
+		return
+	}
+
+	up := services.ImportUpload{
+		FileName:  header.Filename,
+		Data:      data,
+		Source:    strings.ToLower(strings.TrimSpace(c.PostForm("source"))),
+		DateOrder: strings.ToLower(strings.TrimSpace(c.PostForm("dateOrder"))),
+	}
+	if up.Source != "" && !containsString(services.ImportSources, up.Source) {
+		respondValidationError(c, fieldError("source", "oneof", strings.Join(services.ImportSources, " ")))
+		return
+	}
+	if up.DateOrder != "" && up.DateOrder != "dmy" && up.DateOrder != "mdy" {
+		respondValidationError(c, fieldError("dateOrder", "oneof", "dmy mdy"))
+		return
+	}
+	if raw := strings.TrimSpace(c.PostForm("mapping")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &up.Mapping); err != nil {
+			respondValidationError(c, fieldError("mapping", "json", ""))
+			return
+		}
+	}
+
+	imp, err := ctrl.ImportSvc.Preview(up, middleware.CurrentActor(c))
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": localizeImport(c, imp)})
+}
+
+// GET /api/bookings/imports/:id → ผลตรวจ / ผล import
+func (ctrl *BookingImportController) Get(c *gin.Context) {
+	id, ok := importIDParam(c)
+	if !ok {
+		return
+	}
+	imp, err := ctrl.ImportSvc.Get(id)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": localizeImport(c, imp)})
+}
+
+// POST /api/bookings/imports/:id/commit → สร้าง booking ทั้งไฟล์ (ตรวจห้องว่างซ้ำอีกรอบ)
+// มีแถวที่ error = 422 และไม่สร้างอะไรเลย (details.import มีผลตรวจล่าสุด)
+func (ctrl *BookingImportController) Commit(c *gin.Context) {
+	id, ok := importIDParam(c)
+	if !ok {
+		return
+	}
+	imp, err := ctrl.ImportSvc.Commit(id, middleware.CurrentActor(c))
+	if err != nil {
+		if serr, ok := err.(*services.Error); ok && serr.Is(services.ErrImportHasErrors) {
+			err = serr.WithDetails(gin.H{"import": localizeImport(c, imp)})
+		}
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": localizeImport(c, imp)})
+}
+
+// localizeImport เติมข้อความของแต่ละ issue ตามภาษาของ request
+func localizeImport(c *gin.Context, imp models.BookingImport) models.BookingImport {
+	results := make([]models.BookingImportResult, len(imp.Results))
+	for i, r := range imp.Results {
+		issues := make([]models.ImportIssue, len(r.Issues))
+		for j, is := range r.Issues {
+			is.Message = middleware.Message(c, "validation."+is.Rule, "param", is.Param)
+			issues[j] = is
+		}
+		r.Issues = issues
+		results[i] = r
+	}
+	imp.Results = results
+	return imp
+}
+
+func importIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		middleware.Abort(c, services.ErrInvalidID)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
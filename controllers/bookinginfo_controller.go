@@ -2,14 +2,13 @@ package controllers
 
 import (
 	"errors"
-	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
-	"hotel-backend/config"
+	"hotel-backend/middleware"
 	"hotel-backend/models"
 	"hotel-backend/services"
 	"hotel-backend/utils"
@@ -33,12 +32,11 @@ func NewBookingInfoController(svc *services.BookingInfoService) *BookingInfoCont
 func (ctrl *BookingInfoController) SaveBookingInfo(c *gin.Context) {
 	var bi models.BookingInfo
 	if err := c.ShouldBindJSON(&bi); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
 		return
 	}
 	if err := ctrl.InfoSvc.SaveBookingInfo(bi); err != nil {
-		log.Printf("SaveBookingInfo DB error: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save booking info"})
+		middleware.Abort(c, services.ErrInternal.Wrapf("save booking info: %w", err))
 		return
 	}
 	c.JSON(http.StatusCreated, bi)
@@ -48,22 +46,21 @@ func (ctrl *BookingInfoController) SaveBookingInfo(c *gin.Context) {
 func (ctrl *BookingInfoController) GetBookingInfoByID(c *gin.Context) {
 	idStr := c.Param("id")
 	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id"})
+		middleware.Abort(c, services.ErrInvalidID)
 		return
 	}
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		middleware.Abort(c, services.ErrInvalidID)
 		return
 	}
 	bi, err := ctrl.InfoSvc.GetByID(uint(id))
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			middleware.Abort(c, services.ErrBookingInfoNotFound)
 			return
 		}
-		log.Printf("GetBookingInfoByID DB error: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve booking info"})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 	c.JSON(http.StatusOK, bi)
@@ -71,9 +68,7 @@ func (ctrl *BookingInfoController) GetBookingInfoByID(c *gin.Context) {
 
 // DeleteBookingInfo deletes a BookingInfo by ID
 func (ctrl *BookingInfoController) DeleteBookingInfo(c *gin.Context) {
-	c.JSON(http.StatusForbidden, gin.H{
-		"error": "booking_info deletion is disabled",
-	})
+	middleware.Abort(c, services.ErrBookingInfoDeleteBlocked)
 }
 
 // ------------------------------
@@ -85,23 +80,27 @@ func (ctrl *BookingInfoController) DeleteBookingInfo(c *gin.Context) {
 func (ctrl *BookingInfoController) ValidateCheckinCode(c *gin.Context) {
 	var req struct {
 		CheckinCode string `json:"checkinCode"`
-		Query       string `json:"query"` // optional
+		Query       string `json:"query"` // lastName หรือ bookingRef (required)
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
 		return
 	}
 
 	codeRaw := strings.TrimSpace(req.CheckinCode)
 	if codeRaw == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "checkinCode required"})
+		respondValidationError(c, fieldError("checkinCode", "required", ""))
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		respondValidationError(c, fieldError("query", "required", ""))
 		return
 	}
 
 	norm := utils.NormalizeCheckinCode(codeRaw)
 	if len(norm) != 8 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid checkin code format"})
+		middleware.Abort(c, services.ErrInvalidCheckinCodeFormat)
 		return
 	}
 	formatted := strings.ToUpper(norm[:4] + "-" + norm[4:])
@@ -114,7 +113,13 @@ func (ctrl *BookingInfoController) ValidateCheckinCode(c *gin.Context) {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			log.Printf("ValidateCheckinCode error: %v", err)
 		}
-		c.JSON(http.StatusNotFound, gin.H{"error": "confirmation code not found"})
+		middleware.Abort(c, services.ErrCheckinCodeNotFound)
+		return
+	}
+
+	// code ถูกแต่ชื่อ/หมายเลขการจองไม่ตรง -> ตอบเหมือนไม่พบ (ไม่บอกว่า code มีอยู่จริง)
+	if !ctrl.InfoSvc.MatchesQuery(bi, req.Query) {
+		middleware.Abort(c, services.ErrCheckinCodeNotFound)
 		return
 	}
 
@@ -125,18 +130,13 @@ func (ctrl *BookingInfoController) ValidateCheckinCode(c *gin.Context) {
 		} else {
 			expiresAt = nil
 		}
-		c.JSON(http.StatusGone, gin.H{
-			"error":         "confirmation code expired",
+		middleware.Abort(c, services.ErrCheckinCodeExpired.WithDetails(gin.H{
 			"bookingInfoId": bi.ID,
 			"expiresAt":     expiresAt,
-		})
+		}))
 		return
 	}
 
-	// Optionally validate query (last name or booking reference) if provided
-	// Current implementation returns success if code is valid and not expired.
-	// If you want to enforce query matching, implement additional checks here.
-
 	c.JSON(http.StatusOK, gin.H{
 		"status":        "ok",
 		"bookingInfoId": bi.ID,
@@ -147,71 +147,47 @@ func (ctrl *BookingInfoController) ValidateCheckinCode(c *gin.Context) {
 }
 
 // ResendCheckinCode (POST /api/checkin/resend)
-// Body: { "bookingInfoId": 10 } OR { "checkinCode": "AWLI-TEJN" }
+// Body: { "checkinCode": "AWLI-TEJN" }
+// ตอบเหมือนกันทุกกรณี (มี / ไม่มี code) ไม่ให้ใช้เดาว่า code ไหนมีอยู่จริง
 func (ctrl *BookingInfoController) ResendCheckinCode(c *gin.Context) {
 	var req struct {
-		BookingInfoId uint   `json:"bookingInfoId"`
-		CheckinCode   string `json:"checkinCode"`
-	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
-		return
-	}
-
-	var bi models.BookingInfo
-	var err error
-
-	if req.BookingInfoId > 0 {
-		bi, err = ctrl.InfoSvc.GetByID(req.BookingInfoId)
-	} else if strings.TrimSpace(req.CheckinCode) != "" {
-		norm := utils.NormalizeCheckinCode(req.CheckinCode)
-		if len(norm) != 8 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid checkin code format"})
-			return
-		}
-		formatted := strings.ToUpper(norm[:4] + "-" + norm[4:])
-		noDash := strings.ToUpper(norm)
-		bi, _, err = ctrl.InfoSvc.FindByCodeWithExpiry(formatted, noDash)
-	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing bookingInfoId or checkinCode"})
-		return
+		CheckinCode string `json:"checkinCode" binding:"required"`
 	}
-
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "booking info not found"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	newExpiry, err := ctrl.InfoSvc.ExtendExpiry(bi.ID, 15) // extend by 15 minutes
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to extend expiry"})
+	norm := utils.NormalizeCheckinCode(req.CheckinCode)
+	if len(norm) != 8 {
+		middleware.Abort(c, services.ErrInvalidCheckinCodeFormat)
 		return
 	}
+	formatted := strings.ToUpper(norm[:4] + "-" + norm[4:])
+	noDash := strings.ToUpper(norm)
 
-	// async resend email (best-effort)
-	go func(b models.BookingInfo) {
-		// build checkin link
-		link := utils.BuildCheckinLink(utils.EnvOrDefault("FRONTEND_URL", "http://localhost:3000"), b.Token, true)
-
-		// Use helper to gather rooms & send email (silently ignore error)
-		if err := ctrl.sendCheckInEmail(
-			b.GuestEmail,
-			"", // bookingRef optional - helper will load booking and use reference if present
-			link,
-			b.GuestLastName,
-			b.BookingID,
-			"", // checkInDate (unknown here)
-			"", // checkOutDate
-			b.CheckinCode,
-		); err != nil {
-			log.Printf("ResendCheckinCode: send email failed for bookingInfo %d: %v", b.ID, err)
+	bi, _, err := ctrl.InfoSvc.FindByCodeWithExpiry(formatted, noDash)
+	switch {
+	case err != nil:
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("ResendCheckinCode error: %v", err)
+		}
+	case !ctrl.InfoSvc.SessionOpen(bi):
+		// session หมดอายุ / เสร็จแล้ว: ไม่ต่ออายุ code
+	default:
+		if _, err := ctrl.InfoSvc.ExtendExpiry(bi.ID, 15); err != nil { // extend by 15 minutes
+			log.Printf("ResendCheckinCode: extend expiry for bookingInfo %d failed: %v", bi.ID, err)
+			break
 		}
-	}(bi)
+		// async resend via booking's channels (best-effort)
+		go func(b models.BookingInfo) {
+			if _, err := ctrl.InfoSvc.ResendCheckInInvite(b); err != nil {
+				log.Printf("ResendCheckinCode: resend failed for bookingInfo %d: %v", b.ID, err)
+			}
+		}(bi)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":       "code resent",
-		"bookingInfoId": bi.ID,
-		"expiresAt":     newExpiry.UTC().Format(time.RFC3339),
+		"message": middleware.Message(c, utils.MsgCheckinCodeResent),
 	})
 }
 
@@ -222,8 +198,7 @@ func (ctrl *BookingInfoController) InitiateCheckIn(c *gin.Context) {
 		BookingID uint `json:"bookingId" binding:"required"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -231,131 +206,113 @@ func (ctrl *BookingInfoController) InitiateCheckIn(c *gin.Context) {
 	bookingInfo, err := ctrl.InfoSvc.InitiateCheckIn(req.BookingID)
 	if err != nil {
 		log.Printf("InitiateCheckIn error: %v", err)
-		switch {
-		case strings.Contains(err.Error(), "booking_not_found"):
-			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
-		case strings.Contains(err.Error(), "already_checked_in"):
-			c.JSON(http.StatusConflict, gin.H{"error": "already checked in"})
-		case strings.Contains(err.Error(), "email_send_failed"):
-			c.JSON(http.StatusPartialContent, gin.H{
-				"status": "warning",
-				"data": gin.H{
-					"id":           bookingInfo.ID,
-					"token":        bookingInfo.Token,
-					"checkin_code": bookingInfo.CheckinCode,
-				},
-				"error": "email send failed",
-			})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
-		}
+		respondInitiateCheckInError(c, bookingInfo, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"status": "success",
-		"data": gin.H{
-			"id":           bookingInfo.ID,
-			"token":        bookingInfo.Token,
-			"checkin_code": bookingInfo.CheckinCode,
-		},
-	})
+	respondInitiateCheckInOK(c, bookingInfo)
 }
 
-// -----------------------------
-// Helper: load rooms for booking and send email
-// -----------------------------
-// -----------------------------
-// Helper: load rooms for booking and send email
-// -----------------------------
-func (ctrl *BookingInfoController) sendCheckInEmail(
-	recipientEmail string,
-	bookingRef string,
-	checkinLink string,
-	guestName string,
-	bookingID uint,
-	checkInDate string,
-	checkOutDate string,
-	confirmationCode string,
-) error {
-	// Load booking (we only need reference and maybe legacy Room)
-	var booking models.Booking
-	if err := config.DB.
-		Preload("Room"). // legacy single-room relation if present
-		Where("id = ?", bookingID).
-		First(&booking).Error; err != nil {
+// ------------------------------
+// Checkin: QR code
+// ------------------------------
 
-		// If booking not found, still attempt to send email with empty rooms slice
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Printf("sendCheckInEmail: booking %d not found: %v", bookingID, err)
-			return utils.SendCheckInLinkEmail(
-				recipientEmail,
-				bookingRef,
-				checkinLink,
-				guestName,
-				[]utils.RoomInfo{},
-				checkInDate,
-				checkOutDate,
-				confirmationCode,
-			)
+// GetCheckinQR (GET /api/checkin/qr?token=...&format=png|svg&size=256)
+// QR ของลิงก์เช็คอิน สำหรับแสดงบนจอ front desk
+func (ctrl *BookingInfoController) GetCheckinQR(c *gin.Context) {
+	token := strings.TrimSpace(c.Query("token"))
+	if token == "" {
+		middleware.Abort(c, services.ErrMissingToken)
+		return
+	}
+
+	bi, expired, err := ctrl.InfoSvc.FindByTokenWithExpiry(token)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("GetCheckinQR error: %v", err)
+		}
+		middleware.Abort(c, services.ErrBookingInfoNotFound)
+		return
+	}
+	if expired {
+		middleware.Abort(c, services.ErrTokenExpired.WithDetails(gin.H{"bookingInfoId": bi.ID}))
+		return
+	}
+
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "256"))
+	link := utils.BuildCheckinLink(utils.EnvOrDefault("FRONTEND_URL", "http://localhost:3000"), bi.Token, true)
+
+	c.Header("Cache-Control", "no-store")
+	if strings.EqualFold(c.Query("format"), "svg") {
+		svg, err := utils.QRCodeSVG(link, size)
+		if err != nil {
+			middleware.Abort(c, services.ErrQRGenerateFailed.Wrap(err))
+			return
 		}
-		return fmt.Errorf("failed to load booking %d: %w", bookingID, err)
+		c.Data(http.StatusOK, "image/svg+xml", []byte(svg))
+		return
 	}
 
-	// If bookingRef not provided, use booking.ReferenceCode if available
-	if bookingRef == "" {
-		bookingRef = strings.TrimSpace(booking.ReferenceCode)
+	png, err := utils.QRCodePNG(link, size)
+	if err != nil {
+		middleware.Abort(c, services.ErrQRGenerateFailed.Wrap(err))
+		return
 	}
+	c.Data(http.StatusOK, "image/png", png)
+}
 
-	// Query booking_rooms directly to get all rooms for the booking.
-	// This avoids assuming a specific field name on the Booking struct.
-	var bookingRooms []models.BookingRoom
-	if err := config.DB.Preload("Room").Where("booking_id = ?", bookingID).Find(&bookingRooms).Error; err != nil {
-		// non-fatal: log and continue with fallback
-		log.Printf("sendCheckInEmail: failed to query booking_rooms for booking %d: %v", bookingID, err)
+// ScanCheckinQR (POST /api/checkin/scan)
+// Body: { "payload": "<ข้อความที่สแกนได้: ลิงก์เช็คอิน / token>" } (check-in code ต้องใช้ /validate)
+func (ctrl *BookingInfoController) ScanCheckinQR(c *gin.Context) {
+	var req struct {
+		Payload string `json:"payload" binding:"required"`
+	}
+	if !bindJSON(c, &req) {
+		return
 	}
 
-	// Build rooms slice from bookingRooms (if any)
-	rooms := []utils.RoomInfo{}
-	for _, br := range bookingRooms {
-		// Ensure Room was loaded
-		if br.Room.ID == 0 {
-			continue
+	bi, expired, err := ctrl.InfoSvc.ResolveScanPayload(req.Payload)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = services.ErrBookingInfoNotFound
 		}
+		middleware.Abort(c, err)
+		return
+	}
+	if expired {
+		middleware.Abort(c, services.ErrTokenExpired.WithDetails(gin.H{"bookingInfoId": bi.ID}))
+		return
+	}
+
+	var booking models.Booking
+	if err := ctrl.InfoSvc.DB.Preload("Customer").Preload("Rooms.Room").First(&booking, bi.BookingID).Error; err != nil {
+		log.Printf("ScanCheckinQR: load booking %d failed: %v", bi.BookingID, err)
+	}
+	rooms := []string{}
+	for _, br := range services.CurrentBookingRooms(booking.Rooms) {
 		num := strings.TrimSpace(br.Room.RoomCode)
 		if num == "" {
 			num = strings.TrimSpace(br.Room.RoomNumber)
 		}
-		rooms = append(rooms, utils.RoomInfo{
-			Number: num,
-			Type:   strings.TrimSpace(br.Room.Type),
-		})
-	}
-
-	// Fallback: if no booking_rooms rows, try booking.Room (single-room legacy)
-	if len(rooms) == 0 && booking.Room.ID != 0 {
-		num := strings.TrimSpace(booking.Room.RoomCode)
-		if num == "" {
-			num = strings.TrimSpace(booking.Room.RoomNumber)
+		if num != "" {
+			rooms = append(rooms, num)
 		}
-		rooms = append(rooms, utils.RoomInfo{
-			Number: num,
-			Type:   strings.TrimSpace(booking.Room.Type),
-		})
 	}
 
-	// Finally call the utils email sender
-	if err := utils.SendCheckInLinkEmail(
-		recipientEmail,
-		bookingRef,
-		checkinLink,
-		guestName,
-		rooms,
-		checkInDate,
-		checkOutDate,
-		confirmationCode,
-	); err != nil {
-		return fmt.Errorf("failed to send checkin email: %w", err)
-	}
-	return nil
+	c.JSON(http.StatusOK, gin.H{
+		"status":        "ok",
+		"bookingInfoId": bi.ID,
+		"bookingId":     bi.BookingID,
+		"checkinCode":   bi.CheckinCode,
+		"token":         bi.Token,
+		"checkinStatus": bi.Status,
+		"booking": gin.H{
+			"referenceCode": booking.ReferenceCode,
+			"guestName":     booking.Customer.FullName,
+			"status":        booking.Status,
+			"checkIn":       booking.CheckIn,
+			"checkOut":      booking.CheckOut,
+			"rooms":         rooms,
+		},
+	})
 }
@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+	"hotel-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChannelManagerController struct {
+	ChannelSvc *services.ChannelManagerService
+}
+
+func NewChannelManagerController(svc *services.ChannelManagerService) *ChannelManagerController {
+	return &ChannelManagerController{ChannelSvc: svc}
+}
+
+// ChannelMappingRequest body ของ POST / PATCH /api/channel-manager/mappings
+// rate: ไม่ส่ง / 0 = ใช้ราคาต่ำสุดของห้องในประเภทนั้น
+type ChannelMappingRequest struct {
+	Channel    *string  `json:"channel" binding:"omitempty,min=1,max=32"`
+	RoomCode   *string  `json:"roomCode" alias:"room_code" binding:"omitempty,min=1,max=64"`
+	RoomTypeID *uint    `json:"roomTypeId" alias:"room_type_id" binding:"omitempty,gt=0"`
+	Rate       *float64 `json:"rate" binding:"omitempty,gte=0"`
+	Active     *bool    `json:"active"`
+}
+
+func (r ChannelMappingRequest) input() services.ChannelMappingInput {
+	return services.ChannelMappingInput{
+		Channel:    r.Channel,
+		RoomCode:   r.RoomCode,
+		RoomTypeID: r.RoomTypeID,
+		Rate:       r.Rate,
+		Active:     r.Active,
+	}
+}
+
+// bindChannelMapping อ่าน body (create ต้องมี channel, roomCode, roomTypeId)
+func bindChannelMapping(c *gin.Context, create bool) (ChannelMappingRequest, bool) {
+	var req ChannelMappingRequest
+	if !bindJSON(c, &req) {
+		return req, false
+	}
+	if create {
+		for _, f := range []struct {
+			name  string
+			empty bool
+		}{
+			{"channel", req.Channel == nil || strings.TrimSpace(*req.Channel) == ""},
+			{"roomCode", req.RoomCode == nil || strings.TrimSpace(*req.RoomCode) == ""},
+			{"roomTypeId", req.RoomTypeID == nil},
+		} {
+			if f.empty {
+				respondValidationError(c, fieldError(f.name, "required", ""))
+				return req, false
+			}
+		}
+	}
+	return req, true
+}
+
+// respondMappingError roomTypeId ที่ไม่มีอยู่ตอบเป็น validation error ของ field
+func respondMappingError(c *gin.Context, req ChannelMappingRequest, err error) {
+	if errors.Is(err, services.ErrRoomTypeNotFound) && req.RoomTypeID != nil {
+		respondValidationError(c, fieldError("roomTypeId", "exists", strconv.FormatUint(uint64(*req.RoomTypeID), 10)))
+		return
+	}
+	middleware.Abort(c, err)
+}
+
+// GET /api/channel-manager/mappings?channel=agoda
+func (ctrl *ChannelManagerController) GetMappings(c *gin.Context) {
+	mappings, err := ctrl.ChannelSvc.ListMappings(c.Query("channel"))
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": mappings, "enabled": ctrl.ChannelSvc.Enabled()})
+}
+
+// POST /api/channel-manager/mappings {channel, roomCode, roomTypeId, rate?, active?}
+func (ctrl *ChannelManagerController) CreateMapping(c *gin.Context) {
+	req, ok := bindChannelMapping(c, true)
+	if !ok {
+		return
+	}
+	m, err := ctrl.ChannelSvc.CreateMapping(req.input(), middleware.CurrentActor(c))
+	if err != nil {
+		respondMappingError(c, req, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": m})
+}
+
+// PATCH /api/channel-manager/mappings/:id
+func (ctrl *ChannelManagerController) UpdateMapping(c *gin.Context) {
+	id, ok := mappingIDParam(c)
+	if !ok {
+		return
+	}
+	req, ok := bindChannelMapping(c, false)
+	if !ok {
+		return
+	}
+	m, err := ctrl.ChannelSvc.UpdateMapping(id, req.input(), middleware.CurrentActor(c))
+	if err != nil {
+		respondMappingError(c, req, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": m})
+}
+
+// DELETE /api/channel-manager/mappings/:id
+func (ctrl *ChannelManagerController) DeleteMapping(c *gin.Context) {
+	id, ok := mappingIDParam(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.ChannelSvc.DeleteMapping(id, middleware.CurrentActor(c)); err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": middleware.Message(c, utils.MsgChannelMappingDeleted)})
+}
+
+// GET /api/channel-manager/logs?operation=reservations&status=FAILED
+func (ctrl *ChannelManagerController) GetLogs(c *gin.Context) {
+	q, ok := bindListQuery(c, services.ChannelSyncLogListSpec)
+	if !ok {
+		return
+	}
+	logs, page, err := ctrl.ChannelSvc.ListLogs(q)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	respondPage(c, page, gin.H{"status": "success", "data": logs})
+}
+
+// POST /api/channel-manager/sync                        -> ดึงการจอง + push ห้องว่าง + ราคา
+// POST /api/channel-manager/sync?operation=availability -> operation เดียว
+func (ctrl *ChannelManagerController) SyncNow(c *gin.Context) {
+	op := strings.TrimSpace(c.Query("operation"))
+	if op == "" {
+		logs, err := ctrl.ChannelSvc.SyncAll("manual")
+		if err != nil {
+			middleware.Abort(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "data": logs})
+		return
+	}
+	entry, err := ctrl.ChannelSvc.Sync(op, "manual")
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": []interface{}{entry}})
+}
+
+func mappingIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		middleware.Abort(c, services.ErrInvalidID)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ---------------- dev stub ----------------
+// channel manager จำลอง (APP_ENV=development) ตั้ง CHANNEL_MANAGER_URL=http://localhost:8080/api/dev/channel-manager
+
+// POST /api/dev/channel-manager/availability
+func DevChannelAvailability(c *gin.Context) {
+	var body struct {
+		Items []services.ChannelAvailability `json:"items"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
+		return
+	}
+	services.DevChannelStub.ReceiveAvailability(body.Items)
+	c.JSON(http.StatusOK, gin.H{"status": "success", "received": len(body.Items)})
+}
+
+// POST /api/dev/channel-manager/rates
+func DevChannelRates(c *gin.Context) {
+	var body struct {
+		Items []services.ChannelRate `json:"items"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
+		return
+	}
+	services.DevChannelStub.ReceiveRates(body.Items)
+	c.JSON(http.StatusOK, gin.H{"status": "success", "received": len(body.Items)})
+}
+
+// GET /api/dev/channel-manager/reservations?since=<RFC3339>
+func DevChannelReservations(c *gin.Context) {
+	var since time.Time
+	if v := c.Query("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondValidationError(c, fieldError("since", "datetime", ""))
+			return
+		}
+		since = t
+	}
+	c.JSON(http.StatusOK, gin.H{"reservations": services.DevChannelStub.Reservations(since)})
+}
+
+// POST /api/dev/channel-manager/reservations → จำลอง OTA ส่งการจองใหม่ / แก้ / ยกเลิก
+func DevChannelAddReservation(c *gin.Context) {
+	var r services.ChannelReservation
+	if err := c.ShouldBindJSON(&r); err != nil {
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": services.DevChannelStub.AddReservation(r)})
+}
+
+// GET /api/dev/channel-manager/state → ห้องว่าง / ราคาที่ถูก push มาล่าสุด
+func DevChannelState(c *gin.Context) {
+	availability, rates := services.DevChannelStub.State()
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"availability": availability, "rates": rates}})
+}
+
+// DELETE /api/dev/channel-manager
+func DevChannelReset(c *gin.Context) {
+	services.DevChannelStub.Reset()
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
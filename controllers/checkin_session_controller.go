@@ -0,0 +1,105 @@
+// controllers/checkin_session_controller.go
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+	"hotel-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ---------------------------
+// Self-service check-in session (draft ที่บันทึกทีละขั้น)
+// ---------------------------
+
+type SaveCheckInStepPayload struct {
+	Token string          `json:"token"`
+	Step  string          `json:"step" binding:"required"`
+	Data  json.RawMessage `json:"data" binding:"required"`
+}
+
+type SubmitCheckInSessionPayload struct {
+	Token string `json:"token"`
+}
+
+// checkinToken: token จาก body → ?token= → Authorization: Bearer
+func checkinToken(c *gin.Context, bodyToken string) string {
+	if t := strings.TrimSpace(bodyToken); t != "" {
+		return t
+	}
+	if t := strings.TrimSpace(c.Query("token")); t != "" {
+		return t
+	}
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) == 2 && strings.EqualFold(parts[0], "Bearer") {
+		return strings.TrimSpace(parts[1])
+	}
+	return ""
+}
+
+// GET /api/checkin/session?token=
+func (ctrl *BookingController) GetCheckInSession(c *gin.Context) {
+	token := checkinToken(c, "")
+	if token == "" {
+		middleware.Abort(c, services.ErrMissingToken)
+		return
+	}
+
+	state, err := ctrl.BookingSvc.Drafts.Get(token)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": state})
+}
+
+// PUT /api/checkin/session { token, step, data }
+func (ctrl *BookingController) SaveCheckInSessionStep(c *gin.Context) {
+	var payload SaveCheckInStepPayload
+	if !bindJSON(c, &payload) {
+		return
+	}
+	token := checkinToken(c, payload.Token)
+	if token == "" {
+		middleware.Abort(c, services.ErrMissingToken)
+		return
+	}
+
+	state, err := ctrl.BookingSvc.Drafts.SaveStep(token, payload.Step, payload.Data)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": state})
+}
+
+// POST /api/checkin/session/submit { token }
+func (ctrl *BookingController) SubmitCheckInSession(c *gin.Context) {
+	var payload SubmitCheckInSessionPayload
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &payload) {
+			return
+		}
+	}
+	token := checkinToken(c, payload.Token)
+	if token == "" {
+		middleware.Abort(c, services.ErrMissingToken)
+		return
+	}
+
+	state, err := ctrl.BookingSvc.Drafts.Submit(token)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": middleware.Message(c, utils.MsgCheckinCompleted),
+		"data":    state,
+	})
+}
@@ -1,15 +1,15 @@
 package controllers
 
 import (
-	"fmt"
-	
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
 	"hotel-backend/config"
+	"hotel-backend/middleware"
 	"hotel-backend/models"
+	"hotel-backend/services"
+	"hotel-backend/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,30 +22,38 @@ import (
 func GetConsents(c *gin.Context) {
 	var consents []models.Consent
 	if err := config.DB.Order("consent_id desc").Find(&consents).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":  "failed to load consents",
-			"detail": err.Error(),
-		})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 	c.JSON(http.StatusOK, consents)
 }
 
-// POST /api/consents
+// CreateConsentRequest: POST /api/consents
+type CreateConsentRequest struct {
+	Title       string `json:"title" binding:"required,max=255"`
+	Slug        string `json:"slug" binding:"required,max=100"`
+	Description string `json:"description"`
+	Version     string `json:"version" binding:"max=20"`
+}
+
+// AcceptConsentRequest: POST /api/consents/accept
+type AcceptConsentRequest struct {
+	GuestID   uint       `json:"guestId" alias:"guest_id" binding:"required,gt=0"`
+	BookingID BookingRef `json:"bookingId" alias:"booking_id"` // ✅ ไม่ required (id หรือ token)
+	ConsentID uint       `json:"consentId" alias:"consent_id" binding:"required,gt=0"`
+	Action    string     `json:"action,omitempty" binding:"max=50"`
+	Accepted  bool       `json:"accepted"`
+}
+
+// ConsentRef อ้างถึง consent ด้วย id (ใช้ใน ConfirmCheckIn)
+type ConsentRef struct {
+	ID uint `json:"id" alias:"consentId,consent_id" binding:"required,gt=0"`
+}
+
 // POST /api/consents
 func CreateConsent(c *gin.Context) {
-	var req struct {
-		Title       string `json:"title" binding:"required"`
-		Slug        string `json:"slug" binding:"required"`
-		Description string `json:"description"`
-		Version     string `json:"version"`
-	}
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":  "invalid payload",
-			"detail": err.Error(),
-		})
+	var req CreateConsentRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -70,10 +78,7 @@ func CreateConsent(c *gin.Context) {
 		}
 
 		if err := config.DB.Create(&consent).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":  "failed to create consent",
-				"detail": err.Error(),
-			})
+			middleware.Abort(c, services.ErrInternal.Wrap(err))
 			return
 		}
 	}
@@ -86,106 +91,63 @@ func CreateConsent(c *gin.Context) {
 // POST /api/consents/accept
 // ------------------------------------------------------------
 func AcceptConsent(c *gin.Context) {
-    var req struct {
-    GuestID   uint        `json:"guestId" binding:"required"`
-    BookingID interface{} `json:"bookingId"` // ✅ ไม่ required
-    ConsentID uint        `json:"consentId" binding:"required"`
-    Action    string      `json:"action,omitempty"`
-    Accepted  bool        `json:"accepted"`
-}
-
-    // 1️⃣ bind request
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{
-            "error":  "invalid payload",
-            "detail": err.Error(),
-        })
-        return
-    }
-
-    // 🔴 🔴 🔴 เพิ่มตรงนี้ 🔴 🔴 🔴
-    var consent models.Consent
-    if err := config.DB.First(&consent, req.ConsentID).Error; err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{
-            "error": "invalid consentId",
-        })
-        return
-    }
-    // 🔴 🔴 🔴 จบตรงนี้ 🔴 🔴 🔴
-
-    // 2️⃣ ตรวจ bookingId
-   // bookingId อาจยังไม่รู้ → อนุญาตให้ nil
-idPtr, _ := normalizeBookingIdentifier(req.BookingID)
-    localGuestID := req.GuestID
-
-action := req.Action
-if strings.TrimSpace(action) == "" {
-    action = "accepted"
-}
-
-status := "accepted"
-if idPtr == nil {
-    status = "pending"
-}
-
-cl := models.ConsentLog{
-    ConsentID:  consent.ID,
-    GuestID:    &localGuestID,
-    BookingID:  idPtr,
-    AcceptedAt: time.Now().UTC(),
-    Status:     status,
-    Action:     action,
-}
-
+	var req AcceptConsentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
 
-    if err := config.DB.Create(&cl).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{
-            "error":  "db_error",
-            "detail": err.Error(),
-        })
-        return
-    }
+	var consent models.Consent
+	if err := config.DB.First(&consent, req.ConsentID).Error; err != nil {
+		middleware.Abort(c, services.ErrConsentNotFound)
+		return
+	}
 
-    c.JSON(http.StatusCreated, gin.H{
-        "ok":             true,
-        "consent_log_id": cl.ID,
-    })
-}
+	// bookingId อาจยังไม่รู้ (หรือส่งเป็น token) → อนุญาตให้ nil
+	idPtr := req.BookingID.ID
+	localGuestID := req.GuestID
 
+	action := strings.TrimSpace(req.Action)
+	if action == "" {
+		action = "accepted"
+	}
 
-// ------------------------------------------------------------
-// Helpers
-// ------------------------------------------------------------
-func normalizeBookingIdentifier(raw interface{}) (*uint, string) {
-	if raw == nil {
-		return nil, ""
+	status := "accepted"
+	if idPtr == nil {
+		status = "pending"
 	}
-	s := strings.TrimSpace(fmt.Sprint(raw))
-	if s == "" {
-		return nil, ""
+
+	cl := models.ConsentLog{
+		ConsentID:  consent.ID,
+		GuestID:    &localGuestID,
+		BookingID:  idPtr,
+		AcceptedAt: time.Now().UTC(),
+		Status:     status,
+		Action:     action,
 	}
-	if id64, err := strconv.ParseUint(s, 10, 64); err == nil {
-		id := uint(id64)
-		return &id, ""
+
+	if err := config.DB.Create(&cl).Error; err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
 	}
-	return nil, s
+
+	c.JSON(http.StatusCreated, gin.H{
+		"ok":             true,
+		"consent_log_id": cl.ID,
+	})
 }
 
 // DELETE /api/consents/:id
 func DeleteConsent(c *gin.Context) {
 	id := c.Param("id")
 	if strings.TrimSpace(id) == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing id parameter"})
+		middleware.Abort(c, services.ErrInvalidID)
 		return
 	}
 
 	if err := config.DB.Delete(&models.Consent{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":  "failed to delete consent",
-			"detail": err.Error(),
-		})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "consent deleted"})
+	c.JSON(http.StatusOK, gin.H{"message": middleware.Message(c, utils.MsgConsentDeleted)})
 }
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,40 +10,73 @@ import (
 	"time"
 
 	"hotel-backend/config"
+	"hotel-backend/middleware"
 	"hotel-backend/models"
+	"hotel-backend/services"
 	"hotel-backend/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// consentLogListSpec: GET /api/consent-logs
+var consentLogListSpec = services.ListSpec{
+	IDColumn: "id",
+	Sorts: map[string]string{
+		"id":         "id",
+		"createdAt":  "created_at",
+		"acceptedAt": "accepted_at",
+	},
+	DefaultSort: "-id",
+	DateColumn:  "created_at",
+	Filters: map[string]services.ListFilter{
+		"status":    {Cond: "status IN ?", Kind: services.FilterList},
+		"action":    {Cond: "action IN ?", Kind: services.FilterList},
+		"bookingId": {Cond: "booking_id = ?", Kind: services.FilterID},
+		"guestId":   {Cond: "guest_id = ?", Kind: services.FilterID},
+		"consentId": {Cond: "consent_id = ?", Kind: services.FilterID},
+	},
+}
+
 // GET /api/consent-logs
 func GetConsentLogs(c *gin.Context) {
-	var logs []models.ConsentLog
-
-	if err := config.DB.Order("id desc").Find(&logs).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load consent logs", "detail": err.Error()})
+	q, ok := bindListQuery(c, consentLogListSpec)
+	if !ok {
+		return
+	}
+	logs := []models.ConsentLog{}
+	page, err := services.FindPage(config.DB, consentLogListSpec, q, &logs)
+	if err != nil {
+		respondListError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, logs)
+	respondPage(c, page, logs)
+}
+
+// CreateConsentLogRequest: POST /api/consent-logs
+// ชื่อหลักเป็น camelCase, snake_case เดิมรับผ่าน alias
+type CreateConsentLogRequest struct {
+	BookingID       *uint   `json:"bookingId,omitempty" alias:"booking_id" binding:"omitempty,gt=0"`
+	BookingToken    *string `json:"bookingToken,omitempty" alias:"booking_token"`
+	ConsentID       uint    `json:"consentId" alias:"consent_id" binding:"required,gt=0"`
+	GuestID         *uint   `json:"guestId,omitempty" alias:"guest_id" binding:"omitempty,gt=0"`
+	Action          string  `json:"action,omitempty" binding:"max=50"`
+	AcceptedAt      *string `json:"acceptedAt,omitempty" alias:"accepted_at"` // accept string to parse multiple formats
+	AcceptedBy      string  `json:"acceptedBy,omitempty" alias:"accepted_by" binding:"max=255"`
+	FaceImageBase64 *string `json:"faceImageBase64,omitempty" alias:"face_image_base64"` // optional base64 data URI or raw base64
+}
+
+// AttachBookingRequest: PATCH /api/consent-logs/attach-booking
+type AttachBookingRequest struct {
+	BookingID BookingRef `json:"bookingId" alias:"booking_id"` // number หรือ token
+	GuestIDs  []uint     `json:"guestIds" alias:"guest_ids" binding:"omitempty,dive,gt=0"`
+	GuestID   *uint      `json:"guestId" alias:"guest_id" binding:"omitempty,gt=0"`
 }
 
 // POST /api/consent-logs
 func CreateConsentLog(c *gin.Context) {
-	var payload struct {
-		BookingID       *uint   `json:"booking_id,omitempty"`
-		BookingToken    *string `json:"booking_token,omitempty"`
-		ConsentID        uint `json:"consentId" binding:"required"`
-		GuestID         *uint   `json:"guest_id,omitempty"`
-		Action          string  `json:"action,omitempty"`
-		AcceptedAt      *string `json:"accepted_at,omitempty"` // accept string to parse multiple formats
-		AcceptedBy      string  `json:"accepted_by,omitempty"`
-		FaceImageBase64 *string `json:"face_image_base64,omitempty"` // optional base64 data URI or raw base64
-	}
-
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		log.Printf("❌ Consent Log Binding Error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+	var payload CreateConsentLogRequest
+	if !bindJSON(c, &payload) {
 		return
 	}
 
@@ -114,8 +148,7 @@ func CreateConsentLog(c *gin.Context) {
 	}
 
 	if err := config.DB.Create(&entry).Error; err != nil {
-		log.Printf("❌ DB Error creating consent_log: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create consent log", "detail": err.Error()})
+		middleware.Abort(c, services.ErrInternal.Wrapf("create consent_log: %w", err))
 		return
 	}
 
@@ -145,152 +178,40 @@ func tryParseAcceptedAt(s string) (time.Time, error) {
 // Accepts JSON: { bookingId: <number|string>, booking_id: <...>, guestIds?: [...], guestId?: <number> }
 // PATCH /api/consent-logs/attach-booking
 func AttachBookingToPending(c *gin.Context) {
-	// read incoming JSON into a generic map so we can accept flexible keys/names
-	var req map[string]interface{}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		// if body is empty or invalid JSON, try to report useful message
-		log.Printf("AttachBookingToPending: invalid payload: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+	var req AttachBookingRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	// DEBUG: log payload keys (helps debugging missing bookingId)
-	log.Printf("AttachBookingToPending payload keys: %+v", keysOfMap(req))
-
-	// Accept bookingId from multiple keys or from query/header as fallback
-	rawBooking, ok := req["bookingId"]
-	if !ok {
-		rawBooking, ok = req["booking_id"]
-	}
-	if !ok {
-		// fallback: query param
-		if qv := c.Query("bookingId"); qv != "" {
-			rawBooking = qv
-			ok = true
-		} else if qv := c.Query("booking_id"); qv != "" {
-			rawBooking = qv
-			ok = true
-		}
-	}
-	if !ok {
-		// fallback: header
-		if hv := c.GetHeader("X-Booking-Id"); hv != "" {
-			rawBooking = hv
-			ok = true
+	// Accept bookingId from body or from query/header as fallback
+	ref := req.BookingID
+	if ref.IsZero() {
+		for _, raw := range []string{c.Query("bookingId"), c.Query("booking_id"), c.GetHeader("X-Booking-Id")} {
+			if strings.TrimSpace(raw) == "" {
+				continue
+			}
+			// query/header เป็น string เสมอ → ใช้ตัว parse เดียวกับ body
+			b, _ := json.Marshal(strings.TrimSpace(raw))
+			_ = ref.UnmarshalJSON(b)
+			break
 		}
 	}
-
-	if !ok {
-		log.Printf("AttachBookingToPending missing bookingId - payload keys: %+v", keysOfMap(req))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":  "missing bookingId",
-			"detail": "please provide bookingId (number or token) in request body (bookingId or booking_id), query, or X-Booking-Id header",
-		})
+	if ref.IsZero() {
+		respondValidationError(c, requiredOneOf("bookingId", "booking_id", "X-Booking-Id"))
 		return
 	}
-
-	// parse booking identifier: either numeric id -> bookingID (uint) or token string -> bookingToken
-	var bookingID *uint
+	bookingID := ref.ID
 	var bookingToken *string
-	switch v := rawBooking.(type) {
-	case float64:
-		n := uint(v)
-		bookingID = &n
-	case int:
-		n := uint(v)
-		bookingID = &n
-	case int64:
-		n := uint(v)
-		bookingID = &n
-	case uint:
-		n := v
-		bookingID = &n
-	case string:
-		s := strings.TrimSpace(v)
-		if s == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "bookingId empty string"})
-			return
-		}
-		if id, err := strconv.ParseUint(s, 10, 64); err == nil {
-			n := uint(id)
-			bookingID = &n
-		} else {
-			bookingToken = &s
-		}
-	default:
-		// try stringify fallback
-		s := strings.TrimSpace(fmt.Sprint(v))
-		if id, err := strconv.ParseUint(s, 10, 64); err == nil {
-			n := uint(id)
-			bookingID = &n
-		} else {
-			tmp := s
-			if tmp == "" {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "bookingId empty or invalid"})
-				return
-			}
-			bookingToken = &tmp
-		}
+	if ref.Token != "" {
+		bookingToken = &ref.Token
 	}
 
-	// collect guest IDs: support guestIds array or single guestId (both camelCase and snake_case)
-	var guestIDs []uint
-
-	// 1) try guestIds
-	if arr, ok := req["guestIds"]; ok {
-		if cast, ok := arr.([]interface{}); ok {
-			for _, it := range cast {
-				switch vv := it.(type) {
-				case float64:
-					guestIDs = append(guestIDs, uint(vv))
-				case string:
-					if id, err := strconv.ParseUint(strings.TrimSpace(vv), 10, 64); err == nil {
-						guestIDs = append(guestIDs, uint(id))
-					}
-				}
-			}
-		}
-	}
-	// 2) try guest_id (snake case) as array
-	if len(guestIDs) == 0 {
-		if arr, ok := req["guest_ids"]; ok {
-			if cast, ok := arr.([]interface{}); ok {
-				for _, it := range cast {
-					switch vv := it.(type) {
-					case float64:
-						guestIDs = append(guestIDs, uint(vv))
-					case string:
-						if id, err := strconv.ParseUint(strings.TrimSpace(vv), 10, 64); err == nil {
-							guestIDs = append(guestIDs, uint(id))
-						}
-					}
-				}
-			}
-		}
+	guestIDs := req.GuestIDs
+	if len(guestIDs) == 0 && req.GuestID != nil {
+		guestIDs = []uint{*req.GuestID}
 	}
-	// 3) try single guestId or guest_id
 	if len(guestIDs) == 0 {
-		var single interface{}
-		if s, ok := req["guestId"]; ok {
-			single = s
-		} else if s, ok := req["guest_id"]; ok {
-			single = s
-		}
-		if single != nil {
-			switch vv := single.(type) {
-			case float64:
-				guestIDs = append(guestIDs, uint(vv))
-			case string:
-				if id, err := strconv.ParseUint(strings.TrimSpace(vv), 10, 64); err == nil {
-					guestIDs = append(guestIDs, uint(id))
-				}
-			}
-		}
-	}
-
-	if len(guestIDs) == 0 {
-		log.Printf("AttachBookingToPending: no guestIds found in payload keys: %+v", keysOfMap(req))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "guestIds or guestId required"})
+		respondValidationError(c, requiredOneOf("guestIds", "guestId"))
 		return
 	}
 
@@ -301,13 +222,12 @@ func AttachBookingToPending(c *gin.Context) {
 	var matchCount int64
 	cond := config.DB.Model(&models.ConsentLog{}).Where("guest_id IN ?", guestIDs).Where("booking_id IS NULL")
 	if err := cond.Count(&matchCount).Error; err != nil {
-		log.Printf("AttachBookingToPending: failed to count matching consent_logs: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "db_error", "detail": err.Error()})
+		middleware.Abort(c, services.ErrInternal.Wrapf("count pending consent_logs: %w", err))
 		return
 	}
 	if matchCount == 0 {
 		log.Printf("AttachBookingToPending: no pending consent_logs found for guestIDs=%v", guestIDs)
-		c.JSON(http.StatusOK, gin.H{"message": "no pending consent logs matched", "rows_affected": 0})
+		c.JSON(http.StatusOK, gin.H{"message": middleware.Message(c, utils.MsgConsentLogsNoneMatched), "rows_affected": 0})
 		return
 	}
 
@@ -325,13 +245,12 @@ func AttachBookingToPending(c *gin.Context) {
 	// Execute update
 	res := cond.Updates(updateMap)
 	if res.Error != nil {
-		log.Printf("AttachBookingToPending update error: %v", res.Error)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to attach booking", "detail": res.Error.Error()})
+		middleware.Abort(c, services.ErrInternal.Wrapf("attach booking: %w", res.Error))
 		return
 	}
 
 	log.Printf("AttachBookingToPending updated rows: %d", res.RowsAffected)
-	c.JSON(http.StatusOK, gin.H{"message": "pending consent logs updated", "rows_affected": res.RowsAffected})
+	c.JSON(http.StatusOK, gin.H{"message": middleware.Message(c, utils.MsgConsentLogsAttached), "rows_affected": res.RowsAffected})
 }
 
 // DELETE /api/consent-logs/:id
@@ -339,23 +258,14 @@ func DeleteConsentLog(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		middleware.Abort(c, services.ErrInvalidID)
 		return
 	}
 
 	if err := config.DB.Delete(&models.ConsentLog{}, uint(id)).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete consent log"})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Consent log deleted"})
-}
-
-// helper to list map keys (for debugging logs)
-func keysOfMap(m map[string]interface{}) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	return keys
+	c.JSON(http.StatusOK, gin.H{"message": middleware.Message(c, utils.MsgConsentLogDeleted)})
 }
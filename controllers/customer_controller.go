@@ -1,11 +1,14 @@
 package controllers
 
 import (
-	"fmt"
-	"hotel-backend/models"
-	"hotel-backend/services"
-	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+	"hotel-backend/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,27 +21,247 @@ func NewCustomerController(svc *services.CustomerService) *CustomerController {
 	return &CustomerController{CustomerSvc: svc}
 }
 
+// CustomerRequest: POST /api/customers (PATCH ใช้ชุดเดียวกัน ส่งเฉพาะที่จะเปลี่ยน)
+type CustomerRequest struct {
+	FullName          *string   `json:"fullName" alias:"full_name,name" binding:"omitempty,max=255"`
+	Email             *string   `json:"email" binding:"omitempty,max=255"`
+	Phone             *string   `json:"phone" binding:"omitempty,max=32"`
+	LineUserID        *string   `json:"lineUserId" alias:"line_user_id" binding:"omitempty,max=64"`
+	PreferredLanguage *string   `json:"preferredLanguage" alias:"preferred_language" binding:"omitempty,oneof=th en zh"`
+	Nationality       *string   `json:"nationality" binding:"omitempty,max=64"`
+	Address           *string   `json:"address"`
+	Notes             *string   `json:"notes"`
+	Tags              *[]string `json:"tags" binding:"omitempty,max=20,dive,max=32"`
+}
+
+func (r CustomerRequest) input() services.CustomerInput {
+	return services.CustomerInput{
+		FullName:          r.FullName,
+		Email:             r.Email,
+		Phone:             r.Phone,
+		LineUserID:        r.LineUserID,
+		PreferredLanguage: r.PreferredLanguage,
+		Nationality:       r.Nationality,
+		Address:           r.Address,
+		Notes:             r.Notes,
+		Tags:              r.Tags,
+	}
+}
+
+// validate: อีเมลต้องถูกรูปแบบ (ว่างได้), ตอนสร้างต้องมีชื่อ
+func (r CustomerRequest) validate(creating bool) *ValidationError {
+	if creating && (r.FullName == nil || trimmed(r.FullName) == "") {
+		return fieldError("fullName", "required", "")
+	}
+	if !creating && r.FullName != nil && trimmed(r.FullName) == "" {
+		return fieldError("fullName", "required", "")
+	}
+	if r.Email != nil && trimmed(r.Email) != "" && !inviteEmailRegex.MatchString(trimmed(r.Email)) {
+		return fieldError("email", "email", "")
+	}
+	return nil
+}
+
 // CreateCustomer (POST /api/customers) - T0.1
 func (ctrl *CustomerController) CreateCustomer(c *gin.Context) {
-	// 💡 Note: ต้องใช้ 'var' เพื่อให้ GORM อัปเดตค่า ID เข้าไปใน Struct
-	var customer models.Customer
+	var req CustomerRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if verr := req.validate(true); verr != nil {
+		respondValidationError(c, verr)
+		return
+	}
 
-	if err := c.ShouldBindJSON(&customer); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid customer payload: " + err.Error()})
+	customer, err := ctrl.CustomerSvc.CreateFrom(req.input())
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrapf("create customer: %w", err))
 		return
 	}
+	c.JSON(http.StatusCreated, customer)
+}
 
-	// 1. สร้าง Customer Record (Customer.ID จะถูกอัปเดตโดย GORM)
-	if err := ctrl.CustomerSvc.Create(&customer); err != nil { // 💡 ส่ง Address (&customer)
-		log.Printf("❌ DB ERROR during customer creation: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": fmt.Sprintf("Failed to create customer: %s", err.Error())})
+// GET /api/customers?q=&nationality=&preferredLanguage=&tag=&sort=&limit=&offset=
+func (ctrl *CustomerController) GetCustomers(c *gin.Context) {
+	q, ok := bindListQuery(c, services.CustomerListSpec)
+	if !ok {
+		return
+	}
+	customers, page, err := ctrl.CustomerSvc.List(q)
+	if err != nil {
+		respondListError(c, err)
 		return
 	}
+	respondPage(c, page, gin.H{"status": "success", "data": customers})
+}
 
-	// 2. คืนค่า Customer Object พร้อม ID ที่ถูกสร้างโดย DB (HTTP 201 Created)
-	// 💡 เนื่องจากเราไม่เห็นโค้ด models/customer.go ที่มีการเพิ่ม JSON tag 'id'
-	//    เราจะสมมติว่า GORM/Gin จะแปลง ID (uint) เป็น 'id' ใน JSON อัตโนมัติ
-	c.JSON(http.StatusCreated, customer)
+// GET /api/customers/:id → ลูกค้า + สถิติการเข้าพัก + การจอง
+func (ctrl *CustomerController) GetCustomer(c *gin.Context) {
+	id, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+	detail, err := ctrl.CustomerSvc.Detail(id)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": detail})
 }
 
-// 💡 คุณสามารถเพิ่ม CRUD Methods อื่น ๆ ได้ที่นี่
+// PATCH|PUT /api/customers/:id
+func (ctrl *CustomerController) UpdateCustomer(c *gin.Context) {
+	id, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+	var req CustomerRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if verr := req.validate(false); verr != nil {
+		respondValidationError(c, verr)
+		return
+	}
+
+	customer, err := ctrl.CustomerSvc.Update(id, req.input())
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": customer})
+}
+
+// DELETE /api/customers/:id
+func (ctrl *CustomerController) DeleteCustomer(c *gin.Context) {
+	id, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.CustomerSvc.Delete(id); err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": middleware.Message(c, utils.MsgCustomerDeleted)})
+}
+
+// ---------------- duplicates / merge ----------------
+
+const (
+	duplicateDefaultLimit = 50
+	duplicateMaxLimit     = 200
+)
+
+// GET /api/customers/duplicates?customerId=&minScore=&limit= → คู่ลูกค้าที่อาจซ้ำ ให้ staff ตรวจ
+func (ctrl *CustomerController) GetDuplicates(c *gin.Context) {
+	var q services.DuplicateQuery
+	var ok bool
+	if q.Limit, ok = intQuery(c, "limit", duplicateDefaultLimit, 1, duplicateMaxLimit); !ok {
+		return
+	}
+	if q.MinScore, ok = intQuery(c, "minScore", 0, 0, 100); !ok {
+		return
+	}
+	customerID, ok := intQuery(c, "customerId", 0, 0, math.MaxInt32)
+	if !ok {
+		return
+	}
+	q.CustomerID = uint(customerID)
+
+	list, err := ctrl.CustomerSvc.FindDuplicates(q)
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrapf("find duplicate customers: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": list})
+}
+
+type DismissDuplicateRequest struct {
+	CustomerIDs []uint `json:"customerIds" alias:"customer_ids" binding:"required,len=2,dive,gt=0"`
+}
+
+// POST /api/customers/duplicates/dismiss {customerIds:[a,b]} → ไม่ใช่คนเดียวกัน ไม่ต้องแสดงอีก
+func (ctrl *CustomerController) DismissDuplicate(c *gin.Context) {
+	var req DismissDuplicateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	adminID := middleware.CurrentAdmin(c).ID
+	if err := ctrl.CustomerSvc.DismissDuplicate(req.CustomerIDs[0], req.CustomerIDs[1], &adminID); err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": middleware.Message(c, utils.MsgDuplicateDismissed)})
+}
+
+type MergeCustomersRequest struct {
+	MergeIDs []uint `json:"mergeIds" alias:"merge_ids" binding:"required,min=1,max=20,dive,gt=0"`
+	Reason   string `json:"reason" binding:"max=500"`
+}
+
+// POST /api/customers/:id/merge {mergeIds:[...], reason} → รวมลูกค้าใน mergeIds เข้ากับ :id
+func (ctrl *CustomerController) MergeCustomers(c *gin.Context) {
+	id, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+	var req MergeCustomersRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	customer, merges, err := ctrl.CustomerSvc.Merge(id, req.MergeIDs, req.Reason, middleware.CurrentActor(c))
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"customer": customer, "merges": merges}})
+}
+
+// GET /api/customers/:id/merges → ประวัติการรวมของลูกค้า (ใช้ id ของลูกค้าที่ถูกรวมไปแล้วได้)
+func (ctrl *CustomerController) GetMergeHistory(c *gin.Context) {
+	id, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+	list, err := ctrl.CustomerSvc.MergeHistory(id)
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": list})
+}
+
+// intQuery อ่าน query param ที่เป็นตัวเลขในช่วง [lo, hi] (ไม่ส่งมา = def)
+func intQuery(c *gin.Context, name string, def, lo, hi int) (int, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < lo {
+		respondValidationError(c, fieldError(name, "gte", strconv.Itoa(lo)))
+		return 0, false
+	}
+	if n > hi {
+		respondValidationError(c, fieldError(name, "lte", strconv.Itoa(hi)))
+		return 0, false
+	}
+	return n, true
+}
+
+func customerIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		middleware.Abort(c, services.ErrInvalidID)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func trimmed(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return strings.TrimSpace(*s)
+}
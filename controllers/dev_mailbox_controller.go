@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+	"hotel-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// captureStore คืน store ของ capture mailer (ถ้า mailer ปัจจุบันไม่ใช่ capture จะคืน nil)
+func captureStore() utils.MailCaptureStore {
+	if m, ok := utils.CurrentMailer().(*utils.CaptureMailer); ok {
+		return m.Store
+	}
+	return nil
+}
+
+func respondCaptureDisabled(c *gin.Context) {
+	middleware.Abort(c, services.ErrMailCaptureOff.WithDetails(gin.H{"mailer": utils.CurrentMailer().Name()}))
+}
+
+// GET /api/dev/mailbox?to=guest@example.com&limit=50
+func GetDevMailbox(c *gin.Context) {
+	store := captureStore()
+	if store == nil {
+		respondCaptureDisabled(c)
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	messages, err := store.List(c.Query("to"), limit)
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+
+	// list ไม่ส่ง html เต็ม เพื่อให้ response เบา
+	items := make([]gin.H, 0, len(messages))
+	for _, m := range messages {
+		items = append(items, gin.H{
+			"id":        m.ID,
+			"to":        m.To,
+			"subject":   m.Subject,
+			"createdAt": m.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": items})
+}
+
+// GET /api/dev/mailbox/:id
+// GET /api/dev/mailbox/:id?format=html (render HTML ตรง ๆ ใน browser)
+func GetDevMailboxMessage(c *gin.Context) {
+	store := captureStore()
+	if store == nil {
+		respondCaptureDisabled(c)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		middleware.Abort(c, services.ErrInvalidID)
+		return
+	}
+
+	msg, err := store.Get(uint(id))
+	if err != nil {
+		if errors.Is(err, utils.ErrCapturedEmailNotFound) {
+			middleware.Abort(c, services.ErrMessageNotFound)
+			return
+		}
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+
+	if c.Query("format") == "html" {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(msg.HTML))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": msg})
+}
+
+// DELETE /api/dev/mailbox
+func ClearDevMailbox(c *gin.Context) {
+	store := captureStore()
+	if store == nil {
+		respondCaptureDisabled(c)
+		return
+	}
+	if err := store.Clear(); err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "mailbox cleared"})
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"hotel-backend/config"
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+	"hotel-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /api/email-templates
+// คืนรายการ message type และ locale ที่มี template
+func GetEmailTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"types":         utils.EmailTypes,
+		"locales":       utils.SupportedLocales,
+		"defaultLocale": utils.DefaultLocale(),
+	})
+}
+
+// GET /api/email-templates/preview?type=checkin_invite&lang=th&format=html
+// render template ด้วยข้อมูลตัวอย่าง + ข้อมูลโรงแรมจริงจาก HotelSetting
+func PreviewEmailTemplate(c *gin.Context) {
+	kind := strings.TrimSpace(c.DefaultQuery("type", utils.EmailCheckInInvite))
+	lang := c.Query("lang")
+	if lang == "" {
+		lang = c.GetHeader("Accept-Language")
+	}
+	locale := utils.NormalizeLocale(lang)
+
+	hotel := services.LoadHotelBrand(config.DB)
+	frontend := utils.EnvOrDefault("FRONTEND_URL", "http://localhost:3000")
+
+	var data interface{}
+	switch kind {
+	case utils.EmailCheckInInvite, utils.EmailCheckInReminder, utils.EmailThankYou:
+		now := time.Now()
+		link := utils.BuildCheckinLink(frontend, "preview-token", true)
+		qr, _ := utils.QRCodeDataURL(link, 180)
+		data = utils.CheckInEmailData{
+			Hotel:            hotel,
+			GuestName:        "Somchai Jaidee",
+			BookingRef:       "BK-PREVIEW",
+			CheckinLink:      link,
+			ConfirmationCode: "ABCD-1234",
+			CheckInDate:      now.AddDate(0, 0, 7).Format("2006-01-02"),
+			CheckOutDate:     now.AddDate(0, 0, 9).Format("2006-01-02"),
+			Rooms: []utils.RoomInfo{
+				{Number: "101", Type: "Deluxe"},
+				{Number: "102", Type: "Standard"},
+			},
+			QRCodeSrc: qr,
+		}
+	case utils.EmailAdminInvite:
+		data = utils.AdminInviteEmailData{
+			Hotel:      hotel,
+			Name:       "Preview User",
+			Role:       "Receptionist",
+			InviteLink: strings.TrimRight(frontend, "/") + "/#/setup-account?token=preview-token",
+		}
+	default:
+		middleware.Abort(c, services.ErrUnknownTemplate.WithDetails(gin.H{"types": utils.EmailTypes}))
+		return
+	}
+
+	email, err := utils.RenderEmail(kind, locale, data)
+	if err != nil {
+		middleware.Abort(c, services.ErrRenderTemplateFailed.Wrap(err))
+		return
+	}
+
+	if strings.EqualFold(c.Query("format"), "html") {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.HTML))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"type":    kind,
+		"locale":  locale,
+		"subject": email.Subject,
+		"text":    email.Text,
+		"html":    email.HTML,
+	})
+}
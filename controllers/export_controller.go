@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+	"hotel-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportController struct {
+	ExportSvc *services.ExportService
+}
+
+func NewExportController(svc *services.ExportService) *ExportController {
+	return &ExportController{ExportSvc: svc}
+}
+
+// GET /api/exports/:dataset?format=csv|xlsx&from=&to=&status=&nationality=&maskPii=true
+// dataset: customers | guests | bookings | consentLogs (ตอบเป็นไฟล์แนบแบบ stream)
+func (ctrl *ExportController) Export(c *gin.Context) {
+	req, err := services.ParseExportQuery(c.Param("dataset"), c.Query("format"), c.Request.URL.Query())
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	req.MaskPII = c.Query("maskPii") == "true" || c.Query("maskPii") == "1"
+	req.Actor = middleware.CurrentActor(c)
+
+	// WriteTimeout ของ server (20s) ตัด stream ไฟล์ใหญ่กลางทาง — export ไม่จำกัดเวลาเขียน
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("export %s: cannot extend write deadline: %v", req.Dataset, err)
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", req.Dataset, time.Now().Format("20060102-1504"), req.Format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+
+	var tw utils.TableWriter
+	if req.Format == services.ExportXLSX {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		tw, err = utils.NewXLSXTableWriter(c.Writer, req.Dataset)
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		tw, err = utils.NewCSVTableWriter(c.Writer)
+	}
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+
+	// เริ่ม stream แล้วเปลี่ยน status ไม่ได้: ถ้าล้มกลางทางบันทึกไว้ใน ExportLog แทน
+	if entry, err := ctrl.ExportSvc.Run(req, tw); err != nil {
+		log.Printf("export %s (log %d) failed: %v", req.Dataset, entry.ID, err)
+	}
+}
+
+// GET /api/exports/logs?dataset=&status=&adminId=&from=&to= → ใคร export อะไรเมื่อไร
+func (ctrl *ExportController) GetLogs(c *gin.Context) {
+	q, ok := bindListQuery(c, services.ExportLogListSpec)
+	if !ok {
+		return
+	}
+	list, page, err := ctrl.ExportSvc.ListLogs(q)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	respondPage(c, page, gin.H{"status": "success", "data": list})
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -9,13 +10,15 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"time"
     "regexp"
 	"hotel-backend/config"
+	"hotel-backend/middleware"
 	"hotel-backend/models"
 	"hotel-backend/services"
+	"hotel-backend/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // --- Controller ---
@@ -37,13 +40,122 @@ type APIResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// ----------------------------------------------------------------------
+// --- Request DTOs ---
+// ชื่อหลักเป็น camelCase (ตาม models.Guest) ชื่อ snake_case / ชื่อเก่าของ React รับผ่าน alias เท่านั้น
+// ----------------------------------------------------------------------
+
+// GuestRequest ใช้กับ POST /api/guests และ guests[] ของ ConfirmCheckIn
+type GuestRequest struct {
+	BookingID           *uint  `json:"bookingId" alias:"booking_id" binding:"omitempty,gt=0"`
+	FullName            string `json:"fullName" alias:"full_name,name" binding:"required,max=255"`
+	IsMainGuest         bool   `json:"isMainGuest" alias:"is_main_guest,mainGuest,main_guest"`
+	DateOfBirth         string `json:"dateOfBirth" alias:"date_of_birth" binding:"omitempty,dateonly"`
+	Gender              string `json:"gender" binding:"max=32"`
+	Nationality         string `json:"nationality" binding:"max=64"`
+	CurrentAddress      string `json:"currentAddress" alias:"current_address" binding:"max=1000"`
+	IDType              string `json:"idType" alias:"documentType,id_type" binding:"max=32"` // React ส่ง documentType: "ID_CARD" | "PASSPORT"
+	IDNumber            string `json:"idNumber" alias:"documentNumber,id_number" binding:"max=64"`
+	IDIssuedCountry     string `json:"idIssuedCountry" alias:"id_issued_country" binding:"max=64"`
+	Email               string `json:"email" binding:"omitempty,email"`
+	FaceImagePath       string `json:"faceImagePath" alias:"face_image_path"`
+	DocumentImagePath   string `json:"documentImagePath" alias:"document_image_path"`
+	FaceImageBase64     string `json:"faceImageBase64" alias:"face_image_base64"`
+	DocumentImageBase64 string `json:"documentImageBase64" alias:"document_image_base64"`
+}
+
+// toModel แปลงเป็น models.Guest และบันทึกรูป base64 (ถ้ามี) ลง uploads/
+func (r GuestRequest) toModel() models.Guest {
+	g := models.Guest{
+		BookingID:         r.BookingID,
+		FullName:          strings.TrimSpace(r.FullName),
+		IsMainGuest:       r.IsMainGuest,
+		Gender:            strings.TrimSpace(r.Gender),
+		Nationality:       strings.TrimSpace(r.Nationality),
+		CurrentAddress:    strings.TrimSpace(r.CurrentAddress),
+		IDType:            strings.TrimSpace(r.IDType),
+		IDNumber:          strings.TrimSpace(r.IDNumber),
+		IDIssuedCountry:   strings.TrimSpace(r.IDIssuedCountry),
+		Email:             strings.TrimSpace(r.Email),
+		FaceImagePath:     strings.TrimSpace(r.FaceImagePath),
+		DocumentImagePath: strings.TrimSpace(r.DocumentImagePath),
+	}
+	if dob, ok := parseDateOnly(r.DateOfBirth); ok {
+		g.DateOfBirth = &dob
+	}
+
+	if b64 := strings.TrimSpace(r.FaceImageBase64); b64 != "" {
+		if path, err := services.SaveBase64Image(b64, "faces"); err != nil {
+			log.Println("❌ save face image failed:", err)
+		} else {
+			g.FaceImagePath = path
+		}
+	}
+	if b64 := strings.TrimSpace(r.DocumentImageBase64); b64 != "" {
+		if path, err := services.SaveBase64Image(b64, "documents"); err != nil {
+			log.Println("❌ save document image failed:", err)
+		} else {
+			g.DocumentImagePath = path
+		}
+	}
+	return g
+}
+
+// UpdateGuestRequest: PUT /api/guests/:id — เฉพาะ field ที่ส่งมาเท่านั้นที่ถูกแก้
+type UpdateGuestRequest struct {
+	FullName          *string `json:"fullName" alias:"full_name,name" binding:"omitempty,min=1,max=255"`
+	IsMainGuest       *bool   `json:"isMainGuest" alias:"is_main_guest,mainGuest,main_guest"`
+	DateOfBirth       *string `json:"dateOfBirth" alias:"date_of_birth" binding:"omitempty,dateonly"`
+	Gender            *string `json:"gender" binding:"omitempty,max=32"`
+	Nationality       *string `json:"nationality" binding:"omitempty,max=64"`
+	CurrentAddress    *string `json:"currentAddress" alias:"current_address" binding:"omitempty,max=1000"`
+	IDType            *string `json:"idType" alias:"documentType,id_type" binding:"omitempty,max=32"`
+	IDNumber          *string `json:"idNumber" alias:"documentNumber,id_number" binding:"omitempty,max=64"`
+	IDIssuedCountry   *string `json:"idIssuedCountry" alias:"id_issued_country" binding:"omitempty,max=64"`
+	Email             *string `json:"email" binding:"omitempty,email"`
+	FaceImagePath     *string `json:"faceImagePath" alias:"face_image_path"`
+	DocumentImagePath *string `json:"documentImagePath" alias:"document_image_path"`
+}
+
+// columns คืน map column → value ของ field ที่ส่งมา (ใช้กับ GuestService.UpdateFields)
+func (r UpdateGuestRequest) columns() map[string]interface{} {
+	out := map[string]interface{}{}
+	set := func(col string, v *string) {
+		if v != nil {
+			out[col] = strings.TrimSpace(*v)
+		}
+	}
+	set("full_name", r.FullName)
+	set("gender", r.Gender)
+	set("nationality", r.Nationality)
+	set("current_address", r.CurrentAddress)
+	set("id_type", r.IDType)
+	set("id_number", r.IDNumber)
+	set("id_issued_country", r.IDIssuedCountry)
+	set("email", r.Email)
+	set("face_image_path", r.FaceImagePath)
+	set("document_image_path", r.DocumentImagePath)
+	if r.IsMainGuest != nil {
+		out["is_main_guest"] = *r.IsMainGuest
+	}
+	if r.DateOfBirth != nil {
+		// "" = ลบวันเกิด (NULL) — omitempty ปล่อยผ่าน dateonly ห้ามเขียน 0001-01-01
+		if strings.TrimSpace(*r.DateOfBirth) == "" {
+			out["date_of_birth"] = nil
+		} else if dob, ok := parseDateOnly(*r.DateOfBirth); ok { // ผ่าน dateonly แล้ว
+			out["date_of_birth"] = dob
+		}
+	}
+	return out
+}
+
 // ----------------------------------------------------------------------
 // --- OCR ตรวจบัตรประชาชน ---
 // ----------------------------------------------------------------------
 func (c *GuestController) HandleIDCardVerification(ctx *gin.Context, apiKey string) {
 	file, fileHeader, err := ctx.Request.FormFile("id_card_file")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "ไม่พบไฟล์ id_card_file"})
+		middleware.Abort(ctx, services.ErrMissingFile.WithDetails(gin.H{"field": "id_card_file"}))
 		return
 	}
 	defer file.Close()
@@ -51,7 +163,7 @@ func (c *GuestController) HandleIDCardVerification(ctx *gin.Context, apiKey stri
 	tempFilePath := fmt.Sprintf("%s/%s_%s", os.TempDir(), strconv.Itoa(os.Getpid()), fileHeader.Filename)
 	tempFile, err := os.Create(tempFilePath)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "ไม่สามารถสร้าง temp file ได้"})
+		middleware.Abort(ctx, services.ErrInternal.Wrapf("create temp file: %w", err))
 		return
 	}
 	defer tempFile.Close()
@@ -64,13 +176,13 @@ func (c *GuestController) HandleIDCardVerification(ctx *gin.Context, apiKey stri
 
 	result, err := services.DoOCR(imageBase64)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		middleware.Abort(ctx, services.ErrDocumentReadFailed.Wrap(err))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, APIResponse{
 		Status:  "success",
-		Message: "OCR สำเร็จ",
+		Message: middleware.Message(ctx, utils.MsgIDCardRead),
 		Data:    result,
 	})
 }
@@ -81,7 +193,7 @@ func (c *GuestController) HandleIDCardVerification(ctx *gin.Context, apiKey stri
 func (c *GuestController) HandlePassportVerification(ctx *gin.Context, apiKey string) {
 	file, fileHeader, err := ctx.Request.FormFile("passport_file")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "ไม่พบไฟล์ passport_file"})
+		middleware.Abort(ctx, services.ErrMissingFile.WithDetails(gin.H{"field": "passport_file"}))
 		return
 	}
 	defer file.Close()
@@ -89,7 +201,7 @@ func (c *GuestController) HandlePassportVerification(ctx *gin.Context, apiKey st
 	tempFilePath := fmt.Sprintf("%s/%s_%s", os.TempDir(), strconv.Itoa(os.Getpid()), fileHeader.Filename)
 	tempFile, err := os.Create(tempFilePath)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "ไม่สามารถสร้าง temp file ได้"})
+		middleware.Abort(ctx, services.ErrInternal.Wrapf("create temp file: %w", err))
 		return
 	}
 	defer tempFile.Close()
@@ -102,13 +214,13 @@ func (c *GuestController) HandlePassportVerification(ctx *gin.Context, apiKey st
 
 	result, err := services.DoPassportOCR(imageBase64)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		middleware.Abort(ctx, services.ErrDocumentReadFailed.Wrap(err))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, APIResponse{
 		Status:  "success",
-		Message: "Passport OCR สำเร็จ",
+		Message: middleware.Message(ctx, utils.MsgPassportRead),
 		Data:    result,
 	})
 }
@@ -122,19 +234,13 @@ func (c *GuestController) GetGuestsByBookingID(ctx *gin.Context) {
 
     idStr := strings.TrimSpace(ctx.Param("id"))
     if idStr == "" {
-        ctx.JSON(http.StatusBadRequest, gin.H{
-            "status":  "error",
-            "message": "error.missingBookingId",
-        })
+        middleware.Abort(ctx, services.ErrMissingBookingID)
         return
     }
 
     bookingID64, err := strconv.ParseUint(idStr, 10, 64)
     if err != nil || bookingID64 == 0 {
-        ctx.JSON(http.StatusBadRequest, gin.H{
-            "status":  "error",
-            "message": "error.invalidBookingId",
-        })
+        middleware.Abort(ctx, services.ErrInvalidBookingID)
         return
     }
     bookingID := uint(bookingID64)
@@ -147,11 +253,7 @@ func (c *GuestController) GetGuestsByBookingID(ctx *gin.Context) {
         Order("is_main_guest DESC, id ASC").
         Find(&guests).Error; err != nil {
 
-        log.Printf("[GetGuestsByBookingID] booking_id=%d err=%v", bookingID, err)
-        ctx.JSON(http.StatusInternalServerError, gin.H{
-            "status":  "error",
-            "message": "Failed to fetch guests",
-        })
+        middleware.Abort(ctx, services.ErrInternal.Wrapf("fetch guests of booking %d: %w", bookingID, err))
         return
     }
 
@@ -170,19 +272,13 @@ func (c *GuestController) GetGuests(ctx *gin.Context) {
 
 	q := strings.TrimSpace(ctx.Query("bookingId"))
 	if q == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"status":  "error",
-			"message": "error.missingBookingId",
-		})
+		middleware.Abort(ctx, services.ErrMissingBookingID)
 		return
 	}
 
 	bookingID64, err := strconv.ParseUint(q, 10, 64)
 	if err != nil || bookingID64 == 0 {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"status":  "error",
-			"message": "error.invalidBookingId",
-		})
+		middleware.Abort(ctx, services.ErrInvalidBookingID)
 		return
 	}
 	bookingID := uint(bookingID64)
@@ -193,10 +289,7 @@ func (c *GuestController) GetGuests(ctx *gin.Context) {
 		Order("is_main_guest DESC, id ASC").
 		Find(&guests).Error; err != nil {
 
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": "Failed to fetch guests",
-		})
+		middleware.Abort(ctx, services.ErrInternal.Wrapf("fetch guests of booking %d: %w", bookingID, err))
 		return
 	}
 
@@ -212,17 +305,18 @@ func (c *GuestController) GetGuests(ctx *gin.Context) {
 func (c *GuestController) GetAllGuests(ctx *gin.Context) {
 	log.Println("✅ HIT GetAllGuests")
 
-	// แนะนำให้ใช้ service (คุณมี GetAll() แล้ว) เพื่อ preload / แต่งข้อมูลได้
-	guests, err := c.GuestSvc.GetAll()
+	q, ok := bindListQuery(ctx, services.GuestListSpec)
+	if !ok {
+		return
+	}
+	// ใช้ service เพื่อ preload / แต่งข้อมูล (roomNumber)
+	guests, page, err := c.GuestSvc.List(q)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": err.Error(),
-		})
+		respondListError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
+	respondPage(ctx, page, gin.H{
 		"status": "success",
 		"data":   guests,
 	})
@@ -234,13 +328,13 @@ func (c *GuestController) GetGuestByID(ctx *gin.Context) {
     idStr := ctx.Param("id")
     id, err := strconv.ParseUint(idStr, 10, 32)
     if err != nil {
-        ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid guest ID"})
+        middleware.Abort(ctx, services.ErrInvalidID)
         return
     }
 
     guest, err := c.GuestSvc.GetByID(uint(id))
     if err != nil {
-        ctx.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Guest not found"})
+        middleware.Abort(ctx, services.ErrGuestNotFound.Wrap(err))
         return
     }
 
@@ -251,231 +345,48 @@ func (c *GuestController) UpdateGuest(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid guest ID"})
+		middleware.Abort(ctx, services.ErrInvalidID)
 		return
 	}
 
-	var payload models.Guest
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+	var payload UpdateGuestRequest
+	if !bindJSON(ctx, &payload) {
 		return
 	}
 
-	payload.ID = uint(id)
-
-	if err := c.GuestSvc.Update(&payload); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+	guest, err := c.GuestSvc.UpdateFields(uint(id), payload.columns())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			middleware.Abort(ctx, services.ErrGuestNotFound)
+			return
+		}
+		middleware.Abort(ctx, services.ErrInternal.Wrap(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, payload)
+	ctx.JSON(http.StatusOK, guest)
 }
 
 // ----------------------------------------------------------------------
-// --- CreateGuest (STRICT: bookingId only) ---
-// ----------------------------------------------------------------------
-// ----------------------------------------------------------------------
-// --- CreateGuest (รองรับ camelCase + snake_case) ---
-// POST /api/guests
+// --- CreateGuest ---
+// POST /api/guests (bookingId จำเป็น)
 // ----------------------------------------------------------------------
 func (c *GuestController) CreateGuest(ctx *gin.Context) {
-	var payload map[string]interface{}
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"status":  "error",
-			"message": err.Error(),
-		})
+	var payload GuestRequest
+	if !bindJSON(ctx, &payload) {
 		return
 	}
-
-	log.Printf("➡️ RAW CreateGuest payload: %+v", payload)
-
-	// ---------------- helpers ----------------
-	getString := func(keys ...string) string {
-		for _, k := range keys {
-			if v, ok := payload[k]; ok && v != nil {
-				switch vv := v.(type) {
-				case string:
-					s := strings.TrimSpace(vv)
-					if s != "" {
-						return s
-					}
-				case fmt.Stringer:
-					s := strings.TrimSpace(vv.String())
-					if s != "" {
-						return s
-					}
-				}
-			}
-		}
-		return ""
-	}
-
-	getBool := func(keys ...string) (bool, bool) {
-		for _, k := range keys {
-			if v, ok := payload[k]; ok && v != nil {
-				switch vv := v.(type) {
-				case bool:
-					return vv, true
-				case float64:
-					return vv != 0, true
-				case int:
-					return vv != 0, true
-				case string:
-					s := strings.TrimSpace(strings.ToLower(vv))
-					if s == "true" || s == "1" || s == "yes" {
-						return true, true
-					}
-					if s == "false" || s == "0" || s == "no" {
-						return false, true
-					}
-				}
-			}
-		}
-		return false, false
-	}
-
-	getUintPtr := func(keys ...string) (*uint, bool) {
-		for _, k := range keys {
-			if v, ok := payload[k]; ok && v != nil {
-				switch vv := v.(type) {
-				case float64:
-					n := uint(vv)
-					if n > 0 {
-						return &n, true
-					}
-				case int:
-					n := uint(vv)
-					if n > 0 {
-						return &n, true
-					}
-				case string:
-					s := strings.TrimSpace(vv)
-					if s == "" {
-						continue
-					}
-					if id, err := strconv.ParseUint(s, 10, 64); err == nil && id > 0 {
-						n := uint(id)
-						return &n, true
-					}
-				}
-			}
-		}
-		return nil, false
-	}
-
-	parseDOB := func(v string) *time.Time {
-		v = strings.TrimSpace(v)
-		if v == "" {
-			return nil
-		}
-
-		// รองรับ "2006-01-02"
-		if t, err := time.Parse("2006-01-02", v); err == nil {
-			return &t
-		}
-
-		// รองรับ RFC3339 เช่น "2004-07-23T07:00:00+07:00"
-		if t, err := time.Parse(time.RFC3339, v); err == nil {
-			tt := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
-			return &tt
-		}
-
-		log.Println("⚠️ invalid dateOfBirth/date_of_birth:", v)
-		return nil
-	}
-
-	// ---------------- bookingId (required) ----------------
-	bookingID, ok := getUintPtr("bookingId", "booking_id")
-	if !ok || bookingID == nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"status":  "error",
-			"message": "error.missingBookingId",
-		})
+	if payload.BookingID == nil {
+		respondValidationError(ctx, fieldError("bookingId", "required", ""))
 		return
 	}
 
-	// ---------------- map payload -> model ----------------
-	var g models.Guest
-	g.BookingID = bookingID
-
-	// full name
-	fullName := getString("fullName", "full_name", "name")
-	if fullName != "" {
-		g.FullName = fullName
-	}
-
-	// is main guest
-	if b, ok := getBool("isMainGuest", "is_main_guest", "mainGuest", "main_guest"); ok {
-		g.IsMainGuest = b
-	}
-
-	// date of birth
-	dob := getString("dateOfBirth", "date_of_birth")
-	if dob != "" {
-		g.DateOfBirth = parseDOB(dob)
-	}
-
-	// basic info
-	g.Gender = getString("gender")
-	g.Nationality = getString("nationality")
-
-	// address
-	addr := getString("currentAddress", "current_address")
-	if addr != "" {
-		g.CurrentAddress = addr
-	}
-
-	// document type / number
-	// React ส่ง documentType: "ID_CARD" | "PASSPORT"
-	docType := getString("documentType", "idType", "id_type")
-	if docType != "" {
-		g.IDType = docType
-	}
-
-	docNo := getString("documentNumber", "idNumber", "id_number")
-	if docNo != "" {
-		g.IDNumber = docNo
-	}
-
-	issued := getString("idIssuedCountry", "id_issued_country")
-	if issued != "" {
-		g.IDIssuedCountry = issued
-	}
-
-	// images path (ถ้าส่งเป็น path มาอยู่แล้ว)
-	g.FaceImagePath = getString("faceImagePath", "face_image_path")
-	g.DocumentImagePath = getString("documentImagePath", "document_image_path")
-
-	// base64 images (React ส่ง faceImageBase64/documentImageBase64)
-	faceB64 := getString("faceImageBase64", "face_image_base64")
-	if faceB64 != "" {
-		path, err := services.SaveBase64Image(faceB64, "faces")
-		if err != nil {
-			log.Println("❌ save face image failed:", err)
-		} else {
-			g.FaceImagePath = path
-		}
-	}
-
-	docB64 := getString("documentImageBase64", "document_image_base64")
-	if docB64 != "" {
-		path, err := services.SaveBase64Image(docB64, "documents")
-		if err != nil {
-			log.Println("❌ save document image failed:", err)
-		} else {
-			g.DocumentImagePath = path
-		}
-	}
-
-	log.Printf("➡️ CreateGuest mapped model: %+v", g)
+	g := payload.toModel()
+	log.Printf("➡️ CreateGuest mapped model: booking_id=%d fullName=%q", *g.BookingID, g.FullName)
 
 	// ---------------- save ----------------
 	if err := c.GuestSvc.Create(&g); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": err.Error(),
-		})
+		middleware.Abort(ctx, services.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -489,10 +400,7 @@ func (c *GuestController) CreateGuest(ctx *gin.Context) {
 // --- Delete Guest ---
 // ----------------------------------------------------------------------
 func (c *GuestController) DeleteGuest(ctx *gin.Context) {
-	ctx.JSON(http.StatusForbidden, gin.H{
-		"status":  "error",
-		"message": "guest deletion is disabled",
-	})
+	middleware.Abort(ctx, services.ErrGuestDeletionDisabled)
 }
 
 
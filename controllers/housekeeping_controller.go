@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+	"hotel-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HousekeepingController struct {
+	HousekeepingSvc *services.HousekeepingService
+}
+
+func NewHousekeepingController(svc *services.HousekeepingService) *HousekeepingController {
+	return &HousekeepingController{HousekeepingSvc: svc}
+}
+
+// HousekeepingTaskRequest body ของ POST / PATCH /api/housekeeping/tasks
+// assigneeId: 0 = เลิก assign
+type HousekeepingTaskRequest struct {
+	RoomID     *uint   `json:"roomId" alias:"room_id" binding:"omitempty,gt=0"`
+	Date       *string `json:"date" binding:"omitempty,dateonly"`
+	Type       *string `json:"type" binding:"omitempty,oneof=checkout stayover touchup deep"`
+	Priority   *int    `json:"priority" binding:"omitempty,gte=0,lte=9"`
+	AssigneeID *uint   `json:"assigneeId" alias:"assignee_id"`
+	Notes      *string `json:"notes" binding:"omitempty,max=2000"`
+}
+
+func (r HousekeepingTaskRequest) input() services.HousekeepingTaskInput {
+	in := services.HousekeepingTaskInput{
+		RoomID:     r.RoomID,
+		Type:       r.Type,
+		Priority:   r.Priority,
+		AssigneeID: r.AssigneeID,
+		Notes:      r.Notes,
+	}
+	if r.Date != nil {
+		if d, ok := parseDateOnly(*r.Date); ok {
+			in.Date = &d
+		}
+	}
+	return in
+}
+
+// GenerateTasksRequest body ของ POST /api/housekeeping/tasks/generate
+type GenerateTasksRequest struct {
+	Date           string          `json:"date" binding:"omitempty,dateonly"`
+	FloorAssignees map[string]uint `json:"floorAssignees" alias:"floor_assignees"`
+}
+
+type FinishTaskRequest struct {
+	Notes *string `json:"notes" binding:"omitempty,max=2000"`
+}
+
+type InspectTaskRequest struct {
+	Passed *bool  `json:"passed" binding:"required"`
+	Note   string `json:"note" binding:"max=2000"`
+}
+
+type RoomCleanStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=dirty cleaning clean inspected"`
+}
+
+// respondHousekeepingError อ้างอิงที่ไม่มีอยู่ (ห้อง / ผู้รับงาน) ตอบเป็น validation error ของ field
+func respondHousekeepingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRoomNotFound):
+		respondValidationError(c, fieldError("roomId", "exists", ""))
+	case errors.Is(err, services.ErrAssigneeNotFound):
+		respondValidationError(c, fieldError("assigneeId", "exists", ""))
+	default:
+		middleware.Abort(c, err)
+	}
+}
+
+// housekeepingDateQuery ?date=YYYY-MM-DD (ไม่ส่ง = วันนี้)
+func housekeepingDateQuery(c *gin.Context) (time.Time, bool) {
+	v := strings.TrimSpace(c.Query("date"))
+	if v == "" {
+		return services.HousekeepingDate(time.Now()), true
+	}
+	d, ok := parseDateOnly(v)
+	if !ok {
+		respondValidationError(c, fieldError("date", "dateonly", ""))
+		return time.Time{}, false
+	}
+	return d, true
+}
+
+// GET /api/housekeeping/rooms?floor=3&cleanStatus=dirty
+func (ctrl *HousekeepingController) GetRooms(c *gin.Context) {
+	rooms, err := ctrl.HousekeepingSvc.RoomBoard(strings.TrimSpace(c.Query("floor")), strings.TrimSpace(c.Query("cleanStatus")))
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": rooms})
+}
+
+// PATCH /api/housekeeping/rooms/:id/status {status} — inspected ต้องมี housekeeping.inspect
+func (ctrl *HousekeepingController) UpdateRoomStatus(c *gin.Context) {
+	id, ok := housekeepingIDParam(c)
+	if !ok {
+		return
+	}
+	var req RoomCleanStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.Status == services.CleanStatusInspected && !middleware.HasPermission(c, "housekeeping.inspect") {
+		middleware.Abort(c, services.ErrPermissionDenied.WithDetails(gin.H{"permission": "housekeeping.inspect"}))
+		return
+	}
+	room, err := ctrl.HousekeepingSvc.SetRoomCleanStatus(id, req.Status, middleware.CurrentActor(c))
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": room})
+}
+
+// GET /api/housekeeping/tasks?date=2026-10-18&assigneeId=12|me&unassigned=true&floor=3&status=pending
+func (ctrl *HousekeepingController) GetTasks(c *gin.Context) {
+	date, ok := housekeepingDateQuery(c)
+	if !ok {
+		return
+	}
+	f := services.HousekeepingTaskFilter{
+		Date:       date,
+		Floor:      strings.TrimSpace(c.Query("floor")),
+		Status:     strings.TrimSpace(c.Query("status")),
+		Unassigned: c.Query("unassigned") == "true",
+	}
+	switch v := strings.TrimSpace(c.Query("assigneeId")); v {
+	case "":
+	case "me":
+		id := middleware.CurrentAdmin(c).ID
+		f.AssigneeID = &id
+	default:
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			respondValidationError(c, fieldError("assigneeId", "type", "integer"))
+			return
+		}
+		id := uint(n)
+		f.AssigneeID = &id
+	}
+	tasks, err := ctrl.HousekeepingSvc.ListTasks(f)
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": tasks})
+}
+
+// GET /api/housekeeping/my-tasks?date= → งานของตัวเองแบบย่อ จัดกลุ่มตามชั้น (หน้าจอมือถือ)
+func (ctrl *HousekeepingController) GetMyTasks(c *gin.Context) {
+	date, ok := housekeepingDateQuery(c)
+	if !ok {
+		return
+	}
+	me := middleware.CurrentAdmin(c).ID
+	tasks, err := ctrl.HousekeepingSvc.ListTasks(services.HousekeepingTaskFilter{Date: date, AssigneeID: &me})
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	remaining := 0
+	for _, t := range tasks {
+		if t.Status == services.TaskStatusPending || t.Status == services.TaskStatusInProgress {
+			remaining++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"date":      date.Format("2006-01-02"),
+		"total":     len(tasks),
+		"remaining": remaining,
+		"floors":    services.GroupTasksByFloor(tasks),
+	}})
+}
+
+// POST /api/housekeeping/tasks {roomId, date?, type?, priority?, assigneeId?, notes?}
+func (ctrl *HousekeepingController) CreateTask(c *gin.Context) {
+	var req HousekeepingTaskRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.RoomID == nil {
+		respondValidationError(c, fieldError("roomId", "required", ""))
+		return
+	}
+	task, err := ctrl.HousekeepingSvc.CreateTask(req.input(), middleware.CurrentActor(c))
+	if err != nil {
+		respondHousekeepingError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": task})
+}
+
+// POST /api/housekeeping/tasks/generate {date?, floorAssignees?: {"3": 12}}
+func (ctrl *HousekeepingController) GenerateTasks(c *gin.Context) {
+	var req GenerateTasksRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	date := services.HousekeepingDate(time.Now())
+	if req.Date != "" {
+		date, _ = parseDateOnly(req.Date)
+	}
+	tasks, err := ctrl.HousekeepingSvc.GenerateTasks(date, req.FloorAssignees, middleware.CurrentActor(c))
+	if err != nil {
+		respondHousekeepingError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": tasks, "created": len(tasks)})
+}
+
+// PATCH /api/housekeeping/tasks/:id
+func (ctrl *HousekeepingController) UpdateTask(c *gin.Context) {
+	id, ok := housekeepingIDParam(c)
+	if !ok {
+		return
+	}
+	var req HousekeepingTaskRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	task, err := ctrl.HousekeepingSvc.UpdateTask(id, req.input(), middleware.CurrentActor(c))
+	if err != nil {
+		respondHousekeepingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": task})
+}
+
+// DELETE /api/housekeeping/tasks/:id
+func (ctrl *HousekeepingController) DeleteTask(c *gin.Context) {
+	id, ok := housekeepingIDParam(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.HousekeepingSvc.DeleteTask(id, middleware.CurrentActor(c)); err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": middleware.Message(c, utils.MsgHousekeepingTaskDeleted)})
+}
+
+// POST /api/housekeeping/tasks/:id/start
+func (ctrl *HousekeepingController) StartTask(c *gin.Context) {
+	id, ok := housekeepingIDParam(c)
+	if !ok {
+		return
+	}
+	task, err := ctrl.HousekeepingSvc.StartTask(id, middleware.CurrentActor(c), middleware.HasPermission(c, "housekeeping.assign"))
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": task})
+}
+
+// POST /api/housekeeping/tasks/:id/finish {notes?}
+func (ctrl *HousekeepingController) FinishTask(c *gin.Context) {
+	id, ok := housekeepingIDParam(c)
+	if !ok {
+		return
+	}
+	var req FinishTaskRequest
+	if c.Request.ContentLength > 0 && !bindJSON(c, &req) {
+		return
+	}
+	task, err := ctrl.HousekeepingSvc.FinishTask(id, req.Notes, middleware.CurrentActor(c), middleware.HasPermission(c, "housekeeping.assign"))
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": task})
+}
+
+// POST /api/housekeeping/tasks/:id/inspect {passed, note?}
+func (ctrl *HousekeepingController) InspectTask(c *gin.Context) {
+	id, ok := housekeepingIDParam(c)
+	if !ok {
+		return
+	}
+	var req InspectTaskRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	task, err := ctrl.HousekeepingSvc.InspectTask(id, *req.Passed, req.Note, middleware.CurrentActor(c))
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": task})
+}
+
+func housekeepingIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		middleware.Abort(c, services.ErrInvalidID)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,277 @@
+// controllers/kiosk_controller.go
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+	"hotel-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KioskController struct {
+	KioskSvc *services.KioskService
+}
+
+func NewKioskController(svc *services.KioskService) *KioskController {
+	return &KioskController{KioskSvc: svc}
+}
+
+// ---------------------------
+// Device registration (staff)
+// ---------------------------
+
+type RegisterKioskPayload struct {
+	HotelSettingID uint   `json:"hotelSettingId" binding:"required"`
+	Name           string `json:"name"`
+}
+
+// POST /api/kiosk-devices → token แสดงครั้งเดียว
+func (ctrl *KioskController) RegisterDevice(c *gin.Context) {
+	var payload RegisterKioskPayload
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	device, token, err := ctrl.KioskSvc.RegisterDevice(payload.HotelSettingID, payload.Name)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+
+	services.RecordAudit(ctrl.KioskSvc.DB, services.AuditEntry{
+		ActorType: services.ActorAdmin,
+		ActorID:   strconv.FormatUint(uint64(middleware.CurrentActor(c).AdminID), 10),
+		Action:    "kiosk.registered",
+		Target:    "kiosk:" + strconv.FormatUint(uint64(device.ID), 10),
+		IP:        c.ClientIP(),
+	})
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": device, "token": token})
+}
+
+// GET /api/kiosk-devices
+func (ctrl *KioskController) GetDevices(c *gin.Context) {
+	devices, err := ctrl.KioskSvc.ListDevices()
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": devices})
+}
+
+// DELETE /api/kiosk-devices/:id (revoke)
+func (ctrl *KioskController) RevokeDevice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		middleware.Abort(c, services.ErrInvalidID)
+		return
+	}
+	if err := ctrl.KioskSvc.RevokeDevice(uint(id)); err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+
+	services.RecordAudit(ctrl.KioskSvc.DB, services.AuditEntry{
+		ActorType: services.ActorAdmin,
+		ActorID:   strconv.FormatUint(uint64(middleware.CurrentActor(c).AdminID), 10),
+		Action:    "kiosk.revoked",
+		Target:    "kiosk:" + c.Param("id"),
+		IP:        c.ClientIP(),
+	})
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// ---------------------------
+// Kiosk flow (X-Kiosk-Token)
+// ---------------------------
+
+type KioskLookupPayload struct {
+	CheckinCode string `json:"checkinCode"`
+	QR          string `json:"payload"` // ข้อความจาก QR (ชื่อเดียวกับ /checkin/scan)
+	Reference   string `json:"reference"`
+	Surname     string `json:"surname" binding:"required"`
+}
+
+type KioskDocumentPayload struct {
+	Token        string `json:"token" binding:"required"`
+	DocumentType string `json:"documentType" binding:"required"` // idcard | passport
+	Image        string `json:"image" binding:"required"`
+}
+
+type KioskFacePayload struct {
+	Token string `json:"token" binding:"required"`
+	Image string `json:"image" binding:"required"`
+}
+
+type KioskCompletePayload struct {
+	Token string   `json:"token" binding:"required"`
+	Flags []string `json:"flags"`
+}
+
+// GET /api/kiosk/me
+func (ctrl *KioskController) Me(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": middleware.KioskDevice(c)})
+}
+
+// POST /api/kiosk/lookup
+func (ctrl *KioskController) Lookup(c *gin.Context) {
+	var payload KioskLookupPayload
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	bi, booking, err := ctrl.KioskSvc.Lookup(middleware.KioskDevice(c), c.ClientIP(), services.KioskLookupInput{
+		CheckinCode: payload.CheckinCode,
+		QR:          payload.QR,
+		Reference:   payload.Reference,
+		Surname:     payload.Surname,
+	})
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	middleware.PreferLocale(c, booking.Customer.PreferredLanguage)
+
+	rooms := []string{}
+	for _, br := range services.CurrentBookingRooms(booking.Rooms) {
+		num := strings.TrimSpace(br.Room.RoomCode)
+		if num == "" {
+			num = strings.TrimSpace(br.Room.RoomNumber)
+		}
+		if num != "" {
+			rooms = append(rooms, num)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":        "ok",
+		"bookingInfoId": bi.ID,
+		"bookingId":     bi.BookingID,
+		"token":         bi.Token,
+		"booking": gin.H{
+			"referenceCode": booking.ReferenceCode,
+			"guestName":     booking.Customer.FullName,
+			"checkIn":       booking.CheckIn,
+			"checkOut":      booking.CheckOut,
+			"adults":        booking.Adults,
+			"children":      booking.Children,
+			"rooms":         rooms,
+		},
+	})
+}
+
+// POST /api/kiosk/document → OCR
+func (ctrl *KioskController) ReadDocument(c *gin.Context) {
+	var payload KioskDocumentPayload
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	fields, path, err := ctrl.KioskSvc.ReadDocument(middleware.KioskDevice(c), c.ClientIP(), payload.Token, payload.DocumentType, payload.Image)
+	if err != nil {
+		if errors.Is(err, services.ErrDocumentReadFailed) {
+			// รูปบันทึกแล้ว ให้แขกกรอกเองต่อได้
+			log.Printf("kiosk OCR failed: %v", err)
+			c.JSON(http.StatusOK, gin.H{"status": "ocr_failed", "documentImagePath": path, "fields": gin.H{}})
+			return
+		}
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "documentImagePath": path, "fields": fields})
+}
+
+// POST /api/kiosk/face
+func (ctrl *KioskController) CaptureFace(c *gin.Context) {
+	var payload KioskFacePayload
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	path, err := ctrl.KioskSvc.CaptureFace(middleware.KioskDevice(c), c.ClientIP(), payload.Token, payload.Image)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "faceImagePath": path})
+}
+
+// GET /api/kiosk/session?token=
+func (ctrl *KioskController) GetSession(c *gin.Context) {
+	state, err := ctrl.KioskSvc.Bookings.Drafts.Get(c.Query("token"))
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": state})
+}
+
+// PUT /api/kiosk/session { token, step, data } — guests / documents / consents / arrival_time
+func (ctrl *KioskController) SaveStep(c *gin.Context) {
+	var payload SaveCheckInStepPayload
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if strings.TrimSpace(payload.Token) == "" {
+		middleware.Abort(c, services.ErrMissingToken)
+		return
+	}
+
+	state, err := ctrl.KioskSvc.SaveStep(middleware.KioskDevice(c), c.ClientIP(), payload.Token, payload.Step, payload.Data)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": state})
+}
+
+// POST /api/kiosk/complete
+// 200 = เช็คอินแล้ว, 202 = รอ staff ตรวจ (ดูสถานะที่ /api/kiosk/cases/:id)
+func (ctrl *KioskController) Complete(c *gin.Context) {
+	var payload KioskCompletePayload
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	state, vc, err := ctrl.KioskSvc.Complete(middleware.KioskDevice(c), c.ClientIP(), payload.Token, payload.Flags)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	if vc != nil {
+		c.JSON(http.StatusAccepted, gin.H{
+			"status":  "pending_review",
+			"message": middleware.Message(c, utils.MsgCheckinPendingReview),
+			"caseId":  vc.ID,
+			"reasons": strings.Split(vc.Reasons, ","),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": middleware.Message(c, utils.MsgCheckinCompleted), "data": state})
+}
+
+// GET /api/kiosk/cases/:id
+func (ctrl *KioskController) GetCase(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		middleware.Abort(c, services.ErrInvalidID)
+		return
+	}
+	vc, err := ctrl.KioskSvc.GetCase(middleware.KioskDevice(c), uint(id))
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{
+		"id":         vc.ID,
+		"status":     vc.Status,
+		"reasons":    strings.Split(vc.Reasons, ","),
+		"reviewedAt": vc.ReviewedAt,
+	}})
+}
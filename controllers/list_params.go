@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindListQuery อ่าน ?limit/offset/page/cursor/sort/filter ตาม spec
+// ถ้าไม่ถูกต้องจะตอบ 400 error.validation แล้วคืน false
+func bindListQuery(c *gin.Context, spec services.ListSpec) (services.ListQuery, bool) {
+	q, err := services.ParseListQuery(c.Request.URL.Query(), spec)
+	if err != nil {
+		respondListError(c, err)
+		return q, false
+	}
+	return q, true
+}
+
+// respondListError: cursor/param ผิด → 400 validation, domain error ตอบตาม kind, อย่างอื่น → 500
+func respondListError(c *gin.Context, err error) {
+	var perr *services.ListParamError
+	if errors.As(err, &perr) {
+		respondValidationError(c, &ValidationError{Fields: []FieldError{{Field: perr.Param, Rule: perr.Rule, param: perr.Value}}})
+		return
+	}
+	var derr *services.Error
+	if errors.As(err, &derr) {
+		middleware.Abort(c, err)
+		return
+	}
+	middleware.Abort(c, services.ErrInternal.Wrap(err))
+}
+
+// writePageHeaders ใส่ X-Total-Count, X-Next-Cursor และ Link (RFC 8288)
+//
+//	offset: rel="first", "prev", "next", "last"
+//	cursor: rel="first", "next"
+func writePageHeaders(c *gin.Context, page services.PageInfo) {
+	c.Header("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+	}
+
+	link := func(rel string, set map[string]string) string {
+		u := *c.Request.URL
+		values := u.Query()
+		for _, k := range []string{"offset", "page", "cursor"} {
+			values.Del(k)
+		}
+		values.Set("limit", strconv.Itoa(page.Limit))
+		for k, v := range set {
+			values.Set(k, v)
+		}
+		u.RawQuery = values.Encode()
+		return fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), rel)
+	}
+
+	links := []string{link("first", nil)}
+	if page.Cursor {
+		if page.NextCursor != "" {
+			links = append(links, link("next", map[string]string{"cursor": page.NextCursor}))
+		}
+	} else {
+		if page.Offset > 0 {
+			prev := page.Offset - page.Limit
+			if prev < 0 {
+				prev = 0
+			}
+			links = append(links, link("prev", map[string]string{"offset": strconv.Itoa(prev)}))
+		}
+		if page.NextCursor != "" {
+			links = append(links, link("next", map[string]string{"offset": strconv.Itoa(page.Offset + page.Count)}))
+		}
+		if page.Total > 0 {
+			last := (page.Total - 1) / int64(page.Limit) * int64(page.Limit)
+			links = append(links, link("last", map[string]string{"offset": strconv.FormatInt(last, 10)}))
+		}
+	}
+	c.Header("Link", strings.Join(links, ", "))
+}
+
+// respondPage: 200 + page headers + body ตาม shape เดิมของ endpoint
+func respondPage(c *gin.Context, page services.PageInfo, body any) {
+	writePageHeaders(c, page)
+	c.JSON(http.StatusOK, body)
+}
@@ -0,0 +1,299 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+	"hotel-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MaintenanceController struct {
+	MaintenanceSvc *services.MaintenanceService
+}
+
+func NewMaintenanceController(svc *services.MaintenanceService) *MaintenanceController {
+	return &MaintenanceController{MaintenanceSvc: svc}
+}
+
+// MaintenanceTicketRequest body ของ POST / PATCH /api/maintenance/tickets
+// photos = รูปใหม่แบบ base64, assigneeId: 0 = เลิก assign, outOfOrder ใช้ตอนสร้างเท่านั้น
+type MaintenanceTicketRequest struct {
+	RoomID         *uint              `json:"roomId" alias:"room_id" binding:"omitempty,gt=0"`
+	Category       *string            `json:"category" binding:"omitempty,oneof=plumbing electrical hvac furniture appliance structural other"`
+	Priority       *string            `json:"priority" binding:"omitempty,oneof=low normal high urgent"`
+	Title          *string            `json:"title" binding:"omitempty,max=255"`
+	Description    *string            `json:"description" binding:"omitempty,max=5000"`
+	AssigneeID     *uint              `json:"assigneeId" alias:"assignee_id"`
+	Status         *string            `json:"status" binding:"omitempty,oneof=open in_progress on_hold resolved closed cancelled"`
+	ResolutionNote *string            `json:"resolutionNote" alias:"resolution_note" binding:"omitempty,max=2000"`
+	Photos         []string           `json:"photos" binding:"omitempty,max=10"`
+	RemovePhotos   []string           `json:"removePhotos" alias:"remove_photos"`
+	OutOfOrder     *OutOfOrderRequest `json:"outOfOrder" alias:"out_of_order"`
+}
+
+func (r MaintenanceTicketRequest) input() services.MaintenanceTicketInput {
+	return services.MaintenanceTicketInput{
+		RoomID:         r.RoomID,
+		Category:       r.Category,
+		Priority:       r.Priority,
+		Title:          r.Title,
+		Description:    r.Description,
+		AssigneeID:     r.AssigneeID,
+		Status:         r.Status,
+		ResolutionNote: r.ResolutionNote,
+		AddPhotos:      r.Photos,
+		RemovePhotos:   r.RemovePhotos,
+	}
+}
+
+// OutOfOrderRequest ช่วงปิดห้อง [startDate, endDate) — endDate คือวันแรกที่กลับมาขายได้
+type OutOfOrderRequest struct {
+	RoomID    *uint  `json:"roomId" alias:"room_id" binding:"omitempty,gt=0"`
+	TicketID  *uint  `json:"ticketId" alias:"ticket_id" binding:"omitempty,gt=0"`
+	StartDate string `json:"startDate" alias:"start_date" binding:"required,dateonly"`
+	EndDate   string `json:"endDate" alias:"end_date" binding:"required,dateonly"`
+	Reason    string `json:"reason" binding:"max=255"`
+}
+
+func (r OutOfOrderRequest) input() services.OutOfOrderInput {
+	in := services.OutOfOrderInput{TicketID: r.TicketID, Reason: r.Reason}
+	if r.RoomID != nil {
+		in.RoomID = *r.RoomID
+	}
+	in.StartDate, _ = parseDateOnly(r.StartDate)
+	in.EndDate, _ = parseDateOnly(r.EndDate)
+	return in
+}
+
+// BlockUpdateRequest body ของ PATCH /api/maintenance/blocks/:id
+type BlockUpdateRequest struct {
+	StartDate *string `json:"startDate" alias:"start_date" binding:"omitempty,dateonly"`
+	EndDate   *string `json:"endDate" alias:"end_date" binding:"omitempty,dateonly"`
+	Reason    *string `json:"reason" binding:"omitempty,max=255"`
+}
+
+// respondMaintenanceError อ้างอิงที่ไม่มีอยู่ / ช่วงวันที่ผิด ตอบเป็น validation error ของ field
+// prefix = ชื่อ object ของช่วงปิดห้องใน body (เช่น "outOfOrder.")
+func respondMaintenanceError(c *gin.Context, err error, prefix string) {
+	switch {
+	case errors.Is(err, services.ErrRoomNotFound):
+		respondValidationError(c, fieldError("roomId", "exists", ""))
+	case errors.Is(err, services.ErrAssigneeNotFound):
+		respondValidationError(c, fieldError("assigneeId", "exists", ""))
+	case errors.Is(err, services.ErrInvalidBlockRange):
+		respondValidationError(c, fieldError(prefix+"endDate", "after", prefix+"startDate"))
+	case errors.Is(err, services.ErrBlockRoomMismatch):
+		respondValidationError(c, fieldError(prefix+"ticketId", "invalid", ""))
+	default:
+		middleware.Abort(c, err)
+	}
+}
+
+// blockResponse ตอบ block พร้อมคำเตือนเมื่อมี booking ทับช่วงที่ปิด
+func blockResponse(c *gin.Context, data interface{}, conflicts []services.BlockConflict) gin.H {
+	body := gin.H{"status": "success", "data": data}
+	if len(conflicts) > 0 {
+		body["warning"] = gin.H{
+			"code":     utils.MsgRoomBlockConflicts,
+			"message":  middleware.Message(c, utils.MsgRoomBlockConflicts, "count", strconv.Itoa(len(conflicts))),
+			"bookings": conflicts,
+		}
+	}
+	return body
+}
+
+// GET /api/maintenance/tickets?status=open,in_progress&roomId=&assigneeId=&category=&priority=
+func (ctrl *MaintenanceController) GetTickets(c *gin.Context) {
+	q, ok := bindListQuery(c, services.MaintenanceTicketListSpec)
+	if !ok {
+		return
+	}
+	tickets, page, err := ctrl.MaintenanceSvc.ListTickets(q)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	respondPage(c, page, gin.H{"status": "success", "data": tickets})
+}
+
+// GET /api/maintenance/tickets/:id
+func (ctrl *MaintenanceController) GetTicket(c *gin.Context) {
+	id, ok := maintenanceIDParam(c)
+	if !ok {
+		return
+	}
+	ticket, err := ctrl.MaintenanceSvc.GetTicket(id)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": ticket})
+}
+
+// POST /api/maintenance/tickets {roomId, category?, priority?, title?, description?, photos?, assigneeId?, outOfOrder?}
+// outOfOrder ต้องมี roomManagement.editStatus, assigneeId ต้องมี maintenance.assign
+func (ctrl *MaintenanceController) CreateTicket(c *gin.Context) {
+	var req MaintenanceTicketRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.RoomID == nil {
+		respondValidationError(c, fieldError("roomId", "required", ""))
+		return
+	}
+	if !ctrl.allowTicketInput(c, req) {
+		return
+	}
+	var ooo *services.OutOfOrderInput
+	if req.OutOfOrder != nil {
+		in := req.OutOfOrder.input()
+		ooo = &in
+	}
+	ticket, conflicts, err := ctrl.MaintenanceSvc.CreateTicket(req.input(), ooo, middleware.CurrentActor(c))
+	if err != nil {
+		respondMaintenanceError(c, err, "outOfOrder.")
+		return
+	}
+	c.JSON(http.StatusCreated, blockResponse(c, ticket, conflicts))
+}
+
+// PATCH /api/maintenance/tickets/:id {status?, assigneeId?, photos?, removePhotos?, ...}
+func (ctrl *MaintenanceController) UpdateTicket(c *gin.Context) {
+	id, ok := maintenanceIDParam(c)
+	if !ok {
+		return
+	}
+	var req MaintenanceTicketRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.RoomID != nil {
+		respondValidationError(c, fieldError("roomId", "invalid", ""))
+		return
+	}
+	if req.OutOfOrder != nil {
+		respondValidationError(c, fieldError("outOfOrder", "invalid", ""))
+		return
+	}
+	if !ctrl.allowTicketInput(c, req) {
+		return
+	}
+	ticket, err := ctrl.MaintenanceSvc.UpdateTicket(id, req.input(), middleware.CurrentActor(c))
+	if err != nil {
+		respondMaintenanceError(c, err, "")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": ticket})
+}
+
+// allowTicketInput ตรวจสิทธิ์เพิ่มของ field ที่กระทบมากกว่าตัวงานซ่อม
+func (ctrl *MaintenanceController) allowTicketInput(c *gin.Context, req MaintenanceTicketRequest) bool {
+	need := ""
+	switch {
+	case req.AssigneeID != nil && !middleware.HasPermission(c, "maintenance.assign"):
+		need = "maintenance.assign"
+	case req.OutOfOrder != nil && !middleware.HasPermission(c, "roomManagement.editStatus"):
+		need = "roomManagement.editStatus"
+	}
+	if need != "" {
+		middleware.Abort(c, services.ErrPermissionDenied.WithDetails(gin.H{"permission": need}))
+		return false
+	}
+	return true
+}
+
+// GET /api/maintenance/blocks?roomId=&from=YYYY-MM-DD (from ไม่ส่ง = วันนี้)
+func (ctrl *MaintenanceController) GetBlocks(c *gin.Context) {
+	roomID, ok := optionalUintQuery(c, "roomId")
+	if !ok {
+		return
+	}
+	from := services.HousekeepingDate(time.Now())
+	if v := c.Query("from"); v != "" {
+		d, ok := parseDateOnly(v)
+		if !ok {
+			respondValidationError(c, fieldError("from", "dateonly", ""))
+			return
+		}
+		from = d
+	}
+	blocks, err := ctrl.MaintenanceSvc.ListBlocks(roomID, from)
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": blocks})
+}
+
+// POST /api/maintenance/blocks {roomId | ticketId, startDate, endDate, reason?}
+func (ctrl *MaintenanceController) CreateBlock(c *gin.Context) {
+	var req OutOfOrderRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.RoomID == nil && req.TicketID == nil {
+		respondValidationError(c, requiredOneOf("roomId", "ticketId"))
+		return
+	}
+	block, conflicts, err := ctrl.MaintenanceSvc.CreateBlock(req.input(), middleware.CurrentActor(c))
+	if err != nil {
+		respondMaintenanceError(c, err, "")
+		return
+	}
+	c.JSON(http.StatusCreated, blockResponse(c, block, conflicts))
+}
+
+// PATCH /api/maintenance/blocks/:id {startDate?, endDate?, reason?}
+func (ctrl *MaintenanceController) UpdateBlock(c *gin.Context) {
+	id, ok := maintenanceIDParam(c)
+	if !ok {
+		return
+	}
+	var req BlockUpdateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	in := services.BlockUpdateInput{Reason: req.Reason}
+	if req.StartDate != nil {
+		d, _ := parseDateOnly(*req.StartDate)
+		in.StartDate = &d
+	}
+	if req.EndDate != nil {
+		d, _ := parseDateOnly(*req.EndDate)
+		in.EndDate = &d
+	}
+	block, conflicts, err := ctrl.MaintenanceSvc.UpdateBlock(id, in, middleware.CurrentActor(c))
+	if err != nil {
+		respondMaintenanceError(c, err, "")
+		return
+	}
+	c.JSON(http.StatusOK, blockResponse(c, block, conflicts))
+}
+
+// DELETE /api/maintenance/blocks/:id
+func (ctrl *MaintenanceController) DeleteBlock(c *gin.Context) {
+	id, ok := maintenanceIDParam(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.MaintenanceSvc.DeleteBlock(id, middleware.CurrentActor(c)); err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": middleware.Message(c, utils.MsgRoomBlockDeleted)})
+}
+
+func maintenanceIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		middleware.Abort(c, services.ErrInvalidID)
+		return 0, false
+	}
+	return uint(id), true
+}
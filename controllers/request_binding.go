@@ -0,0 +1,322 @@
+// controllers/request_binding.go
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+	"hotel-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ---------------------------
+// Typed request binding
+//
+// DTO ใช้ json tag เป็นชื่อหลัก (camelCase หรือ snake_case ตามที่ endpoint ใช้มาแต่เดิม)
+// ชื่ออื่นที่รับได้ต้องประกาศใน tag `alias:"a,b"` ของ field นั้นเท่านั้น
+// validation ใช้ tag `binding` (go-playground/validator) เหมือน ShouldBindJSON
+// ---------------------------
+
+const maxRequestBody = 8 << 20 // รองรับรูป base64
+
+// FieldError รายละเอียด validation ต่อ field (field เป็นชื่อ JSON เช่น "guests[0].fullName")
+// Message แปลจาก catalog (validation.<key>) ตอนตอบ request
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+
+	key   string // key ใน catalog ถ้าต่างจาก rule (เช่น minLen)
+	param string // ค่าแทน {param}
+}
+
+func (f FieldError) messageKey() string {
+	if f.key != "" {
+		return "validation." + f.key
+	}
+	return "validation." + f.Rule
+}
+
+// ValidationError รวม FieldError ของ request หนึ่ง
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+utils.Message("en", f.messageKey(), "param", f.param))
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		// ให้ error อ้างชื่อ field ตาม JSON ไม่ใช่ชื่อ Go
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			if name == "" || name == "-" {
+				return f.Name
+			}
+			return name
+		})
+		_ = v.RegisterValidation("dateonly", validateDateOnly)
+	}
+}
+
+// dateonly: "2006-01-02" หรือ RFC3339 (frontend บางหน้าส่งแบบมีเวลา)
+func validateDateOnly(fl validator.FieldLevel) bool {
+	_, ok := parseDateOnly(fl.Field().String())
+	return ok
+}
+
+func parseDateOnly(v string) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), true
+	}
+	return time.Time{}, false
+}
+
+// bindJSON อ่าน body → แปลง alias → decode → validate
+// ถ้าไม่ผ่านจะตอบ 400 error.validation พร้อมรายการ field แล้วคืน false
+func bindJSON(c *gin.Context, dst interface{}) bool {
+	if err := decodeJSON(c, dst); err != nil {
+		respondValidationError(c, err)
+		return false
+	}
+	return true
+}
+
+func decodeJSON(c *gin.Context, dst interface{}) error {
+	if c.Request.Body == nil {
+		return fieldError("", "body", "")
+	}
+	raw, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRequestBody))
+	if err != nil {
+		return err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return fieldError("", "body", "")
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return fieldError("", "json", "")
+	}
+	applyAliases(generic, reflect.TypeOf(dst))
+	normalized, _ := json.Marshal(generic)
+
+	if err := json.Unmarshal(normalized, dst); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return fieldError(typeErr.Field, "type", jsonKind(typeErr.Type))
+		}
+		return fieldError("", "json", "")
+	}
+
+	if err := binding.Validator.ValidateStruct(dst); err != nil {
+		return toValidationError(err)
+	}
+	return nil
+}
+
+// applyAliases ย้ายค่าจากชื่อ alias ไปชื่อหลัก (เฉพาะเมื่อไม่มีชื่อหลักส่งมา) ตามโครงสร้างของ DTO
+func applyAliases(v interface{}, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if arr, ok := v.([]interface{}); ok {
+			for _, item := range arr {
+				applyAliases(item, t.Elem())
+			}
+		}
+	case reflect.Struct:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		names := make(map[string]bool, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			names[name] = true
+			if _, present := obj[name]; !present {
+				for _, alias := range strings.Split(f.Tag.Get("alias"), ",") {
+					alias = strings.TrimSpace(alias)
+					if val, ok := obj[alias]; ok && alias != "" {
+						obj[name] = val
+						delete(obj, alias)
+						break
+					}
+				}
+			}
+			if child, ok := obj[name]; ok {
+				applyAliases(child, f.Type)
+			}
+		}
+		// encoding/json จับคู่ชื่อแบบไม่สนตัวพิมพ์ — ตัด key ที่ไม่ตรงเป๊ะออก ไม่งั้น "fullname" จะกลายเป็น alias แฝง
+		for key := range obj {
+			if names[key] {
+				continue
+			}
+			for name := range names {
+				if strings.EqualFold(key, name) {
+					delete(obj, key)
+					break
+				}
+			}
+		}
+	}
+}
+
+func toValidationError(err error) error {
+	var ves validator.ValidationErrors
+	if !errors.As(err, &ves) {
+		return fieldError("", "invalid", "")
+	}
+	out := &ValidationError{}
+	for _, fe := range ves {
+		ns := fe.Namespace()
+		if i := strings.Index(ns, "."); i >= 0 {
+			ns = ns[i+1:] // ตัดชื่อ struct ตัวนอกสุด
+		}
+		key, param := ruleMessage(fe)
+		out.Fields = append(out.Fields, FieldError{Field: ns, Rule: fe.Tag(), key: key, param: param})
+	}
+	return out
+}
+
+// ruleMessage: validator tag → key ของ validation.* ใน catalog + ค่าแทน {param}
+func ruleMessage(fe validator.FieldError) (string, string) {
+	lengthKind := fe.Kind() == reflect.String || fe.Kind() == reflect.Slice
+	switch fe.Tag() {
+	case "required", "email", "dateonly":
+		return fe.Tag(), ""
+	case "oneof":
+		return "oneof", strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "datetime", "gt", "gte", "lte":
+		return fe.Tag(), fe.Param()
+	case "max", "min":
+		if lengthKind {
+			return fe.Tag() + "Len", fe.Param()
+		}
+		return fe.Tag(), fe.Param()
+	}
+	return "unknown", fe.Tag()
+}
+
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return t.String()
+}
+
+// respondValidationError: 400 พร้อม field-level errors (message แปลตามภาษาของ request)
+//
+//	{"error": {"code": "error.validation", "message": "...", "details": {"fields": [{field, rule, message}]}}}
+func respondValidationError(c *gin.Context, err error) {
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		verr = fieldError("", "invalid", "")
+	}
+	fields := make([]FieldError, len(verr.Fields))
+	for i, f := range verr.Fields {
+		f.Message = middleware.Message(c, f.messageKey(), "param", f.param)
+		fields[i] = f
+	}
+	middleware.Abort(c, services.ErrValidation.WithDetails(gin.H{"fields": fields}))
+}
+
+// fieldError สร้าง ValidationError ของ field เดียว (ใช้กับเงื่อนไขที่ tag ทำไม่ได้)
+// rule เป็นชื่อ validation.* ใน catalog, param แทน {param} ในข้อความ
+func fieldError(field, rule, param string) *ValidationError {
+	return &ValidationError{Fields: []FieldError{{Field: field, Rule: rule, param: param}}}
+}
+
+// requiredOneOf: ต้องส่งอย่างน้อยหนึ่งใน names (รายงานที่ field ตัวแรก)
+func requiredOneOf(names ...string) *ValidationError {
+	return &ValidationError{Fields: []FieldError{{Field: names[0], Rule: "required", key: "requiredOneOf", param: strings.Join(names, ", ")}}}
+}
+
+// BookingRef รับ booking ได้ทั้งเลข id (number หรือ string ตัวเลข) และ booking token
+type BookingRef struct {
+	ID    *uint
+	Token string
+}
+
+func (r *BookingRef) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || string(b) == "null" {
+		return nil
+	}
+	var s string
+	if b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	} else {
+		s = string(b)
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if id, err := strconv.ParseUint(s, 10, 64); err == nil {
+		if id > 0 {
+			n := uint(id)
+			r.ID = &n
+		}
+		return nil
+	}
+	if b[0] != '"' {
+		return &json.UnmarshalTypeError{Value: "number " + s, Type: reflect.TypeOf(uint(0))}
+	}
+	r.Token = s
+	return nil
+}
+
+func (r BookingRef) IsZero() bool { return r.ID == nil && r.Token == "" }
@@ -6,7 +6,9 @@ import (
 	"strings"
 
 	"hotel-backend/config"
+	"hotel-backend/middleware"
 	"hotel-backend/models"
+	"hotel-backend/services"
 
 	"github.com/gin-gonic/gin"
 
@@ -30,6 +32,9 @@ var defaultActionsByModule = map[string][]string{
 	"customerList":        {"view", "create", "edit", "delete", "export"},
 	"tm30Verification":    {"view", "submit", "verify"},
 	"rolesAndPermissions": {"view", "create", "edit", "delete"},
+	"housekeeping":        {"view", "update", "assign", "inspect"},
+	"maintenance":         {"view", "create", "update", "assign"},
+	"settings":            {"view", "edit"},
 }
 
 func buildDefaultPermissions() map[string]map[string]bool {
@@ -54,7 +59,7 @@ type roleResponse struct {
 func GetRoles(c *gin.Context) {
 	var roles []models.Role
 	if err := config.DB.Preload("Permissions").Preload("Members").Find(&roles).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -98,7 +103,7 @@ func GetRoles(c *gin.Context) {
 func UpdateRolePermissions(c *gin.Context) {
 	var payload rolePermissionsPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
 		return
 	}
 
@@ -107,24 +112,24 @@ func UpdateRolePermissions(c *gin.Context) {
 	roleID, err := strconv.ParseUint(idStr, 10, 64)
 	if err == nil && roleID > 0 {
 		if err := config.DB.First(&role, roleID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+			middleware.Abort(c, services.ErrRoleNotFound)
 			return
 		}
 	} else {
 		if err := config.DB.Where("name = ?", idStr).First(&role).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+			middleware.Abort(c, services.ErrRoleNotFound)
 			return
 		}
 		roleID = uint64(role.ID)
 	}
 
 	if roleID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role id"})
+		middleware.Abort(c, services.ErrInvalidID)
 		return
 	}
 
 	if role.ID == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+		middleware.Abort(c, services.ErrRoleNotFound)
 		return
 	}
 
@@ -155,7 +160,7 @@ func UpdateRolePermissions(c *gin.Context) {
 
 		return nil
 	}); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
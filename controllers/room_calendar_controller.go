@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+	"hotel-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoomCalendarController struct {
+	CalendarSvc *services.RoomCalendarService
+}
+
+func NewRoomCalendarController(svc *services.RoomCalendarService) *RoomCalendarController {
+	return &RoomCalendarController{CalendarSvc: svc}
+}
+
+// CalendarFeedRequest body ของ POST /api/calendars/feeds
+type CalendarFeedRequest struct {
+	Scope    string `json:"scope" binding:"required,oneof=room roomType"`
+	TargetID uint   `json:"targetId" alias:"target_id" binding:"required,gt=0"`
+	Name     string `json:"name" binding:"max=100"`
+}
+
+// CalendarImportRequest body ของ POST /api/calendars/imports
+type CalendarImportRequest struct {
+	RoomID uint   `json:"roomId" alias:"room_id" binding:"required,gt=0"`
+	Name   string `json:"name" binding:"max=100"`
+	URL    string `json:"url" binding:"required,max=1024"`
+}
+
+// calendarFeedURL URL ที่ให้ปฏิทินภายนอก subscribe (PUBLIC_BASE_URL หรือ host ของ request)
+func calendarFeedURL(c *gin.Context, token string) string {
+	base := strings.TrimRight(utils.EnvOrDefault("PUBLIC_BASE_URL", ""), "/")
+	if base == "" {
+		scheme := "http"
+		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		base = scheme + "://" + c.Request.Host
+	}
+	return base + "/api/calendars/feeds/" + token + ".ics"
+}
+
+// GET /api/calendars/feeds/:token(.ics) — public, token เป็นสิทธิ์เข้าถึง
+func (ctrl *RoomCalendarController) ServeFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	feed, err := ctrl.CalendarSvc.FeedByToken(token)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	events, err := ctrl.CalendarSvc.FeedEvents(feed)
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	var buf bytes.Buffer
+	if err := utils.WriteICalendar(&buf, feed.Name, events); err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+// GET /api/calendars/feeds
+func (ctrl *RoomCalendarController) GetFeeds(c *gin.Context) {
+	feeds, err := ctrl.CalendarSvc.ListFeeds()
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": feeds})
+}
+
+// POST /api/calendars/feeds {scope: room|roomType, targetId, name?} → token / url แสดงครั้งเดียว
+func (ctrl *RoomCalendarController) CreateFeed(c *gin.Context) {
+	var req CalendarFeedRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	feed, token, err := ctrl.CalendarSvc.CreateFeed(req.Scope, req.TargetID, strings.TrimSpace(req.Name), middleware.CurrentActor(c))
+	if err != nil {
+		if errors.Is(err, services.ErrRoomNotFound) || errors.Is(err, services.ErrRoomTypeNotFound) {
+			respondValidationError(c, fieldError("targetId", "exists", strconv.FormatUint(uint64(req.TargetID), 10)))
+			return
+		}
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"status": "success",
+		"data":   feed,
+		"token":  token,
+		"url":    calendarFeedURL(c, token),
+	})
+}
+
+// DELETE /api/calendars/feeds/:id
+func (ctrl *RoomCalendarController) RevokeFeed(c *gin.Context) {
+	id, ok := calendarIDParam(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.CalendarSvc.RevokeFeed(id, middleware.CurrentActor(c)); err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": middleware.Message(c, utils.MsgCalendarFeedRevoked)})
+}
+
+// GET /api/calendars/imports?roomId=12
+func (ctrl *RoomCalendarController) GetImports(c *gin.Context) {
+	roomID, ok := optionalUintQuery(c, "roomId")
+	if !ok {
+		return
+	}
+	imports, err := ctrl.CalendarSvc.ListImports(roomID)
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": imports})
+}
+
+// POST /api/calendars/imports {roomId, url, name?} → sync ทันที (ผลอยู่ใน lastError / eventCount)
+func (ctrl *RoomCalendarController) CreateImport(c *gin.Context) {
+	var req CalendarImportRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	imp, err := ctrl.CalendarSvc.CreateImport(req.RoomID, req.Name, req.URL, middleware.CurrentActor(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCalendarURL):
+			respondValidationError(c, fieldError("url", "invalid", ""))
+		case errors.Is(err, services.ErrRoomNotFound):
+			respondValidationError(c, fieldError("roomId", "exists", strconv.FormatUint(uint64(req.RoomID), 10)))
+		default:
+			middleware.Abort(c, err)
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "data": imp})
+}
+
+// POST /api/calendars/imports/:id/sync
+func (ctrl *RoomCalendarController) SyncImport(c *gin.Context) {
+	id, ok := calendarIDParam(c)
+	if !ok {
+		return
+	}
+	imp, err := ctrl.CalendarSvc.SyncImport(id)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": imp})
+}
+
+// DELETE /api/calendars/imports/:id → ลบ block ที่มาจากปฏิทินนี้ด้วย
+func (ctrl *RoomCalendarController) DeleteImport(c *gin.Context) {
+	id, ok := calendarIDParam(c)
+	if !ok {
+		return
+	}
+	if err := ctrl.CalendarSvc.DeleteImport(id, middleware.CurrentActor(c)); err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": middleware.Message(c, utils.MsgCalendarImportDeleted)})
+}
+
+// GET /api/calendars/blocks?roomId=12 → block ที่ยังไม่สิ้นสุด (ตั้งแต่วันนี้)
+func (ctrl *RoomCalendarController) GetBlocks(c *gin.Context) {
+	roomID, ok := optionalUintQuery(c, "roomId")
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	blocks, err := ctrl.CalendarSvc.ListBlocks(roomID, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": blocks})
+}
+
+func calendarIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		middleware.Abort(c, services.ErrInvalidID)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// optionalUintQuery query param ตัวเลขที่ไม่บังคับ (ไม่ส่ง = 0)
+func optionalUintQuery(c *gin.Context, name string) (uint, bool) {
+	v := strings.TrimSpace(c.Query(name))
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		respondValidationError(c, fieldError(name, "type", "integer"))
+		return 0, false
+	}
+	return uint(n), true
+}
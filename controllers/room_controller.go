@@ -5,9 +5,12 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"hotel-backend/config"
+	"hotel-backend/middleware"
 	"hotel-backend/models"
+	"hotel-backend/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,11 +20,81 @@ import (
 // 1. Get Rooms (GET /api/rooms)
 // ----------------------------------------------------
 
+// roomListSpec: GET /api/rooms?cleanStatus=&serviceStatus=&roomTypeId=&floor=&sort=roomNumber&checkIn=&checkOut=
+// (occupancy / sellable คำนวณจาก booking และ block จึงกรองใน query ไม่ได้)
+var roomListSpec = services.ListSpec{
+	IDColumn: "id",
+	Sorts: map[string]string{
+		"id":            "id",
+		"roomNumber":    "room_number",
+		"floor":         "floor",
+		"cleanStatus":   "clean_status",
+		"serviceStatus": "service_status",
+		"price":         "price",
+		"createdAt":     "created_at",
+	},
+	DefaultSort: "id",
+	Filters: map[string]services.ListFilter{
+		"cleanStatus":   {Cond: "clean_status IN ?", Kind: services.FilterList},
+		"serviceStatus": {Cond: "service_status IN ?", Kind: services.FilterList},
+		"roomTypeId":    {Cond: "room_type_id = ?", Kind: services.FilterID},
+		"floor":         {Cond: "floor = ?", Kind: services.FilterText},
+	},
+	MaxLimit: 1000, // หน้าผังห้องโหลดทั้งโรงแรมในครั้งเดียว
+}
+
 func GetRooms(c *gin.Context) {
-	var rooms []models.Room
-	config.DB.Preload("RoomType").Find(&rooms)
+	q, ok := bindListQuery(c, roomListSpec)
+	if !ok {
+		return
+	}
+	from, to, ok := roomSellableRange(c)
+	if !ok {
+		return
+	}
+	rooms := []models.Room{}
+	page, err := services.FindPage(config.DB, roomListSpec, q, &rooms, "RoomType")
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	if err := services.FillRoomOccupancy(config.DB, rooms); err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	if err := services.FillRoomSellable(config.DB, rooms, from, to); err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+
+	respondPage(c, page, rooms)
+}
 
-	c.JSON(http.StatusOK, rooms)
+// roomSellableRange ?checkIn=&checkOut= ช่วงที่ใช้คำนวณ sellable (ไม่ส่ง = คืนนี้)
+func roomSellableRange(c *gin.Context) (time.Time, time.Time, bool) {
+	from := services.HousekeepingDate(time.Now())
+	if v := strings.TrimSpace(c.Query("checkIn")); v != "" {
+		d, ok := parseDateOnly(v)
+		if !ok {
+			respondValidationError(c, fieldError("checkIn", "dateonly", ""))
+			return from, from, false
+		}
+		from = d
+	}
+	to := from.AddDate(0, 0, 1)
+	if v := strings.TrimSpace(c.Query("checkOut")); v != "" {
+		d, ok := parseDateOnly(v)
+		if !ok {
+			respondValidationError(c, fieldError("checkOut", "dateonly", ""))
+			return from, from, false
+		}
+		if !d.After(from) {
+			respondValidationError(c, fieldError("checkOut", "after", "checkIn"))
+			return from, from, false
+		}
+		to = d
+	}
+	return from, to, true
 }
 
 
@@ -29,71 +102,67 @@ func GetRooms(c *gin.Context) {
 // 2. Create Room (POST /api/rooms)
 // ----------------------------------------------------
 
-func CreateRoom(c *gin.Context) {
-    var room models.Room
-
-    if err := c.ShouldBindJSON(&room); err != nil {
-        log.Printf("❌ JSON BINDING ERROR (400): %v", err)
-        c.JSON(http.StatusBadRequest, gin.H{
-            "status":  "error",
-            "message": "Invalid request payload",
-            "details": err.Error(),
-        })
-        return
-    }
-
-    // Normalize / trim room number (frontend sends roomNumber)
-    room.RoomNumber = strings.TrimSpace(room.RoomNumber)
-    if room.RoomNumber == "" {
-        log.Println("❌ RoomNumber cannot be empty.")
-        c.JSON(http.StatusBadRequest, gin.H{
-            "status":  "error",
-            "message": "Room Number is required.",
-        })
-        return
-    }
-
-    // If RoomTypeID pointer exists but is zero or invalid -> set to nil to avoid FK=0 insertion
-   if room.RoomTypeID != nil {
-    var rt models.RoomType
-    err := config.DB.
-        Where("id = ?", *room.RoomTypeID).
-        First(&rt).Error
-
-    if err != nil {
-        log.Printf("❌ Invalid RoomTypeID provided: %v", *room.RoomTypeID)
-        c.JSON(http.StatusBadRequest, gin.H{
-            "status": "error",
-            "message": "Invalid roomTypeID provided.",
-        })
-        return
-    }
+// CreateRoomRequest body ของ POST /api/rooms — ไม่มี field สถานะ
+// ห้องใหม่เริ่มที่ clean / in_service เสมอ เปลี่ยนต่อผ่าน PATCH /api/rooms/:id/status (มีประวัติ)
+type CreateRoomRequest struct {
+	RoomNumber   string  `json:"roomNumber" alias:"room_number" binding:"required,max=50"`
+	RoomCode     string  `json:"roomCode" alias:"room_code" binding:"max=50"`
+	RoomTypeID   *uint   `json:"RoomTypeID" alias:"roomTypeId,room_type_id"` // 0 = ไม่ระบุ
+	Type         string  `json:"type" binding:"max=255"`
+	Floor        string  `json:"floor" binding:"max=10"`
+	Price        float64 `json:"price" binding:"gte=0"`
+	MaxOccupancy int     `json:"maxOccupancy" alias:"max_occupancy" binding:"gte=0"`
+	Description  string  `json:"description"`
 }
 
+func CreateRoom(c *gin.Context) {
+	var req CreateRoomRequest
+	if !bindJSON(c, &req) {
+		return
+	}
 
+	room := models.Room{
+		RoomNumber:   strings.TrimSpace(req.RoomNumber),
+		RoomCode:     strings.TrimSpace(req.RoomCode),
+		RoomTypeID:   req.RoomTypeID,
+		Type:         strings.TrimSpace(req.Type),
+		Floor:        strings.TrimSpace(req.Floor),
+		Price:        req.Price,
+		MaxOccupancy: req.MaxOccupancy,
+		Description:  req.Description,
+	}
+	if room.RoomNumber == "" {
+		respondValidationError(c, fieldError("roomNumber", "required", ""))
+		return
+	}
+
+	if room.RoomTypeID != nil && *room.RoomTypeID == 0 {
+		room.RoomTypeID = nil // ไม่ให้ insert FK = 0
+	}
+	if room.RoomTypeID != nil {
+		var rt models.RoomType
+		if err := config.DB.Where("id = ?", *room.RoomTypeID).First(&rt).Error; err != nil {
+			log.Printf("❌ Invalid RoomTypeID provided: %v", *room.RoomTypeID)
+			respondValidationError(c, fieldError("roomTypeId", "exists", fmt.Sprint(*room.RoomTypeID)))
+			return
+		}
+	}
 
-    // Save
-    if result := config.DB.Create(&room); result.Error != nil {
-        // Check duplicate room_number (unique index)
-        if strings.Contains(result.Error.Error(), "Duplicate entry") || strings.Contains(result.Error.Error(), "UNIQUE constraint failed") {
-            log.Printf("❌ Duplicate Room Number: %s", room.RoomNumber)
-            c.JSON(http.StatusConflict, gin.H{
-                "status":  "error",
-                "message": fmt.Sprintf("Room Number '%s' already exists.", room.RoomNumber),
-            })
-            return
-        }
-
-        log.Printf("❌ DB ERROR: %v", result.Error)
-        c.JSON(http.StatusInternalServerError, gin.H{
-            "status":  "error",
-            "message": "Database error",
-            "details": result.Error.Error(),
-        })
-        return
-    }
-
-    c.JSON(http.StatusCreated, room)
+	// Save
+	if result := config.DB.Create(&room); result.Error != nil {
+		// Check duplicate room_number (unique index)
+		if strings.Contains(result.Error.Error(), "Duplicate entry") || strings.Contains(result.Error.Error(), "UNIQUE constraint failed") {
+			log.Printf("❌ Duplicate Room Number: %s", room.RoomNumber)
+			middleware.Abort(c, services.ErrRoomExists.WithDetails(gin.H{"roomNumber": room.RoomNumber}))
+			return
+		}
+
+		log.Printf("❌ DB ERROR: %v", result.Error)
+		middleware.Abort(c, services.ErrInternal.Wrap(result.Error))
+		return
+	}
+
+	c.JSON(http.StatusCreated, room)
 }
 
 
@@ -101,34 +170,49 @@ func CreateRoom(c *gin.Context) {
 // 3. Update Room (PATCH /api/rooms/:id)
 // ----------------------------------------------------
 
+// roomEditableColumns key ใน body ของ UpdateRoom (ทั้ง camelCase และ snake_case) → column
+var roomEditableColumns = map[string]string{
+	"roomNumber":    "room_number",
+	"room_number":   "room_number",
+	"roomCode":      "room_code",
+	"room_code":     "room_code",
+	"RoomTypeID":    "room_type_id",
+	"roomTypeId":    "room_type_id",
+	"room_type_id":  "room_type_id",
+	"type":          "type",
+	"floor":         "floor",
+	"price":         "price",
+	"maxOccupancy":  "max_occupancy",
+	"max_occupancy": "max_occupancy",
+	"description":   "description",
+}
+
 func UpdateRoom(c *gin.Context) {
 	id := c.Param("id")
 	var updateData map[string]interface{}
 
 	// Bind JSON
 	if err := c.ShouldBindJSON(&updateData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "error",
-			"message": "Invalid request payload",
-			"details": err.Error(),
-		})
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
 		return
 	}
 
-	// ป้องกันแก้ไขฟิลด์สำคัญ
-	delete(updateData, "id")
-	delete(updateData, "created_at")
-	delete(updateData, "updated_at")
-	delete(updateData, "deleted_at")
+	// แก้ได้เฉพาะข้อมูลห้อง สถานะ (ความสะอาด / ปิดใช้งาน) ต้องเปลี่ยนผ่าน PATCH /api/rooms/:id/status
+	updates := map[string]interface{}{}
+	for key, value := range updateData {
+		if column, ok := roomEditableColumns[key]; ok {
+			updates[column] = value
+		}
+	}
+	if len(updates) == 0 {
+		respondValidationError(c, fieldError("", "body", ""))
+		return
+	}
 
 	// Update DB
-	if err := config.DB.Model(&models.Room{}).Where("id = ?", id).Updates(updateData).Error; err != nil {
+	if err := config.DB.Model(&models.Room{}).Where("id = ?", id).Updates(updates).Error; err != nil {
 		log.Printf("❌ Update Error for Room %s: %v", id, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": "Update failed",
-			"details": err.Error(),
-		})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -152,19 +236,13 @@ func DeleteRoom(c *gin.Context) {
 
 	if result.Error != nil {
 		log.Printf("❌ DB Error during deletion (ID: %s): %v", id, result.Error)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": "Failed to delete room.",
-		})
+		middleware.Abort(c, services.ErrInternal.Wrap(result.Error))
 		return
 	}
 
 	if result.RowsAffected == 0 {
 		log.Printf("⚠️ No room found with ID: %s", id)
-		c.JSON(http.StatusNotFound, gin.H{
-			"status":  "error",
-			"message": fmt.Sprintf("Room with ID %s not found.", id),
-		})
+		middleware.Abort(c, services.ErrRoomNotFound)
 		return
 	}
 
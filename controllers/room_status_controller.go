@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoomStatusController struct {
+	StatusSvc *services.RoomStatusService
+}
+
+func NewRoomStatusController(svc *services.RoomStatusService) *RoomStatusController {
+	return &RoomStatusController{StatusSvc: svc}
+}
+
+// RoomStatusRequest body ของ PATCH /api/rooms/:id/status
+// serviceStatus ที่ไม่ใช่ in_service ต้องมี reason; serviceUntil = วันที่คาดว่าจะกลับมาขายได้
+type RoomStatusRequest struct {
+	CleanStatus   *string `json:"cleanStatus" alias:"clean_status" binding:"omitempty,oneof=dirty cleaning clean inspected"`
+	ServiceStatus *string `json:"serviceStatus" alias:"service_status" binding:"omitempty,oneof=in_service out_of_order out_of_service"`
+	Reason        string  `json:"reason" binding:"max=255"`
+	ServiceUntil  *string `json:"serviceUntil" alias:"service_until" binding:"omitempty,dateonly"`
+}
+
+// PATCH /api/rooms/:id/status {cleanStatus?, serviceStatus?, reason?, serviceUntil?}
+func (ctrl *RoomStatusController) UpdateStatus(c *gin.Context) {
+	id, ok := roomIDParam(c)
+	if !ok {
+		return
+	}
+	var req RoomStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.CleanStatus == nil && req.ServiceStatus == nil {
+		respondValidationError(c, requiredOneOf("cleanStatus", "serviceStatus"))
+		return
+	}
+	in := services.RoomStatusInput{CleanStatus: req.CleanStatus, ServiceStatus: req.ServiceStatus, Reason: req.Reason}
+	if req.ServiceUntil != nil {
+		if d, ok := parseDateOnly(*req.ServiceUntil); ok {
+			in.ServiceUntil = &d
+		}
+	}
+	room, err := ctrl.StatusSvc.UpdateStatus(id, in, middleware.CurrentActor(c))
+	if err != nil {
+		if errors.Is(err, services.ErrServiceReasonRequired) {
+			respondValidationError(c, fieldError("reason", "required", ""))
+			return
+		}
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": room})
+}
+
+// GET /api/rooms/:id/status-history?limit=100
+func (ctrl *RoomStatusController) GetHistory(c *gin.Context) {
+	id, ok := roomIDParam(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	history, err := ctrl.StatusSvc.History(id, limit)
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": history})
+}
+
+func roomIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		middleware.Abort(c, services.ErrInvalidID)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -3,7 +3,9 @@ package controllers
 import (
 	"net/http"
 	"hotel-backend/config"
+	"hotel-backend/middleware"
 	"hotel-backend/models"
+	"hotel-backend/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,7 +19,7 @@ func GetRoomTypes(c *gin.Context) {
 func CreateRoomType(c *gin.Context) {
 	var rt models.RoomType
 	if err := c.ShouldBindJSON(&rt); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
 		return
 	}
 
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"hotel-backend/middleware"
+	"hotel-backend/models"
+	"hotel-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SchedulerController struct {
+	SchedulerSvc *services.SchedulerService
+}
+
+func NewSchedulerController(svc *services.SchedulerService) *SchedulerController {
+	return &SchedulerController{SchedulerSvc: svc}
+}
+
+// GET /api/scheduler/settings
+func (ctrl *SchedulerController) GetSettings(c *gin.Context) {
+	setting, err := ctrl.SchedulerSvc.GetSettings()
+	if err != nil {
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": setting, "jobs": services.SchedulerJobs})
+}
+
+// PUT /api/scheduler/settings
+func (ctrl *SchedulerController) UpdateSettings(c *gin.Context) {
+	var payload models.ScheduleSetting
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
+		return
+	}
+
+	setting, err := ctrl.SchedulerSvc.UpdateSettings(payload)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": setting})
+}
+
+// GET /api/scheduler/runs?job=checkin_reminder&limit=50
+func (ctrl *SchedulerController) GetRuns(c *gin.Context) {
+	q, ok := bindListQuery(c, services.JobRunListSpec)
+	if !ok {
+		return
+	}
+	runs, page, err := ctrl.SchedulerSvc.ListRuns(q)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	respondPage(c, page, gin.H{"status": "success", "data": runs})
+}
+
+// POST /api/scheduler/run            -> รันทุกงานที่เปิดอยู่
+// POST /api/scheduler/run?job=<name> -> รันงานเดียว
+func (ctrl *SchedulerController) RunNow(c *gin.Context) {
+	job := strings.TrimSpace(c.Query("job"))
+
+	var runs []models.JobRun
+	var err error
+	if job != "" {
+		var run models.JobRun
+		run, err = ctrl.SchedulerSvc.RunJob(job, "manual")
+		runs = []models.JobRun{run}
+	} else {
+		runs, err = ctrl.SchedulerSvc.RunAll("manual")
+	}
+
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": runs})
+}
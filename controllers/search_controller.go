@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchController struct {
+	SearchSvc *services.SearchService
+}
+
+func NewSearchController(svc *services.SearchService) *SearchController {
+	return &SearchController{SearchSvc: svc}
+}
+
+// GET /api/search?q=<ชื่อ/อีเมล/เบอร์/เลขห้อง/เลขอ้างอิง/เลขพาสปอร์ต>&limit=10
+// เลขพาสปอร์ต / บัตรของแขกค้นและเห็นเต็มได้เฉพาะผู้มี tm30Verification.view
+func (ctrl *SearchController) Search(c *gin.Context) {
+	limit := services.SearchDefaultLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			respondValidationError(c, fieldError("limit", "gte", "1"))
+			return
+		}
+		if n > services.SearchMaxLimit {
+			respondValidationError(c, fieldError("limit", "lte", strconv.Itoa(services.SearchMaxLimit)))
+			return
+		}
+		limit = n
+	}
+
+	results, err := ctrl.SearchSvc.Search(c.Query("q"), limit, services.SearchOptions{
+		ShowIDNumbers: middleware.HasPermission(c, "tm30Verification.view"),
+	})
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": results})
+}
@@ -5,7 +5,9 @@ import (
 	"net/http"
 
 	"hotel-backend/config"
+	"hotel-backend/middleware"
 	"hotel-backend/models"
+	"hotel-backend/services"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -27,7 +29,7 @@ func GetHotelSettings(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"hotel": models.HotelSetting{}})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -37,7 +39,7 @@ func GetHotelSettings(c *gin.Context) {
 func UpdateHotelSettings(c *gin.Context) {
 	var payload hotelSettingsPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInvalidPayload.Wrap(err))
 		return
 	}
 
@@ -54,13 +56,13 @@ func UpdateHotelSettings(c *gin.Context) {
 				Logo:    payload.Logo,
 			}
 			if err := config.DB.Create(&hotel).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				middleware.Abort(c, services.ErrInternal.Wrap(err))
 				return
 			}
 			c.JSON(http.StatusOK, gin.H{"hotel": hotel})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -72,7 +74,7 @@ func UpdateHotelSettings(c *gin.Context) {
 	hotel.Logo = payload.Logo
 
 	if err := config.DB.Save(&hotel).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		middleware.Abort(c, services.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -0,0 +1,99 @@
+// controllers/verification_case_controller.go
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"hotel-backend/middleware"
+	"hotel-backend/models"
+	"hotel-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VerificationCaseController struct {
+	CaseSvc *services.VerificationCaseService
+}
+
+func NewVerificationCaseController(svc *services.VerificationCaseService) *VerificationCaseController {
+	return &VerificationCaseController{CaseSvc: svc}
+}
+
+// ReviewCasePayload ผู้ตรวจคือ staff ที่ login อยู่ (ไม่รับจาก body)
+type ReviewCasePayload struct {
+	Note string `json:"note"`
+}
+
+// GET /api/verification-cases?status=PENDING&limit=50
+// ไม่ส่ง status = PENDING, ส่ง status= (ว่าง) = ทุกสถานะ
+func (ctrl *VerificationCaseController) GetCases(c *gin.Context) {
+	if _, given := c.GetQuery("status"); !given {
+		values := c.Request.URL.Query()
+		values.Set("status", services.CasePending)
+		c.Request.URL.RawQuery = values.Encode()
+	}
+	q, ok := bindListQuery(c, services.VerificationCaseListSpec)
+	if !ok {
+		return
+	}
+	cases, page, err := ctrl.CaseSvc.List(q)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	respondPage(c, page, gin.H{"status": "success", "data": cases})
+}
+
+// GET /api/verification-cases/:id
+func (ctrl *VerificationCaseController) GetCase(c *gin.Context) {
+	id, ok := caseIDParam(c)
+	if !ok {
+		return
+	}
+	vc, err := ctrl.CaseSvc.Get(id)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": vc})
+}
+
+// POST /api/verification-cases/:id/approve
+func (ctrl *VerificationCaseController) ApproveCase(c *gin.Context) {
+	ctrl.review(c, ctrl.CaseSvc.Approve)
+}
+
+// POST /api/verification-cases/:id/reject
+func (ctrl *VerificationCaseController) RejectCase(c *gin.Context) {
+	ctrl.review(c, ctrl.CaseSvc.Reject)
+}
+
+func (ctrl *VerificationCaseController) review(c *gin.Context, fn func(uint, string, string) (models.VerificationCase, error)) {
+	id, ok := caseIDParam(c)
+	if !ok {
+		return
+	}
+	var payload ReviewCasePayload
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &payload) {
+			return
+		}
+	}
+
+	vc, err := fn(id, middleware.CurrentActor(c).AdminName, payload.Note)
+	if err != nil {
+		middleware.Abort(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": vc})
+}
+
+func caseIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		middleware.Abort(c, services.ErrInvalidID)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -40,20 +40,49 @@ func main() {
 	}
 	log.Println("✅ Database connection established and migrations applied (if configured).")
 
+	// Mailer (smtp / capture) ต้องตั้งก่อน service ที่ส่งอีเมล
+	services.ConfigureMailer(db)
+
+	// Rate limit store (memory / db) สำหรับ check-in code และ login
+	services.ConfigureRateLimiting(db)
+
 	// Initialize services
 	guestService := services.NewGuestService(db)
 	customerService := services.NewCustomerService(db)
 	bookingService := services.NewBookingService(db)
 	bookingInfoService := services.NewBookingInfoService(db)
+	schedulerService := services.NewSchedulerService(db, bookingService)
+	caseService := services.NewVerificationCaseService(db, bookingService)
+	kioskService := services.NewKioskService(db, bookingService, bookingInfoService, caseService)
+	searchService := services.NewSearchService(db)
+	sessionService := services.NewAdminSessionService(db)
+	exportService := services.NewExportService(db)
+	bookingImportService := services.NewBookingImportService(db)
+	channelManagerService := services.NewChannelManagerService(db)
+	roomCalendarService := services.NewRoomCalendarService(db)
+	housekeepingService := services.NewHousekeepingService(db)
+	roomStatusService := services.NewRoomStatusService(db)
+	maintenanceService := services.NewMaintenanceService(db)
 
 	// Initialize controllers
 	guestController := controllers.NewGuestController(guestService)
 	customerController := controllers.NewCustomerController(customerService)
 	bookingController := controllers.NewBookingController(bookingService)
 	bookingInfoController := controllers.NewBookingInfoController(bookingInfoService)
+	schedulerController := controllers.NewSchedulerController(schedulerService)
+	kioskController := controllers.NewKioskController(kioskService)
+	caseController := controllers.NewVerificationCaseController(caseService)
+	searchController := controllers.NewSearchController(searchService)
+	exportController := controllers.NewExportController(exportService)
+	bookingImportController := controllers.NewBookingImportController(bookingImportService)
+	channelManagerController := controllers.NewChannelManagerController(channelManagerService)
+	roomCalendarController := controllers.NewRoomCalendarController(roomCalendarService)
+	housekeepingController := controllers.NewHousekeepingController(housekeepingService)
+	roomStatusController := controllers.NewRoomStatusController(roomStatusService)
+	maintenanceController := controllers.NewMaintenanceController(maintenanceService)
 
 	// Build router
-	router := routes.SetupRouter(guestController, bookingController, bookingInfoController, customerController, apiKey)
+	router := routes.SetupRouter(guestController, bookingController, bookingInfoController, customerController, schedulerController, kioskController, caseController, searchController, exportController, bookingImportController, channelManagerController, roomCalendarController, housekeepingController, roomStatusController, maintenanceController, sessionService, apiKey)
 
 	// Port from env (prefer), fallback to 8080
 	port := os.Getenv("PORT")
@@ -80,11 +109,21 @@ func main() {
 		}
 	}()
 
+	// Background scheduler (ปิดได้ด้วย SCHEDULER_ENABLED=false)
+	schedCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	if os.Getenv("SCHEDULER_ENABLED") != "false" {
+		go schedulerService.Start(schedCtx)
+	}
+	// Channel manager sync (ทำงานเมื่อตั้ง CHANNEL_MANAGER_URL)
+	go channelManagerService.Start(schedCtx)
+
 	// Wait for interrupt signal to gracefully shutdown the server with timeout
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
 	log.Println("⚠️  Shutdown signal received, shutting down server...")
+	stopScheduler()
 
 	// Create context with timeout for shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"hotel-backend/models"
+	"hotel-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	adminKey            = "admin"
+	adminPermissionsKey = "adminPermissions"
+)
+
+// BearerToken อ่าน token จาก "Authorization: Bearer <token>"
+func BearerToken(c *gin.Context) string {
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) == 2 && strings.EqualFold(parts[0], "Bearer") {
+		return strings.TrimSpace(parts[1])
+	}
+	return ""
+}
+
+// RequireAuth ตรวจ session token ของ staff แล้วเก็บ admin + permission ไว้ใน context
+func RequireAuth(svc *services.AdminSessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		admin, _, err := svc.Authenticate(BearerToken(c), c.ClientIP())
+		if err != nil {
+			if !errors.Is(err, services.ErrUnauthenticated) {
+				log.Printf("admin auth error: %v", err)
+			}
+			Abort(c, services.ErrUnauthenticated)
+			return
+		}
+		perms, err := svc.Permissions(admin.ID)
+		if err != nil {
+			Abort(c, services.ErrInternal.Wrapf("load permissions: %w", err))
+			return
+		}
+		c.Set(adminKey, admin)
+		c.Set(adminPermissionsKey, perms)
+		c.Next()
+	}
+}
+
+// RequirePermission ต้องผ่าน RequireAuth ก่อน และ admin ต้องมี permission นี้จาก role ใด role หนึ่ง
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasPermission(c, permission) {
+			Abort(c, services.ErrPermissionDenied.WithDetails(gin.H{"permission": permission}))
+			return
+		}
+		c.Next()
+	}
+}
+
+// HasPermission ใช้ใน handler ที่ต้องเช็ค permission เพิ่มเติมเอง
+func HasPermission(c *gin.Context, permission string) bool {
+	return AdminPermissions(c)[permission]
+}
+
+// AdminPermissions permission ทั้งหมดของ admin ที่ผ่าน RequireAuth แล้ว
+func AdminPermissions(c *gin.Context) map[string]bool {
+	perms, _ := c.Get(adminPermissionsKey)
+	m, _ := perms.(map[string]bool)
+	return m
+}
+
+// CurrentAdmin คืน admin ที่ผ่าน RequireAuth แล้ว
+func CurrentAdmin(c *gin.Context) models.Admin {
+	if v, ok := c.Get(adminKey); ok {
+		if a, ok := v.(models.Admin); ok {
+			return a
+		}
+	}
+	return models.Admin{}
+}
+
+// CurrentActor admin ปัจจุบัน + IP สำหรับบันทึกประวัติใน service
+func CurrentActor(c *gin.Context) services.AdminActor {
+	a := CurrentAdmin(c)
+	return services.AdminActor{AdminID: a.ID, AdminName: a.Username, IP: c.ClientIP()}
+}
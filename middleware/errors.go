@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"hotel-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ErrorHandler แปลง error ที่ handler แนบไว้ (c.Error) เป็น response รูปแบบเดียวกันทั้ง API:
+//
+//	{"error": {"code": "error.xxx", "message": "...", "details": ...}}
+//
+// message แปลตามภาษาของ request (ดู Locale)
+//
+// ต้องลงทะเบียนก่อน route ทั้งหมด (r.Use) — handler แค่เรียก Abort(c, err) แล้ว return
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+		de := Resolve(err)
+		if de.Kind == services.KindInternal || de.Kind == services.KindUpstream {
+			log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+
+		body := gin.H{"code": de.Code, "message": Message(c, de.MessageKey())}
+		if de.Details != nil {
+			body["details"] = de.Details
+		}
+		c.JSON(StatusOf(de.Kind), gin.H{"error": body})
+	}
+}
+
+// Abort แนบ error ให้ ErrorHandler ตอบ และหยุด handler chain
+func Abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// Resolve หา domain error ของ err (error ที่ไม่รู้จัก → error.internal)
+func Resolve(err error) *services.Error {
+	if de := services.AsError(err); de != nil {
+		return de
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return services.ErrNotFound
+	}
+	return services.ErrInternal
+}
+
+// StatusOf: ErrorKind → HTTP status
+func StatusOf(kind services.ErrorKind) int {
+	switch kind {
+	case services.KindInvalid:
+		return http.StatusBadRequest
+	case services.KindUnauthorized:
+		return http.StatusUnauthorized
+	case services.KindForbidden:
+		return http.StatusForbidden
+	case services.KindNotFound:
+		return http.StatusNotFound
+	case services.KindConflict:
+		return http.StatusConflict
+	case services.KindGone:
+		return http.StatusGone
+	case services.KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case services.KindTooManyRequests:
+		return http.StatusTooManyRequests
+	case services.KindUpstream:
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
+
+// ResponseStatus status ที่ client จะได้รับ (รวม error ที่ ErrorHandler ยังไม่ได้เขียน)
+// ใช้ใน middleware ที่ต้องดูผลลัพธ์หลัง c.Next() เช่น RateLimit
+func ResponseStatus(c *gin.Context) int {
+	if !c.Writer.Written() && len(c.Errors) > 0 {
+		return StatusOf(Resolve(c.Errors.Last().Err).Kind)
+	}
+	return c.Writer.Status()
+}
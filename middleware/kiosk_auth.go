@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+
+	"hotel-backend/models"
+	"hotel-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KioskTokenHeader header ที่แท็บเล็ต kiosk ส่ง device token มา
+const KioskTokenHeader = "X-Kiosk-Token"
+
+const kioskDeviceKey = "kioskDevice"
+
+// KioskAuth ตรวจ device token ของ kiosk และเก็บเครื่องไว้ใน context
+func KioskAuth(svc *services.KioskService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		device, err := svc.Authenticate(c.GetHeader(KioskTokenHeader), c.ClientIP())
+		if err != nil {
+			if !errors.Is(err, services.ErrKioskUnauthorized) {
+				log.Printf("kiosk auth error: %v", err)
+			}
+			Abort(c, services.ErrKioskUnauthorized)
+			return
+		}
+		c.Set(kioskDeviceKey, device)
+		c.Next()
+	}
+}
+
+// KioskDevice คืนเครื่อง kiosk ที่ผ่าน KioskAuth แล้ว
+func KioskDevice(c *gin.Context) models.KioskDevice {
+	if v, ok := c.Get(kioskDeviceKey); ok {
+		if d, ok := v.(models.KioskDevice); ok {
+			return d
+		}
+	}
+	return models.KioskDevice{}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"strings"
+
+	"hotel-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const preferredLocaleKey = "preferredLocale"
+
+// Locale ภาษาของข้อความใน response:
+// ?lang= → Accept-Language → ภาษาที่แขกตั้งไว้ (PreferLocale) → utils.DefaultAPILocale()
+func Locale(c *gin.Context) string {
+	if l, ok := utils.MatchAPILocale(c.Query("lang")); ok {
+		return l
+	}
+	if l, ok := utils.MatchAPILocale(c.GetHeader("Accept-Language")); ok {
+		return l
+	}
+	if l := c.GetString(preferredLocaleKey); l != "" {
+		return l
+	}
+	return utils.DefaultAPILocale()
+}
+
+// PreferLocale ตั้งภาษาที่แขกเลือกไว้ (เช่น Customer.PreferredLanguage) ใช้เมื่อ client ไม่ได้ระบุภาษามาเอง
+func PreferLocale(c *gin.Context, lang string) {
+	if l, ok := utils.MatchAPILocale(strings.TrimSpace(lang)); ok {
+		c.Set(preferredLocaleKey, l)
+	}
+}
+
+// Message ข้อความของ code (success.* / error.* / validation.*) ตามภาษาของ request
+func Message(c *gin.Context, code string, params ...string) string {
+	return utils.Message(Locale(c), code, params...)
+}
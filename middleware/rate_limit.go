@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TargetFunc ดึงค่าเป้าหมาย (เช่น check-in code, username) จาก request
+type TargetFunc func(c *gin.Context) string
+
+// JSONBodyTarget อ่าน field แรกที่มีค่าจาก JSON body (body ถูกคืนให้ handler อ่านต่อได้)
+func JSONBodyTarget(normalize func(string) string, fields ...string) TargetFunc {
+	return func(c *gin.Context) string {
+		if c.Request.Body == nil {
+			return ""
+		}
+		raw, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+		if err != nil {
+			return ""
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+
+		var body map[string]interface{}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			return ""
+		}
+		for _, f := range fields {
+			if s, ok := body[f].(string); ok && strings.TrimSpace(s) != "" {
+				if normalize != nil {
+					return normalize(s)
+				}
+				return strings.TrimSpace(s)
+			}
+		}
+		return ""
+	}
+}
+
+// RateLimit ปฏิเสธ request ด้วย 429 ถ้า IP หรือเป้าหมายถูก lock อยู่
+// และนับผลลัพธ์หลัง handler: 401/403/404 = ล้มเหลว, 2xx = สำเร็จ
+func RateLimit(limiter *services.RateLimiter, target TargetFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		t := ""
+		if target != nil {
+			t = target(c)
+		}
+
+		if wait := limiter.Check(ip, t); wait > 0 {
+			tooManyAttempts(c, wait)
+			return
+		}
+
+		c.Next()
+
+		status := ResponseStatus(c)
+		switch {
+		case status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusNotFound:
+			limiter.Fail(ip, t)
+		case status >= 200 && status < 300:
+			limiter.Succeed(ip, t)
+		}
+	}
+}
+
+func tooManyAttempts(c *gin.Context, wait time.Duration) {
+	secs := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(secs))
+	Abort(c, services.ErrTooManyAttempts.WithDetails(gin.H{"retryAfter": secs}))
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// AdminSession token ที่ได้จาก /api/auth/login (เก็บเฉพาะ hash)
+type AdminSession struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	AdminID    uint       `gorm:"index" json:"adminId"`
+	TokenHash  string     `gorm:"uniqueIndex;size:64" json:"-"`
+	ExpiresAt  time.Time  `gorm:"index" json:"expiresAt"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+	IP         string     `gorm:"size:64" json:"ip,omitempty"`
+	UserAgent  string     `gorm:"size:255" json:"userAgent,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
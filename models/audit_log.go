@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// AuditLog บันทึกเหตุการณ์ด้านความปลอดภัย/การใช้งานที่ต้องตรวจสอบย้อนหลังได้
+type AuditLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ActorType string    `gorm:"size:32;index" json:"actorType"` // system | admin | guest | kiosk
+	ActorID   string    `gorm:"size:64" json:"actorId,omitempty"`
+	Action    string    `gorm:"size:64;index" json:"action"`
+	Target    string    `gorm:"size:255" json:"target,omitempty"`
+	IP        string    `gorm:"size:64" json:"ip,omitempty"`
+	Details   string    `gorm:"type:text" json:"details,omitempty"` // JSON
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
@@ -17,6 +17,7 @@ type Booking struct {
 
 	CustomerID       uint       `gorm:"index;column:customer_id" json:"customer_id"`
 	ReferenceCode    string     `gorm:"column:reference_code;size:64" json:"reference_code,omitempty"`
+	Source           string     `gorm:"column:source;size:32;index" json:"source,omitempty"` // ว่าง = สร้างในระบบ, booking.com | agoda | spreadsheet (import)
 	Status           string     `gorm:"column:status;size:64" json:"status,omitempty"`
 	CheckIn          *time.Time `gorm:"column:check_in" json:"check_in,omitempty"`
 	CheckOut         *time.Time `gorm:"column:check_out" json:"check_out,omitempty"`
@@ -32,6 +33,15 @@ type Booking struct {
 
 	AccompanyingGuests datatypes.JSON `gorm:"column:accompanying_guests" json:"accompanyingGuests,omitempty"`
 
+	// ช่องทางส่งลิงก์เช็คอิน คั่นด้วย comma เช่น "email,sms,line" (ว่าง = email)
+	NotificationChannels string `gorm:"column:notification_channels;size:64" json:"notificationChannels,omitempty"`
+
+	// เวลาที่แขกแจ้งว่าจะมาถึง "HH:MM" (กรอกตอนเช็คอินออนไลน์)
+	ExpectedArrivalTime string `gorm:"column:expected_arrival_time;size:5" json:"expectedArrivalTime,omitempty"`
+
+	// ส่งข้อความขอบคุณหลังเช็คเอาท์แล้ว (scheduler)
+	ThankYouSentAt *time.Time `gorm:"column:thank_you_sent_at" json:"thankYouSentAt,omitempty"`
+
 	Room     Room          `gorm:"foreignKey:RoomID;references:ID" json:"room,omitempty"`
 	Customer Customer      `gorm:"foreignKey:CustomerID;references:ID" json:"customer,omitempty"`
 	Rooms    []BookingRoom `gorm:"foreignKey:BookingID" json:"rooms"`
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// BookingImport ไฟล์ booking ที่อัปโหลดมา import (CSV / XLSX จาก OTA extranet หรือ spreadsheet)
+// สร้างเป็น preview (dry run) ก่อน แล้ว commit ทั้งไฟล์ใน transaction เดียว
+type BookingImport struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	AdminID   *uint  `gorm:"index" json:"adminId,omitempty"`
+	AdminName string `gorm:"size:150" json:"adminName,omitempty"`
+
+	Source    string                                `gorm:"size:32" json:"source"` // booking.com | agoda | spreadsheet
+	FileName  string                                `gorm:"size:255" json:"fileName"`
+	Format    string                                `gorm:"size:8" json:"format"`    // csv | xlsx
+	DateOrder string                                `gorm:"size:3" json:"dateOrder"` // dmy | mdy (วันที่แบบ 01/02/2025)
+	Columns   datatypes.JSONMap                     `json:"columns"`                 // field → หัวคอลัมน์ในไฟล์ที่ใช้
+	Rows      datatypes.JSONSlice[BookingImportRow] `json:"-"`
+
+	Status      string `gorm:"size:16;index" json:"status"` // preview | committed
+	TotalRows   int    `json:"totalRows"`
+	ReadyRows   int    `json:"readyRows"`
+	ErrorRows   int    `json:"errorRows"`
+	SkippedRows int    `json:"skippedRows"`
+
+	Results    datatypes.JSONSlice[BookingImportResult] `json:"results"`
+	BookingIDs datatypes.JSONSlice[uint]                `json:"bookingIds,omitempty"`
+
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	CommittedAt *time.Time `json:"committedAt,omitempty"`
+}
+
+// BookingImportRow ค่าของหนึ่งแถวตาม field มาตรฐาน (reference, guestName, checkIn, ...)
+type BookingImportRow struct {
+	Row    int               `json:"row"` // เลขแถวในไฟล์ (หัวตาราง = 1)
+	Values map[string]string `json:"values"`
+}
+
+// BookingImportResult ผลตรวจ / ผล import ของหนึ่งแถว
+type BookingImportResult struct {
+	Row        int    `json:"row"`
+	Status     string `json:"status"` // ready | error | skipped | created
+	Reference  string `json:"reference,omitempty"`
+	GuestName  string `json:"guestName,omitempty"`
+	CheckIn    string `json:"checkIn,omitempty"`
+	CheckOut   string `json:"checkOut,omitempty"`
+	RoomID     uint   `json:"roomId,omitempty"`
+	RoomNumber string `json:"roomNumber,omitempty"`
+	CustomerID uint   `json:"customerId,omitempty"` // ลูกค้าเดิมที่จับคู่ได้ (0 = สร้างใหม่)
+	Customer   string `json:"customer,omitempty"`   // matched | new
+	BookingID  uint   `json:"bookingId,omitempty"`
+
+	Issues []ImportIssue `json:"issues,omitempty"`
+}
+
+// ImportIssue ปัญหาของแถว: Rule ใช้ key validation.<rule> ใน message catalog
+type ImportIssue struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Warning bool   `json:"warning,omitempty"` // true = ไม่ทำให้แถว error
+	Message string `json:"message,omitempty"` // เติมตอนตอบ (ตามภาษาของ request)
+}
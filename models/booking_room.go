@@ -1,7 +1,7 @@
 package models
 
 import (
-	
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -16,6 +16,14 @@ type BookingRoom struct {
 	Hours  *int   `gorm:"column:hours" json:"hours,omitempty"`
 	Status string `gorm:"column:status;size:64" json:"status,omitempty"`
 
+	// ช่วงที่ใช้ห้องนี้ [StartDate, EndDate) — nil = ตามวันเข้าพัก / ออกของ booking
+	// ย้ายห้องระหว่างพัก: segment เดิมสิ้นสุดวันที่ย้าย (Status = Moved) และมี segment ใหม่ที่ชี้กลับมา
+	StartDate   *time.Time `gorm:"column:start_date;type:date" json:"startDate,omitempty"`
+	EndDate     *time.Time `gorm:"column:end_date;type:date" json:"endDate,omitempty"`
+	MovedFromID *uint      `gorm:"column:moved_from_id;index" json:"movedFromId,omitempty"`
+	MoveReason  string     `gorm:"column:move_reason;size:255" json:"moveReason,omitempty"`
+	MovedByID   *uint      `gorm:"column:moved_by_id" json:"movedById,omitempty"`
+
 	// timestamps already included via gorm.Model (CreatedAt, UpdatedAt, DeletedAt)
 	// add convenience relation tags if needed:
 	Booking Booking `gorm:"foreignKey:BookingID;references:ID" json:"booking,omitempty"`
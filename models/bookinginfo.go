@@ -15,9 +15,16 @@ type BookingInfo struct {
 	Status        string     `gorm:"default:INITIATED" json:"status"`
 	EmailStatus   string     `gorm:"default:PENDING" json:"emailStatus"`
 	EmailError    string     `json:"emailError"`
+	SMSStatus     string     `gorm:"column:sms_status;size:16" json:"smsStatus,omitempty"`
+	SMSError      string     `gorm:"column:sms_error" json:"smsError,omitempty"`
+	LineStatus    string     `gorm:"column:line_status;size:16" json:"lineStatus,omitempty"`
+	LineError     string     `gorm:"column:line_error" json:"lineError,omitempty"`
 	ExpiresAt     *time.Time `json:"expiresAt"`
 	CodeExpiresAt *time.Time `json:"codeExpiresAt"`
 
+	// ส่งเตือนเช็คอินล่วงหน้าแล้ว (scheduler)
+	ReminderSentAt *time.Time `json:"reminderSentAt,omitempty"`
+
 	GuestEmail    string `json:"guestEmail"`
 	GuestLastName string `json:"guestLastName"`
 }
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// CapturedEmail เก็บอีเมลที่ถูก capture ไว้ (MAILER=capture + MAIL_CAPTURE_STORE=db)
+type CapturedEmail struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	To        string    `gorm:"column:to_address;size:255;index" json:"to"`
+	Subject   string    `gorm:"size:500" json:"subject"`
+	Text      string    `gorm:"type:longtext" json:"text"`
+	HTML      string    `gorm:"type:longtext" json:"html"`
+	CreatedAt time.Time `json:"createdAt"`
+}
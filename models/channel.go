@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// ChannelRoomMapping จับคู่รหัสห้องของ OTA (ผ่าน channel manager) กับ RoomType ของเรา
+// ห้องว่าง / ราคาที่ push ไปคิดตาม RoomType, reservation ที่ดึงมาใช้ mapping นี้หา RoomType
+type ChannelRoomMapping struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Channel    string    `gorm:"size:32;not null;uniqueIndex:idx_channel_room_code" json:"channel"` // booking.com | agoda | ...
+	RoomCode   string    `gorm:"size:64;not null;uniqueIndex:idx_channel_room_code" json:"roomCode"`
+	RoomTypeID uint      `gorm:"index;not null" json:"roomTypeId"`
+	Rate       *float64  `json:"rate,omitempty"` // nil = ราคาต่ำสุดของห้องในประเภทนั้น
+	Active     bool      `gorm:"default:true" json:"active"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+
+	RoomType RoomType `gorm:"foreignKey:RoomTypeID" json:"roomType,omitempty"`
+}
+
+// ChannelSyncLog ประวัติการ sync กับ channel manager หนึ่งครั้งต่อ operation
+type ChannelSyncLog struct {
+	ID         uint              `gorm:"primaryKey" json:"id"`
+	Operation  string            `gorm:"size:32;index" json:"operation"` // availability | rates | reservations
+	Provider   string            `gorm:"size:32" json:"provider"`
+	Trigger    string            `gorm:"size:16" json:"trigger"` // scheduler | manual
+	Status     string            `gorm:"size:16;index" json:"status"`
+	StartedAt  time.Time         `gorm:"index" json:"startedAt"`
+	FinishedAt *time.Time        `json:"finishedAt,omitempty"`
+	Processed  int               `json:"processed"`
+	Succeeded  int               `json:"succeeded"`
+	Failed     int               `json:"failed"`
+	Skipped    int               `json:"skipped"`
+	Details    datatypes.JSONMap `json:"details,omitempty"`
+	Error      string            `gorm:"type:text" json:"error,omitempty"`
+}
+
+// ChannelReservationRetry reservation ที่ดึงมาแล้วบันทึกไม่สำเร็จ (room code ไม่มี mapping, DB error, แขกเช็คอินแล้ว ...)
+// เก็บ payload ไว้ลองใหม่ทุกรอบ pull จนสำเร็จ — cursor ของการ pull เดินต่อได้โดยไม่ทำรายการนี้หาย
+type ChannelReservationRetry struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	Channel       string         `gorm:"size:32;not null;uniqueIndex:idx_channel_retry_reservation" json:"channel"`
+	ReservationID string         `gorm:"size:64;not null;uniqueIndex:idx_channel_retry_reservation" json:"reservationId"`
+	Payload       datatypes.JSON `json:"payload"`
+	Attempts      int            `json:"attempts"`
+	LastError     string         `gorm:"type:text" json:"lastError"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+}
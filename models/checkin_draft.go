@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// CheckInDraft เก็บความคืบหน้าของการเช็คอินออนไลน์ (1 แถวต่อ BookingInfo)
+// แขกบันทึกทีละขั้นได้ ถ้าหลุดกลางทางก็กลับมาทำต่อด้วย token เดิม
+type CheckInDraft struct {
+	ID            uint `gorm:"primaryKey" json:"id"`
+	BookingInfoID uint `gorm:"uniqueIndex" json:"bookingInfoId"`
+
+	// ข้อมูลแต่ละขั้น (JSON ตามรูปแบบของ payload ที่ส่งมา)
+	Guests      datatypes.JSON `json:"guests,omitempty"`
+	Documents   datatypes.JSON `json:"documents,omitempty"`
+	Consents    datatypes.JSON `json:"consents,omitempty"`
+	ArrivalTime string         `gorm:"size:5" json:"arrivalTime,omitempty"` // "HH:MM"
+
+	// รูปที่ server บันทึกให้ draft นี้ (กล้อง / เครื่องสแกนของ kiosk) — ขั้น documents อ้าง path ได้เฉพาะในรายการนี้
+	Uploads datatypes.JSONSlice[string] `json:"-"`
+
+	// ขั้นที่ผ่าน validation แล้ว คั่นด้วย comma เช่น "guests,documents"
+	CompletedSteps string     `gorm:"size:64" json:"-"`
+	CurrentStep    string     `gorm:"size:16" json:"currentStep"`
+	SubmittedAt    *time.Time `json:"submittedAt,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
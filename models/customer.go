@@ -2,24 +2,35 @@
 package models
 
 import (
+	"time"
+
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
 type Customer struct {
-	gorm.Model
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
-	// 🔥 แก้ไข: เปลี่ยน id ใน JSON tag ให้ Map ไปที่ string field
-	// หรือให้เป็น field ที่ไม่ชนกับ GORM ID แต่เราต้องใช้ค่า string ชั่วคราวนี้
-	FrontendTempID string `json:"frontendId,omitempty"`
+	FullName string `gorm:"size:255" json:"fullName"`
+	Email    string `gorm:"size:255;index" json:"email"`
 
-	ID       uint   `gorm:"primaryKey"`
-	FullName string `json:"fullName"`
-	Email    string `json:"email"`
-	// ...
-}
+	// ช่องทางติดต่อสำหรับ SMS / LINE
+	Phone      string `gorm:"size:32" json:"phone,omitempty"`
+	LineUserID string `gorm:"size:64" json:"lineUserId,omitempty"`
+
+	// ภาษาที่ลูกค้าต้องการ (th, en, zh) ใช้เลือก template อีเมล / ข้อความ API
+	PreferredLanguage string `gorm:"size:8" json:"preferredLanguage,omitempty"`
+
+	Nationality string `gorm:"size:64;index" json:"nationality,omitempty"`
+	Address     string `gorm:"type:text" json:"address,omitempty"`
+	Notes       string `gorm:"type:text" json:"notes,omitempty"` // บันทึกภายในของ staff
+
+	// ป้ายกำกับ เช่น ["vip", "corporate"] (JSON array)
+	Tags datatypes.JSONSlice[string] `json:"tags"`
 
-type CustomerBasic struct {
-	ID       uint `gorm:"primaryKey"`
-	FullName string
-	Email    string
+	// ถูกรวมเข้ากับลูกค้ารายอื่นแล้ว (แถวนี้ถูก soft delete) ดู CustomerMerge
+	MergedIntoID *uint `gorm:"index" json:"mergedIntoId,omitempty"`
 }
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// CustomerMerge ประวัติการรวมลูกค้าซ้ำ (หนึ่งแถวต่อหนึ่งลูกค้าที่ถูกรวม) ใช้ตรวจสอบย้อนหลัง
+type CustomerMerge struct {
+	ID         uint `gorm:"primaryKey" json:"id"`
+	SurvivorID uint `gorm:"index" json:"survivorId"` // ลูกค้าที่เหลืออยู่
+	MergedID   uint `gorm:"index" json:"mergedId"`   // ลูกค้าที่ถูกรวมแล้ว soft delete
+
+	MergedByID   *uint  `gorm:"index" json:"mergedById,omitempty"` // admin ที่สั่งรวม
+	MergedByName string `gorm:"size:100" json:"mergedByName,omitempty"`
+	Reason       string `gorm:"type:text" json:"reason,omitempty"`
+
+	// snapshot ก่อนรวม (JSON ของ models.Customer)
+	SurvivorBefore string `gorm:"type:text" json:"survivorBefore"`
+	MergedBefore   string `gorm:"type:text" json:"mergedBefore"`
+
+	// ข้อมูลที่ย้ายมาที่ survivor
+	BookingIDs    datatypes.JSONSlice[uint]   `json:"bookingIds"`
+	GuestIDs      datatypes.JSONSlice[uint]   `json:"guestIds"`
+	ConsentLogIDs datatypes.JSONSlice[uint]   `json:"consentLogIds"`
+	FieldsFilled  datatypes.JSONSlice[string] `json:"fieldsFilled"` // field ของ survivor ที่เติมจากลูกค้าที่ถูกรวม
+
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
+
+// CustomerDuplicateDismissal คู่ลูกค้าที่ staff ตรวจแล้วว่าไม่ใช่คนเดียวกัน (ไม่แสดงในรายการซ้ำอีก)
+// เก็บ CustomerAID < CustomerBID เสมอ
+type CustomerDuplicateDismissal struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	CustomerAID   uint      `gorm:"uniqueIndex:idx_customer_dup_pair" json:"customerAId"`
+	CustomerBID   uint      `gorm:"uniqueIndex:idx_customer_dup_pair" json:"customerBId"`
+	DismissedByID *uint     `json:"dismissedById,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
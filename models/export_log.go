@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ExportLog บันทึกว่าใคร export ข้อมูลชุดไหน เงื่อนไขอะไร เมื่อไร (ข้อมูลส่วนบุคคล / PDPA)
+type ExportLog struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	AdminID   *uint  `gorm:"index" json:"adminId,omitempty"`
+	AdminName string `gorm:"size:150" json:"adminName,omitempty"`
+	IP        string `gorm:"size:64" json:"ip,omitempty"`
+
+	Dataset string `gorm:"size:32;index" json:"dataset"`       // customers | guests | bookings | consentLogs
+	Format  string `gorm:"size:8" json:"format"`               // csv | xlsx
+	Filters string `gorm:"type:text" json:"filters,omitempty"` // JSON ของ query ที่ใช้
+	MaskPII bool   `json:"maskPii"`
+
+	Status      string     `gorm:"size:16;index" json:"status"` // running | completed | failed
+	RowCount    int        `json:"rowCount"`
+	Error       string     `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt   time.Time  `gorm:"index" json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
@@ -34,6 +34,12 @@ type Guest struct {
     FaceImagePath     string `json:"faceImagePath"`
     DocumentImagePath string `json:"documentImagePath"`
 
+    // ผลเทียบ selfie กับรูปบนเอกสาร (FaceMatchService)
+    FaceMatchScore    *float64   `json:"faceMatchScore,omitempty"`
+    FaceMatchDecision string     `gorm:"size:16;index" json:"faceMatchDecision,omitempty"` // match | low_score | skipped | error
+    FaceMatchProvider string     `gorm:"size:32" json:"faceMatchProvider,omitempty"`
+    FaceMatchedAt     *time.Time `json:"faceMatchedAt,omitempty"`
+
     // เพิ่มฟิลด์นี้เพื่อเก็บอีเมล
     Email string `json:"email"`
 }
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// HousekeepingTask งานทำความสะอาดห้องหนึ่งห้องในวันหนึ่ง
+// Status: pending → in_progress → done → inspected (ตรวจไม่ผ่านกลับเป็น pending)
+type HousekeepingTask struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	RoomID       uint      `gorm:"index:idx_housekeeping_room_date;not null" json:"roomId"`
+	Date         time.Time `gorm:"type:date;index:idx_housekeeping_room_date;index" json:"date"`
+	Type         string    `gorm:"size:16" json:"type"` // checkout | stayover | touchup | deep
+	Status       string    `gorm:"size:16;index" json:"status"`
+	Priority     int       `gorm:"default:0" json:"priority"` // มาก = ทำก่อน
+	AssignedToID *uint     `gorm:"index" json:"assignedToId,omitempty"`
+	BookingID    *uint     `gorm:"index" json:"bookingId,omitempty"` // booking ที่เช็คเอาท์แล้วสร้างงานนี้
+	Notes        string    `gorm:"type:text" json:"notes,omitempty"`
+
+	StartedAt      *time.Time `json:"startedAt,omitempty"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty"`
+	InspectedAt    *time.Time `json:"inspectedAt,omitempty"`
+	InspectedByID  *uint      `json:"inspectedById,omitempty"`
+	InspectionNote string     `gorm:"type:text" json:"inspectionNote,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Room       Room   `gorm:"foreignKey:RoomID" json:"room"`
+	AssignedTo *Admin `gorm:"foreignKey:AssignedToID" json:"assignedTo,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// JobRun ประวัติการรันงานของ scheduler
+type JobRun struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Job        string     `gorm:"size:64;index" json:"job"`
+	Trigger    string     `gorm:"size:16" json:"trigger"` // scheduler | manual
+	Status     string     `gorm:"size:16;index" json:"status"`
+	StartedAt  time.Time  `gorm:"index" json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Processed  int        `json:"processed"`
+	Succeeded  int        `json:"succeeded"`
+	Failed     int        `json:"failed"`
+	Skipped    int        `json:"skipped"`
+	Error      string     `gorm:"type:text" json:"error,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// KioskDevice แท็บเล็ตเช็คอินด้วยตนเองที่ลงทะเบียนกับโรงแรม
+// เก็บเฉพาะ hash ของ device token (token จริงแสดงครั้งเดียวตอนลงทะเบียน)
+type KioskDevice struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	HotelSettingID uint   `gorm:"index" json:"hotelSettingId"`
+	Name           string `gorm:"size:100" json:"name"`
+	TokenHash      string `gorm:"uniqueIndex;size:64" json:"-"`
+	TokenPrefix    string `gorm:"size:12" json:"tokenPrefix"` // ไว้ให้ staff แยกเครื่อง
+
+	Active     bool       `gorm:"default:true" json:"active"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+	LastIP     string     `gorm:"size:64" json:"lastIp,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
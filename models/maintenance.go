@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// MaintenanceTicket งานซ่อมของห้อง
+// Status: open → in_progress ⇄ on_hold → resolved → closed (ยกเลิกได้ก่อน resolved, resolved เปิดใหม่ได้)
+type MaintenanceTicket struct {
+	ID          uint                        `gorm:"primaryKey" json:"id"`
+	RoomID      uint                        `gorm:"index;not null" json:"roomId"`
+	Category    string                      `gorm:"size:32;index" json:"category"` // plumbing | electrical | hvac | furniture | appliance | structural | other
+	Priority    string                      `gorm:"size:16;index" json:"priority"` // low | normal | high | urgent
+	Title       string                      `gorm:"size:255" json:"title"`
+	Description string                      `gorm:"type:text" json:"description,omitempty"`
+	Photos      datatypes.JSONSlice[string] `json:"photos"` // path ใต้ uploads/ เช่น "maintenance/xxx.jpg"
+	Status      string                      `gorm:"size:16;index" json:"status"`
+
+	AssignedToID   *uint      `gorm:"index" json:"assignedToId,omitempty"`
+	ReportedByID   *uint      `json:"reportedById,omitempty"`
+	ResolvedAt     *time.Time `json:"resolvedAt,omitempty"`
+	ResolutionNote string     `gorm:"type:text" json:"resolutionNote,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Room       Room        `gorm:"foreignKey:RoomID" json:"room"`
+	AssignedTo *Admin      `gorm:"foreignKey:AssignedToID" json:"assignedTo,omitempty"`
+	Blocks     []RoomBlock `gorm:"foreignKey:TicketID" json:"blocks,omitempty"` // ช่วงปิดห้อง (out of order) ของงานนี้
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// RateLimitEvent คือความพยายามที่ล้มเหลว 1 ครั้ง (ใช้กับ DB store สำหรับหลาย instance)
+type RateLimitEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	Key       string    `gorm:"size:191;index:idx_rate_limit_key_time,priority:1"`
+	CreatedAt time.Time `gorm:"index:idx_rate_limit_key_time,priority:2"`
+}
+
+// RateLimitLock สถานะ lockout ของ key (Level เพิ่มทุกครั้งที่โดน lock -> ระยะเวลานานขึ้น)
+type RateLimitLock struct {
+	Key       string    `gorm:"primaryKey;size:191"`
+	Until     time.Time `gorm:"index"`
+	Level     int
+	UpdatedAt time.Time
+}
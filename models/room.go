@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -14,11 +16,27 @@ type Room struct {
 	RoomCode   string `json:"roomCode"   gorm:"column:room_code;type:varchar(50)"`
 
 	Type         string  `json:"type"`
-	Status       string  `json:"status"`
 	Floor        string  `json:"floor" gorm:"type:varchar(10)"`
 	Price        float64 `json:"price"`
 	MaxOccupancy int     `json:"maxOccupancy" gorm:"column:max_occupancy"`
 	Description  string  `json:"description" gorm:"type:text"`
 
+	// สถานะห้องแยกเป็น 3 ส่วน: การเข้าพัก (Occupancy คำนวณจาก booking ไม่เก็บใน DB),
+	// ความสะอาด (CleanStatus) และการใช้งาน (ServiceStatus) เปลี่ยนผ่าน RoomStatusService เท่านั้น
+	Occupancy string `json:"occupancy" gorm:"-"` // vacant | reserved | occupied
+
+	// dirty | cleaning | clean | inspected
+	CleanStatus   string     `json:"cleanStatus" gorm:"column:clean_status;size:16;default:clean;index"`
+	CleanStatusAt *time.Time `json:"cleanStatusAt,omitempty" gorm:"column:clean_status_at"`
+
+	// in_service | out_of_order (ซ่อม ขายไม่ได้) | out_of_service (ปิดชั่วคราว ไม่นับเป็นห้องเสีย)
+	ServiceStatus string     `json:"serviceStatus" gorm:"column:service_status;size:16;default:in_service;index"`
+	ServiceReason string     `json:"serviceReason,omitempty" gorm:"column:service_reason;size:255"`
+	ServiceUntil  *time.Time `json:"serviceUntil,omitempty" gorm:"column:service_until"` // วันที่คาดว่าจะกลับมาขายได้ (nil = ไม่กำหนด)
+
+	// ขายได้ในช่วงที่ถาม (ไม่ปิดใช้งานและไม่มี RoomBlock ทับ) และ block ที่ทับช่วงนั้น คำนวณตอนตอบ GET /api/rooms
+	Sellable bool        `json:"sellable" gorm:"-"`
+	Blocks   []RoomBlock `json:"blocks,omitempty" gorm:"-"`
+
 	RoomType RoomType `gorm:"foreignKey:RoomTypeID"`
 }
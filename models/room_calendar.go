@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// RoomBlock ช่วงวันที่ห้องถูกปิดไม่ให้จอง [StartDate, EndDate)
+// Kind: external = event จากปฏิทินภายนอก (เช่น Airbnb), out_of_order = ปิดซ่อมที่ตั้งเอง
+type RoomBlock struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	RoomID      uint      `gorm:"index;not null" json:"roomId"`
+	Kind        string    `gorm:"size:16;default:external;index" json:"kind"`
+	StartDate   time.Time `gorm:"index" json:"startDate"`
+	EndDate     time.Time `gorm:"index" json:"endDate"`
+	Summary     string    `gorm:"size:255" json:"summary,omitempty"` // out_of_order: เหตุผลที่ปิด
+	ImportID    *uint     `gorm:"index" json:"importId,omitempty"`   // RoomCalendarImport ที่สร้าง block นี้
+	ExternalUID string    `gorm:"size:255" json:"externalUid,omitempty"`
+	TicketID    *uint     `gorm:"index" json:"ticketId,omitempty"` // MaintenanceTicket ที่ทำให้ปิดห้อง
+	CreatedByID *uint     `json:"createdById,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// RoomCalendarFeed iCal feed ของห้องหรือประเภทห้องที่เปิดให้ปฏิทินภายนอก subscribe
+// เก็บเฉพาะ hash ของ token (URL จริงแสดงครั้งเดียวตอนสร้าง)
+type RoomCalendarFeed struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Scope        string     `gorm:"size:16;index:idx_calendar_feed_target" json:"scope"` // room | roomType
+	TargetID     uint       `gorm:"index:idx_calendar_feed_target" json:"targetId"`
+	Name         string     `gorm:"size:100" json:"name"`
+	TokenHash    string     `gorm:"uniqueIndex;size:64" json:"-"`
+	TokenPrefix  string     `gorm:"size:12" json:"tokenPrefix"`
+	CreatedByID  uint       `json:"createdById,omitempty"`
+	LastAccessAt *time.Time `json:"lastAccessAt,omitempty"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// RoomCalendarImport ปฏิทินภายนอก (URL .ics) ที่ดึงมาเป็น RoomBlock ของห้อง
+type RoomCalendarImport struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	RoomID       uint       `gorm:"index;not null" json:"roomId"`
+	Name         string     `gorm:"size:100" json:"name"`
+	URL          string     `gorm:"size:1024" json:"url"`
+	Active       bool       `gorm:"default:true" json:"active"`
+	LastSyncedAt *time.Time `json:"lastSyncedAt,omitempty"`
+	LastError    string     `gorm:"type:text" json:"lastError,omitempty"`
+	EventCount   int        `json:"eventCount"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RoomStatusChange ประวัติการเปลี่ยนสถานะห้อง (ความสะอาด / การใช้งาน)
+type RoomStatusChange struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	RoomID      uint      `gorm:"index;not null" json:"roomId"`
+	Field       string    `gorm:"size:16" json:"field"` // clean | service
+	FromStatus  string    `gorm:"size:16" json:"from"`
+	ToStatus    string    `gorm:"size:16" json:"to"`
+	Reason      string    `gorm:"size:255" json:"reason,omitempty"`
+	Source      string    `gorm:"size:32" json:"source"` // manual | checkout | housekeeping | room_move
+	ChangedByID *uint     `json:"changedById,omitempty"` // nil = ระบบ
+	CreatedAt   time.Time `gorm:"index" json:"createdAt"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ScheduleSetting ตั้งค่างานอัตโนมัติของแต่ละโรงแรม (1 แถวต่อ HotelSetting)
+type ScheduleSetting struct {
+	ID             uint `gorm:"primaryKey" json:"id"`
+	HotelSettingID uint `gorm:"uniqueIndex" json:"hotelSettingId"`
+
+	Enabled         bool `json:"enabled"`
+	IntervalMinutes int  `json:"intervalMinutes"`
+
+	// ส่งลิงก์เช็คอินอัตโนมัติ N วันก่อน CheckInDate
+	AutoInitiateEnabled    bool `json:"autoInitiateEnabled"`
+	AutoInitiateDaysBefore int  `json:"autoInitiateDaysBefore"`
+
+	// เตือนซ้ำถ้า BookingInfo ยังเป็น INITIATED ภายใน N ชั่วโมงก่อนเข้าพัก
+	ReminderEnabled     bool `json:"reminderEnabled"`
+	ReminderHoursBefore int  `json:"reminderHoursBefore"`
+
+	// ขอบคุณหลังเช็คเอาท์ N ชั่วโมง
+	ThankYouEnabled    bool `json:"thankYouEnabled"`
+	ThankYouHoursAfter int  `json:"thankYouHoursAfter"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// VerificationCase คิวให้ staff ตรวจสอบการเช็คอินที่ถูก flag (kiosk, ใบหน้าไม่ตรงเอกสาร ฯลฯ)
+type VerificationCase struct {
+	ID            uint  `gorm:"primaryKey" json:"id"`
+	BookingID     uint  `gorm:"index" json:"bookingId"`
+	BookingInfoID uint  `gorm:"index" json:"bookingInfoId"`
+	GuestID       *uint `gorm:"index" json:"guestId,omitempty"`
+	KioskDeviceID *uint `gorm:"index" json:"kioskDeviceId,omitempty"`
+
+	Source  string `gorm:"size:32" json:"source"`              // kiosk | online | face_match
+	Reasons string `gorm:"size:255" json:"reasons"`            // คั่นด้วย comma
+	Details string `gorm:"type:text" json:"details,omitempty"` // JSON
+
+	// true = การเช็คอินยังไม่ถูก finalize จนกว่า staff จะ approve
+	BlocksCheckIn bool `json:"blocksCheckIn"`
+
+	Status     string     `gorm:"size:16;index;default:PENDING" json:"status"` // PENDING | APPROVED | REJECTED
+	ReviewedBy string     `gorm:"size:100" json:"reviewedBy,omitempty"`
+	ReviewNote string     `gorm:"type:text" json:"reviewNote,omitempty"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"log"
 	"net/http"
 	"os"
 	"strings"
@@ -10,6 +11,9 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"hotel-backend/controllers"
+	"hotel-backend/middleware"
+	"hotel-backend/services"
+	"hotel-backend/utils"
 )
 
 func parseCorsOrigins() []string {
@@ -32,15 +36,42 @@ func parseCorsOrigins() []string {
 	return origins
 }
 
-// SetupRouter Ѻ Controller Instances ͡˹ Route
+// parseTrustedProxies TRUSTED_PROXIES=10.0.0.1,10.0.0.0/8 (reverse proxy หน้า backend)
+// ไม่ตั้ง = ไม่เชื่อ X-Forwarded-For เลย, ClientIP() = IP ที่ต่อเข้ามาจริง (rate limit ต่อ IP ปลอมไม่ได้)
+func parseTrustedProxies() []string {
+	proxies := []string{}
+	for _, part := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if p := strings.TrimSpace(part); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
+
+// SetupRouter รับ Controller Instances เข้ามาเพื่อกำหนด Route
 func SetupRouter(
 	gc *controllers.GuestController,
 	bc *controllers.BookingController,
 	bic *controllers.BookingInfoController,
 	ctc *controllers.CustomerController,
+	sc *controllers.SchedulerController,
+	kc *controllers.KioskController,
+	vcc *controllers.VerificationCaseController,
+	src *controllers.SearchController,
+	ec *controllers.ExportController,
+	bimc *controllers.BookingImportController,
+	cmc *controllers.ChannelManagerController,
+	rcc *controllers.RoomCalendarController,
+	hkc *controllers.HousekeepingController,
+	rsc *controllers.RoomStatusController,
+	mc *controllers.MaintenanceController,
+	sessions *services.AdminSessionService,
 	apiKey string,
 ) *gin.Engine {
 	r := gin.Default()
+	if err := r.SetTrustedProxies(parseTrustedProxies()); err != nil {
+		log.Fatalf("❌ invalid TRUSTED_PROXIES: %v", err)
+	}
 	r.Static("/uploads", "./uploads")
 
 	origins := parseCorsOrigins()
@@ -55,36 +86,69 @@ func SetupRouter(
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     origins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", middleware.KioskTokenHeader},
+		ExposeHeaders:    []string{"Content-Length", "X-Total-Count", "X-Next-Cursor", "Link"},
 		AllowCredentials: allowCredentials,
 		MaxAge:           12 * time.Hour,
 	}))
 
+	// error ที่ handler แนบผ่าน middleware.Abort → {"error": {"code", "message", "details"}}
+	r.Use(middleware.ErrorHandler())
+
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
+	// brute-force protection (store ตั้งค่าใน main ผ่าน services.ConfigureRateLimiting)
+	checkinLimit := middleware.RateLimit(
+		services.NewRateLimiter(services.CheckinCodePolicy),
+		middleware.JSONBodyTarget(utils.NormalizeCheckinCode, "checkinCode", "payload"),
+	)
+	loginLimit := middleware.RateLimit(
+		services.NewRateLimiter(services.LoginPolicy),
+		middleware.JSONBodyTarget(strings.ToLower, "username"),
+	)
+
+	// session token จาก /api/auth/login
+	requireAuth := middleware.RequireAuth(sessions)
+
 	api := r.Group("/api")
 	{
 		guests := api.Group("/guests")
 		{
 			guests.GET("", gc.GetGuests)
 
-			// ? ͧ͹ /:id
+			// ? ต้องอยู่ก่อน /:id
 			guests.GET("/all", gc.GetAllGuests)
 
-			// ? Ѻ੾еŢ ͧѹ all/xyz 仪 handler 
+			// ? รับเฉพาะตัวเลข ป้องกัน all/xyz ไปชน handler นี้
 			guests.GET("/:id", gc.GetGuestByID)
 			guests.POST("", gc.CreateGuest)
 			guests.PUT("/:id", gc.UpdateGuest)
 			guests.DELETE("/:id", gc.DeleteGuest)
 		}
 
-		// Customers
-		customersRoutes := api.Group("/customers")
+		// Customers (staff ที่ login แล้ว + permission customerList.*)
+		customersRoutes := api.Group("/customers", requireAuth)
+		{
+			customersRoutes.GET("", middleware.RequirePermission("customerList.view"), ctc.GetCustomers)
+			customersRoutes.GET("/duplicates", middleware.RequirePermission("customerList.view"), ctc.GetDuplicates)
+			customersRoutes.POST("/duplicates/dismiss", middleware.RequirePermission("customerList.edit"), ctc.DismissDuplicate)
+			customersRoutes.GET("/:id", middleware.RequirePermission("customerList.view"), ctc.GetCustomer)
+			customersRoutes.GET("/:id/merges", middleware.RequirePermission("customerList.view"), ctc.GetMergeHistory)
+			// รวมลูกค้า = แก้ลูกค้าที่เหลือ + ลบลูกค้าที่ถูกรวม
+			customersRoutes.POST("/:id/merge", middleware.RequirePermission("customerList.edit"), middleware.RequirePermission("customerList.delete"), ctc.MergeCustomers)
+			customersRoutes.POST("", middleware.RequirePermission("customerList.create"), ctc.CreateCustomer)
+			customersRoutes.PATCH("/:id", middleware.RequirePermission("customerList.edit"), ctc.UpdateCustomer)
+			customersRoutes.PUT("/:id", middleware.RequirePermission("customerList.edit"), ctc.UpdateCustomer)
+			customersRoutes.DELETE("/:id", middleware.RequirePermission("customerList.delete"), ctc.DeleteCustomer)
+		}
+
+		// Exports (CSV / XLSX) ทุกชุดข้อมูลต้องมี customerList.export และถูกบันทึกใน export_logs
+		exports := api.Group("/exports", requireAuth, middleware.RequirePermission("customerList.export"))
 		{
-			customersRoutes.POST("", ctc.CreateCustomer)
+			exports.GET("/logs", ec.GetLogs)
+			exports.GET("/:dataset", ec.Export)
 		}
 
 		// Bookings
@@ -94,14 +158,78 @@ func SetupRouter(
 			bookings.GET("", bc.GetBookings)
 			bookings.POST("", bc.CreateBooking)
 
-			// ? ÷Ѵ (ͧ)
+			// ? เพิ่มบรรทัดนี้ (ต้องมี)
 			bookings.GET("/:id", bc.GetBookingDetails)
 
 			bookings.DELETE("/:id", bc.DeleteBooking)
 			bookings.POST("/:id/checkout", bc.CheckoutBooking)
+			bookings.POST("/:id/rooms/:bookingRoomId/move", requireAuth, middleware.RequirePermission("bookingManagement.edit"), bc.MoveRoom)
+			bookings.PUT("/:id/notification-channels", bc.UpdateNotificationChannels)
 			bookings.GET("/:id/guests", gc.GetGuestsByBookingID)
 		}
 
+		// Booking import (CSV/XLSX จาก OTA หรือ spreadsheet) preview ก่อนแล้วค่อย commit
+		imports := api.Group("/bookings/imports", requireAuth, middleware.RequirePermission("bookingManagement.create"))
+		{
+			imports.POST("", bimc.Preview)
+			imports.GET("/:id", bimc.Get)
+			imports.POST("/:id/commit", bimc.Commit)
+		}
+
+		// Channel manager (OTA): mapping รหัสห้อง ↔ RoomType, ประวัติ sync, สั่ง sync ทันที
+		channels := api.Group("/channel-manager", requireAuth)
+		{
+			channels.GET("/mappings", middleware.RequirePermission("roomManagement.view"), cmc.GetMappings)
+			channels.POST("/mappings", middleware.RequirePermission("roomManagement.edit"), cmc.CreateMapping)
+			channels.PATCH("/mappings/:id", middleware.RequirePermission("roomManagement.edit"), cmc.UpdateMapping)
+			channels.DELETE("/mappings/:id", middleware.RequirePermission("roomManagement.edit"), cmc.DeleteMapping)
+			channels.GET("/logs", middleware.RequirePermission("roomManagement.view"), cmc.GetLogs)
+			channels.POST("/sync", middleware.RequirePermission("roomManagement.edit"), cmc.SyncNow)
+		}
+
+		// iCal: feed การจองของห้อง / ประเภทห้อง (public ด้วย token) และปฏิทินภายนอกที่ปิดการจองห้อง
+		api.GET("/calendars/feeds/:token", rcc.ServeFeed)
+		calendars := api.Group("/calendars", requireAuth)
+		{
+			calendars.GET("/feeds", middleware.RequirePermission("roomManagement.view"), rcc.GetFeeds)
+			calendars.POST("/feeds", middleware.RequirePermission("roomManagement.edit"), rcc.CreateFeed)
+			calendars.DELETE("/feeds/:id", middleware.RequirePermission("roomManagement.edit"), rcc.RevokeFeed)
+			calendars.GET("/imports", middleware.RequirePermission("roomManagement.view"), rcc.GetImports)
+			calendars.POST("/imports", middleware.RequirePermission("roomManagement.edit"), rcc.CreateImport)
+			calendars.POST("/imports/:id/sync", middleware.RequirePermission("roomManagement.edit"), rcc.SyncImport)
+			calendars.DELETE("/imports/:id", middleware.RequirePermission("roomManagement.edit"), rcc.DeleteImport)
+			calendars.GET("/blocks", middleware.RequirePermission("roomManagement.view"), rcc.GetBlocks)
+		}
+
+		// Housekeeping: สถานะความสะอาดของห้อง, งานรายวันตามพนักงาน / ชั้น, ตรวจห้องโดย supervisor
+		housekeeping := api.Group("/housekeeping", requireAuth)
+		{
+			housekeeping.GET("/rooms", middleware.RequirePermission("housekeeping.view"), hkc.GetRooms)
+			housekeeping.PATCH("/rooms/:id/status", middleware.RequirePermission("housekeeping.update"), hkc.UpdateRoomStatus)
+			housekeeping.GET("/my-tasks", middleware.RequirePermission("housekeeping.view"), hkc.GetMyTasks)
+			housekeeping.GET("/tasks", middleware.RequirePermission("housekeeping.view"), hkc.GetTasks)
+			housekeeping.POST("/tasks", middleware.RequirePermission("housekeeping.assign"), hkc.CreateTask)
+			housekeeping.POST("/tasks/generate", middleware.RequirePermission("housekeeping.assign"), hkc.GenerateTasks)
+			housekeeping.PATCH("/tasks/:id", middleware.RequirePermission("housekeeping.assign"), hkc.UpdateTask)
+			housekeeping.DELETE("/tasks/:id", middleware.RequirePermission("housekeeping.assign"), hkc.DeleteTask)
+			housekeeping.POST("/tasks/:id/start", middleware.RequirePermission("housekeeping.update"), hkc.StartTask)
+			housekeeping.POST("/tasks/:id/finish", middleware.RequirePermission("housekeeping.update"), hkc.FinishTask)
+			housekeeping.POST("/tasks/:id/inspect", middleware.RequirePermission("housekeeping.inspect"), hkc.InspectTask)
+		}
+
+		// Maintenance: งานซ่อมของห้อง และช่วงปิดห้อง (out of order) ที่ขายไม่ได้
+		maintenance := api.Group("/maintenance", requireAuth)
+		{
+			maintenance.GET("/tickets", middleware.RequirePermission("maintenance.view"), mc.GetTickets)
+			maintenance.POST("/tickets", middleware.RequirePermission("maintenance.create"), mc.CreateTicket)
+			maintenance.GET("/tickets/:id", middleware.RequirePermission("maintenance.view"), mc.GetTicket)
+			maintenance.PATCH("/tickets/:id", middleware.RequirePermission("maintenance.update"), mc.UpdateTicket)
+			maintenance.GET("/blocks", middleware.RequirePermission("roomManagement.view"), mc.GetBlocks)
+			maintenance.POST("/blocks", middleware.RequirePermission("roomManagement.editStatus"), mc.CreateBlock)
+			maintenance.PATCH("/blocks/:id", middleware.RequirePermission("roomManagement.editStatus"), mc.UpdateBlock)
+			maintenance.DELETE("/blocks/:id", middleware.RequirePermission("roomManagement.editStatus"), mc.DeleteBlock)
+		}
+
 		infoRoutes := api.Group("/booking-info")
 		{
 			infoRoutes.POST("", bic.SaveBookingInfo)
@@ -112,7 +240,7 @@ func SetupRouter(
 		{
 			consents.GET("", controllers.GetConsents)
 			consents.POST("", controllers.CreateConsent)
-			consents.POST("/accept", controllers.AcceptConsent) //  ѹ
+			consents.POST("/accept", controllers.AcceptConsent) //  อันใหม่
 			consents.DELETE("/:id", controllers.DeleteConsent)
 		}
 		consentLogs := api.Group("/consent-logs")
@@ -123,10 +251,11 @@ func SetupRouter(
 			consentLogs.PATCH("/attach-booking", controllers.AttachBookingToPending)
 		}
 
-		roles := api.Group("/roles")
+		// Roles / admins (staff ที่ login แล้ว + permission rolesAndPermissions.*)
+		roles := api.Group("/roles", requireAuth)
 		{
-			roles.GET("", controllers.GetRoles)
-			roles.PUT("/:id/permissions", controllers.UpdateRolePermissions)
+			roles.GET("", middleware.RequirePermission("rolesAndPermissions.view"), controllers.GetRoles)
+			roles.PUT("/:id/permissions", middleware.RequirePermission("rolesAndPermissions.edit"), controllers.UpdateRolePermissions)
 		}
 
 		settings := api.Group("/settings")
@@ -135,27 +264,46 @@ func SetupRouter(
 			settings.PUT("/hotel", controllers.UpdateHotelSettings)
 		}
 
+		emailTemplates := api.Group("/email-templates", requireAuth, middleware.RequirePermission("settings.view"))
+		{
+			emailTemplates.GET("", controllers.GetEmailTemplates)
+			emailTemplates.GET("/preview", controllers.PreviewEmailTemplate)
+		}
+
+		scheduler := api.Group("/scheduler", requireAuth)
+		{
+			scheduler.GET("/settings", middleware.RequirePermission("settings.view"), sc.GetSettings)
+			scheduler.PUT("/settings", middleware.RequirePermission("settings.edit"), sc.UpdateSettings)
+			scheduler.GET("/runs", middleware.RequirePermission("settings.view"), sc.GetRuns)
+			scheduler.POST("/run", middleware.RequirePermission("settings.edit"), sc.RunNow)
+		}
+
 		auth := api.Group("/auth")
 		{
-			auth.POST("/login", controllers.Login)
+			auth.POST("/login", loginLimit, controllers.Login(sessions))
 			auth.POST("/forgot", controllers.ForgotPassword)
+			auth.POST("/logout", controllers.Logout(sessions))
+			auth.GET("/me", requireAuth, controllers.Me)
 		}
 
 		admins := api.Group("/admins")
 		{
-			admins.GET("", controllers.GetAdmins)
-			admins.POST("", controllers.CreateAdmin)
-			admins.POST("/invite", controllers.InviteAdmin)
+			admins.GET("", requireAuth, middleware.RequirePermission("rolesAndPermissions.view"), controllers.GetAdmins)
+			admins.POST("", requireAuth, middleware.RequirePermission("rolesAndPermissions.create"), controllers.CreateAdmin)
+			admins.POST("/invite", requireAuth, middleware.RequirePermission("rolesAndPermissions.create"), controllers.InviteAdmin)
+			// ตั้งรหัสผ่านจากลิงก์เชิญ (ยืนยันด้วย reset token) ยังไม่มี session
 			admins.POST("/activate", controllers.ActivateAdmin)
-			admins.DELETE("/:id", controllers.DeleteAdmin)
+			admins.DELETE("/:id", requireAuth, middleware.RequirePermission("rolesAndPermissions.delete"), controllers.DeleteAdmin)
 		}
 		rooms := api.Group("/rooms")
 		{
 			rooms.GET("", controllers.GetRooms)
-			rooms.POST("", controllers.CreateRoom)
-			rooms.PATCH("/:id", controllers.UpdateRoom)
-			rooms.PUT("/:id", controllers.UpdateRoom)
-			rooms.DELETE("/:id", controllers.DeleteRoom)
+			rooms.POST("", requireAuth, middleware.RequirePermission("roomManagement.create"), controllers.CreateRoom)
+			rooms.PATCH("/:id", requireAuth, middleware.RequirePermission("roomManagement.edit"), controllers.UpdateRoom)
+			rooms.PUT("/:id", requireAuth, middleware.RequirePermission("roomManagement.edit"), controllers.UpdateRoom)
+			rooms.DELETE("/:id", requireAuth, middleware.RequirePermission("roomManagement.delete"), controllers.DeleteRoom)
+			rooms.PATCH("/:id/status", requireAuth, middleware.RequirePermission("roomManagement.editStatus"), rsc.UpdateStatus)
+			rooms.GET("/:id/status-history", requireAuth, middleware.RequirePermission("roomManagement.view"), rsc.GetHistory)
 		}
 		roomTypes := api.Group("/room-types")
 		{
@@ -169,8 +317,64 @@ func SetupRouter(
 			checkin.POST("/initiate", bc.InitiateCheckIn)
 			checkin.POST("", bc.ConfirmCheckIn)
 			checkin.GET("/verify", bc.VerifyToken)
-			checkin.POST("/validate", bic.ValidateCheckinCode)
-			checkin.POST("/resend", bic.ResendCheckinCode)
+			checkin.GET("/session", bc.GetCheckInSession)
+			checkin.PUT("/session", bc.SaveCheckInSessionStep)
+			checkin.POST("/session/submit", bc.SubmitCheckInSession)
+			checkin.POST("/validate", checkinLimit, bic.ValidateCheckinCode)
+			checkin.POST("/resend", checkinLimit, bic.ResendCheckinCode)
+			checkin.GET("/qr", bic.GetCheckinQR)
+			checkin.POST("/scan", checkinLimit, bic.ScanCheckinQR)
+		}
+
+		// Lobby kiosk (ทุก request ต้องมี X-Kiosk-Token)
+		kiosk := api.Group("/kiosk", middleware.KioskAuth(kc.KioskSvc))
+		{
+			kiosk.GET("/me", kc.Me)
+			kiosk.POST("/lookup", checkinLimit, kc.Lookup)
+			kiosk.POST("/document", kc.ReadDocument)
+			kiosk.POST("/face", kc.CaptureFace)
+			kiosk.GET("/session", kc.GetSession)
+			kiosk.PUT("/session", kc.SaveStep)
+			kiosk.POST("/complete", kc.Complete)
+			kiosk.GET("/cases/:id", kc.GetCase)
+		}
+
+		// token ของ kiosk ใช้เช็คอินได้ทั้ง flow → ถือเป็น credential จัดการได้เฉพาะผู้ดูแลสิทธิ์
+		kioskDevices := api.Group("/kiosk-devices", requireAuth)
+		{
+			kioskDevices.GET("", middleware.RequirePermission("rolesAndPermissions.view"), kc.GetDevices)
+			kioskDevices.POST("", middleware.RequirePermission("rolesAndPermissions.edit"), kc.RegisterDevice)
+			kioskDevices.DELETE("/:id", middleware.RequirePermission("rolesAndPermissions.edit"), kc.RevokeDevice)
+		}
+
+		// คิวตรวจตัวตนของ staff (kiosk / face match ที่ถูก flag)
+		verificationCases := api.Group("/verification-cases", requireAuth)
+		{
+			verificationCases.GET("", middleware.RequirePermission("tm30Verification.view"), vcc.GetCases)
+			verificationCases.GET("/:id", middleware.RequirePermission("tm30Verification.view"), vcc.GetCase)
+			verificationCases.POST("/:id/approve", middleware.RequirePermission("tm30Verification.verify"), vcc.ApproveCase)
+			verificationCases.POST("/:id/reject", middleware.RequirePermission("tm30Verification.verify"), vcc.RejectCase)
+		}
+
+		// ช่องค้นหาของ front desk (การจอง / ลูกค้า / แขก / ห้อง)
+		api.GET("/search", requireAuth, middleware.RequirePermission("bookingManagement.view"), src.Search)
+
+		// dev only: ดูอีเมลที่ถูก capture (APP_ENV=development)
+		if utils.IsDevMode() {
+			dev := api.Group("/dev")
+			{
+				dev.GET("/mailbox", controllers.GetDevMailbox)
+				dev.GET("/mailbox/:id", controllers.GetDevMailboxMessage)
+				dev.DELETE("/mailbox", controllers.ClearDevMailbox)
+
+				// channel manager จำลอง (CHANNEL_MANAGER_URL=http://localhost:<port>/api/dev/channel-manager)
+				dev.POST("/channel-manager/availability", controllers.DevChannelAvailability)
+				dev.POST("/channel-manager/rates", controllers.DevChannelRates)
+				dev.GET("/channel-manager/reservations", controllers.DevChannelReservations)
+				dev.POST("/channel-manager/reservations", controllers.DevChannelAddReservation)
+				dev.GET("/channel-manager/state", controllers.DevChannelState)
+				dev.DELETE("/channel-manager", controllers.DevChannelReset)
+			}
 		}
 
 		api.POST("/verify/idcard", func(c *gin.Context) {
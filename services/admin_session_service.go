@@ -0,0 +1,116 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnauthenticated  = NewError(KindUnauthorized, "error.unauthenticated")
+	ErrPermissionDenied = NewError(KindForbidden, "error.permissionDenied")
+)
+
+// AdminSessionService ออก / ตรวจ / ยกเลิก token ของ staff และหา permission จาก role
+type AdminSessionService struct {
+	DB *gorm.DB
+}
+
+func NewAdminSessionService(db *gorm.DB) *AdminSessionService {
+	return &AdminSessionService{DB: db}
+}
+
+// adminSessionTTL อายุ token (ADMIN_SESSION_TTL_HOURS, default 12 ชม.)
+func adminSessionTTL() time.Duration {
+	if n, err := strconv.Atoi(utils.EnvOrDefault("ADMIN_SESSION_TTL_HOURS", "12")); err == nil && n > 0 {
+		return time.Duration(n) * time.Hour
+	}
+	return 12 * time.Hour
+}
+
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create เปิด session ใหม่ให้ admin และคืน token จริง (เก็บเฉพาะ hash)
+func (s *AdminSessionService) Create(adminID uint, ip, userAgent string) (models.AdminSession, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return models.AdminSession{}, "", err
+	}
+	token := hex.EncodeToString(raw)
+
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	session := models.AdminSession{
+		AdminID:   adminID,
+		TokenHash: hashSessionToken(token),
+		ExpiresAt: time.Now().UTC().Add(adminSessionTTL()),
+		IP:        ip,
+		UserAgent: userAgent,
+	}
+	if err := s.DB.Create(&session).Error; err != nil {
+		return session, "", err
+	}
+	return session, token, nil
+}
+
+// Authenticate ตรวจ token → admin ของ session (หมดอายุ / ถูกยกเลิก / admin ถูกลบ = ErrUnauthenticated)
+func (s *AdminSessionService) Authenticate(token, ip string) (models.Admin, models.AdminSession, error) {
+	var admin models.Admin
+	var session models.AdminSession
+	if strings.TrimSpace(token) == "" {
+		return admin, session, ErrUnauthenticated
+	}
+	now := time.Now().UTC()
+	if err := s.DB.Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ?", hashSessionToken(token), now).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return admin, session, ErrUnauthenticated
+		}
+		return admin, session, err
+	}
+	if err := s.DB.First(&admin, session.AdminID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return admin, session, ErrUnauthenticated
+		}
+		return admin, session, err
+	}
+	s.DB.Model(&session).Updates(map[string]interface{}{"last_seen_at": now, "ip": ip})
+	return admin, session, nil
+}
+
+// Revoke ยกเลิก token (logout); token ที่ไม่มีอยู่ถือว่าสำเร็จ
+func (s *AdminSessionService) Revoke(token string) error {
+	return s.DB.Model(&models.AdminSession{}).
+		Where("token_hash = ? AND revoked_at IS NULL", hashSessionToken(token)).
+		Update("revoked_at", time.Now().UTC()).Error
+}
+
+// Permissions รวม permission ("module.action") จากทุก role ที่ admin เป็นสมาชิก
+func (s *AdminSessionService) Permissions(adminID uint) (map[string]bool, error) {
+	var perms []string
+	if err := s.DB.Model(&models.RolePermission{}).
+		Distinct("role_permissions.permission").
+		Joins("JOIN role_members rm ON rm.role_id = role_permissions.role_id").
+		Where("rm.admin_id = ?", adminID).
+		Pluck("role_permissions.permission", &perms).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		out[p] = true
+	}
+	return out, nil
+}
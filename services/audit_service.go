@@ -0,0 +1,60 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"hotel-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Audit actor types
+const (
+	ActorSystem = "system"
+	ActorAdmin  = "admin"
+	ActorGuest  = "guest"
+	ActorKiosk  = "kiosk"
+)
+
+// AuditEntry ข้อมูลที่ต้องการบันทึกลง audit_logs
+type AuditEntry struct {
+	ActorType string
+	ActorID   string
+	Action    string
+	Target    string
+	IP        string
+	Details   map[string]interface{}
+}
+
+// AdminActor staff ที่สั่งงาน (ใช้บันทึกประวัติ / audit log)
+type AdminActor struct {
+	AdminID   uint
+	AdminName string
+	IP        string
+}
+
+// RecordAudit บันทึก audit log แบบ best-effort (ไม่ทำให้ request หลักล้มถ้าบันทึกไม่สำเร็จ)
+func RecordAudit(db *gorm.DB, e AuditEntry) {
+	if db == nil {
+		log.Printf("audit (no db): %s %s target=%s ip=%s", e.ActorType, e.Action, e.Target, e.IP)
+		return
+	}
+	row := models.AuditLog{
+		ActorType: e.ActorType,
+		ActorID:   e.ActorID,
+		Action:    e.Action,
+		Target:    e.Target,
+		IP:        e.IP,
+		CreatedAt: time.Now().UTC(),
+	}
+	if len(e.Details) > 0 {
+		if b, err := json.Marshal(e.Details); err == nil {
+			row.Details = string(b)
+		}
+	}
+	if err := db.Create(&row).Error; err != nil {
+		log.Printf("failed to write audit log %s: %v", e.Action, err)
+	}
+}
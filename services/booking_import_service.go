@@ -0,0 +1,674 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUnsupportedImportFormat = NewError(KindInvalid, "error.unsupportedImportFormat")
+	ErrImportUnreadable        = NewError(KindInvalid, "error.importUnreadable")
+	ErrImportColumnsMissing    = NewError(KindInvalid, "error.importColumnsMissing")
+	ErrImportTooManyRows       = NewError(KindInvalid, "error.importTooManyRows")
+	ErrImportNotFound          = NewError(KindNotFound, "error.importNotFound")
+	ErrImportAlreadyCommitted  = NewError(KindConflict, "error.importAlreadyCommitted")
+	ErrImportHasErrors         = NewError(KindUnprocessable, "error.importHasErrors")
+)
+
+const (
+	ImportPreview   = "preview"
+	ImportCommitted = "committed"
+
+	ImportRowReady   = "ready"
+	ImportRowError   = "error"
+	ImportRowSkipped = "skipped"
+	ImportRowCreated = "created"
+
+	MaxImportRows = 5000
+)
+
+// ImportSources แหล่งที่มาของไฟล์ (เก็บเป็น bookings.source)
+var ImportSources = []string{"booking.com", "agoda", "spreadsheet"}
+
+// importFieldAliases หัวคอลัมน์ที่รู้จักของแต่ละ field (ไม่สนตัวพิมพ์ / ช่องว่าง / เครื่องหมาย)
+// รวมชื่อคอลัมน์จากไฟล์ export ของ Booking.com และ Agoda; ใช้ mapping ของ request แทนได้
+var importFieldAliases = map[string][]string{
+	"reference":   {"reference", "referencecode", "bookingreference", "booknumber", "bookingnumber", "bookingid", "reservationid", "reservationnumber", "confirmationnumber"},
+	"guestName":   {"guestname", "guestnames", "guest", "customername", "name", "fullname", "bookedby", "bookername"},
+	"firstName":   {"firstname", "guestfirstname"},
+	"lastName":    {"lastname", "surname", "guestlastname"},
+	"email":       {"email", "guestemail", "emailaddress", "bookeremail"},
+	"phone":       {"phone", "phonenumber", "telephone", "mobile", "guestphone", "bookerphone"},
+	"nationality": {"nationality", "country", "bookercountry", "guestcountry"},
+	"checkIn":     {"checkin", "checkindate", "arrival", "arrivaldate"},
+	"checkOut":    {"checkout", "checkoutdate", "departure", "departuredate"},
+	"room":        {"room", "roomnumber", "roomno", "unit"},
+	"roomType":    {"roomtype", "unittype", "roomname", "roomcategory"},
+	"adults":      {"adults", "adult", "persons", "guests", "numberofguests"},
+	"children":    {"children", "child", "kids"},
+	"status":      {"status", "bookingstatus", "reservationstatus"},
+}
+
+// ImportFields field มาตรฐานที่ import รองรับ (ใช้เป็น key ของ mapping)
+func ImportFields() []string {
+	fields := make([]string, 0, len(importFieldAliases))
+	for f := range importFieldAliases {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ImportUpload ไฟล์ที่อัปโหลดมาพร้อมตัวเลือก
+type ImportUpload struct {
+	FileName  string
+	Data      []byte
+	Source    string
+	DateOrder string            // dmy (default) | mdy
+	Mapping   map[string]string // field → หัวคอลัมน์ในไฟล์ (ไม่ส่ง = เดาจากชื่อคอลัมน์)
+}
+
+// BookingImportService import booking จาก CSV / XLSX: Preview = dry run, Commit = สร้างจริงทั้งไฟล์ใน transaction เดียว
+type BookingImportService struct {
+	DB *gorm.DB
+}
+
+func NewBookingImportService(db *gorm.DB) *BookingImportService {
+	return &BookingImportService{DB: db}
+}
+
+// Preview อ่านไฟล์ จับคู่คอลัมน์ ตรวจทุกแถว (ลูกค้า / ห้อง / ห้องว่าง / reference ซ้ำ) แล้วเก็บเป็น import รอ commit
+func (s *BookingImportService) Preview(up ImportUpload, actor AdminActor) (models.BookingImport, error) {
+	imp := models.BookingImport{
+		AdminName: actor.AdminName,
+		Source:    strings.ToLower(strings.TrimSpace(up.Source)),
+		FileName:  filepath.Base(up.FileName),
+		DateOrder: up.DateOrder,
+		Status:    ImportPreview,
+	}
+	if actor.AdminID != 0 {
+		imp.AdminID = &actor.AdminID
+	}
+	if imp.Source == "" {
+		imp.Source = "spreadsheet"
+	}
+	if imp.DateOrder != "mdy" {
+		imp.DateOrder = "dmy"
+	}
+
+	var table [][]string
+	var err error
+	switch strings.ToLower(filepath.Ext(up.FileName)) {
+	case ".csv", ".txt":
+		imp.Format = ExportCSV
+		table, err = utils.ReadCSVTable(up.Data)
+	case ".xlsx":
+		imp.Format = ExportXLSX
+		table, err = utils.ReadXLSXTable(up.Data)
+	default:
+		return imp, ErrUnsupportedImportFormat.WithDetails(map[string]interface{}{"formats": []string{"csv", "xlsx"}})
+	}
+	if err != nil {
+		return imp, ErrImportUnreadable.Wrap(err)
+	}
+	if len(table)-1 > MaxImportRows {
+		return imp, ErrImportTooManyRows.WithDetails(map[string]interface{}{"max": MaxImportRows})
+	}
+
+	columns, err := resolveImportColumns(table[0], up.Mapping)
+	if err != nil {
+		return imp, err
+	}
+	imp.Columns = datatypes.JSONMap{}
+	for field, idx := range columns {
+		imp.Columns[field] = table[0][idx]
+	}
+
+	imp.Rows = datatypes.JSONSlice[models.BookingImportRow]{}
+	for i, raw := range table[1:] {
+		row := models.BookingImportRow{Row: i + 2, Values: map[string]string{}}
+		empty := true
+		for field, idx := range columns {
+			if idx < len(raw) && raw[idx] != "" {
+				row.Values[field] = raw[idx]
+				empty = false
+			}
+		}
+		if !empty {
+			imp.Rows = append(imp.Rows, row)
+		}
+	}
+
+	plans, err := s.plan(s.DB, imp)
+	if err != nil {
+		return imp, err
+	}
+	setImportResults(&imp, plans)
+	if err := s.DB.Create(&imp).Error; err != nil {
+		return imp, fmt.Errorf("save booking import: %w", err)
+	}
+	return imp, nil
+}
+
+// Get import ที่เคย preview / commit
+func (s *BookingImportService) Get(id uint) (models.BookingImport, error) {
+	var imp models.BookingImport
+	if err := s.DB.First(&imp, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return imp, ErrImportNotFound
+		}
+		return imp, err
+	}
+	return imp, nil
+}
+
+// Commit ตรวจทุกแถวใหม่ใน transaction (ห้องอาจถูกจองไปหลัง preview) แล้วสร้างลูกค้า / booking ทั้งไฟล์
+// ถ้ายังมีแถวที่ error จะไม่สร้างอะไรเลย และคืนผลตรวจล่าสุดพร้อม ErrImportHasErrors
+// import ถูก lock ตลอด transaction — กดส่งซ้ำพร้อมกันจะ commit ได้ครั้งเดียว
+func (s *BookingImportService) Commit(id uint, actor AdminActor) (models.BookingImport, error) {
+	var imp models.BookingImport
+	var plans []importPlan
+	txErr := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&imp, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrImportNotFound
+			}
+			return err
+		}
+		if imp.Status != ImportPreview {
+			return ErrImportAlreadyCommitted
+		}
+
+		var err error
+		if plans, err = s.plan(tx, imp); err != nil {
+			return err
+		}
+		for _, p := range plans {
+			if p.result.Status == ImportRowError {
+				return ErrImportHasErrors
+			}
+		}
+
+		created := map[string]uint{} // customer ใหม่ที่สร้างใน import นี้ (key จาก importPlan.customerKey)
+		for i := range plans {
+			p := &plans[i]
+			if p.result.Status != ImportRowReady {
+				continue
+			}
+			customerID := p.result.CustomerID
+			if customerID == 0 {
+				if id, ok := created[p.customerKey]; ok {
+					customerID = id
+				} else {
+					if err := tx.Create(&p.newCustomer).Error; err != nil {
+						return fmt.Errorf("row %d: create customer: %w", p.result.Row, err)
+					}
+					customerID = p.newCustomer.ID
+					created[p.customerKey] = customerID
+				}
+			}
+
+			booking := models.Booking{
+				CustomerID:     customerID,
+				ReferenceCode:  p.result.Reference,
+				Source:         imp.Source,
+				Status:         "Confirmed",
+				CheckIn:        &p.checkIn,
+				CheckOut:       &p.checkOut,
+				CheckInDate:    &p.checkIn,
+				CheckOutDate:   &p.checkOut,
+				Nights:         p.nights,
+				Adults:         p.adults,
+				Children:       p.children,
+				NumberOfGuests: p.adults + p.children,
+			}
+			if err := tx.Create(&booking).Error; err != nil {
+				return fmt.Errorf("row %d: create booking: %w", p.result.Row, err)
+			}
+			br := models.BookingRoom{BookingID: booking.ID, RoomID: p.result.RoomID, Nights: p.nights, Status: "Reserved"}
+			if err := tx.Create(&br).Error; err != nil {
+				return fmt.Errorf("row %d: create booking room: %w", p.result.Row, err)
+			}
+			p.result.Status = ImportRowCreated
+			p.result.BookingID = booking.ID
+			p.result.CustomerID = customerID
+		}
+
+		setImportResults(&imp, plans)
+		now := time.Now().UTC()
+		imp.Status = ImportCommitted
+		imp.CommittedAt = &now
+		imp.BookingIDs = datatypes.JSONSlice[uint]{}
+		for _, p := range plans {
+			if p.result.BookingID != 0 {
+				imp.BookingIDs = append(imp.BookingIDs, p.result.BookingID)
+			}
+		}
+		return tx.Save(&imp).Error
+	})
+
+	if errors.Is(txErr, ErrImportHasErrors) {
+		// เก็บผลตรวจล่าสุดไว้ให้ดู (ยังเป็น preview แก้ไฟล์แล้วอัปโหลดใหม่ได้)
+		setImportResults(&imp, plans)
+		// ไม่ทับผลของ import ที่ถูก commit ไปแล้วโดย request อื่น
+		s.DB.Model(&imp).Where("status = ?", ImportPreview).Select("results", "ready_rows", "error_rows", "skipped_rows").Updates(&imp)
+		return imp, ErrImportHasErrors.WithDetails(map[string]interface{}{"errorRows": imp.ErrorRows})
+	}
+	if txErr != nil {
+		return imp, txErr
+	}
+
+	RecordAudit(s.DB, AuditEntry{
+		ActorType: ActorAdmin,
+		ActorID:   strconv.FormatUint(uint64(actor.AdminID), 10),
+		Action:    "booking.import",
+		Target:    fmt.Sprintf("booking_import:%d", imp.ID),
+		IP:        actor.IP,
+		Details:   map[string]interface{}{"source": imp.Source, "file": imp.FileName, "bookings": len(imp.BookingIDs)},
+	})
+	return imp, nil
+}
+
+// ---------------- planning ----------------
+
+type importPlan struct {
+	result      models.BookingImportResult
+	checkIn     time.Time
+	checkOut    time.Time
+	nights      int
+	adults      int
+	children    int
+	newCustomer models.Customer
+	customerKey string
+}
+
+// plan ตรวจทุกแถวกับข้อมูลใน db (ไม่เขียนอะไร) แถวที่ผ่านจะได้ห้องที่จองให้แล้ว
+func (s *BookingImportService) plan(db *gorm.DB, imp models.BookingImport) ([]importPlan, error) {
+	var rooms []models.Room
+	if err := db.Preload("RoomType").Order("room_number").Find(&rooms).Error; err != nil {
+		return nil, fmt.Errorf("load rooms: %w", err)
+	}
+	roomsByNumber := map[string]models.Room{}
+	for _, r := range rooms {
+		roomsByNumber[strings.ToLower(r.RoomNumber)] = r
+	}
+
+	taken := map[uint][]roomInterval{} // ห้องที่แถวก่อนหน้าในไฟล์เดียวกันจองไปแล้ว
+	refs := map[string]int{}           // reference → แถวแรกที่ใช้
+	customers := newImportCustomerMatcher(db)
+
+	plans := make([]importPlan, 0, len(imp.Rows))
+	for _, row := range imp.Rows {
+		v := row.Values
+		p := importPlan{result: models.BookingImportResult{Row: row.Row, Reference: v["reference"]}}
+		issue := func(field, rule, param string) {
+			p.result.Issues = append(p.result.Issues, models.ImportIssue{Field: field, Rule: rule, Param: param})
+		}
+
+		if isCancelledImportStatus(v["status"]) {
+			p.result.Status = ImportRowSkipped
+			p.result.Issues = append(p.result.Issues, models.ImportIssue{Field: "status", Rule: "cancelled", Param: v["status"], Warning: true})
+			plans = append(plans, p)
+			continue
+		}
+
+		name := v["guestName"]
+		if name == "" {
+			name = strings.TrimSpace(v["firstName"] + " " + v["lastName"])
+		}
+		p.result.GuestName = name
+		if name == "" {
+			issue("guestName", "required", "")
+		}
+		if ref := p.result.Reference; ref != "" {
+			if first, dup := refs[strings.ToLower(ref)]; dup {
+				issue("reference", "duplicate", strconv.Itoa(first))
+			} else {
+				refs[strings.ToLower(ref)] = row.Row
+				var n int64
+				if err := db.Model(&models.Booking{}).Where("reference_code = ?", ref).Count(&n).Error; err != nil {
+					return nil, err
+				}
+				if n > 0 {
+					issue("reference", "taken", ref)
+				}
+			}
+		}
+
+		ci, ciOK := parseImportDate(v["checkIn"], imp.DateOrder)
+		co, coOK := parseImportDate(v["checkOut"], imp.DateOrder)
+		switch {
+		case v["checkIn"] == "":
+			issue("checkIn", "required", "")
+		case !ciOK:
+			issue("checkIn", "dateonly", "")
+		}
+		switch {
+		case v["checkOut"] == "":
+			issue("checkOut", "required", "")
+		case !coOK:
+			issue("checkOut", "dateonly", "")
+		case ciOK && !co.After(ci):
+			issue("checkOut", "after", "checkIn")
+		}
+		if ciOK {
+			p.checkIn, p.result.CheckIn = ci, ci.Format("2006-01-02")
+		}
+		if coOK {
+			p.checkOut, p.result.CheckOut = co, co.Format("2006-01-02")
+		}
+		p.nights = int(p.checkOut.Sub(p.checkIn).Hours() / 24)
+
+		p.adults, p.children = 1, 0
+		if n, ok := importCount(v["adults"]); ok {
+			if n > 0 {
+				p.adults = n
+			}
+		} else {
+			issue("adults", "type", "integer")
+		}
+		if n, ok := importCount(v["children"]); ok {
+			p.children = n
+		} else {
+			issue("children", "type", "integer")
+		}
+
+		email := strings.ToLower(strings.TrimSpace(v["email"]))
+		if email != "" && !importEmailRegex.MatchString(email) {
+			issue("email", "email", "")
+			email = ""
+		}
+
+		// ห้อง: ระบุเลขห้องตรง ๆ หรือหาห้องว่างจากประเภทห้อง
+		datesOK := ciOK && coOK && co.After(ci)
+		var candidates []models.Room
+		roomField := "room"
+		switch {
+		case v["room"] != "":
+			r, ok := roomsByNumber[strings.ToLower(v["room"])]
+			if !ok {
+				issue("room", "exists", v["room"])
+			} else {
+				candidates = []models.Room{r}
+			}
+		case v["roomType"] != "":
+			roomField = "roomType"
+			for _, r := range rooms {
+				if strings.EqualFold(r.RoomType.TypeName, v["roomType"]) || strings.EqualFold(r.Type, v["roomType"]) {
+					candidates = append(candidates, r)
+				}
+			}
+			if len(candidates) == 0 {
+				issue("roomType", "exists", v["roomType"])
+			}
+		default:
+			issue("room", "requiredOneOf", "room, roomType")
+		}
+		if datesOK && len(candidates) > 0 {
+			ids := make([]uint, len(candidates))
+			for i, r := range candidates {
+				ids[i] = r.ID
+			}
+			booked, err := BookedRoomIDs(db, ids, ci, co, 0)
+			if err != nil {
+				return nil, fmt.Errorf("check availability: %w", err)
+			}
+			for _, r := range candidates {
+				if !booked[r.ID] && !overlapsAny(taken[r.ID], ci, co) {
+					p.result.RoomID, p.result.RoomNumber = r.ID, r.RoomNumber
+					break
+				}
+			}
+			if p.result.RoomID == 0 {
+				issue(roomField, "unavailable", p.result.CheckIn+" – "+p.result.CheckOut)
+			}
+		}
+
+		if len(p.result.Issues) > 0 {
+			p.result.Status = ImportRowError
+			plans = append(plans, p)
+			continue
+		}
+		taken[p.result.RoomID] = append(taken[p.result.RoomID], roomInterval{ci, co})
+
+		match, key, err := customers.match(email, v["phone"])
+		if key == "" {
+			key = "row:" + strconv.Itoa(row.Row)
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.customerKey = key
+		if match != 0 {
+			p.result.CustomerID, p.result.Customer = match, "matched"
+		} else {
+			p.result.Customer = "new"
+			p.newCustomer = models.Customer{
+				FullName:    name,
+				Email:       email,
+				Phone:       strings.TrimSpace(v["phone"]),
+				Nationality: strings.TrimSpace(v["nationality"]),
+				Tags:        datatypes.JSONSlice[string]{},
+			}
+		}
+		p.result.Status = ImportRowReady
+		plans = append(plans, p)
+	}
+	return plans, nil
+}
+
+func setImportResults(imp *models.BookingImport, plans []importPlan) {
+	imp.Results = make(datatypes.JSONSlice[models.BookingImportResult], 0, len(plans))
+	imp.TotalRows, imp.ReadyRows, imp.ErrorRows, imp.SkippedRows = len(plans), 0, 0, 0
+	for _, p := range plans {
+		imp.Results = append(imp.Results, p.result)
+		switch p.result.Status {
+		case ImportRowReady, ImportRowCreated:
+			imp.ReadyRows++
+		case ImportRowError:
+			imp.ErrorRows++
+		case ImportRowSkipped:
+			imp.SkippedRows++
+		}
+	}
+}
+
+// importCustomerMatcher จับคู่ลูกค้าเดิมด้วยอีเมล แล้วเบอร์โทร (ไม่จับคู่ด้วยชื่ออย่างเดียว)
+// แถวในไฟล์เดียวกันที่อีเมล / เบอร์ตรงกันจะได้ลูกค้าใหม่คนเดียวกัน
+type importCustomerMatcher struct {
+	db      *gorm.DB
+	byEmail map[string]uint
+	byPhone map[string]uint
+}
+
+func newImportCustomerMatcher(db *gorm.DB) *importCustomerMatcher {
+	return &importCustomerMatcher{db: db, byEmail: map[string]uint{}, byPhone: map[string]uint{}}
+}
+
+// match คืน id ลูกค้าเดิม (0 = ต้องสร้างใหม่) และ key ที่ใช้รวมแถวที่เป็นลูกค้าใหม่คนเดียวกัน
+// (key ว่าง = ไม่มีอีเมล / เบอร์ สร้างลูกค้าใหม่ของแถวนั้น)
+func (m *importCustomerMatcher) match(email, phone string) (uint, string, error) {
+	if email != "" {
+		id, ok := m.byEmail[email]
+		if !ok {
+			var c models.Customer
+			err := m.db.Select("id").Where("LOWER(email) = ?", email).Order("id").First(&c).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, "", err
+			}
+			id = c.ID
+			m.byEmail[email] = id
+		}
+		return id, "email:" + email, nil
+	}
+	if p := normalizePhone(phone); p != "" {
+		id, ok := m.byPhone[p]
+		if !ok {
+			var list []models.Customer
+			tail := p[len(p)-8:]
+			if err := m.db.Select("id", "phone").
+				Where("REPLACE(REPLACE(REPLACE(phone, '-', ''), ' ', ''), '+', '') LIKE ?", "%"+tail).
+				Order("id").Find(&list).Error; err != nil {
+				return 0, "", err
+			}
+			for _, c := range list {
+				if normalizePhone(c.Phone) == p {
+					id = c.ID
+					break
+				}
+			}
+			m.byPhone[p] = id
+		}
+		return id, "phone:" + p, nil
+	}
+	return 0, "", nil
+}
+
+var importEmailRegex = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+
+// resolveImportColumns field → index ของคอลัมน์ (mapping ของ request มาก่อน แล้วค่อยเดาจากหัวคอลัมน์)
+func resolveImportColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	byName := map[string]int{}
+	for i, h := range header {
+		if k := importHeaderKey(h); k != "" {
+			if _, dup := byName[k]; !dup {
+				byName[k] = i
+			}
+		}
+	}
+
+	columns := map[string]int{}
+	var unknown []string
+	for field, h := range mapping {
+		if _, ok := importFieldAliases[field]; !ok {
+			unknown = append(unknown, field)
+			continue
+		}
+		idx, ok := byName[importHeaderKey(h)]
+		if !ok {
+			unknown = append(unknown, field+"="+h)
+			continue
+		}
+		columns[field] = idx
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, ErrImportColumnsMissing.WithDetails(map[string]interface{}{"unknownMapping": unknown, "headers": header, "fields": ImportFields()})
+	}
+
+	used := map[int]bool{}
+	for _, idx := range columns {
+		used[idx] = true
+	}
+	for _, field := range ImportFields() {
+		if _, ok := columns[field]; ok {
+			continue
+		}
+		for _, alias := range importFieldAliases[field] {
+			if idx, ok := byName[alias]; ok && !used[idx] {
+				columns[field], used[idx] = idx, true
+				break
+			}
+		}
+	}
+
+	var missing []string
+	for _, field := range []string{"checkIn", "checkOut"} {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if _, ok := columns["guestName"]; !ok {
+		if _, ok := columns["lastName"]; !ok {
+			missing = append(missing, "guestName")
+		}
+	}
+	_, hasRoom := columns["room"]
+	_, hasType := columns["roomType"]
+	if !hasRoom && !hasType {
+		missing = append(missing, "room|roomType")
+	}
+	if len(missing) > 0 {
+		return nil, ErrImportColumnsMissing.WithDetails(map[string]interface{}{"missing": missing, "headers": header, "fields": ImportFields()})
+	}
+	return columns, nil
+}
+
+// importHeaderKey "Guest name(s)" → "guestnames"
+func importHeaderKey(h string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(h) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+var importDateLayouts = []string{
+	"2006-01-02", "2006/01/02", "2006-01-02 15:04:05", "2006-01-02T15:04:05", time.RFC3339,
+	"2 Jan 2006", "02 Jan 2006", "2 January 2006", "Jan 2, 2006", "January 2, 2006", "Mon, Jan 2, 2006",
+}
+
+// parseImportDate วันที่จากไฟล์ → เที่ยงคืน UTC (แบบเดียวกับ CreateBookingMultiple)
+// รองรับ serial number ของ Excel และ 01/02/2025 ตาม dateOrder (dmy / mdy)
+func parseImportDate(v, dateOrder string) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, false
+	}
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	if t, ok := utils.ExcelSerialTime(v); ok {
+		return day(t), true
+	}
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return day(t), true
+		}
+	}
+	slash := []string{"02/01/2006", "2/1/2006", "02.01.2006", "02-01-2006"}
+	if dateOrder == "mdy" {
+		slash = []string{"01/02/2006", "1/2/2006", "01.02.2006", "01-02-2006"}
+	}
+	for _, layout := range slash {
+		if t, err := time.Parse(layout, v); err == nil {
+			return day(t), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// importCount จำนวนคน (ว่าง = 0; "2 adults" อ่านเฉพาะตัวเลขต้น)
+func importCount(v string) (int, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, true
+	}
+	end := 0
+	for end < len(v) && v[end] >= '0' && v[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v[:end])
+	return n, err == nil && n >= 0 && n <= 50
+}
+
+func isCancelledImportStatus(status string) bool {
+	s := importHeaderKey(status)
+	return strings.HasPrefix(s, "cancel") || s == "noshow" || s == "rejected"
+}
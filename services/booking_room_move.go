@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BookingRoom.Status
+const (
+	BookingRoomStatusReserved = "Reserved"
+	BookingRoomStatusMoved    = "Moved" // segment ที่สิ้นสุดเพราะย้ายห้อง
+)
+
+var (
+	ErrBookingRoomNotFound = NewError(KindNotFound, "error.bookingRoomNotFound")
+	ErrRoomMoveNotAllowed  = NewError(KindConflict, "error.roomMoveNotAllowed")
+	ErrRoomMoveDate        = NewError(KindInvalid, "error.roomMoveDate")
+	ErrRoomMoveSameRoom    = NewError(KindInvalid, "error.roomMoveSameRoom")
+	ErrRoomNotAvailable    = NewError(KindConflict, "error.roomNotAvailable")
+)
+
+// RoomMoveInput ย้ายแขกไปห้อง ToRoomID ตั้งแต่คืนของวันที่ Date (nil = วันนี้)
+type RoomMoveInput struct {
+	ToRoomID uint
+	Date     *time.Time
+	Reason   string
+}
+
+// RoomMoveResult segment เดิม (สิ้นสุดวันที่ย้าย) และ segment ใหม่
+type RoomMoveResult struct {
+	From models.BookingRoom `json:"from"`
+	To   models.BookingRoom `json:"to"`
+}
+
+// MoveRoom ย้ายห้องระหว่างพัก: ตัด segment เดิมที่วันย้าย แล้วเปิด segment ใหม่ของห้องปลายทางจนถึงวันออกเดิม
+// ห้องปลายทางต้องว่าง (ไม่มี booking / block / ปิดใช้งาน) ตลอดช่วงที่เหลือ
+// ถ้าแขกเข้าพักอยู่และย้ายวันนี้ ห้องเดิมเป็น dirty พร้อมงานแม่บ้าน; สถานะเข้าพักของทั้งสองห้องคำนวณจาก segment
+func (s *BookingService) MoveRoom(bookingID, bookingRoomID uint, in RoomMoveInput, actor AdminActor) (RoomMoveResult, error) {
+	var res RoomMoveResult
+	var from, to models.BookingRoom
+	var moveDate time.Time
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var booking models.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, bookingID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookingNotFound
+			}
+			return err
+		}
+		if containsStatus(BookingReleasedStatuses, booking.Status) {
+			return ErrRoomMoveNotAllowed.WithDetails(map[string]interface{}{"status": booking.Status})
+		}
+		if booking.CheckIn == nil || booking.CheckOut == nil {
+			return ErrRoomMoveNotAllowed.WithDetails(map[string]interface{}{"reason": "missingDates"})
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("booking_id = ?", bookingID).First(&from, bookingRoomID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookingRoomNotFound
+			}
+			return err
+		}
+		if from.Status == BookingRoomStatusMoved {
+			return ErrRoomMoveNotAllowed.WithDetails(map[string]interface{}{"reason": "segmentEnded"})
+		}
+
+		start, end := HousekeepingDate(*booking.CheckIn), HousekeepingDate(*booking.CheckOut)
+		if from.StartDate != nil {
+			start = HousekeepingDate(*from.StartDate)
+		}
+		if from.EndDate != nil {
+			end = HousekeepingDate(*from.EndDate)
+		}
+		moveDate = HousekeepingDate(time.Now())
+		if in.Date != nil {
+			moveDate = HousekeepingDate(*in.Date)
+		}
+		if moveDate.Before(start) || !moveDate.Before(end) {
+			return ErrRoomMoveDate.WithDetails(map[string]interface{}{
+				"startDate": start.Format("2006-01-02"),
+				"endDate":   end.Format("2006-01-02"),
+			})
+		}
+
+		if err := ensureMoveTarget(tx, from.RoomID, in.ToRoomID, moveDate, end); err != nil {
+			return err
+		}
+
+		to = models.BookingRoom{
+			BookingID:   bookingID,
+			RoomID:      in.ToRoomID,
+			StartDate:   &moveDate,
+			EndDate:     from.EndDate, // nil = ตามวันออกของ booking (ขยายวันพักแล้วตามไปด้วย)
+			Nights:      nightsBetween(moveDate, end),
+			Status:      firstNonEmpty(from.Status, BookingRoomStatusReserved),
+			MovedFromID: &from.ID,
+			MoveReason:  truncateRunes(strings.TrimSpace(in.Reason), 255),
+		}
+		if actor.AdminID != 0 {
+			id := actor.AdminID
+			to.MovedByID = &id
+		}
+		if err := tx.Model(&from).Updates(map[string]interface{}{
+			"end_date": moveDate,
+			"nights":   nightsBetween(start, moveDate),
+			"status":   BookingRoomStatusMoved,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&to).Error; err != nil {
+			return err
+		}
+
+		// bookings.room_id แบบเดิมนับทั้งช่วงเข้าพัก — หลังแบ่ง segment ใช้ booking_rooms อย่างเดียว
+		if booking.RoomID != nil && *booking.RoomID == from.RoomID {
+			if err := tx.Model(&booking).Update("room_id", nil).Error; err != nil {
+				return err
+			}
+		}
+
+		// แขกออกจากห้องเดิมแล้ว → ทำความสะอาดก่อนขายต่อ (ย้ายล่วงหน้า: งานแม่บ้านสร้างตามวันที่ segment สิ้นสุด)
+		if booking.Status == "Checked-In" && !moveDate.After(HousekeepingDate(time.Now())) {
+			return markRoomsDirty(tx, []uint{from.RoomID}, bookingID, RoomStatusSourceRoomMove)
+		}
+		return nil
+	})
+	if err != nil {
+		return res, err
+	}
+
+	RecordAudit(s.DB, AuditEntry{
+		ActorType: ActorAdmin,
+		ActorID:   strconv.FormatUint(uint64(actor.AdminID), 10),
+		Action:    "booking.room.move",
+		Target:    fmt.Sprintf("booking:%d", bookingID),
+		IP:        actor.IP,
+		Details: map[string]interface{}{
+			"fromRoomId":    from.RoomID,
+			"toRoomId":      to.RoomID,
+			"bookingRoomId": to.ID,
+			"date":          moveDate.Format("2006-01-02"),
+			"reason":        to.MoveReason,
+		},
+	})
+
+	if err := s.DB.Preload("Room").First(&res.From, from.ID).Error; err != nil {
+		return res, err
+	}
+	if err := s.DB.Preload("Room").First(&res.To, to.ID).Error; err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// ensureMoveTarget ห้องปลายทางต้องมีอยู่จริง ไม่ใช่ห้องเดิม และว่างตลอด [from, to)
+// lock แถวห้องปลายทางไว้จนจบ transaction กันการย้าย / จองห้องเดียวกันพร้อมกัน
+func ensureMoveTarget(tx *gorm.DB, currentRoomID, roomID uint, from, to time.Time) error {
+	if roomID == currentRoomID {
+		return ErrRoomMoveSameRoom
+	}
+	var room models.Room
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&room, roomID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoomNotFound
+		}
+		return err
+	}
+	if roomOutOfServiceOn(room, from) {
+		return ErrRoomOutOfService.WithDetails(map[string]interface{}{
+			"roomId":        room.ID,
+			"serviceStatus": room.ServiceStatus,
+			"serviceUntil":  room.ServiceUntil,
+		})
+	}
+	block, err := FirstRoomBlock(tx, []uint{roomID}, from, to)
+	if err != nil {
+		return err
+	}
+	if block != nil {
+		return ErrRoomBlocked.WithDetails(map[string]interface{}{
+			"roomId":    block.RoomID,
+			"kind":      block.Kind,
+			"startDate": block.StartDate.Format("2006-01-02"),
+			"endDate":   block.EndDate.Format("2006-01-02"),
+			"summary":   block.Summary,
+		})
+	}
+	conflicts, err := BlockConflicts(tx, roomID, from, to)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return ErrRoomNotAvailable.WithDetails(map[string]interface{}{"roomId": roomID, "bookings": conflicts})
+	}
+	return nil
+}
+
+// CurrentBookingRooms ห้องที่ booking ใช้อยู่ (ตัด segment ที่ย้ายออกไปแล้ว) สำหรับแสดงห้องของ booking
+func CurrentBookingRooms(rooms []models.BookingRoom) []models.BookingRoom {
+	out := make([]models.BookingRoom, 0, len(rooms))
+	for _, br := range rooms {
+		if br.Status != BookingRoomStatusMoved {
+			out = append(out, br)
+		}
+	}
+	return out
+}
+
+// nightsBetween จำนวนคืนระหว่างวันที่ (เที่ยงคืน UTC) from → to
+func nightsBetween(from, to time.Time) int {
+	if !to.After(from) {
+		return 0
+	}
+	return int(to.Sub(from).Hours() / 24)
+}
@@ -19,35 +19,35 @@ import (
 
 // BookingService เป็น wrapper รอบ *gorm.DB เพื่อแยก logic ของ booking
 type BookingService struct {
-	DB *gorm.DB
+	DB       *gorm.DB
+	Notifier *NotificationService
+	Sessions *CheckInSessionService
+	Drafts   *CheckInDraftService
+
+	// เทียบ selfie กับรูปเอกสารหลังเช็คอิน (nil = ปิด)
+	FaceMatch *FaceMatchService
 }
 
 func NewBookingService(db *gorm.DB) *BookingService {
-	return &BookingService{DB: db}
+	notifier := NewNotificationService(db)
+	s := &BookingService{DB: db, Notifier: notifier, Sessions: NewCheckInSessionService(db, notifier)}
+	s.Drafts = NewCheckInDraftService(db, s)
+	s.FaceMatch = NewFaceMatchService(db, NewVerificationCaseService(db, s))
+	return s
 }
 
-// ✅ helper ดึง string จาก map (รองรับหลายชื่อ key)
-func getStringFromMap(m map[string]interface{}, keys ...string) string {
-	for _, k := range keys {
-		if v, ok := m[k]; ok && v != nil {
-			if s, ok2 := v.(string); ok2 {
-				return strings.TrimSpace(s)
-			}
-			return strings.TrimSpace(fmt.Sprintf("%v", v))
-		}
-	}
-	return ""
+// AccompanyingGuest ผู้เข้าพักร่วมที่ staff กรอกตอนสร้าง booking (เก็บเป็น draft ใน bookings.accompanying_guests)
+type AccompanyingGuest struct {
+	FullName string `json:"fullName"`
+	Type     string `json:"type"` // Adult | Child
 }
 
 // ✅ helper normalize guest list -> keep only safe fields (optional)
-func normalizeGuestList(guestList []map[string]interface{}) []map[string]interface{} {
-	if len(guestList) == 0 {
-		return []map[string]interface{}{}
-	}
-	out := make([]map[string]interface{}, 0, len(guestList))
+func normalizeGuestList(guestList []AccompanyingGuest) []AccompanyingGuest {
+	out := make([]AccompanyingGuest, 0, len(guestList))
 	for _, g := range guestList {
-		name := getStringFromMap(g, "name", "fullName", "full_name")
-		typ := getStringFromMap(g, "type", "guestType", "guest_type")
+		name := strings.TrimSpace(g.FullName)
+		typ := strings.TrimSpace(g.Type)
 
 		if name == "" {
 			continue
@@ -56,119 +56,21 @@ func normalizeGuestList(guestList []map[string]interface{}) []map[string]interfa
 			typ = "Adult"
 		}
 
-		out = append(out, map[string]interface{}{
-			"fullName": name,
-			"type":     typ,
-		})
+		out = append(out, AccompanyingGuest{FullName: name, Type: typ})
 	}
 	return out
 }
 
-// InitiateCheckInProcess: สร้าง BookingInfo (token + checkin code) และส่งอีเมลเชิญเช็คอิน
+// InitiateCheckInProcess: สร้าง BookingInfo (token + checkin code) และส่งลิงก์เชิญเช็คอิน (ผ่าน CheckInSessionService)
 func (s *BookingService) InitiateCheckInProcess(bookingID uint) (models.BookingInfo, error) {
-	var booking models.Booking
-	if err := s.DB.Preload("Rooms.Room.RoomType").Preload("Customer").First(&booking, bookingID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return models.BookingInfo{}, errors.New("booking_not_found")
-		}
-		return models.BookingInfo{}, fmt.Errorf("failed to find booking: %w", err)
-	}
-
-	// validations
-	if booking.Customer.ID == 0 {
-		return models.BookingInfo{}, errors.New("missing customer")
-	}
-	if len(booking.Rooms) == 0 && booking.Room.ID == 0 {
-		return models.BookingInfo{}, errors.New("missing room")
-	}
-	if strings.TrimSpace(booking.Customer.Email) == "" {
-		return models.BookingInfo{}, errors.New("customer_email_missing")
-	}
-	if strings.EqualFold(booking.Status, "Checked-In") ||
-		strings.EqualFold(booking.Status, "Checkedin") ||
-		strings.EqualFold(booking.Status, "Checked in") {
-		return models.BookingInfo{}, errors.New("already_checked_in")
-	}
-	if booking.CheckedInAt != nil {
-		return models.BookingInfo{}, errors.New("already_checked_in")
-	}
-	if strings.EqualFold(strings.TrimSpace(booking.Status), "Checked-Out") {
-		return models.BookingInfo{}, errors.New("booking_checked_out")
-	}
-
-	// check existing non-expired booking_info
-	var existing models.BookingInfo
-	now := time.Now().UTC()
-	err := s.DB.
-		Where("(expires_at IS NULL OR expires_at > ?) AND booking_id = ? AND deleted_at IS NULL", now, bookingID).
-		Order("id DESC").
-		First(&existing).Error
-	if err == nil {
-		return models.BookingInfo{}, errors.New("checkin_already_initiated")
-	}
-	if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return models.BookingInfo{}, fmt.Errorf("failed to check existing booking info: %w", err)
-	}
-
-	// create booking_info with retries on unique collision
-	var bookingInfo models.BookingInfo
-	maxRetries := 5
-	var createErr error
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		token, gErr := utils.GenerateSecureToken(32)
-		if gErr != nil {
-			return models.BookingInfo{}, fmt.Errorf("failed to generate token: %w", gErr)
-		}
-		rawCode, cErr := utils.GenerateCheckinCode(8)
-		if cErr != nil {
-			return models.BookingInfo{}, fmt.Errorf("failed to generate checkin code: %w", cErr)
-		}
-		formatted, fErr := utils.GenerateFormattedCheckinCode(rawCode)
-		if fErr != nil {
-			return models.BookingInfo{}, fmt.Errorf("failed to format checkin code: %w", fErr)
-		}
-
-		expiresAt := time.Now().UTC().Add(24 * time.Hour)
-		var codeExp *time.Time
-		if strings.ToLower(utils.EnvOrDefault("CHECKIN_CODE_NEVER_EXPIRE", "false")) == "true" {
-			codeExp = nil
-		} else {
-			t := time.Now().UTC().Add(7 * 24 * time.Hour)
-			codeExp = &t
-		}
-
-		bookingInfo = models.BookingInfo{
-			BookingID:     bookingID,
-			Token:         token,
-			CheckinCode:   formatted,
-			Status:        "INITIATED",
-			EmailStatus:   "PENDING",
-			ExpiresAt:     &expiresAt,
-			CodeExpiresAt: codeExp,
-			GuestEmail:    booking.Customer.Email,
-			GuestLastName: booking.Customer.FullName,
-		}
-
-		createErr = s.DB.Create(&bookingInfo).Error
-		if createErr == nil {
-			break
-		}
-
-		lc := strings.ToLower(createErr.Error())
-		if strings.Contains(lc, "duplicate") || strings.Contains(lc, "unique") || strings.Contains(lc, "constraint") {
-			log.Printf("create booking_info collision (attempt %d) - retrying", attempt+1)
-			continue
-		}
-		return models.BookingInfo{}, fmt.Errorf("failed to create booking info: %w", createErr)
-	}
-	if createErr != nil {
-		return models.BookingInfo{}, fmt.Errorf("failed to create booking info after retries: %w", createErr)
-	}
+	return s.Sessions.Initiate(bookingID)
+}
 
-	// build rooms list for email (best-effort)
+// checkInInviteData: ประกอบข้อมูลสำหรับ template check-in invite จาก booking ที่ preload Rooms.Room.RoomType + Customer แล้ว
+func checkInInviteData(db *gorm.DB, booking models.Booking, bookingInfo models.BookingInfo) utils.CheckInEmailData {
 	roomsForEmail := []utils.RoomInfo{}
 	if len(booking.Rooms) > 0 {
-		for _, br := range booking.Rooms {
+		for _, br := range CurrentBookingRooms(booking.Rooms) {
 			num := ""
 			typ := ""
 			if br.Room.ID != 0 {
@@ -200,36 +102,45 @@ func (s *BookingService) InitiateCheckInProcess(bookingID uint) (models.BookingI
 	frontend := utils.EnvOrDefault("FRONTEND_URL", "http://localhost:3000")
 	checkinLink := fmt.Sprintf("%s/checkin?token=%s", strings.TrimRight(frontend, "/"), bookingInfo.Token)
 
-	// send email (best-effort) and update email status
-	if mailErr := utils.SendCheckInLinkEmail(
-		booking.Customer.Email,
-		booking.ReferenceCode,
-		checkinLink,
-		booking.Customer.FullName,
-		roomsForEmail,
-		func() string {
-			if booking.CheckIn != nil {
-				return booking.CheckIn.Format("2006-01-02")
+	return utils.CheckInEmailData{
+		Hotel:            LoadHotelBrand(db),
+		GuestName:        booking.Customer.FullName,
+		BookingRef:       booking.ReferenceCode,
+		CheckinLink:      checkinLink,
+		ConfirmationCode: bookingInfo.CheckinCode,
+		CheckInDate:      formatDatePtr(booking.CheckIn),
+		CheckOutDate:     formatDatePtr(booking.CheckOut),
+		Rooms:            roomsForEmail,
+	}
+}
+
+// hasContactFor: customer มีปลายทางสำหรับอย่างน้อยหนึ่งช่องทางที่เลือกหรือไม่
+func hasContactFor(cust models.Customer, channels []string) bool {
+	for _, ch := range channels {
+		switch ch {
+		case ChannelEmail:
+			if strings.TrimSpace(cust.Email) != "" {
+				return true
+			}
+		case ChannelSMS:
+			if strings.TrimSpace(cust.Phone) != "" {
+				return true
 			}
-			return "N/A"
-		}(),
-		func() string {
-			if booking.CheckOut != nil {
-				return booking.CheckOut.Format("2006-01-02")
+		case ChannelLINE:
+			if strings.TrimSpace(cust.LineUserID) != "" {
+				return true
 			}
-			return "N/A"
-		}(),
-		bookingInfo.CheckinCode,
-	); mailErr != nil {
-		_ = s.DB.Model(&bookingInfo).Where("id = ?", bookingInfo.ID).
-			Updates(map[string]interface{}{"email_status": "FAILED", "email_error": mailErr.Error()}).Error
-		return bookingInfo, fmt.Errorf("email_send_failed: %w", mailErr)
+		}
 	}
+	return false
+}
 
-	_ = s.DB.Model(&bookingInfo).Where("id = ?", bookingInfo.ID).
-		Updates(map[string]interface{}{"email_status": "SENT"}).Error
-
-	return bookingInfo, nil
+// formatDatePtr: "2006-01-02" หรือ "N/A"
+func formatDatePtr(t *time.Time) string {
+	if t == nil {
+		return "N/A"
+	}
+	return t.Format("2006-01-02")
 }
 
 // ValidateCheckinCodeByBooking: ตรวจสอบ code + query (name or reference code) และยังไม่หมดอายุ
@@ -239,13 +150,13 @@ func (s *BookingService) ValidateCheckinCodeByBooking(code string, query string)
 
 	norm := utils.NormalizeCheckinCode(code)
 	if len(norm) != 8 {
-		return bi, errors.New("invalid_or_expired_code")
+		return bi, ErrInvalidOrExpiredCode
 	}
 	formatted := strings.ToUpper(norm[:4] + "-" + norm[4:])
 
 	qLower := strings.ToLower(strings.TrimSpace(query))
 	if qLower == "" {
-		return bi, errors.New("invalid_query")
+		return bi, ErrCheckinQueryRequired
 	}
 
 	err := s.DB.
@@ -269,7 +180,7 @@ func (s *BookingService) ValidateCheckinCodeByBooking(code string, query string)
 			if err2 == nil {
 				log.Printf("ValidateCheckinCodeByBooking: code exists but expired (booking_info_id=%d)", biAny.ID)
 			}
-			return bi, errors.New("invalid_or_expired_code")
+			return bi, ErrInvalidOrExpiredCode
 		}
 		return bi, fmt.Errorf("failed to validate code: %w", err)
 	}
@@ -285,8 +196,9 @@ func (s *BookingService) FinalizeCheckInTransaction(
 ) error {
 
 	now := time.Now().UTC()
+	var checkedIn models.BookingInfo
 
-	return s.DB.Transaction(func(tx *gorm.DB) error {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
 
 		var bookingInfo models.BookingInfo
 		if err := tx.
@@ -294,7 +206,7 @@ func (s *BookingService) FinalizeCheckInTransaction(
 			First(&bookingInfo).Error; err != nil {
 
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return errors.New("invalid_or_expired_token")
+				return ErrInvalidOrExpiredToken
 			}
 			return err
 		}
@@ -363,8 +275,15 @@ func (s *BookingService) FinalizeCheckInTransaction(
 			return err
 		}
 
+		checkedIn = bookingInfo
 		return nil
 	})
+
+	// face match ทำหลัง commit (เรียก provider ภายนอก ไม่ให้ถือ transaction ไว้)
+	if err == nil && checkedIn.ID != 0 && s.FaceMatch != nil {
+		go s.FaceMatch.CheckBooking(checkedIn.BookingID, checkedIn.ID)
+	}
+	return err
 }
 
 // CreateBooking: สร้าง booking แบบ single-room helper
@@ -403,26 +322,44 @@ func (s *BookingService) GetBookingDetails(bookingID uint) (*models.Booking, err
 	var bk models.Booking
 	if err := s.DB.Preload("Rooms.Room.RoomType").Preload("Customer").First(&bk, bookingID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("booking_not_found")
+			return nil, ErrBookingNotFound
 		}
 		return nil, fmt.Errorf("failed to retrieve booking details: %w", err)
 	}
 	return &bk, nil
 }
 
-// GetAllWithRelations
-func (s *BookingService) GetAllWithRelations() ([]models.Booking, error) {
-	var list []models.Booking
-
-	if err := s.DB.
-		Preload("Customer").
-		Preload("Rooms").
-		Preload("Rooms.Room").
-		Preload("Rooms.Room.RoomType").
-		Order("created_at DESC").
-		Find(&list).Error; err != nil {
+// BookingListSpec: GET /api/bookings
+var BookingListSpec = ListSpec{
+	IDColumn: "bookings.id",
+	Sorts: map[string]string{
+		"id":            "bookings.id",
+		"createdAt":     "bookings.created_at",
+		"checkIn":       "bookings.check_in",
+		"checkOut":      "bookings.check_out",
+		"status":        "bookings.status",
+		"referenceCode": "bookings.reference_code",
+	},
+	DefaultSort: "-createdAt",
+	DateColumn:  "bookings.check_in",
+	Filters: map[string]ListFilter{
+		"status":     {Cond: "bookings.status IN ?", Kind: FilterList},
+		"customerId": {Cond: "bookings.customer_id = ?", Kind: FilterID},
+		"roomId": {
+			Cond: "(bookings.room_id = @v OR bookings.id IN (SELECT booking_id FROM booking_rooms WHERE room_id = @v AND deleted_at IS NULL))",
+			Kind: FilterID,
+		},
+		"nationality": {Cond: "bookings.id IN (SELECT booking_id FROM guests WHERE nationality = ?)", Kind: FilterText},
+	},
+}
 
-		return nil, fmt.Errorf("failed to retrieve bookings: %w", err)
+// List หนึ่งหน้าของ booking พร้อม customer/rooms
+func (s *BookingService) List(q ListQuery) ([]models.Booking, PageInfo, error) {
+	list := []models.Booking{}
+	page, err := FindPage(s.DB, BookingListSpec, q, &list,
+		"Customer", "Rooms", "Rooms.Room", "Rooms.Room.RoomType")
+	if err != nil {
+		return nil, page, fmt.Errorf("failed to retrieve bookings: %w", err)
 	}
 
 	for i := range list {
@@ -431,7 +368,7 @@ func (s *BookingService) GetAllWithRelations() ([]models.Booking, error) {
 		}
 	}
 
-	return list, nil
+	return list, page, nil
 }
 
 // DeleteByStringID
@@ -452,14 +389,15 @@ func (s *BookingService) CreateBookingMultiple(
 	roomIDs []uint,
 	adults int,
 	children int,
-	guestList []map[string]interface{},
+	guestList []AccompanyingGuest,
+	notificationChannels []string,
 	sendEmail bool,
 ) (models.Booking, error) {
 
 	var resultBooking models.Booking
 
 	if len(roomIDs) == 0 {
-		return resultBooking, fmt.Errorf("validation: no room ids provided")
+		return resultBooking, ErrInvalidBooking.WithDetails("no room ids provided")
 	}
 
 	if adults <= 0 {
@@ -477,23 +415,25 @@ func (s *BookingService) CreateBookingMultiple(
 	var cust models.Customer
 	if err := s.DB.First(&cust, customerID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return resultBooking, fmt.Errorf("validation: customer not found")
+			return resultBooking, ErrInvalidBooking.WithDetails("customer not found")
 		}
 		return resultBooking, fmt.Errorf("db error checking customer: %w", err)
 	}
 
 	// validate rooms exist
+	rooms := make([]models.Room, 0, len(roomIDs))
 	for _, rid := range roomIDs {
 		if rid == 0 {
-			return resultBooking, fmt.Errorf("validation: invalid room id 0 in roomIDs")
+			return resultBooking, ErrInvalidBooking.WithDetails("invalid room id 0 in roomIDs")
 		}
 		var rm models.Room
 		if err := s.DB.First(&rm, rid).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return resultBooking, fmt.Errorf("validation: room %d not found", rid)
+				return resultBooking, ErrInvalidBooking.WithDetails(fmt.Sprintf("room %d not found", rid))
 			}
 			return resultBooking, fmt.Errorf("db error checking room %d: %w", rid, err)
 		}
+		rooms = append(rooms, rm)
 	}
 
 	// parse dates (best-effort)
@@ -505,7 +445,7 @@ func (s *BookingService) CreateBookingMultiple(
 		} else if t2, err2 := time.Parse(time.RFC3339, checkIn); err2 == nil {
 			checkInDate = &t2
 		} else {
-			return resultBooking, fmt.Errorf("validation: invalid check_in format: %v", err)
+			return resultBooking, ErrInvalidBooking.WithDetails("invalid check_in format")
 		}
 	}
 	if checkOut != "" {
@@ -514,14 +454,26 @@ func (s *BookingService) CreateBookingMultiple(
 		} else if t2, err2 := time.Parse(time.RFC3339, checkOut); err2 == nil {
 			checkOutDate = &t2
 		} else {
-			return resultBooking, fmt.Errorf("validation: invalid check_out format: %v", err)
+			return resultBooking, ErrInvalidBooking.WithDetails("invalid check_out format")
+		}
+	}
+
+	// ห้องที่ปิดซ่อม / ปิดใช้งานอยู่ในวันเข้าพักจองไม่ได้
+	if checkInDate != nil {
+		for _, rm := range rooms {
+			if roomOutOfServiceOn(rm, *checkInDate) {
+				return resultBooking, ErrRoomOutOfService.WithDetails(map[string]interface{}{
+					"roomId":        rm.ID,
+					"serviceStatus": rm.ServiceStatus,
+					"serviceUntil":  rm.ServiceUntil,
+				})
+			}
 		}
 	}
 
 	var bookingID uint
-	var bookingRef string
 
-	// transaction create booking + booking_room + update room status
+	// transaction create booking + booking_room
 	txErr := s.DB.Transaction(func(tx *gorm.DB) error {
 		var ciDate *time.Time
 		var coDate *time.Time
@@ -536,6 +488,31 @@ func (s *BookingService) CreateBookingMultiple(
 			coDate = &t
 		}
 
+		// lock แถวห้องที่จองไว้จนจบ transaction (MoveRoom ตรวจห้องว่างภายใต้ lock เดียวกัน)
+		if len(roomIDs) > 0 {
+			var locked []models.Room
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id IN ?", roomIDs).Find(&locked).Error; err != nil {
+				return fmt.Errorf("failed to lock rooms: %w", err)
+			}
+		}
+
+		// ห้องที่ถูก block (ปฏิทินภายนอกเช่น Airbnb หรือปิดซ่อม) จองทับไม่ได้
+		if ciDate != nil && coDate != nil {
+			block, err := FirstRoomBlock(tx, roomIDs, *ciDate, *coDate)
+			if err != nil {
+				return fmt.Errorf("failed to check room blocks: %w", err)
+			}
+			if block != nil {
+				return ErrRoomBlocked.WithDetails(map[string]interface{}{
+					"roomId":    block.RoomID,
+					"kind":      block.Kind,
+					"startDate": block.StartDate.Format("2006-01-02"),
+					"endDate":   block.EndDate.Format("2006-01-02"),
+					"summary":   block.Summary,
+				})
+			}
+		}
+
 		booking := models.Booking{
 			CustomerID:   uint(customerID),
 			CheckIn:      checkInDate,
@@ -550,6 +527,8 @@ func (s *BookingService) CreateBookingMultiple(
 
 			// ✅ ต้องมี field นี้ใน models.Booking ด้วย
 			AccompanyingGuests: datatypes.JSON(accompanyingJSON),
+
+			NotificationChannels: strings.Join(ParseChannels(strings.Join(notificationChannels, ",")), ","),
 		}
 
 		if err := tx.Create(&booking).Error; err != nil {
@@ -557,7 +536,6 @@ func (s *BookingService) CreateBookingMultiple(
 		}
 
 		bookingID = booking.ID
-		bookingRef = booking.ReferenceCode
 
 		nights := 0
 		if checkInDate != nil && checkOutDate != nil && checkOutDate.After(*checkInDate) {
@@ -578,12 +556,6 @@ func (s *BookingService) CreateBookingMultiple(
 			if err := tx.Create(&br).Error; err != nil {
 				return fmt.Errorf("failed to create booking_room for room %d: %w", rid, err)
 			}
-
-			if err := tx.Model(&models.Room{}).
-				Where("id = ?", rid).
-				Updates(map[string]interface{}{"status": "Reserved"}).Error; err != nil {
-				return fmt.Errorf("failed to update room %d status: %w", rid, err)
-			}
 		}
 
 		// ❌ ไม่สร้าง records ใน guests ที่นี่แล้ว
@@ -595,76 +567,6 @@ func (s *BookingService) CreateBookingMultiple(
 		return resultBooking, txErr
 	}
 
-	// optional: send checkin link email for newly created booking
-	if sendEmail {
-		token, genErr := utils.GenerateSecureToken(32)
-		if genErr != nil {
-			return resultBooking, fmt.Errorf("email_send_failed: failed to generate token: %w", genErr)
-		}
-		raw, gErr := utils.GenerateCheckinCode(8)
-		if gErr != nil {
-			return resultBooking, fmt.Errorf("email_send_failed: failed to generate code: %w", gErr)
-		}
-		formatted, fErr := utils.GenerateFormattedCheckinCode(raw)
-		if fErr != nil {
-			return resultBooking, fmt.Errorf("email_send_failed: failed to format code: %w", fErr)
-		}
-
-		expiresAt := time.Now().UTC().Add(24 * time.Hour)
-		codeExpires := time.Now().UTC().Add(7 * 24 * time.Hour)
-
-		bookingInfo := models.BookingInfo{
-			BookingID:     bookingID,
-			Token:         token,
-			CheckinCode:   formatted,
-			Status:        "INITIATED",
-			EmailStatus:   "PENDING",
-			ExpiresAt:     &expiresAt,
-			CodeExpiresAt: &codeExpires,
-			GuestEmail:    cust.Email,
-			GuestLastName: cust.FullName,
-		}
-
-		if err := s.DB.Create(&bookingInfo).Error; err != nil {
-			return resultBooking, fmt.Errorf("email_send_failed: failed to create booking_info: %w", err)
-		}
-
-		frontend := utils.EnvOrDefault("FRONTEND_URL", "http://localhost:3000")
-		checkinLink := fmt.Sprintf("%s/checkin?token=%s", strings.TrimRight(frontend, "/"), bookingInfo.Token)
-
-		// load room models for email content
-		var roomModels []models.Room
-		if err := s.DB.Where("id IN ?", roomIDs).Find(&roomModels).Error; err != nil {
-			log.Printf("warning: failed to load room models for email: %v", err)
-		}
-		roomsForEmail := make([]utils.RoomInfo, 0, len(roomModels))
-		for _, rm := range roomModels {
-			num := strings.TrimSpace(rm.RoomCode)
-			if num == "" {
-				num = strings.TrimSpace(rm.RoomNumber)
-			}
-			roomsForEmail = append(roomsForEmail, utils.RoomInfo{Number: num, Type: strings.TrimSpace(rm.Type)})
-		}
-
-		if mailErr := utils.SendCheckInLinkEmail(
-			cust.Email,
-			bookingRef,
-			// ถ้าต้องการให้ไม่ว่าง ต้องแน่ใจว่า booking มี reference_code ถูก generate แล้ว
-			checkinLink,
-			cust.FullName,
-			roomsForEmail,
-			checkIn,
-			checkOut,
-			bookingInfo.CheckinCode,
-		); mailErr != nil {
-			_ = s.DB.Model(&bookingInfo).Where("id = ?", bookingInfo.ID).
-				Updates(map[string]interface{}{"email_status": "FAILED", "email_error": mailErr.Error()}).Error
-			return resultBooking, fmt.Errorf("email_send_failed: %w", mailErr)
-		}
-		_ = s.DB.Model(&bookingInfo).Where("id = ?", bookingInfo.ID).
-			Updates(map[string]interface{}{"email_status": "SENT"}).Error
-	}
-
 	// reload booking with relations (สำคัญมาก)
 	if err := s.DB.
 		Preload("Customer").
@@ -679,6 +581,13 @@ func (s *BookingService) CreateBookingMultiple(
 		resultBooking.Rooms = []models.BookingRoom{}
 	}
 
+	// optional: send checkin link via selected channels for newly created booking
+	if sendEmail {
+		if _, err := s.Sessions.Initiate(resultBooking.ID); err != nil {
+			return resultBooking, err
+		}
+	}
+
 	return resultBooking, nil
 }
 
@@ -689,13 +598,13 @@ func (s *BookingService) CheckoutBooking(bookingID uint) error {
 		var booking models.Booking
 		if err := tx.Preload("Rooms").First(&booking, bookingID).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return fmt.Errorf("booking_not_found")
+				return ErrBookingNotFound
 			}
 			return err
 		}
 
 		if booking.Status != "Checked-In" {
-			return fmt.Errorf("not_checked_in")
+			return ErrNotCheckedIn
 		}
 
 		now := time.Now().UTC()
@@ -717,14 +626,31 @@ func (s *BookingService) CheckoutBooking(bookingID uint) error {
 			return err
 		}
 
+		roomIDs := make([]uint, 0, len(booking.Rooms)+1)
 		for _, br := range booking.Rooms {
-			if err := tx.Model(&models.Room{}).
-				Where("id = ?", br.RoomID).
-				Updates(map[string]interface{}{"status": "Available"}).Error; err != nil {
-				return err
+			if br.Status != BookingRoomStatusMoved { // ห้องที่ย้ายออกไปแล้วทำความสะอาดไปตั้งแต่ตอนย้าย
+				roomIDs = append(roomIDs, br.RoomID)
 			}
 		}
+		if booking.RoomID != nil && len(booking.Rooms) == 0 {
+			roomIDs = append(roomIDs, *booking.RoomID)
+		}
 
-		return nil
+		// ห้องที่แขกออกแล้วต้องทำความสะอาดก่อนขายต่อ
+		return markRoomsDirty(tx, roomIDs, booking.ID, RoomStatusSourceCheckout)
 	})
 }
+
+// UpdateNotificationChannels: ตั้งค่าช่องทางส่งลิงก์เช็คอินของ booking (คืนค่าที่ normalize แล้ว)
+func (s *BookingService) UpdateNotificationChannels(bookingID uint, channels []string) ([]string, error) {
+	var booking models.Booking
+	if err := s.DB.Select("id").First(&booking, bookingID).Error; err != nil {
+		return nil, err
+	}
+	normalized := ParseChannels(strings.Join(channels, ","))
+	if err := s.DB.Model(&models.Booking{}).Where("id = ?", bookingID).
+		Update("notification_channels", strings.Join(normalized, ",")).Error; err != nil {
+		return nil, fmt.Errorf("failed to update notification channels: %w", err)
+	}
+	return normalized, nil
+}
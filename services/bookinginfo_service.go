@@ -2,23 +2,27 @@ package services
 
 import (
 	"errors"
+	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
 	"hotel-backend/models"
-	"hotel-backend/utils"
 
 	"gorm.io/gorm"
 )
 
 // BookingInfoService handles BookingInfo persistence and logic.
 type BookingInfoService struct {
-	DB *gorm.DB
+	DB       *gorm.DB
+	Notifier *NotificationService
+	Sessions *CheckInSessionService
 }
 
 // NewBookingInfoService constructor
 func NewBookingInfoService(db *gorm.DB) *BookingInfoService {
-	return &BookingInfoService{DB: db}
+	notifier := NewNotificationService(db)
+	return &BookingInfoService{DB: db, Notifier: notifier, Sessions: NewCheckInSessionService(db, notifier)}
 }
 
 // SaveBookingInfo saves or updates a BookingInfo
@@ -93,13 +97,112 @@ func (s *BookingInfoService) FindByCodeWithExpiry(code string, codeNoDash string
 	return models.BookingInfo{}, false, err2
 }
 
+// MatchesQuery: query ต้องตรงกับหมายเลขการจองทั้งหมด หรือเป็นนามสกุล/ชื่อของแขกแบบทั้งคำ (ไม่สนตัวพิมพ์)
+// ไม่นับ substring — "a" ต้องไม่ผ่านกับ "Anna Brown"
+func (s *BookingInfoService) MatchesQuery(bi models.BookingInfo, query string) bool {
+	if strings.TrimSpace(query) == "" {
+		return false
+	}
+	if nameMatches(bi.GuestLastName, query) {
+		return true
+	}
+
+	var booking models.Booking
+	if err := s.DB.Preload("Customer").First(&booking, bi.BookingID).Error; err != nil {
+		return false
+	}
+	return bookingQueryMatches(booking.ReferenceCode, booking.Customer.FullName, query)
+}
+
+// bookingQueryMatches หมายเลขการจองตรงทั้งหมด หรือ query เป็นคำเต็มในชื่อลูกค้า
+func bookingQueryMatches(reference, fullName, query string) bool {
+	q := strings.TrimSpace(query)
+	if q == "" {
+		return false
+	}
+	if ref := strings.TrimSpace(reference); ref != "" && strings.EqualFold(ref, q) {
+		return true
+	}
+	return nameMatches(fullName, q)
+}
+
+// FindByTokenWithExpiry: หา BookingInfo จาก token, expired = true ถ้า expires_at ผ่านไปแล้ว
+func (s *BookingInfoService) FindByTokenWithExpiry(token string) (models.BookingInfo, bool, error) {
+	var bi models.BookingInfo
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return bi, false, gorm.ErrRecordNotFound
+	}
+	if err := s.DB.Where("token = ?", token).First(&bi).Error; err != nil {
+		return models.BookingInfo{}, false, err
+	}
+	expired := bi.ExpiresAt != nil && !bi.ExpiresAt.After(time.Now().UTC())
+	return bi, expired, nil
+}
+
+var tokenPattern = regexp.MustCompile(`^[a-fA-F0-9]{32,128}$`)
+
+// ErrInvalidScanPayload: payload ที่สแกนได้ไม่ใช่ลิงก์เช็คอิน / token
+var ErrInvalidScanPayload = NewError(KindInvalid, "error.invalidScanPayload")
+
+// ResolveScanPayload แปลงข้อความจาก QR (ลิงก์เช็คอิน หรือ token ตรงๆ) เป็น BookingInfo
+// ไม่รับ check-in code 8 ตัว — code สั้นเดาได้ ต้องผ่าน /validate ที่ตรวจชื่อ/หมายเลขการจองด้วย
+func (s *BookingInfoService) ResolveScanPayload(payload string) (models.BookingInfo, bool, error) {
+	token, err := scanToken(payload)
+	if err != nil {
+		return models.BookingInfo{}, false, err
+	}
+	return s.FindByTokenWithExpiry(token)
+}
+
+// scanToken ดึง token จากข้อความที่สแกนได้
+//  1. ลิงก์: .../checkin?token=XXX หรือ .../checkin/XXX
+//  2. token ตรงๆ
+func scanToken(payload string) (string, error) {
+	payload = strings.TrimSpace(payload)
+	if payload == "" {
+		return "", ErrInvalidScanPayload
+	}
+
+	if u, err := url.Parse(payload); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		token := u.Query().Get("token")
+		if token == "" {
+			if i := strings.LastIndex(strings.TrimRight(u.Path, "/"), "/checkin/"); i >= 0 {
+				token = strings.Trim(u.Path[i+len("/checkin/"):], "/")
+			}
+		}
+		if !tokenPattern.MatchString(token) {
+			return "", ErrInvalidScanPayload
+		}
+		return token, nil
+	}
+
+	if tokenPattern.MatchString(payload) {
+		return payload, nil
+	}
+	return "", ErrInvalidScanPayload
+}
+
+// SessionOpen: session ยังใช้ได้ (ยังไม่เสร็จ / ไม่ถูกยกเลิก / token ยังไม่หมดอายุ)
+func (s *BookingInfoService) SessionOpen(bi models.BookingInfo) bool {
+	switch strings.ToUpper(strings.TrimSpace(bi.Status)) {
+	case "EXPIRED", "COMPLETED", "REVOKED":
+		return false
+	}
+	return bi.ExpiresAt == nil || bi.ExpiresAt.After(time.Now().UTC())
+}
+
 // ExtendExpiry extends CodeExpiresAt by minutes and returns new expiry time.
+// code ไม่มีวันอยู่นานกว่า token ของ session (ExpiresAt)
 func (s *BookingInfoService) ExtendExpiry(bookingInfoId uint, minutes int) (*time.Time, error) {
 	var bi models.BookingInfo
 	if err := s.DB.First(&bi, bookingInfoId).Error; err != nil {
 		return nil, err
 	}
 	newExpiry := time.Now().UTC().Add(time.Duration(minutes) * time.Minute)
+	if bi.ExpiresAt != nil && newExpiry.After(*bi.ExpiresAt) {
+		newExpiry = bi.ExpiresAt.UTC()
+	}
 	bi.CodeExpiresAt = &newExpiry
 	if err := s.DB.Save(&bi).Error; err != nil {
 		return nil, err
@@ -107,85 +210,24 @@ func (s *BookingInfoService) ExtendExpiry(bookingInfoId uint, minutes int) (*tim
 	return &newExpiry, nil
 }
 
-// InitiateCheckIn creates a BookingInfo record and sends a check-in email.
-// Enhanced: if an active BookingInfo already exists, return it with error "checkin_already_initiated".
+// InitiateCheckIn creates a BookingInfo record and sends the check-in link (ผ่าน CheckInSessionService).
+// If an active BookingInfo already exists, it is returned with ErrCheckinAlreadyInitiated.
 func (s *BookingInfoService) InitiateCheckIn(bookingID uint) (models.BookingInfo, error) {
+	return s.Sessions.Initiate(bookingID)
+}
+
+// ResendCheckInInvite ส่งลิงก์เช็คอินของ bookingInfo เดิมซ้ำตามช่องทางของ booking
+func (s *BookingInfoService) ResendCheckInInvite(bi models.BookingInfo) ([]DeliveryResult, error) {
 	var booking models.Booking
-	if err := s.DB.Preload("Customer").First(&booking, bookingID).Error; err != nil {
+	if err := s.DB.Preload("Rooms.Room.RoomType").Preload("Room.RoomType").Preload("Customer").
+		First(&booking, bi.BookingID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return models.BookingInfo{}, errors.New("booking_not_found")
+			return nil, ErrBookingNotFound
 		}
-		return models.BookingInfo{}, err
-	}
-
-	// If booking already checked in
-	if strings.EqualFold(booking.Status, "Checked-In") || strings.EqualFold(booking.Status, "CHECKED-IN") {
-		return models.BookingInfo{}, errors.New("already_checked_in")
-	}
-
-	// Look for existing active BookingInfo (not deleted and not expired)
-	var existing models.BookingInfo
-	now := time.Now().UTC()
-	err := s.DB.
-		Where("booking_id = ? AND deleted_at IS NULL", bookingID).
-		Where("(code_expires_at IS NULL OR code_expires_at > ?) OR (expires_at IS NULL OR expires_at > ?)", now, now).
-		Order("id desc").
-		First(&existing).Error
-	if err == nil {
-		// found existing active session
-		return existing, errors.New("checkin_already_initiated")
-	}
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return models.BookingInfo{}, err
-	}
-
-	// create new BookingInfo
-	token, err := utils.GenerateSecureToken(32)
-	if err != nil {
-		return models.BookingInfo{}, err
+		return nil, err
 	}
-	checkinCode, err := utils.GenerateCheckinCode(8)
-	if err != nil {
-		return models.BookingInfo{}, err
-	}
-
-	bookingInfo := models.BookingInfo{
-		BookingID:     bookingID,
-		Token:         token,
-		CheckinCode:   checkinCode,
-		Status:        "INITIATED",
-		GuestEmail:    booking.Customer.Email,
-		GuestLastName: booking.Customer.FullName,
-	}
-
-	if err := s.DB.Create(&bookingInfo).Error; err != nil {
-		return models.BookingInfo{}, err
-	}
-
-	// Build checkin link and send email (best-effort)
-	frontendURL := utils.EnvOrDefault("FRONTEND_URL", "http://localhost:3000")
-	bookingRef := booking.ReferenceCode
-	guestEmail := booking.Customer.Email
-	guestLastName := booking.Customer.FullName
-	checkInStr := ""
-	checkOutStr := ""
-
-	err = utils.SendCheckInLinkEmail(
-		guestEmail,
-		bookingRef,
-		utils.BuildCheckinLink(frontendURL, token, true),
-		guestLastName,
-		[]utils.RoomInfo{},
-		checkInStr,
-		checkOutStr,
-		bookingInfo.CheckinCode,
-	)
-	if err != nil {
-		_ = s.DB.Model(&bookingInfo).Update("email_status", "FAILED").Error
-		// return partial success with bookingInfo and sentinel error
-		return bookingInfo, errors.New("email_send_failed")
+	if strings.TrimSpace(booking.Customer.FullName) == "" {
+		booking.Customer.FullName = bi.GuestLastName
 	}
-
-	_ = s.DB.Model(&bookingInfo).Update("email_status", "SENT").Error
-	return bookingInfo, nil
+	return s.Notifier.SendCheckInInvite(booking, bi, checkInInviteData(s.DB, booking, bi))
 }
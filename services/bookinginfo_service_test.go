@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"hotel-backend/utils"
+)
+
+func TestBookingQueryMatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		reference string
+		fullName  string
+		query     string
+		want      bool
+	}{
+		{"exact reference", "BK-2026-0001", "Anna Brown", "BK-2026-0001", true},
+		{"reference ignores case and spaces", "BK-2026-0001", "Anna Brown", "  bk-2026-0001 ", true},
+		{"partial reference", "BK-2026-0001", "Anna Brown", "BK-2026", false},
+		{"surname", "BK-1", "Anna Brown", "brown", true},
+		{"first name", "BK-1", "Anna Brown", "Anna", true},
+		{"full name", "BK-1", "Anna Brown", "anna brown", true},
+		{"name with punctuation", "BK-1", "Brown, Anna J.", "anna j", true},
+		{"multi-word surname", "BK-1", "Pieter van der Berg", "van der berg", true},
+		{"thai name", "BK-1", "สมชาย ใจดี", "ใจดี", true},
+		{"single letter", "BK-1", "Anna Brown", "a", false},
+		{"substring of a word", "BK-1", "Anna Brown", "row", false},
+		{"prefix of a word", "BK-1", "Anna Brown", "an", false},
+		{"words out of order", "BK-1", "Anna Maria Brown", "brown anna", false},
+		{"empty query", "BK-1", "Anna Brown", "   ", false},
+		{"empty reference never matches", "", "Anna Brown", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bookingQueryMatches(tt.reference, tt.fullName, tt.query); got != tt.want {
+				t.Fatalf("bookingQueryMatches(%q, %q, %q) = %v, want %v", tt.reference, tt.fullName, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanToken(t *testing.T) {
+	token := "0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+		wantErr bool
+	}{
+		{"bare token", token, token, false},
+		{"bare token with spaces", "  " + token + "\n", token, false},
+		{"link with token query", "https://hotel.example/checkin?token=" + token, token, false},
+		{"link from BuildCheckinLink (query)", utils.BuildCheckinLink("https://hotel.example", token, true), token, false},
+		{"link from BuildCheckinLink (path)", utils.BuildCheckinLink("https://hotel.example/", token, false), token, false},
+		{"link with token path", "https://hotel.example/checkin/" + token + "/", token, false},
+		{"link with short token", "https://hotel.example/checkin?token=abc", "", true},
+		{"link without token", "https://hotel.example/checkin", "", true},
+		{"non-http link", "ftp://hotel.example/checkin/" + token, "", true},
+		{"check-in code", "ABCD-EFGH", "", true},
+		{"check-in code without dash", "ABCDEFGH", "", true},
+		{"short hex", "abcdef12", "", true},
+		{"empty", "   ", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scanToken(tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scanToken(%q) error = %v, wantErr %v", tt.payload, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("scanToken(%q) = %q, want %q", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+// payload ที่ไม่ใช่ลิงก์ / token ต้องถูกปฏิเสธก่อนถึง DB
+func TestResolveScanPayloadRejectsCodes(t *testing.T) {
+	s := &BookingInfoService{}
+	for _, payload := range []string{"ABCD-EFGH", "abcd efgh", "https://hotel.example/checkin?code=ABCD-EFGH"} {
+		if _, _, err := s.ResolveScanPayload(payload); !errors.Is(err, ErrInvalidScanPayload) {
+			t.Errorf("ResolveScanPayload(%q) error = %v, want ErrInvalidScanPayload", payload, err)
+		}
+	}
+}
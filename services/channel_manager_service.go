@@ -0,0 +1,799 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Channel sync operations (ChannelSyncLog.Operation)
+const (
+	ChannelOpReservations = "reservations"
+	ChannelOpAvailability = "availability"
+	ChannelOpRates        = "rates"
+)
+
+// ChannelSyncOperations ลำดับการ sync ในแต่ละรอบ (ดึงการจองก่อน ห้องว่างที่ push จะได้ตรง)
+var ChannelSyncOperations = []string{ChannelOpReservations, ChannelOpAvailability, ChannelOpRates}
+
+// reservation ที่ดึงซ้ำช่วงนี้จากรอบก่อน (กันหลุดเพราะนาฬิกาสองฝั่งไม่ตรงกัน) ประมวลผลซ้ำได้ไม่มีผลเพิ่ม
+const channelPullOverlap = 5 * time.Minute
+
+var (
+	ErrChannelManagerOff       = NewError(KindConflict, "error.channelManagerDisabled")
+	ErrChannelSyncBusy         = NewError(KindConflict, "error.channelSyncBusy")
+	ErrUnknownChannelOperation = NewError(KindInvalid, "error.unknownChannelOperation").WithDetails(map[string]interface{}{"operations": ChannelSyncOperations})
+	ErrChannelMappingNotFound  = NewError(KindNotFound, "error.channelMappingNotFound")
+	ErrChannelMappingExists    = NewError(KindConflict, "error.channelMappingExists")
+	ErrRoomTypeNotFound        = NewError(KindNotFound, "error.roomTypeNotFound")
+)
+
+// ChannelManagerService sync ห้องว่าง / ราคาต่อ RoomType ไป channel manager และดึงการจองจาก OTA กลับมา
+type ChannelManagerService struct {
+	DB       *gorm.DB
+	Provider ChannelProvider // nil = ปิดใช้งาน
+	Days     int             // จำนวนวันข้างหน้าที่ push ห้องว่าง / ราคา
+	Currency string
+	Interval time.Duration
+
+	running sync.Mutex
+}
+
+// NewChannelManagerService อ่าน CHANNEL_SYNC_DAYS (default 180), CHANNEL_SYNC_INTERVAL_MINUTES (default 15),
+// CHANNEL_MANAGER_CURRENCY (default THB) และ provider จาก NewChannelProviderFromEnv
+func NewChannelManagerService(db *gorm.DB) *ChannelManagerService {
+	s := &ChannelManagerService{
+		DB:       db,
+		Provider: NewChannelProviderFromEnv(),
+		Days:     180,
+		Currency: strings.ToUpper(utils.EnvOrDefault("CHANNEL_MANAGER_CURRENCY", "THB")),
+		Interval: 15 * time.Minute,
+	}
+	if n, err := strconv.Atoi(utils.EnvOrDefault("CHANNEL_SYNC_DAYS", "180")); err == nil && n > 0 && n <= 730 {
+		s.Days = n
+	}
+	if n, err := strconv.Atoi(utils.EnvOrDefault("CHANNEL_SYNC_INTERVAL_MINUTES", "15")); err == nil && n > 0 {
+		s.Interval = time.Duration(n) * time.Minute
+	}
+	return s
+}
+
+// Enabled มี provider ให้ sync หรือไม่
+func (s *ChannelManagerService) Enabled() bool { return s != nil && s.Provider != nil }
+
+// ---------------- mappings ----------------
+
+// ChannelMappingInput ค่าที่แก้ได้ของ mapping (nil = ไม่เปลี่ยน)
+type ChannelMappingInput struct {
+	Channel    *string
+	RoomCode   *string
+	RoomTypeID *uint
+	Rate       *float64 // 0 = กลับไปใช้ราคาห้อง
+	Active     *bool
+}
+
+// ListMappings mapping ทั้งหมด (filter ตาม channel ได้)
+func (s *ChannelManagerService) ListMappings(channel string) ([]models.ChannelRoomMapping, error) {
+	q := s.DB.Preload("RoomType").Order("channel, room_code")
+	if channel = strings.ToLower(strings.TrimSpace(channel)); channel != "" {
+		q = q.Where("channel = ?", channel)
+	}
+	mappings := []models.ChannelRoomMapping{}
+	return mappings, q.Find(&mappings).Error
+}
+
+func (s *ChannelManagerService) CreateMapping(in ChannelMappingInput, actor AdminActor) (models.ChannelRoomMapping, error) {
+	m := models.ChannelRoomMapping{Active: true}
+	if err := s.applyMapping(&m, in); err != nil {
+		return m, err
+	}
+	if err := s.DB.Create(&m).Error; err != nil {
+		return m, fmt.Errorf("create channel mapping: %w", err)
+	}
+	s.auditMapping("channel.mapping.create", m, actor)
+	return m, s.DB.Preload("RoomType").First(&m, m.ID).Error
+}
+
+func (s *ChannelManagerService) UpdateMapping(id uint, in ChannelMappingInput, actor AdminActor) (models.ChannelRoomMapping, error) {
+	var m models.ChannelRoomMapping
+	if err := s.DB.First(&m, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return m, ErrChannelMappingNotFound
+		}
+		return m, err
+	}
+	if err := s.applyMapping(&m, in); err != nil {
+		return m, err
+	}
+	if err := s.DB.Omit("RoomType").Save(&m).Error; err != nil {
+		return m, fmt.Errorf("update channel mapping: %w", err)
+	}
+	s.auditMapping("channel.mapping.update", m, actor)
+	return m, s.DB.Preload("RoomType").First(&m, m.ID).Error
+}
+
+func (s *ChannelManagerService) DeleteMapping(id uint, actor AdminActor) error {
+	var m models.ChannelRoomMapping
+	if err := s.DB.First(&m, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrChannelMappingNotFound
+		}
+		return err
+	}
+	if err := s.DB.Delete(&m).Error; err != nil {
+		return fmt.Errorf("delete channel mapping: %w", err)
+	}
+	s.auditMapping("channel.mapping.delete", m, actor)
+	return nil
+}
+
+// applyMapping ตรวจแล้วใส่ค่าใหม่ลง m (channel+roomCode ห้ามซ้ำ, roomType ต้องมีอยู่)
+func (s *ChannelManagerService) applyMapping(m *models.ChannelRoomMapping, in ChannelMappingInput) error {
+	if in.Channel != nil {
+		m.Channel = strings.ToLower(strings.TrimSpace(*in.Channel))
+	}
+	if in.RoomCode != nil {
+		m.RoomCode = strings.TrimSpace(*in.RoomCode)
+	}
+	if in.RoomTypeID != nil {
+		var n int64
+		if err := s.DB.Model(&models.RoomType{}).Where("id = ?", *in.RoomTypeID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrRoomTypeNotFound.WithDetails(map[string]interface{}{"roomTypeId": *in.RoomTypeID})
+		}
+		m.RoomTypeID = *in.RoomTypeID
+	}
+	if in.Rate != nil {
+		m.Rate = in.Rate
+		if *in.Rate == 0 {
+			m.Rate = nil
+		}
+	}
+	if in.Active != nil {
+		m.Active = *in.Active
+	}
+
+	var n int64
+	if err := s.DB.Model(&models.ChannelRoomMapping{}).
+		Where("channel = ? AND room_code = ? AND id <> ?", m.Channel, m.RoomCode, m.ID).
+		Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrChannelMappingExists.WithDetails(map[string]interface{}{"channel": m.Channel, "roomCode": m.RoomCode})
+	}
+	return nil
+}
+
+func (s *ChannelManagerService) auditMapping(action string, m models.ChannelRoomMapping, actor AdminActor) {
+	RecordAudit(s.DB, AuditEntry{
+		ActorType: ActorAdmin,
+		ActorID:   strconv.FormatUint(uint64(actor.AdminID), 10),
+		Action:    action,
+		Target:    fmt.Sprintf("channel_mapping:%d", m.ID),
+		IP:        actor.IP,
+		Details:   map[string]interface{}{"channel": m.Channel, "roomCode": m.RoomCode, "roomTypeId": m.RoomTypeID, "active": m.Active},
+	})
+}
+
+// ---------------- sync log ----------------
+
+// ChannelSyncLogListSpec: GET /api/channel-manager/logs
+var ChannelSyncLogListSpec = ListSpec{
+	IDColumn: "id",
+	Sorts: map[string]string{
+		"id":        "id",
+		"startedAt": "started_at",
+	},
+	DefaultSort: "-id",
+	DateColumn:  "started_at",
+	Filters: map[string]ListFilter{
+		"operation": {Cond: "operation IN ?", Kind: FilterList},
+		"status":    {Cond: "status IN ?", Kind: FilterList},
+	},
+	MaxLimit: 500,
+}
+
+func (s *ChannelManagerService) ListLogs(q ListQuery) ([]models.ChannelSyncLog, PageInfo, error) {
+	logs := []models.ChannelSyncLog{}
+	page, err := FindPage(s.DB, ChannelSyncLogListSpec, q, &logs)
+	return logs, page, err
+}
+
+// ---------------- sync ----------------
+
+// Start sync ทุก Interval จนกว่า ctx ถูก cancel (ไม่ทำอะไรถ้าไม่มี provider)
+func (s *ChannelManagerService) Start(ctx context.Context) {
+	if !s.Enabled() {
+		return
+	}
+	log.Printf("🔁 Channel manager sync started (provider=%s, every %s)", s.Provider.Name(), s.Interval)
+	for {
+		if _, err := s.SyncAll("scheduler"); err != nil && !errors.Is(err, ErrChannelSyncBusy) {
+			log.Printf("channel manager: sync failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			log.Println("🔁 Channel manager sync stopped")
+			return
+		case <-time.After(s.Interval):
+		}
+	}
+}
+
+// SyncAll ดึงการจองแล้ว push ห้องว่างและราคา (ไม่รันซ้อนกัน)
+func (s *ChannelManagerService) SyncAll(trigger string) ([]models.ChannelSyncLog, error) {
+	if !s.Enabled() {
+		return nil, ErrChannelManagerOff
+	}
+	if !s.running.TryLock() {
+		return nil, ErrChannelSyncBusy
+	}
+	defer s.running.Unlock()
+
+	logs := make([]models.ChannelSyncLog, 0, len(ChannelSyncOperations))
+	for _, op := range ChannelSyncOperations {
+		logs = append(logs, s.runSync(op, trigger))
+	}
+	return logs, nil
+}
+
+// Sync รัน operation เดียว (manual)
+func (s *ChannelManagerService) Sync(op, trigger string) (models.ChannelSyncLog, error) {
+	if !isChannelOperation(op) {
+		return models.ChannelSyncLog{}, ErrUnknownChannelOperation
+	}
+	if !s.Enabled() {
+		return models.ChannelSyncLog{}, ErrChannelManagerOff
+	}
+	if !s.running.TryLock() {
+		return models.ChannelSyncLog{}, ErrChannelSyncBusy
+	}
+	defer s.running.Unlock()
+	return s.runSync(op, trigger), nil
+}
+
+func isChannelOperation(op string) bool {
+	for _, o := range ChannelSyncOperations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ChannelManagerService) runSync(op, trigger string) models.ChannelSyncLog {
+	entry := models.ChannelSyncLog{
+		Operation: op,
+		Provider:  s.Provider.Name(),
+		Trigger:   trigger,
+		Status:    JobStatusRunning,
+		StartedAt: time.Now().UTC(),
+	}
+	if err := s.DB.Create(&entry).Error; err != nil {
+		log.Printf("channel manager: failed to record sync log %s: %v", op, err)
+	}
+
+	var res jobResult
+	details := map[string]interface{}{}
+	var runErr error
+	switch op {
+	case ChannelOpReservations:
+		runErr = s.pullReservations(&res, details)
+	case ChannelOpAvailability:
+		runErr = s.pushAvailability(&res, details)
+	case ChannelOpRates:
+		runErr = s.pushRates(&res, details)
+	}
+
+	finished := time.Now().UTC()
+	entry.FinishedAt = &finished
+	entry.Processed = res.processed
+	entry.Succeeded = res.succeeded
+	entry.Failed = res.failed
+	entry.Skipped = res.skipped
+	if len(details) > 0 {
+		entry.Details = datatypes.JSONMap(details)
+	}
+
+	switch {
+	case runErr != nil:
+		entry.Status = JobStatusFailed
+		res.errs = append([]string{runErr.Error()}, res.errs...)
+	case res.failed > 0 && res.succeeded == 0:
+		entry.Status = JobStatusFailed
+	case res.failed > 0:
+		entry.Status = JobStatusPartial
+	default:
+		entry.Status = JobStatusSuccess
+	}
+	entry.Error = strings.Join(res.errs, "\n")
+
+	if entry.ID != 0 {
+		if err := s.DB.Save(&entry).Error; err != nil {
+			log.Printf("channel manager: failed to update sync log %d: %v", entry.ID, err)
+		}
+	}
+	if res.processed > 0 || runErr != nil {
+		log.Printf("channel manager: %s done status=%s processed=%d ok=%d failed=%d skipped=%d",
+			op, entry.Status, res.processed, res.succeeded, res.failed, res.skipped)
+	}
+	return entry
+}
+
+// syncWindow ช่วงวันที่ push [วันนี้, วันนี้+Days) เวลา UTC เที่ยงคืน
+func (s *ChannelManagerService) syncWindow() (time.Time, time.Time) {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 0, s.Days)
+}
+
+func (s *ChannelManagerService) activeMappings() ([]models.ChannelRoomMapping, error) {
+	var mappings []models.ChannelRoomMapping
+	err := s.DB.Where("active = ?", true).Order("channel, room_code").Find(&mappings).Error
+	return mappings, err
+}
+
+// roomsByType id ห้องของแต่ละ RoomType ใน typeIDs
+func (s *ChannelManagerService) roomsByType(mappings []models.ChannelRoomMapping) (map[uint][]models.Room, error) {
+	typeIDs := make([]uint, 0, len(mappings))
+	for _, m := range mappings {
+		typeIDs = append(typeIDs, m.RoomTypeID)
+	}
+	var rooms []models.Room
+	if err := s.DB.Select("id", "room_type_id", "price").Where("room_type_id IN ?", uniqueIDs(typeIDs)).Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	out := map[uint][]models.Room{}
+	for _, r := range rooms {
+		out[*r.RoomTypeID] = append(out[*r.RoomTypeID], r)
+	}
+	return out, nil
+}
+
+// pushAvailability นับห้องว่างรายวันของแต่ละ RoomType แล้วส่งเป็นช่วงวันที่ค่าเท่ากันติดกัน
+func (s *ChannelManagerService) pushAvailability(res *jobResult, details map[string]interface{}) error {
+	mappings, err := s.activeMappings()
+	if err != nil || len(mappings) == 0 {
+		return err
+	}
+	rooms, err := s.roomsByType(mappings)
+	if err != nil {
+		return fmt.Errorf("load rooms: %w", err)
+	}
+	from, to := s.syncWindow()
+	var roomIDs []uint
+	for _, list := range rooms {
+		for _, r := range list {
+			roomIDs = append(roomIDs, r.ID)
+		}
+	}
+	booked, err := roomBookingIntervals(s.DB, roomIDs, from, to)
+	if err != nil {
+		return fmt.Errorf("load bookings: %w", err)
+	}
+
+	// ห้องว่างรายวันต่อ RoomType (คิดครั้งเดียวแม้หลาย channel ใช้ type เดียวกัน)
+	daily := map[uint][]int{}
+	for typeID, list := range rooms {
+		counts := make([]int, s.Days)
+		for i := range counts {
+			day := from.AddDate(0, 0, i)
+			for _, r := range list {
+				if !overlapsAny(booked[r.ID], day, day.AddDate(0, 0, 1)) {
+					counts[i]++
+				}
+			}
+		}
+		daily[typeID] = counts
+	}
+
+	items := []ChannelAvailability{}
+	for _, m := range mappings {
+		res.processed++
+		counts, ok := daily[m.RoomTypeID]
+		if !ok {
+			counts = make([]int, s.Days) // ประเภทที่ไม่มีห้อง → ปิดขาย
+		}
+		for start := 0; start < len(counts); {
+			end := start + 1
+			for end < len(counts) && counts[end] == counts[start] {
+				end++
+			}
+			items = append(items, ChannelAvailability{
+				Channel:   m.Channel,
+				RoomCode:  m.RoomCode,
+				From:      from.AddDate(0, 0, start).Format("2006-01-02"),
+				To:        from.AddDate(0, 0, end).Format("2006-01-02"),
+				Available: counts[start],
+			})
+			start = end
+		}
+	}
+	details["from"], details["to"], details["items"] = from.Format("2006-01-02"), to.Format("2006-01-02"), len(items)
+
+	if err := s.Provider.PushAvailability(items); err != nil {
+		res.failed = res.processed
+		return fmt.Errorf("push availability: %w", err)
+	}
+	res.succeeded = res.processed
+	return nil
+}
+
+// pushRates ราคาต่อคืนของแต่ละ mapping: Rate ที่ตั้งไว้ หรือราคาต่ำสุดของห้องในประเภทนั้น
+func (s *ChannelManagerService) pushRates(res *jobResult, details map[string]interface{}) error {
+	mappings, err := s.activeMappings()
+	if err != nil || len(mappings) == 0 {
+		return err
+	}
+	rooms, err := s.roomsByType(mappings)
+	if err != nil {
+		return fmt.Errorf("load rooms: %w", err)
+	}
+	from, to := s.syncWindow()
+
+	items := []ChannelRate{}
+	noPrice := []string{}
+	for _, m := range mappings {
+		res.processed++
+		price := 0.0
+		if m.Rate != nil {
+			price = *m.Rate
+		} else {
+			for _, r := range rooms[m.RoomTypeID] {
+				if r.Price > 0 && (price == 0 || r.Price < price) {
+					price = r.Price
+				}
+			}
+		}
+		if price <= 0 {
+			res.skipped++
+			noPrice = append(noPrice, m.Channel+":"+m.RoomCode)
+			continue
+		}
+		items = append(items, ChannelRate{
+			Channel:  m.Channel,
+			RoomCode: m.RoomCode,
+			From:     from.Format("2006-01-02"),
+			To:       to.Format("2006-01-02"),
+			Price:    price,
+			Currency: s.Currency,
+		})
+	}
+	details["items"] = len(items)
+	if len(noPrice) > 0 {
+		details["noPrice"] = noPrice
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	if err := s.Provider.PushRates(items); err != nil {
+		res.failed = len(items)
+		return fmt.Errorf("push rates: %w", err)
+	}
+	res.succeeded = len(items)
+	return nil
+}
+
+// pullReservations ดึงการจองที่เปลี่ยนตั้งแต่รอบที่สำเร็จล่าสุด แล้วสร้าง / แก้ / ยกเลิก booking ทีละรายการ
+// รายการที่บันทึกไม่สำเร็จเก็บไว้ใน channel_reservation_retries แล้วลองใหม่ทุกรอบจนสำเร็จ
+func (s *ChannelManagerService) pullReservations(res *jobResult, details map[string]interface{}) error {
+	var since time.Time
+	var last models.ChannelSyncLog
+	err := s.DB.Where("operation = ? AND status IN ?", ChannelOpReservations, []string{JobStatusSuccess, JobStatusPartial}).
+		Order("started_at DESC").First(&last).Error
+	switch {
+	case err == nil:
+		since = last.StartedAt.Add(-channelPullOverlap)
+		details["since"] = since
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return fmt.Errorf("load last sync: %w", err)
+	}
+
+	pulled, err := s.Provider.PullReservations(since)
+	if err != nil {
+		return fmt.Errorf("pull reservations: %w", err)
+	}
+
+	// รายการที่ค้างจากรอบก่อนลองใหม่ก่อน; ถ้า pull รอบนี้ได้ฉบับใหม่ของ reservation เดียวกันใช้ฉบับใหม่แทน
+	retries, err := s.pendingRetries()
+	if err != nil {
+		return fmt.Errorf("load reservation retries: %w", err)
+	}
+	reservations := make([]ChannelReservation, 0, len(retries)+len(pulled))
+	index := map[string]int{}
+	for _, r := range append(retries, pulled...) {
+		r.Channel = strings.ToLower(strings.TrimSpace(r.Channel))
+		key := r.Channel + ":" + strings.TrimSpace(r.ReservationID)
+		if i, ok := index[key]; ok {
+			reservations[i] = r
+			continue
+		}
+		index[key] = len(reservations)
+		reservations = append(reservations, r)
+	}
+	if len(retries) > 0 {
+		details["retried"] = len(retries)
+	}
+
+	counts := map[string]int{}
+	overbooked := []string{}
+	pending := []string{}
+	for _, r := range reservations {
+		res.processed++
+		outcome, err := s.applyReservation(r)
+		if err != nil {
+			res.fail("%s %s: %v", r.Channel, r.ReservationID, err)
+			pending = append(pending, r.Channel+":"+r.ReservationID)
+			s.saveRetry(r, err)
+			continue
+		}
+		s.clearRetry(r)
+		counts[outcome.action]++
+		if outcome.overbooked {
+			overbooked = append(overbooked, r.Channel+":"+r.ReservationID)
+		}
+		if outcome.action == "unchanged" || outcome.action == "skipped" {
+			res.skipped++
+		} else {
+			res.succeeded++
+		}
+	}
+	for k, v := range counts {
+		details[k] = v
+	}
+	if len(overbooked) > 0 {
+		details["overbooked"] = overbooked
+	}
+	if len(pending) > 0 {
+		details["pendingRetry"] = pending
+	}
+	return nil
+}
+
+// pendingRetries reservation ที่บันทึกไม่สำเร็จในรอบก่อน ๆ (payload ที่เสียถูกข้ามและลบทิ้ง)
+func (s *ChannelManagerService) pendingRetries() ([]ChannelReservation, error) {
+	var rows []models.ChannelReservationRetry
+	if err := s.DB.Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]ChannelReservation, 0, len(rows))
+	for _, row := range rows {
+		var r ChannelReservation
+		if err := json.Unmarshal(row.Payload, &r); err != nil {
+			log.Printf("channel manager: drop unreadable retry %s:%s: %v", row.Channel, row.ReservationID, err)
+			s.DB.Delete(&row)
+			continue
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// saveRetry เก็บ / อัปเดต payload ล่าสุดของ reservation ที่ล้มเหลวไว้ลองใหม่รอบหน้า
+func (s *ChannelManagerService) saveRetry(r ChannelReservation, cause error) {
+	if r.Channel == "" || strings.TrimSpace(r.ReservationID) == "" {
+		return // ไม่มี key ให้ลองใหม่ได้
+	}
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	row := models.ChannelReservationRetry{
+		Channel:       r.Channel,
+		ReservationID: strings.TrimSpace(r.ReservationID),
+		Payload:       datatypes.JSON(payload),
+		Attempts:      1,
+		LastError:     cause.Error(),
+	}
+	err = s.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "channel"}, {Name: "reservation_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"payload":    row.Payload,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": row.LastError,
+			"updated_at": time.Now().UTC(),
+		}),
+	}).Create(&row).Error
+	if err != nil {
+		log.Printf("channel manager: failed to save retry %s:%s: %v", r.Channel, r.ReservationID, err)
+	}
+}
+
+func (s *ChannelManagerService) clearRetry(r ChannelReservation) {
+	if err := s.DB.Where("channel = ? AND reservation_id = ?", r.Channel, strings.TrimSpace(r.ReservationID)).
+		Delete(&models.ChannelReservationRetry{}).Error; err != nil {
+		log.Printf("channel manager: failed to clear retry %s:%s: %v", r.Channel, r.ReservationID, err)
+	}
+}
+
+type reservationOutcome struct {
+	action     string // created | modified | cancelled | unchanged | skipped
+	overbooked bool   // ไม่มีห้องว่างให้ (booking ถูกบันทึกแต่ยังไม่มีห้อง)
+}
+
+// applyReservation บันทึก reservation หนึ่งรายการใน transaction (booking หาด้วย source = channel, reference_code = reservationId)
+func (s *ChannelManagerService) applyReservation(r ChannelReservation) (reservationOutcome, error) {
+	var out reservationOutcome
+	if r.Channel == "" || strings.TrimSpace(r.ReservationID) == "" {
+		return out, errors.New("missing channel or reservationId")
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var booking models.Booking
+		err := tx.Preload("Rooms").Where("source = ? AND reference_code = ?", r.Channel, r.ReservationID).First(&booking).Error
+		found := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if found && (booking.CheckedInAt != nil || booking.Status == "Checked-In" || booking.Status == "Checked-Out") {
+			if r.Status == ChannelReservationCancelled || reservationChanged(booking, r) {
+				return errors.New("guest already checked in; change the booking at the front desk")
+			}
+			out.action = "unchanged"
+			return nil
+		}
+
+		if r.Status == ChannelReservationCancelled {
+			switch {
+			case !found:
+				out.action = "skipped"
+			case booking.Status == "Cancelled":
+				out.action = "unchanged"
+			default:
+				if err := tx.Model(&booking).Update("status", "Cancelled").Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&models.BookingRoom{}).Where("booking_id = ?", booking.ID).Update("status", "Cancelled").Error; err != nil {
+					return err
+				}
+				out.action = "cancelled"
+			}
+			return nil
+		}
+
+		var mapping models.ChannelRoomMapping
+		if err := tx.Where("channel = ? AND room_code = ? AND active = ?", r.Channel, r.RoomCode, true).First(&mapping).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("room code %q is not mapped for %s", r.RoomCode, r.Channel)
+			}
+			return err
+		}
+		ci, err1 := time.Parse("2006-01-02", strings.TrimSpace(r.CheckIn))
+		co, err2 := time.Parse("2006-01-02", strings.TrimSpace(r.CheckOut))
+		if err1 != nil || err2 != nil || !co.After(ci) {
+			return fmt.Errorf("invalid stay %q – %q", r.CheckIn, r.CheckOut)
+		}
+		adults := r.Adults
+		if adults < 1 {
+			adults = 1
+		}
+
+		// ไม่เปลี่ยนอะไร: จบเลย ถ้ายังไม่มีห้อง (overbooked รอบก่อน) ลองหาห้องให้อีกครั้ง
+		unchanged := found && booking.Status != "Cancelled" && !reservationChanged(booking, r)
+		if unchanged && bookingRoomMatches(tx, booking, mapping.RoomTypeID) {
+			out.action = "unchanged"
+			return nil
+		}
+
+		if !found {
+			customerID, err := s.reservationCustomer(tx, r)
+			if err != nil {
+				return err
+			}
+			booking = models.Booking{CustomerID: customerID, ReferenceCode: r.ReservationID, Source: r.Channel}
+			out.action = "created"
+		} else {
+			out.action = "modified"
+		}
+		booking.Status = "Confirmed"
+		booking.CheckIn, booking.CheckOut = &ci, &co
+		booking.CheckInDate, booking.CheckOutDate = &ci, &co
+		booking.Nights = int(co.Sub(ci).Hours() / 24)
+		booking.Adults, booking.Children = adults, r.Children
+		booking.NumberOfGuests = adults + r.Children
+		rooms := booking.Rooms
+		booking.Rooms = nil
+		if err := tx.Omit("Room", "Customer", "Rooms").Save(&booking).Error; err != nil {
+			return fmt.Errorf("save booking: %w", err)
+		}
+
+		// ห้องเดิมยังใช้ได้ (ประเภทตรงและไม่ชนใคร) ใช้ต่อ ไม่งั้นหาห้องว่างใหม่ของประเภทนั้น
+		roomID := uint(0)
+		for _, br := range rooms {
+			var room models.Room
+			if tx.Select("id", "room_type_id").First(&room, br.RoomID).Error != nil ||
+				room.RoomTypeID == nil || *room.RoomTypeID != mapping.RoomTypeID {
+				continue
+			}
+			busy, err := BookedRoomIDs(tx, []uint{room.ID}, ci, co, booking.ID)
+			if err != nil {
+				return err
+			}
+			if !busy[room.ID] {
+				roomID = room.ID
+				break
+			}
+		}
+		if roomID == 0 {
+			if roomID, err = firstFreeRoomOfType(tx, mapping.RoomTypeID, ci, co, booking.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("booking_id = ?", booking.ID).Delete(&models.BookingRoom{}).Error; err != nil {
+			return err
+		}
+		if roomID == 0 {
+			// OTA ขายไปแล้ว ปฏิเสธไม่ได้ → บันทึก booking ไว้ให้ staff จัดห้องเอง
+			out.overbooked = true
+			if unchanged {
+				out.action = "unchanged"
+			}
+			return nil
+		}
+		return tx.Create(&models.BookingRoom{BookingID: booking.ID, RoomID: roomID, Nights: booking.Nights, Status: "Reserved"}).Error
+	})
+	return out, err
+}
+
+// reservationChanged วันเข้าพัก / จำนวนแขกต่างจาก booking เดิม
+func reservationChanged(b models.Booking, r ChannelReservation) bool {
+	adults := r.Adults
+	if adults < 1 {
+		adults = 1
+	}
+	return b.CheckIn == nil || b.CheckOut == nil ||
+		b.CheckIn.UTC().Format("2006-01-02") != strings.TrimSpace(r.CheckIn) ||
+		b.CheckOut.UTC().Format("2006-01-02") != strings.TrimSpace(r.CheckOut) ||
+		b.Adults != adults || b.Children != r.Children
+}
+
+// bookingRoomMatches booking มีห้องของ roomTypeID อยู่แล้ว
+func bookingRoomMatches(db *gorm.DB, b models.Booking, roomTypeID uint) bool {
+	ids := make([]uint, 0, len(b.Rooms))
+	for _, br := range CurrentBookingRooms(b.Rooms) {
+		ids = append(ids, br.RoomID)
+	}
+	if len(ids) == 0 {
+		return false
+	}
+	var n int64
+	db.Model(&models.Room{}).Where("id IN ? AND room_type_id = ?", ids, roomTypeID).Count(&n)
+	return n > 0
+}
+
+// reservationCustomer ลูกค้าเดิมที่อีเมล / เบอร์ตรงกัน หรือสร้างใหม่จากข้อมูลผู้จอง
+func (s *ChannelManagerService) reservationCustomer(tx *gorm.DB, r ChannelReservation) (uint, error) {
+	email := strings.ToLower(strings.TrimSpace(r.Guest.Email))
+	id, _, err := newImportCustomerMatcher(tx).match(email, r.Guest.Phone)
+	if err != nil || id != 0 {
+		return id, err
+	}
+	c := models.Customer{
+		FullName:    firstNonEmpty(r.Guest.Name, r.Channel+" "+r.ReservationID),
+		Email:       email,
+		Phone:       strings.TrimSpace(r.Guest.Phone),
+		Nationality: strings.TrimSpace(r.Guest.Nationality),
+		Tags:        datatypes.JSONSlice[string]{},
+	}
+	if err := tx.Create(&c).Error; err != nil {
+		return 0, fmt.Errorf("create customer: %w", err)
+	}
+	return c.ID, nil
+}
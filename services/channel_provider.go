@@ -0,0 +1,284 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"hotel-backend/utils"
+)
+
+// ---------------------------
+// Channel manager protocol
+// ---------------------------
+
+// ChannelAvailability จำนวนห้องว่างของ roomCode ในช่วงวันที่ [From, To) (YYYY-MM-DD)
+type ChannelAvailability struct {
+	Channel   string `json:"channel"`
+	RoomCode  string `json:"roomCode"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Available int    `json:"available"`
+}
+
+// ChannelRate ราคาต่อคืนของ roomCode ในช่วงวันที่ [From, To)
+type ChannelRate struct {
+	Channel  string  `json:"channel"`
+	RoomCode string  `json:"roomCode"`
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+}
+
+// Reservation statuses ที่ channel manager ส่งมา
+const (
+	ChannelReservationNew       = "new"
+	ChannelReservationModified  = "modified"
+	ChannelReservationCancelled = "cancelled"
+)
+
+// ChannelGuest ผู้จองตามที่ OTA ส่งมา
+type ChannelGuest struct {
+	Name        string `json:"name"`
+	Email       string `json:"email,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+	Nationality string `json:"nationality,omitempty"`
+}
+
+// ChannelReservation การจองใหม่ / แก้ไข / ยกเลิก จาก OTA
+type ChannelReservation struct {
+	Channel       string       `json:"channel"`
+	ReservationID string       `json:"reservationId"`
+	Status        string       `json:"status"` // new | modified | cancelled
+	RoomCode      string       `json:"roomCode"`
+	CheckIn       string       `json:"checkIn"`
+	CheckOut      string       `json:"checkOut"`
+	Adults        int          `json:"adults"`
+	Children      int          `json:"children"`
+	Guest         ChannelGuest `json:"guest"`
+	TotalPrice    float64      `json:"totalPrice,omitempty"`
+	Currency      string       `json:"currency,omitempty"`
+	UpdatedAt     time.Time    `json:"updatedAt"`
+}
+
+// ChannelProvider ช่องทางคุยกับ channel manager (push ห้องว่าง / ราคา, ดึง reservation)
+type ChannelProvider interface {
+	Name() string
+	PushAvailability(items []ChannelAvailability) error
+	PushRates(items []ChannelRate) error
+	// PullReservations คืน reservation ที่สร้าง / แก้ / ยกเลิกตั้งแต่ since (เรียงตาม UpdatedAt)
+	PullReservations(since time.Time) ([]ChannelReservation, error)
+}
+
+// ---------------------------
+// HTTP/JSON provider
+// ---------------------------
+
+// HTTPChannelProvider reference implementation ของ protocol แบบ HTTP/JSON:
+//
+//	POST {URL}/availability        {"propertyId": "...", "items": [ChannelAvailability]}
+//	POST {URL}/rates               {"propertyId": "...", "items": [ChannelRate]}
+//	GET  {URL}/reservations?since=<RFC3339>&propertyId=...  →  {"reservations": [ChannelReservation]}
+//
+// ใช้กับ stub ในเครื่องได้ (APP_ENV=development: CHANNEL_MANAGER_URL=http://localhost:8080/api/dev/channel-manager)
+type HTTPChannelProvider struct {
+	BaseURL    string
+	APIKey     string
+	PropertyID string
+	Client     *http.Client
+}
+
+func (p *HTTPChannelProvider) Name() string { return "http" }
+
+func (p *HTTPChannelProvider) PushAvailability(items []ChannelAvailability) error {
+	return p.post("/availability", map[string]interface{}{"propertyId": p.PropertyID, "items": items})
+}
+
+func (p *HTTPChannelProvider) PushRates(items []ChannelRate) error {
+	return p.post("/rates", map[string]interface{}{"propertyId": p.PropertyID, "items": items})
+}
+
+func (p *HTTPChannelProvider) PullReservations(since time.Time) ([]ChannelReservation, error) {
+	q := url.Values{}
+	if !since.IsZero() {
+		q.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	if p.PropertyID != "" {
+		q.Set("propertyId", p.PropertyID)
+	}
+	endpoint := p.BaseURL + "/reservations"
+	if len(q) > 0 {
+		endpoint += "?" + q.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build request: %w", err)
+	}
+	raw, err := p.do(req)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Reservations []ChannelReservation `json:"reservations"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("JSON parse error: %w", err)
+	}
+	sort.SliceStable(out.Reservations, func(i, j int) bool {
+		return out.Reservations[i].UpdatedAt.Before(out.Reservations[j].UpdatedAt)
+	})
+	return out.Reservations, nil
+}
+
+func (p *HTTPChannelProvider) post(path string, payload interface{}) error {
+	body, _ := json.Marshal(payload)
+	req, err := http.NewRequest(http.MethodPost, p.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("cannot build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	_, err = p.do(req)
+	return err
+}
+
+func (p *HTTPChannelProvider) do(req *http.Request) ([]byte, error) {
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	return raw, nil
+}
+
+// NewChannelProviderFromEnv:
+//
+//	CHANNEL_MANAGER_PROVIDER     http | off (default: http ถ้ามี CHANNEL_MANAGER_URL ไม่งั้น off)
+//	CHANNEL_MANAGER_URL          base URL ของ channel manager
+//	CHANNEL_MANAGER_API_KEY      ส่งเป็น Bearer token
+//	CHANNEL_MANAGER_PROPERTY_ID  รหัสโรงแรมฝั่ง channel manager
+func NewChannelProviderFromEnv() ChannelProvider {
+	base := strings.TrimRight(strings.TrimSpace(os.Getenv("CHANNEL_MANAGER_URL")), "/")
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("CHANNEL_MANAGER_PROVIDER")))
+	if provider == "" && base != "" {
+		provider = "http"
+	}
+
+	switch provider {
+	case "", "off", "none", "disabled":
+		return nil
+	case "http":
+		if base == "" {
+			log.Println("⚠️  CHANNEL_MANAGER_PROVIDER=http but CHANNEL_MANAGER_URL is empty; channel manager disabled")
+			return nil
+		}
+		return &HTTPChannelProvider{
+			BaseURL:    base,
+			APIKey:     strings.TrimSpace(os.Getenv("CHANNEL_MANAGER_API_KEY")),
+			PropertyID: strings.TrimSpace(utils.EnvOrDefault("CHANNEL_MANAGER_PROPERTY_ID", "")),
+			Client:     &http.Client{Timeout: 30 * time.Second},
+		}
+	}
+	log.Printf("⚠️  unknown CHANNEL_MANAGER_PROVIDER %q; channel manager disabled", provider)
+	return nil
+}
+
+// ---------------------------
+// Local stub (dev)
+// ---------------------------
+
+// ChannelStub channel manager จำลองในหน่วยความจำ (เสิร์ฟผ่าน /api/dev/channel-manager ตอน dev)
+// เก็บค่าที่ถูก push ล่าสุดต่อ channel+roomCode และคืน reservation ที่ใส่ไว้ให้ตอน pull
+type ChannelStub struct {
+	mu           sync.Mutex
+	availability []ChannelAvailability
+	rates        []ChannelRate
+	reservations []ChannelReservation
+}
+
+// DevChannelStub stub ตัวเดียวของ process
+var DevChannelStub = &ChannelStub{}
+
+// ReceiveAvailability แทนค่าของ roomCode ที่ส่งมา (push แต่ละครั้งส่งทั้งช่วงของห้องนั้น)
+func (s *ChannelStub) ReceiveAvailability(items []ChannelAvailability) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.availability = replaceChannelItems(s.availability, items, func(a ChannelAvailability) string { return a.Channel + "|" + a.RoomCode })
+}
+
+func (s *ChannelStub) ReceiveRates(items []ChannelRate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rates = replaceChannelItems(s.rates, items, func(r ChannelRate) string { return r.Channel + "|" + r.RoomCode })
+}
+
+// AddReservation จำลอง OTA ส่งการจองเข้ามา (UpdatedAt = ตอนนี้ถ้าไม่ระบุ)
+func (s *ChannelStub) AddReservation(r ChannelReservation) ChannelReservation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.UpdatedAt.IsZero() {
+		r.UpdatedAt = time.Now().UTC()
+	}
+	if r.Status == "" {
+		r.Status = ChannelReservationNew
+	}
+	s.reservations = append(s.reservations, r)
+	return r
+}
+
+// Reservations คืน reservation ที่ UpdatedAt หลัง since (since ว่าง = ทั้งหมด)
+func (s *ChannelStub) Reservations(since time.Time) []ChannelReservation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []ChannelReservation{}
+	for _, r := range s.reservations {
+		if since.IsZero() || r.UpdatedAt.After(since) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// State ค่าที่ถูก push ล่าสุดทั้งหมด (ให้ dev ตรวจว่าส่งอะไรไป)
+func (s *ChannelStub) State() ([]ChannelAvailability, []ChannelRate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ChannelAvailability{}, s.availability...), append([]ChannelRate{}, s.rates...)
+}
+
+func (s *ChannelStub) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.availability, s.rates, s.reservations = nil, nil, nil
+}
+
+// replaceChannelItems ลบรายการเดิมของ key ที่มีในชุดใหม่ แล้วต่อชุดใหม่ท้าย
+func replaceChannelItems[T any](current, incoming []T, key func(T) string) []T {
+	replaced := map[string]bool{}
+	for _, it := range incoming {
+		replaced[key(it)] = true
+	}
+	out := make([]T, 0, len(current)+len(incoming))
+	for _, it := range current {
+		if !replaced[key(it)] {
+			out = append(out, it)
+		}
+	}
+	return append(out, incoming...)
+}
@@ -0,0 +1,654 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"hotel-backend/models"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ขั้นตอนของการเช็คอินออนไลน์ (เรียงตามลำดับที่หน้าเว็บแสดง)
+const (
+	DraftStepGuests    = "guests"
+	DraftStepDocuments = "documents"
+	DraftStepConsents  = "consents"
+	DraftStepArrival   = "arrival_time"
+)
+
+// DraftSteps ลำดับขั้นทั้งหมด; arrival_time ไม่บังคับตอน submit
+var DraftSteps = []string{DraftStepGuests, DraftStepDocuments, DraftStepConsents, DraftStepArrival}
+
+var requiredDraftSteps = []string{DraftStepGuests, DraftStepDocuments, DraftStepConsents}
+
+const maxDraftGuests = 20
+
+var (
+	ErrDraftSubmitted     = ErrAlreadyCheckedIn // ส่งแล้ว = เช็คอินแล้ว
+	ErrDraftIncomplete    = NewError(KindUnprocessable, "error.checkinIncomplete")
+	ErrUnknownDraftStep   = NewError(KindInvalid, "error.unknownCheckinStep").WithDetails(map[string]interface{}{"steps": DraftSteps})
+	ErrCheckinStepInvalid = NewError(KindUnprocessable, "error.checkinStepInvalid")
+)
+
+var arrivalTimePattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// draftInvalid: ข้อมูลของขั้นไม่ผ่าน validation (field → เหตุผล)
+func draftInvalid(step string, fields map[string]string) error {
+	return ErrCheckinStepInvalid.WithDetails(map[string]interface{}{"step": step, "fields": fields})
+}
+
+// DraftGuest ข้อมูลแขกในขั้น guests
+type DraftGuest struct {
+	FullName       string `json:"fullName"`
+	IsMainGuest    bool   `json:"isMainGuest"`
+	DateOfBirth    string `json:"dateOfBirth,omitempty"` // YYYY-MM-DD
+	Gender         string `json:"gender,omitempty"`
+	Nationality    string `json:"nationality,omitempty"`
+	CurrentAddress string `json:"currentAddress,omitempty"`
+	Email          string `json:"email,omitempty"`
+}
+
+// DraftDocument เอกสารของแขกคนที่ GuestIndex (index ใน guests)
+// รูปส่งมาเป็น base64 ได้ จะถูกบันทึกเป็นไฟล์แล้วเก็บแค่ path ใน draft
+// *ImagePath ที่ client ส่งมาต้องเป็นรูปที่ server บันทึกให้ draft นี้ (CheckInDraft.Uploads) ไม่งั้นถูกทิ้ง
+type DraftDocument struct {
+	GuestIndex        int    `json:"guestIndex"`
+	IDType            string `json:"idType"`
+	IDNumber          string `json:"idNumber"`
+	IDIssuedCountry   string `json:"idIssuedCountry,omitempty"`
+	DocumentImage     string `json:"documentImage,omitempty"`
+	FaceImage         string `json:"faceImage,omitempty"`
+	DocumentImagePath string `json:"documentImagePath,omitempty"`
+	FaceImagePath     string `json:"faceImagePath,omitempty"`
+}
+
+// DraftConsent การยอมรับ consent แต่ละรายการ
+type DraftConsent struct {
+	ConsentID uint `json:"consentId"`
+	Accepted  bool `json:"accepted"`
+}
+
+type draftArrival struct {
+	ArrivalTime string `json:"arrivalTime"`
+}
+
+// CheckInDraftState คือสิ่งที่ส่งกลับให้หน้าเว็บใช้ resume
+type CheckInDraftState struct {
+	BookingInfoID  uint            `json:"bookingInfoId"`
+	BookingID      uint            `json:"bookingId"`
+	Steps          []string        `json:"steps"`
+	CurrentStep    string          `json:"currentStep"`
+	CompletedSteps []string        `json:"completedSteps"`
+	MissingSteps   []string        `json:"missingSteps"`
+	Guests         []DraftGuest    `json:"guests"`
+	Documents      []DraftDocument `json:"documents"`
+	Consents       []DraftConsent  `json:"consents"`
+	ArrivalTime    string          `json:"arrivalTime,omitempty"`
+	SubmittedAt    *time.Time      `json:"submittedAt,omitempty"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+// CheckInDraftService จัดการ draft ของการเช็คอินออนไลน์ที่บันทึกทีละขั้น
+type CheckInDraftService struct {
+	DB       *gorm.DB
+	Bookings *BookingService
+}
+
+func NewCheckInDraftService(db *gorm.DB, bookings *BookingService) *CheckInDraftService {
+	return &CheckInDraftService{DB: db, Bookings: bookings}
+}
+
+// Get คืน draft ของ token (สร้างใหม่ถ้ายังไม่มี)
+func (s *CheckInDraftService) Get(token string) (CheckInDraftState, error) {
+	bi, err := s.session(token)
+	if err != nil {
+		return CheckInDraftState{}, err
+	}
+	draft, err := s.loadOrCreate(bi)
+	if err != nil {
+		return CheckInDraftState{}, err
+	}
+	return draftState(bi, draft), nil
+}
+
+// SaveStep validate แล้วบันทึกข้อมูลของขั้น step ลง draft
+func (s *CheckInDraftService) SaveStep(token, step string, data json.RawMessage) (CheckInDraftState, error) {
+	step = strings.TrimSpace(step)
+	if !isDraftStep(step) {
+		return CheckInDraftState{}, ErrUnknownDraftStep
+	}
+
+	bi, err := s.session(token)
+	if err != nil {
+		return CheckInDraftState{}, err
+	}
+	draft, err := s.loadOrCreate(bi)
+	if err != nil {
+		return CheckInDraftState{}, err
+	}
+
+	updates := map[string]interface{}{}
+	completed := splitSteps(draft.CompletedSteps)
+	var replaced []string // รูปเอกสารเดิมที่ถูกแทนที่ ลบหลังบันทึก draft สำเร็จ
+
+	switch step {
+	case DraftStepGuests:
+		guests, err := validateDraftGuests(data)
+		if err != nil {
+			return CheckInDraftState{}, err
+		}
+		// จำนวนแขกเปลี่ยน → เอกสารเดิมอาจอ้าง index ผิด ต้องกรอกขั้น documents ใหม่
+		if len(decodeDraftGuests(draft.Guests)) != len(guests) {
+			completed = removeStep(completed, DraftStepDocuments)
+		}
+		updates["guests"] = mustJSON(guests)
+
+	case DraftStepDocuments:
+		if !hasStep(completed, DraftStepGuests) {
+			return CheckInDraftState{}, draftInvalid(step, map[string]string{
+				"guests": "complete the guests step first",
+			})
+		}
+		docs, err := validateDraftDocuments(data, decodeDraftGuests(draft.Guests), draft.Uploads)
+		if err != nil {
+			return CheckInDraftState{}, err
+		}
+		var prev []DraftDocument
+		if len(draft.Documents) > 0 {
+			_ = json.Unmarshal(draft.Documents, &prev)
+		}
+		if err := storeDraftDocumentImages(docs, prev); err != nil {
+			return CheckInDraftState{}, err
+		}
+		updates["documents"] = mustJSON(docs)
+		replaced = replacedDraftImages(prev, docs)
+
+	case DraftStepConsents:
+		consents, err := s.validateDraftConsents(data)
+		if err != nil {
+			return CheckInDraftState{}, err
+		}
+		updates["consents"] = mustJSON(consents)
+
+	case DraftStepArrival:
+		var payload draftArrival
+		if err := json.Unmarshal(data, &payload); err != nil || !arrivalTimePattern.MatchString(strings.TrimSpace(payload.ArrivalTime)) {
+			return CheckInDraftState{}, draftInvalid(step, map[string]string{
+				"arrivalTime": "must be HH:MM (24-hour)",
+			})
+		}
+		updates["arrival_time"] = strings.TrimSpace(payload.ArrivalTime)
+	}
+
+	completed = addStep(completed, step)
+	updates["completed_steps"] = strings.Join(completed, ",")
+	updates["current_step"] = nextDraftStep(completed)
+
+	if err := s.DB.Model(&draft).Updates(updates).Error; err != nil {
+		return CheckInDraftState{}, err
+	}
+	removeUploadedFiles(replaced)
+	if err := s.DB.First(&draft, draft.ID).Error; err != nil {
+		return CheckInDraftState{}, err
+	}
+	return draftState(bi, draft), nil
+}
+
+// Submit ส่ง draft ที่กรอกครบแล้วเข้า FinalizeCheckInTransaction
+// ถ้ายังไม่ครบคืน ErrDraftIncomplete พร้อม state (ดู MissingSteps)
+func (s *CheckInDraftService) Submit(token string) (CheckInDraftState, error) {
+	bi, err := s.session(token)
+	if err != nil {
+		return CheckInDraftState{}, err
+	}
+	draft, err := s.loadOrCreate(bi)
+	if err != nil {
+		return CheckInDraftState{}, err
+	}
+
+	state := draftState(bi, draft)
+	if len(state.MissingSteps) > 0 {
+		return state, ErrDraftIncomplete.WithDetails(map[string]interface{}{"missingSteps": state.MissingSteps})
+	}
+
+	guests := buildDraftGuests(state.Guests, state.Documents)
+	consents := make([]models.Consent, 0, len(state.Consents))
+	for _, c := range state.Consents {
+		if c.Accepted {
+			consents = append(consents, models.Consent{ID: c.ConsentID})
+		}
+	}
+
+	if err := s.Bookings.FinalizeCheckInTransaction(bi.Token, guests, consents); err != nil {
+		return state, err
+	}
+
+	now := time.Now().UTC()
+	if state.ArrivalTime != "" {
+		if err := s.DB.Model(&models.Booking{}).Where("id = ?", bi.BookingID).
+			Update("expected_arrival_time", state.ArrivalTime).Error; err != nil {
+			return state, err
+		}
+	}
+	if err := s.DB.Model(&draft).Updates(map[string]interface{}{
+		"submitted_at": now,
+		"current_step": "",
+	}).Error; err != nil {
+		return state, err
+	}
+
+	draft.SubmittedAt = &now
+	draft.CurrentStep = ""
+	return draftState(bi, draft), nil
+}
+
+// session หา BookingInfo ที่ยังใช้เช็คอินออนไลน์ได้จาก token
+func (s *CheckInDraftService) session(token string) (models.BookingInfo, error) {
+	var bi models.BookingInfo
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return bi, ErrInvalidOrExpiredToken
+	}
+	now := time.Now().UTC()
+	if err := s.DB.Where("token = ? AND (expires_at IS NULL OR expires_at > ?)", token, now).First(&bi).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return bi, ErrInvalidOrExpiredToken
+		}
+		return bi, err
+	}
+
+	switch strings.ToUpper(bi.Status) {
+	case "COMPLETED":
+		return bi, ErrDraftSubmitted
+	case "EXPIRED", "REVOKED":
+		return bi, ErrInvalidOrExpiredToken
+	}
+
+	var booking models.Booking
+	if err := s.DB.First(&booking, bi.BookingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return bi, ErrBookingNotFound
+		}
+		return bi, err
+	}
+	if strings.EqualFold(strings.TrimSpace(booking.Status), "Checked-Out") {
+		return bi, ErrBookingCheckedOut
+	}
+	if booking.CheckinCompleted || booking.CheckedInAt != nil {
+		return bi, ErrAlreadyCheckedIn
+	}
+	return bi, nil
+}
+
+func (s *CheckInDraftService) loadOrCreate(bi models.BookingInfo) (models.CheckInDraft, error) {
+	draft := models.CheckInDraft{BookingInfoID: bi.ID}
+	err := s.DB.Where("booking_info_id = ?", bi.ID).
+		Attrs(models.CheckInDraft{CurrentStep: DraftStepGuests}).
+		FirstOrCreate(&draft).Error
+	if err != nil {
+		return draft, err
+	}
+	if draft.SubmittedAt != nil {
+		return draft, ErrDraftSubmitted
+	}
+	return draft, nil
+}
+
+func (s *CheckInDraftService) validateDraftConsents(data json.RawMessage) ([]DraftConsent, error) {
+	var consents []DraftConsent
+	fields := map[string]string{}
+	if err := json.Unmarshal(data, &consents); err != nil {
+		return nil, draftInvalid(DraftStepConsents, map[string]string{"consents": "must be an array"})
+	}
+
+	var active []models.Consent
+	if err := s.DB.Find(&active).Error; err != nil {
+		return nil, err
+	}
+	known := make(map[uint]bool, len(active))
+	for _, c := range active {
+		known[c.ID] = true
+	}
+
+	accepted := map[uint]bool{}
+	for i, c := range consents {
+		if !known[c.ConsentID] {
+			fields[fmt.Sprintf("consents[%d].consentId", i)] = "unknown consent"
+			continue
+		}
+		if c.Accepted {
+			accepted[c.ConsentID] = true
+		}
+	}
+	// consent ที่มีในระบบเป็นเงื่อนไขการเข้าพัก ต้องยอมรับครบทุกข้อ
+	for _, c := range active {
+		if !accepted[c.ID] {
+			fields[fmt.Sprintf("consent.%d", c.ID)] = "must be accepted"
+		}
+	}
+	if len(fields) > 0 {
+		return nil, draftInvalid(DraftStepConsents, fields)
+	}
+	return consents, nil
+}
+
+func validateDraftGuests(data json.RawMessage) ([]DraftGuest, error) {
+	var guests []DraftGuest
+	fields := map[string]string{}
+	if err := json.Unmarshal(data, &guests); err != nil {
+		return nil, draftInvalid(DraftStepGuests, map[string]string{"guests": "must be an array"})
+	}
+	if len(guests) == 0 {
+		fields["guests"] = "at least one guest is required"
+	}
+	if len(guests) > maxDraftGuests {
+		fields["guests"] = fmt.Sprintf("at most %d guests", maxDraftGuests)
+	}
+
+	mainCount := 0
+	today := time.Now().UTC()
+	for i := range guests {
+		g := &guests[i]
+		g.FullName = strings.TrimSpace(g.FullName)
+		g.Email = strings.TrimSpace(g.Email)
+		g.DateOfBirth = strings.TrimSpace(g.DateOfBirth)
+
+		if g.FullName == "" {
+			fields[fmt.Sprintf("guests[%d].fullName", i)] = "required"
+		}
+		if g.IsMainGuest {
+			mainCount++
+		}
+		if g.DateOfBirth != "" {
+			dob, err := time.Parse("2006-01-02", g.DateOfBirth)
+			if err != nil {
+				fields[fmt.Sprintf("guests[%d].dateOfBirth", i)] = "must be YYYY-MM-DD"
+			} else if dob.After(today) {
+				fields[fmt.Sprintf("guests[%d].dateOfBirth", i)] = "cannot be in the future"
+			}
+		}
+		if g.Email != "" {
+			if _, err := mail.ParseAddress(g.Email); err != nil {
+				fields[fmt.Sprintf("guests[%d].email", i)] = "invalid email"
+			}
+		}
+	}
+
+	switch {
+	case mainCount == 0 && len(guests) > 0:
+		guests[0].IsMainGuest = true
+	case mainCount > 1:
+		fields["guests.isMainGuest"] = "only one main guest is allowed"
+	}
+
+	if len(fields) > 0 {
+		return nil, draftInvalid(DraftStepGuests, fields)
+	}
+	return guests, nil
+}
+
+func validateDraftDocuments(data json.RawMessage, guests []DraftGuest, uploads []string) ([]DraftDocument, error) {
+	var docs []DraftDocument
+	fields := map[string]string{}
+	if err := json.Unmarshal(data, &docs); err != nil {
+		return nil, draftInvalid(DraftStepDocuments, map[string]string{"documents": "must be an array"})
+	}
+
+	seen := map[int]bool{}
+	for i := range docs {
+		d := &docs[i]
+		d.IDType = strings.TrimSpace(d.IDType)
+		d.IDNumber = strings.TrimSpace(d.IDNumber)
+		d.DocumentImagePath = issuedUpload(uploads, d.DocumentImagePath)
+		d.FaceImagePath = issuedUpload(uploads, d.FaceImagePath)
+
+		if d.GuestIndex < 0 || d.GuestIndex >= len(guests) {
+			fields[fmt.Sprintf("documents[%d].guestIndex", i)] = "does not match a guest"
+			continue
+		}
+		if seen[d.GuestIndex] {
+			fields[fmt.Sprintf("documents[%d].guestIndex", i)] = "duplicate guest"
+		}
+		seen[d.GuestIndex] = true
+		if d.IDType == "" {
+			fields[fmt.Sprintf("documents[%d].idType", i)] = "required"
+		}
+		if d.IDNumber == "" {
+			fields[fmt.Sprintf("documents[%d].idNumber", i)] = "required"
+		}
+	}
+
+	// แขกหลักต้องมีเอกสารเสมอ
+	for i, g := range guests {
+		if g.IsMainGuest && !seen[i] {
+			fields[fmt.Sprintf("documents.guest[%d]", i)] = "main guest document is required"
+		}
+	}
+
+	if len(fields) > 0 {
+		return nil, draftInvalid(DraftStepDocuments, fields)
+	}
+	return docs, nil
+}
+
+// issuedUpload คืน path ถ้าอยู่ในรายการรูปที่ server บันทึกให้ draft ไม่งั้นคืน ""
+func issuedUpload(uploads []string, path string) string {
+	path = strings.TrimSpace(path)
+	for _, u := range uploads {
+		if path != "" && u == path {
+			return path
+		}
+	}
+	return ""
+}
+
+// RecordUpload จำรูปที่ server บันทึกให้ draft ของ bi (kiosk) เพื่อให้ขั้น documents อ้าง path นั้นได้
+func (s *CheckInDraftService) RecordUpload(bi models.BookingInfo, path string) error {
+	draft, err := s.loadOrCreate(bi)
+	if err != nil {
+		return err
+	}
+	uploads := append(datatypes.JSONSlice[string]{}, draft.Uploads...)
+	uploads = append(uploads, path)
+	return s.DB.Model(&draft).Update("uploads", uploads).Error
+}
+
+// storeDraftDocumentImages เขียนรูป base64 ลง uploads แล้วแทนที่ด้วย path
+// แขกที่ไม่ได้ส่งรูปใหม่ใช้ path เดิมจาก draft ก่อนหน้า (prev) ตาม GuestIndex
+func storeDraftDocumentImages(docs, prev []DraftDocument) error {
+	byGuest := make(map[int]DraftDocument, len(prev))
+	for _, p := range prev {
+		byGuest[p.GuestIndex] = p
+	}
+	for i := range docs {
+		d := &docs[i]
+		if p, ok := byGuest[d.GuestIndex]; ok {
+			d.DocumentImagePath = firstNonEmpty(d.DocumentImagePath, p.DocumentImagePath)
+			d.FaceImagePath = firstNonEmpty(d.FaceImagePath, p.FaceImagePath)
+		}
+		if strings.TrimSpace(d.DocumentImage) != "" {
+			path, err := SaveBase64Image(d.DocumentImage, "documents")
+			if err != nil {
+				return draftInvalid(DraftStepDocuments, map[string]string{
+					fmt.Sprintf("documents[%d].documentImage", i): "invalid image",
+				})
+			}
+			d.DocumentImagePath = path
+			d.DocumentImage = ""
+		}
+		if strings.TrimSpace(d.FaceImage) != "" {
+			path, err := SaveBase64Image(d.FaceImage, "faces")
+			if err != nil {
+				return draftInvalid(DraftStepDocuments, map[string]string{
+					fmt.Sprintf("documents[%d].faceImage", i): "invalid image",
+				})
+			}
+			d.FaceImagePath = path
+			d.FaceImage = ""
+		}
+	}
+	return nil
+}
+
+// replacedDraftImages path รูปใน prev ที่ docs ไม่ได้อ้างแล้ว (ส่งรูปใหม่แทน / ลบเอกสารของแขกออก)
+func replacedDraftImages(prev, docs []DraftDocument) []string {
+	kept := map[string]bool{}
+	for _, d := range docs {
+		kept[d.DocumentImagePath], kept[d.FaceImagePath] = true, true
+	}
+	var out []string
+	for _, p := range prev {
+		for _, path := range []string{p.DocumentImagePath, p.FaceImagePath} {
+			if path != "" && !kept[path] {
+				kept[path] = true
+				out = append(out, path)
+			}
+		}
+	}
+	return out
+}
+
+// buildDraftGuests รวมข้อมูลขั้น guests + documents เป็น models.Guest สำหรับ finalize
+func buildDraftGuests(guests []DraftGuest, docs []DraftDocument) []models.Guest {
+	byGuest := make(map[int]DraftDocument, len(docs))
+	for _, d := range docs {
+		byGuest[d.GuestIndex] = d
+	}
+
+	out := make([]models.Guest, 0, len(guests))
+	for i, g := range guests {
+		m := models.Guest{
+			FullName:       g.FullName,
+			IsMainGuest:    g.IsMainGuest,
+			Gender:         g.Gender,
+			Nationality:    g.Nationality,
+			CurrentAddress: g.CurrentAddress,
+			Email:          g.Email,
+		}
+		if dob, err := time.Parse("2006-01-02", g.DateOfBirth); err == nil {
+			m.DateOfBirth = &dob
+		}
+		if d, ok := byGuest[i]; ok {
+			m.IDType = d.IDType
+			m.IDNumber = d.IDNumber
+			m.IDIssuedCountry = d.IDIssuedCountry
+			m.DocumentImagePath = d.DocumentImagePath
+			m.FaceImagePath = d.FaceImagePath
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func draftState(bi models.BookingInfo, draft models.CheckInDraft) CheckInDraftState {
+	completed := splitSteps(draft.CompletedSteps)
+	missing := []string{}
+	for _, step := range requiredDraftSteps {
+		if !hasStep(completed, step) {
+			missing = append(missing, step)
+		}
+	}
+
+	state := CheckInDraftState{
+		BookingInfoID:  bi.ID,
+		BookingID:      bi.BookingID,
+		Steps:          DraftSteps,
+		CurrentStep:    draft.CurrentStep,
+		CompletedSteps: completed,
+		MissingSteps:   missing,
+		Guests:         decodeDraftGuests(draft.Guests),
+		Documents:      []DraftDocument{},
+		Consents:       []DraftConsent{},
+		ArrivalTime:    draft.ArrivalTime,
+		SubmittedAt:    draft.SubmittedAt,
+		UpdatedAt:      draft.UpdatedAt,
+	}
+	if len(draft.Documents) > 0 {
+		_ = json.Unmarshal(draft.Documents, &state.Documents)
+	}
+	if len(draft.Consents) > 0 {
+		_ = json.Unmarshal(draft.Consents, &state.Consents)
+	}
+	return state
+}
+
+func decodeDraftGuests(raw datatypes.JSON) []DraftGuest {
+	guests := []DraftGuest{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &guests)
+	}
+	return guests
+}
+
+func nextDraftStep(completed []string) string {
+	for _, step := range DraftSteps {
+		if !hasStep(completed, step) {
+			return step
+		}
+	}
+	return DraftStepArrival
+}
+
+func isDraftStep(step string) bool {
+	for _, s := range DraftSteps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+func splitSteps(raw string) []string {
+	out := []string{}
+	for _, p := range strings.Split(raw, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func hasStep(steps []string, step string) bool {
+	for _, s := range steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+func addStep(steps []string, step string) []string {
+	if hasStep(steps, step) {
+		return steps
+	}
+	// เก็บตามลำดับของ DraftSteps
+	out := []string{}
+	for _, s := range DraftSteps {
+		if s == step || hasStep(steps, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func removeStep(steps []string, step string) []string {
+	out := make([]string, 0, len(steps))
+	for _, s := range steps {
+		if s != step {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func mustJSON(v interface{}) datatypes.JSON {
+	b, _ := json.Marshal(v)
+	return datatypes.JSON(b)
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CheckInSessionConfig กำหนดอายุ token / code และจำนวน session ที่เปิดพร้อมกันได้ต่อ booking
+type CheckInSessionConfig struct {
+	TokenTTL          time.Duration
+	CodeTTL           time.Duration // 0 = code ไม่หมดอายุ
+	MaxActiveSessions int
+}
+
+// CheckInSessionConfigFromEnv:
+//
+//	CHECKIN_TOKEN_TTL            (default 24h)
+//	CHECKIN_CODE_TTL             (default 168h, "0" = ไม่หมดอายุ)
+//	CHECKIN_CODE_NEVER_EXPIRE    (true = ไม่หมดอายุ, คงไว้เพื่อความเข้ากันได้)
+//	CHECKIN_MAX_ACTIVE_SESSIONS  (default 1)
+func CheckInSessionConfigFromEnv() CheckInSessionConfig {
+	cfg := CheckInSessionConfig{
+		TokenTTL:          envDuration("CHECKIN_TOKEN_TTL", 24*time.Hour),
+		CodeTTL:           envDuration("CHECKIN_CODE_TTL", 7*24*time.Hour),
+		MaxActiveSessions: 1,
+	}
+	if strings.EqualFold(utils.EnvOrDefault("CHECKIN_CODE_NEVER_EXPIRE", "false"), "true") {
+		cfg.CodeTTL = 0
+	}
+	if n, err := strconv.Atoi(utils.EnvOrDefault("CHECKIN_MAX_ACTIVE_SESSIONS", "1")); err == nil && n > 0 {
+		cfg.MaxActiveSessions = n
+	}
+	if cfg.TokenTTL <= 0 {
+		cfg.TokenTTL = 24 * time.Hour
+	}
+	return cfg
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	raw := strings.TrimSpace(utils.EnvOrDefault(key, ""))
+	if raw == "" {
+		return def
+	}
+	if raw == "0" {
+		return 0
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("⚠️  invalid %s=%q, using %s", key, raw, def)
+		return def
+	}
+	return d
+}
+
+// CheckInSessionService เป็นจุดเดียวที่สร้าง BookingInfo (token + check-in code) และส่งลิงก์เช็คอิน
+type CheckInSessionService struct {
+	DB       *gorm.DB
+	Config   CheckInSessionConfig
+	Notifier *NotificationService
+}
+
+func NewCheckInSessionService(db *gorm.DB, notifier *NotificationService) *CheckInSessionService {
+	if notifier == nil {
+		notifier = NewNotificationService(db)
+	}
+	return &CheckInSessionService{DB: db, Config: CheckInSessionConfigFromEnv(), Notifier: notifier}
+}
+
+// Initiate ตรวจ booking, สร้าง BookingInfo ใหม่ แล้วส่งลิงก์ตามช่องทางของ booking
+//   - ถ้ามี session ที่ยังใช้งานได้ครบ MaxActiveSessions แล้ว คืน session ล่าสุด + ErrCheckinAlreadyInitiated
+//   - ถ้าส่งบางช่องทางไม่สำเร็จ คืน BookingInfo ที่สร้างแล้ว + error "notification_send_failed"
+func (s *CheckInSessionService) Initiate(bookingID uint) (models.BookingInfo, error) {
+	return s.initiate(bookingID, s.Config.MaxActiveSessions)
+}
+
+// InitiateOnce เหมือน Initiate แต่เปิดได้เมื่อยังไม่มี session ที่ใช้ได้เลย (งาน scheduler)
+// scheduler หลาย instance รันพร้อมกันจึงส่งลิงก์ให้ booking เดียวกันได้ครั้งเดียว
+func (s *CheckInSessionService) InitiateOnce(bookingID uint) (models.BookingInfo, error) {
+	return s.initiate(bookingID, 1)
+}
+
+func (s *CheckInSessionService) initiate(bookingID uint, maxActive int) (models.BookingInfo, error) {
+	var booking models.Booking
+	if err := s.DB.Preload("Rooms.Room.RoomType").Preload("Room.RoomType").Preload("Customer").
+		First(&booking, bookingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.BookingInfo{}, ErrBookingNotFound
+		}
+		return models.BookingInfo{}, fmt.Errorf("failed to find booking: %w", err)
+	}
+
+	if err := validateForCheckIn(booking); err != nil {
+		return models.BookingInfo{}, err
+	}
+
+	// lock แถว booking ระหว่างนับ session กับสร้างใหม่ ไม่ให้ request พร้อมกันเปิดเกิน maxActive
+	var bookingInfo, active models.BookingInfo
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockBookingRow(tx, bookingID); err != nil {
+			return err
+		}
+		latest, n, err := s.activeSessions(tx, bookingID)
+		if err != nil {
+			return err
+		}
+		if n >= maxActive {
+			active = latest
+			return ErrCheckinAlreadyInitiated
+		}
+		bookingInfo, err = s.createSession(tx, booking)
+		return err
+	})
+	if errors.Is(err, ErrCheckinAlreadyInitiated) {
+		return active, err
+	}
+	if err != nil {
+		return models.BookingInfo{}, err
+	}
+
+	if _, sendErr := s.Notifier.SendCheckInInvite(booking, bookingInfo, checkInInviteData(s.DB, booking, bookingInfo)); sendErr != nil {
+		return bookingInfo, sendErr
+	}
+	return bookingInfo, nil
+}
+
+// OpenOnSite คืน session ที่ยังใช้ได้ของ booking หรือสร้างใหม่โดยไม่ส่งลิงก์ (เช็คอินที่ kiosk / หน้าเคาน์เตอร์)
+// ไม่ต้องมีอีเมล/เบอร์โทรของลูกค้า เพราะแขกอยู่ที่โรงแรมแล้ว
+func (s *CheckInSessionService) OpenOnSite(bookingID uint) (models.BookingInfo, error) {
+	var booking models.Booking
+	if err := s.DB.Preload("Rooms.Room").Preload("Room").Preload("Customer").
+		First(&booking, bookingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.BookingInfo{}, ErrBookingNotFound
+		}
+		return models.BookingInfo{}, fmt.Errorf("failed to find booking: %w", err)
+	}
+
+	if err := validateForCheckIn(booking); err != nil && !errors.Is(err, ErrCustomerContactMissing) {
+		return models.BookingInfo{}, err
+	}
+
+	var bookingInfo models.BookingInfo
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockBookingRow(tx, bookingID); err != nil {
+			return err
+		}
+		active, n, err := s.activeSessions(tx, bookingID)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			bookingInfo = active
+			return nil
+		}
+		bookingInfo, err = s.createSession(tx, booking)
+		return err
+	})
+	if err != nil {
+		return models.BookingInfo{}, err
+	}
+	return bookingInfo, nil
+}
+
+// lockBookingRow lock แถว booking (SELECT ... FOR UPDATE) จนจบ transaction
+func lockBookingRow(tx *gorm.DB, bookingID uint) error {
+	var locked models.Booking
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, bookingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBookingNotFound
+		}
+		return fmt.Errorf("failed to lock booking: %w", err)
+	}
+	return nil
+}
+
+func validateForCheckIn(booking models.Booking) error {
+	status := strings.ToLower(strings.TrimSpace(booking.Status))
+	switch {
+	case booking.Customer.ID == 0:
+		return ErrBookingMissingCustomer
+	case len(booking.Rooms) == 0 && booking.Room.ID == 0:
+		return ErrBookingMissingRoom
+	case booking.CheckedInAt != nil,
+		status == "checked-in", status == "checkedin", status == "checked in":
+		return ErrAlreadyCheckedIn
+	case status == "checked-out":
+		return ErrBookingCheckedOut
+	case !hasContactFor(booking.Customer, ParseChannels(booking.NotificationChannels)):
+		return ErrCustomerContactMissing
+	}
+	return nil
+}
+
+// activeSessions คืน session ล่าสุดที่ยังใช้ได้ และจำนวน session ที่ยังใช้ได้ทั้งหมด
+func (s *CheckInSessionService) activeSessions(db *gorm.DB, bookingID uint) (models.BookingInfo, int, error) {
+	now := time.Now().UTC()
+	q := db.Model(&models.BookingInfo{}).
+		Where("booking_id = ?", bookingID).
+		Where("status NOT IN ?", []string{"EXPIRED", "COMPLETED", "REVOKED"}).
+		Where("(expires_at IS NULL OR expires_at > ?)", now)
+
+	var count int64
+	if err := q.Count(&count).Error; err != nil {
+		return models.BookingInfo{}, 0, fmt.Errorf("failed to check existing booking info: %w", err)
+	}
+	if count == 0 {
+		return models.BookingInfo{}, 0, nil
+	}
+
+	var latest models.BookingInfo
+	if err := q.Order("id DESC").First(&latest).Error; err != nil {
+		return models.BookingInfo{}, 0, fmt.Errorf("failed to load existing booking info: %w", err)
+	}
+	return latest, int(count), nil
+}
+
+// createSession สร้าง BookingInfo (retry เมื่อ token/code ชน unique index)
+func (s *CheckInSessionService) createSession(db *gorm.DB, booking models.Booking) (models.BookingInfo, error) {
+	const maxRetries = 5
+	var createErr error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		token, err := utils.GenerateSecureToken(32)
+		if err != nil {
+			return models.BookingInfo{}, fmt.Errorf("failed to generate token: %w", err)
+		}
+		raw, err := utils.GenerateCheckinCode(8)
+		if err != nil {
+			return models.BookingInfo{}, fmt.Errorf("failed to generate checkin code: %w", err)
+		}
+		code, err := utils.GenerateFormattedCheckinCode(raw)
+		if err != nil {
+			return models.BookingInfo{}, fmt.Errorf("failed to format checkin code: %w", err)
+		}
+
+		now := time.Now().UTC()
+		expiresAt := now.Add(s.Config.TokenTTL)
+		var codeExpiresAt *time.Time
+		if s.Config.CodeTTL > 0 {
+			t := now.Add(s.Config.CodeTTL)
+			codeExpiresAt = &t
+		}
+
+		bookingInfo := models.BookingInfo{
+			BookingID:     booking.ID,
+			Token:         token,
+			CheckinCode:   code,
+			Status:        "INITIATED",
+			EmailStatus:   DeliveryPending,
+			ExpiresAt:     &expiresAt,
+			CodeExpiresAt: codeExpiresAt,
+			GuestEmail:    booking.Customer.Email,
+			GuestLastName: booking.Customer.FullName,
+		}
+
+		createErr = db.Create(&bookingInfo).Error
+		if createErr == nil {
+			return bookingInfo, nil
+		}
+
+		lc := strings.ToLower(createErr.Error())
+		if strings.Contains(lc, "duplicate") || strings.Contains(lc, "unique") || strings.Contains(lc, "constraint") {
+			log.Printf("create booking_info collision (attempt %d) - retrying", attempt+1)
+			continue
+		}
+		return models.BookingInfo{}, fmt.Errorf("failed to create booking info: %w", createErr)
+	}
+	return models.BookingInfo{}, fmt.Errorf("failed to create booking info after retries: %w", createErr)
+}
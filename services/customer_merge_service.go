@@ -0,0 +1,575 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"hotel-backend/models"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCustomerMergeSelf  = NewError(KindInvalid, "error.customerMergeSelf")
+	ErrCustomerMergeEmpty = NewError(KindInvalid, "error.customerMergeEmpty")
+)
+
+// คะแนนความน่าจะซ้ำ (รวมกันแล้วไม่เกิน 100)
+const (
+	DuplicateScoreIDNumber = 60
+	DuplicateScoreEmail    = 50
+	DuplicateScorePhone    = 40
+	DuplicateScoreName     = 30 // × ความคล้ายของชื่อ
+
+	// ชื่อคล้ายกันอย่างเดียว (ไม่มี email / phone / เลขเอกสารตรงกัน) ต้องคล้ายอย่างน้อยเท่านี้
+	duplicateNameOnlySimilarity = 0.92
+	duplicateNameSimilarity     = 0.85
+	// กลุ่มชื่อที่ใหญ่กว่านี้ (ชื่อที่พบบ่อยมาก) ไม่นำมาเทียบแบบคู่ต่อคู่
+	duplicateNameBlockMax = 200
+)
+
+// DuplicateReason เหตุผลที่จับคู่ลูกค้าว่าอาจซ้ำ
+type DuplicateReason struct {
+	Field      string  `json:"field"` // email | phone | idNumber | name
+	Value      string  `json:"value,omitempty"`
+	Similarity float64 `json:"similarity,omitempty"`
+}
+
+// DuplicateCandidate ลูกค้าหนึ่งคู่ที่อาจเป็นคนเดียวกัน (Customers[0].ID < Customers[1].ID)
+type DuplicateCandidate struct {
+	Customers []models.Customer `json:"customers"`
+	Score     int               `json:"score"`
+	Reasons   []DuplicateReason `json:"reasons"`
+}
+
+// DuplicateQuery ตัวกรองรายการลูกค้าซ้ำ
+type DuplicateQuery struct {
+	CustomerID uint // เฉพาะคู่ที่มีลูกค้ารายนี้ (0 = ทั้งหมด)
+	MinScore   int
+	Limit      int
+}
+
+type customerPair struct{ a, b uint }
+
+func newCustomerPair(x, y uint) customerPair {
+	if x > y {
+		x, y = y, x
+	}
+	return customerPair{x, y}
+}
+
+// FindDuplicates หาคู่ลูกค้าที่อาจซ้ำจาก email / เบอร์โทร / เลขเอกสารของผู้เข้าพักหลัก (จากการจองเดิม) / ชื่อคล้ายกัน
+// เรียงคะแนนมากไปน้อย คู่ที่ staff เคย dismiss แล้วจะไม่แสดง
+func (s *CustomerService) FindDuplicates(q DuplicateQuery) ([]DuplicateCandidate, error) {
+	var customers []models.Customer
+	if err := s.DB.Select("id", "full_name", "email", "phone").Find(&customers).Error; err != nil {
+		return nil, fmt.Errorf("load customers: %w", err)
+	}
+
+	var idRows []struct {
+		CustomerID uint
+		IDNumber   string
+	}
+	if err := s.DB.Table("guests").
+		Select("DISTINCT bookings.customer_id, guests.id_number").
+		Joins("JOIN bookings ON bookings.id = guests.booking_id AND bookings.deleted_at IS NULL").
+		Where("guests.is_main_guest = ? AND guests.id_number <> '' AND bookings.customer_id > 0", true).
+		Scan(&idRows).Error; err != nil {
+		return nil, fmt.Errorf("load guest id numbers: %w", err)
+	}
+
+	var dismissed []models.CustomerDuplicateDismissal
+	if err := s.DB.Find(&dismissed).Error; err != nil {
+		return nil, fmt.Errorf("load dismissed duplicates: %w", err)
+	}
+	skip := make(map[customerPair]bool, len(dismissed))
+	for _, d := range dismissed {
+		skip[newCustomerPair(d.CustomerAID, d.CustomerBID)] = true
+	}
+
+	reasons := map[customerPair][]DuplicateReason{}
+	addGroup := func(field string, groups map[string][]uint) {
+		for value, ids := range groups {
+			for i := 0; i < len(ids); i++ {
+				for j := i + 1; j < len(ids); j++ {
+					if ids[i] == ids[j] {
+						continue
+					}
+					p := newCustomerPair(ids[i], ids[j])
+					if !hasReason(reasons[p], field) {
+						reasons[p] = append(reasons[p], DuplicateReason{Field: field, Value: value})
+					}
+				}
+			}
+		}
+	}
+
+	byEmail, byPhone, byID := map[string][]uint{}, map[string][]uint{}, map[string][]uint{}
+	names := map[uint]string{}
+	blocks := map[string][]uint{}
+	for _, c := range customers {
+		if e := normalizeEmail(c.Email); e != "" {
+			byEmail[e] = append(byEmail[e], c.ID)
+		}
+		if p := normalizePhone(c.Phone); p != "" {
+			byPhone[p] = append(byPhone[p], c.ID)
+		}
+		if n := normalizePersonName(c.FullName); n != "" {
+			names[c.ID] = n
+			seen := map[string]bool{}
+			for _, tok := range strings.Fields(n) {
+				r := []rune(tok)
+				if len(r) < 3 || seen[string(r[:3])] {
+					continue
+				}
+				seen[string(r[:3])] = true
+				blocks[string(r[:3])] = append(blocks[string(r[:3])], c.ID)
+			}
+		}
+	}
+	for _, row := range idRows {
+		if n := normalizeIDNumber(row.IDNumber); n != "" {
+			byID[n] = append(byID[n], row.CustomerID)
+		}
+	}
+	addGroup("email", byEmail)
+	addGroup("phone", byPhone)
+	addGroup("idNumber", byID)
+
+	compared := map[customerPair]bool{}
+	for _, ids := range blocks {
+		if len(ids) > duplicateNameBlockMax {
+			continue
+		}
+		for i := 0; i < len(ids); i++ {
+			for j := i + 1; j < len(ids); j++ {
+				p := newCustomerPair(ids[i], ids[j])
+				if compared[p] {
+					continue
+				}
+				compared[p] = true
+				sim := nameSimilarity(names[p.a], names[p.b])
+				if sim < duplicateNameSimilarity || (len(reasons[p]) == 0 && sim < duplicateNameOnlySimilarity) {
+					continue
+				}
+				reasons[p] = append(reasons[p], DuplicateReason{Field: "name", Similarity: roundTo(sim, 2)})
+			}
+		}
+	}
+	// ชื่อตรงกันทุกตัวอักษรแต่ไม่มี token ยาวพอเข้า block (เช่น ชื่อสั้น ๆ)
+	byName := map[string][]uint{}
+	for id, n := range names {
+		byName[n] = append(byName[n], id)
+	}
+	for _, ids := range byName {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		for i := 0; i < len(ids); i++ {
+			for j := i + 1; j < len(ids); j++ {
+				p := newCustomerPair(ids[i], ids[j])
+				if !hasReason(reasons[p], "name") {
+					reasons[p] = append(reasons[p], DuplicateReason{Field: "name", Similarity: 1})
+				}
+			}
+		}
+	}
+
+	var out []DuplicateCandidate
+	for p, rs := range reasons {
+		if skip[p] || (q.CustomerID != 0 && p.a != q.CustomerID && p.b != q.CustomerID) {
+			continue
+		}
+		score := duplicateScore(rs)
+		if score < q.MinScore {
+			continue
+		}
+		sort.Slice(rs, func(i, j int) bool { return rs[i].Field < rs[j].Field })
+		out = append(out, DuplicateCandidate{
+			Customers: []models.Customer{{ID: p.a}, {ID: p.b}},
+			Score:     score,
+			Reasons:   rs,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		if out[i].Customers[0].ID != out[j].Customers[0].ID {
+			return out[i].Customers[0].ID < out[j].Customers[0].ID
+		}
+		return out[i].Customers[1].ID < out[j].Customers[1].ID
+	})
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	if len(out) == 0 {
+		return []DuplicateCandidate{}, nil
+	}
+
+	// โหลดข้อมูลลูกค้าเต็มเฉพาะคู่ที่จะส่งกลับ
+	idSet := map[uint]bool{}
+	for _, d := range out {
+		idSet[d.Customers[0].ID] = true
+		idSet[d.Customers[1].ID] = true
+	}
+	var full []models.Customer
+	if err := s.DB.Where("id IN ?", mapKeySlice(idSet)).Find(&full).Error; err != nil {
+		return nil, fmt.Errorf("load duplicate customers: %w", err)
+	}
+	byCustomerID := make(map[uint]models.Customer, len(full))
+	for _, c := range full {
+		byCustomerID[c.ID] = c
+	}
+	for i := range out {
+		out[i].Customers[0] = byCustomerID[out[i].Customers[0].ID]
+		out[i].Customers[1] = byCustomerID[out[i].Customers[1].ID]
+	}
+	return out, nil
+}
+
+// DismissDuplicate บันทึกว่าลูกค้าสองรายนี้ไม่ใช่คนเดียวกัน
+func (s *CustomerService) DismissDuplicate(a, b uint, adminID *uint) error {
+	if a == b {
+		return ErrCustomerMergeSelf
+	}
+	for _, id := range []uint{a, b} {
+		if _, err := s.GetByID(id); err != nil {
+			return err
+		}
+	}
+	p := newCustomerPair(a, b)
+	row := models.CustomerDuplicateDismissal{CustomerAID: p.a, CustomerBID: p.b, DismissedByID: adminID}
+	return s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error
+}
+
+// Merge รวมลูกค้าใน mergedIDs เข้ากับ survivorID ใน transaction เดียว:
+// ย้ายการจองมาที่ survivor (ผู้เข้าพักและ consent log ผูกกับการจอง จึงตามมาด้วย และถูกบันทึกไว้ในประวัติ),
+// เติม field ที่ survivor ยังว่าง, รวม tags / notes, แล้ว soft delete ลูกค้าที่ถูกรวม
+func (s *CustomerService) Merge(survivorID uint, mergedIDs []uint, reason string, actor AdminActor) (models.Customer, []models.CustomerMerge, error) {
+	var survivor models.Customer
+	var history []models.CustomerMerge
+
+	ids := uniqueIDs(mergedIDs)
+	if len(ids) == 0 {
+		return survivor, nil, ErrCustomerMergeEmpty
+	}
+	for _, id := range ids {
+		if id == survivorID {
+			return survivor, nil, ErrCustomerMergeSelf
+		}
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockCustomer(tx, survivorID, &survivor); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			var merged models.Customer
+			if err := lockCustomer(tx, id, &merged); err != nil {
+				return err
+			}
+			h, err := mergeCustomerInto(tx, &survivor, merged)
+			if err != nil {
+				return err
+			}
+			if actor.AdminID != 0 {
+				h.MergedByID = &actor.AdminID
+			}
+			h.MergedByName = actor.AdminName
+			h.Reason = strings.TrimSpace(reason)
+			if err := tx.Create(&h).Error; err != nil {
+				return fmt.Errorf("record customer merge: %w", err)
+			}
+			history = append(history, h)
+		}
+		return tx.Save(&survivor).Error
+	})
+	if err != nil {
+		return survivor, nil, err
+	}
+
+	for _, h := range history {
+		RecordAudit(s.DB, AuditEntry{
+			ActorType: ActorAdmin,
+			ActorID:   fmt.Sprint(actor.AdminID),
+			Action:    "customer.merge",
+			Target:    fmt.Sprintf("customer:%d", survivorID),
+			IP:        actor.IP,
+			Details: map[string]interface{}{
+				"mergeId":    h.ID,
+				"mergedId":   h.MergedID,
+				"bookingIds": h.BookingIDs,
+				"reason":     h.Reason,
+			},
+		})
+	}
+	return survivor, history, nil
+}
+
+func lockCustomer(tx *gorm.DB, id uint, dest *models.Customer) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(dest, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCustomerNotFound.WithDetails(map[string]interface{}{"customerId": id})
+	}
+	return err
+}
+
+// mergeCustomerInto ย้ายข้อมูลของ merged มาที่ survivor (ยังไม่ Save survivor)
+func mergeCustomerInto(tx *gorm.DB, survivor *models.Customer, merged models.Customer) (models.CustomerMerge, error) {
+	h := models.CustomerMerge{
+		SurvivorID:     survivor.ID,
+		MergedID:       merged.ID,
+		SurvivorBefore: customerSnapshot(*survivor),
+		MergedBefore:   customerSnapshot(merged),
+		BookingIDs:     datatypes.JSONSlice[uint]{},
+		GuestIDs:       datatypes.JSONSlice[uint]{},
+		ConsentLogIDs:  datatypes.JSONSlice[uint]{},
+		FieldsFilled:   datatypes.JSONSlice[string]{},
+	}
+
+	var bookingIDs []uint
+	if err := tx.Model(&models.Booking{}).Unscoped().Where("customer_id = ?", merged.ID).Pluck("id", &bookingIDs).Error; err != nil {
+		return h, err
+	}
+	if len(bookingIDs) > 0 {
+		if err := tx.Model(&models.Booking{}).Unscoped().Where("id IN ?", bookingIDs).
+			Update("customer_id", survivor.ID).Error; err != nil {
+			return h, fmt.Errorf("repoint bookings: %w", err)
+		}
+		var guestIDs, consentIDs []uint
+		if err := tx.Model(&models.Guest{}).Where("booking_id IN ?", bookingIDs).Pluck("id", &guestIDs).Error; err != nil {
+			return h, err
+		}
+		cq := tx.Model(&models.ConsentLog{}).Unscoped().Where("booking_id IN ?", bookingIDs)
+		if len(guestIDs) > 0 {
+			cq = cq.Or("guest_id IN ?", guestIDs)
+		}
+		if err := cq.Pluck("id", &consentIDs).Error; err != nil {
+			return h, err
+		}
+		h.BookingIDs = append(h.BookingIDs, bookingIDs...)
+		h.GuestIDs = append(h.GuestIDs, guestIDs...)
+		h.ConsentLogIDs = append(h.ConsentLogIDs, consentIDs...)
+	}
+
+	fill := func(field string, dst *string, v string) {
+		if strings.TrimSpace(*dst) == "" && strings.TrimSpace(v) != "" {
+			*dst = v
+			h.FieldsFilled = append(h.FieldsFilled, field)
+		}
+	}
+	fill("fullName", &survivor.FullName, merged.FullName)
+	fill("email", &survivor.Email, merged.Email)
+	fill("phone", &survivor.Phone, merged.Phone)
+	fill("lineUserId", &survivor.LineUserID, merged.LineUserID)
+	fill("preferredLanguage", &survivor.PreferredLanguage, merged.PreferredLanguage)
+	fill("nationality", &survivor.Nationality, merged.Nationality)
+	fill("address", &survivor.Address, merged.Address)
+	if n := strings.TrimSpace(merged.Notes); n != "" && !strings.Contains(survivor.Notes, n) {
+		if strings.TrimSpace(survivor.Notes) == "" {
+			survivor.Notes = n
+		} else {
+			survivor.Notes = strings.TrimSpace(survivor.Notes) + "\n" + n
+		}
+		h.FieldsFilled = append(h.FieldsFilled, "notes")
+	}
+	if len(merged.Tags) > 0 {
+		before := len(survivor.Tags)
+		survivor.Tags = normalizeTags(append(append([]string{}, survivor.Tags...), merged.Tags...))
+		if len(survivor.Tags) != before {
+			h.FieldsFilled = append(h.FieldsFilled, "tags")
+		}
+	}
+
+	// ลูกค้าที่เคยถูกรวมเข้ากับ merged ให้ชี้มาที่ survivor แทน
+	if err := tx.Model(&models.Customer{}).Unscoped().Where("merged_into_id = ?", merged.ID).
+		Update("merged_into_id", survivor.ID).Error; err != nil {
+		return h, err
+	}
+	if err := tx.Where("customer_a_id = ? OR customer_b_id = ?", merged.ID, merged.ID).
+		Delete(&models.CustomerDuplicateDismissal{}).Error; err != nil {
+		return h, err
+	}
+	if err := tx.Model(&merged).Update("merged_into_id", survivor.ID).Error; err != nil {
+		return h, err
+	}
+	if err := tx.Delete(&merged).Error; err != nil {
+		return h, fmt.Errorf("delete merged customer %d: %w", merged.ID, err)
+	}
+	return h, nil
+}
+
+// MergeHistory ประวัติการรวมที่เกี่ยวกับลูกค้ารายนี้ (ทั้งฝั่ง survivor และฝั่งที่ถูกรวม) ใหม่สุดก่อน
+func (s *CustomerService) MergeHistory(customerID uint) ([]models.CustomerMerge, error) {
+	list := []models.CustomerMerge{}
+	err := s.DB.Where("survivor_id = ? OR merged_id = ?", customerID, customerID).
+		Order("id DESC").Find(&list).Error
+	return list, err
+}
+
+func customerSnapshot(c models.Customer) string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// ---------------- normalization ----------------
+
+// normalizeEmail ตัวพิมพ์เล็ก ตัด +tag ของ local part
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return ""
+	}
+	local, domain := email[:at], email[at+1:]
+	if i := strings.Index(local, "+"); i > 0 {
+		local = local[:i]
+	}
+	return local + "@" + domain
+}
+
+// normalizePhone เหลือแต่ตัวเลข และแปลง +66 เป็น 0 (เบอร์ไทย)
+func normalizePhone(phone string) string {
+	d := digitsOnly(phone)
+	if strings.HasPrefix(d, "66") && len(d) == 11 {
+		d = "0" + d[2:]
+	}
+	if len(d) < 8 {
+		return ""
+	}
+	return d
+}
+
+// normalizeIDNumber ตัวพิมพ์ใหญ่ เหลือแต่ตัวอักษร/ตัวเลข
+func normalizeIDNumber(id string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(id) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() < 5 {
+		return ""
+	}
+	return b.String()
+}
+
+var personTitles = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "miss": true, "dr": true,
+	"นาย": true, "นาง": true, "นางสาว": true, "น.ส.": true,
+}
+
+// normalizePersonName ตัวพิมพ์เล็ก ตัดคำนำหน้า เรียง token (ชื่อ-นามสกุลสลับที่กันได้)
+func normalizePersonName(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == '-'
+	})
+	tokens := fields[:0]
+	for _, f := range fields {
+		if personTitles[strings.TrimSuffix(f, ".")] || personTitles[f] {
+			continue
+		}
+		tokens = append(tokens, f)
+	}
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+// nameSimilarity 1 - (edit distance / ความยาวที่ยาวกว่า) เทียบทีละ rune
+func nameSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func duplicateScore(rs []DuplicateReason) int {
+	score := 0.0
+	for _, r := range rs {
+		switch r.Field {
+		case "idNumber":
+			score += DuplicateScoreIDNumber
+		case "email":
+			score += DuplicateScoreEmail
+		case "phone":
+			score += DuplicateScorePhone
+		case "name":
+			score += DuplicateScoreName * r.Similarity
+		}
+	}
+	if score > 100 {
+		score = 100
+	}
+	return int(score + 0.5)
+}
+
+func hasReason(rs []DuplicateReason, field string) bool {
+	for _, r := range rs {
+		if r.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func roundTo(v float64, places int) float64 {
+	p := 1.0
+	for i := 0; i < places; i++ {
+		p *= 10
+	}
+	return float64(int(v*p+0.5)) / p
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := map[uint]bool{}
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
+
+func mapKeySlice(m map[uint]bool) []uint {
+	out := make([]uint, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
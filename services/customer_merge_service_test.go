@@ -0,0 +1,83 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDuplicateNormalization(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(string) string
+		in   string
+		want string
+	}{
+		{"email lowercases", normalizeEmail, "  Anna.Brown@Example.COM ", "anna.brown@example.com"},
+		{"email drops +tag", normalizeEmail, "anna+booking@example.com", "anna@example.com"},
+		{"email keeps leading +", normalizeEmail, "+anna@example.com", "+anna@example.com"},
+		{"email without @", normalizeEmail, "anna.example.com", ""},
+		{"email without local part", normalizeEmail, "@example.com", ""},
+
+		{"phone digits only", normalizePhone, "081-234 5678", "0812345678"},
+		{"phone +66 to 0", normalizePhone, "+66 81 234 5678", "0812345678"},
+		{"phone foreign number kept", normalizePhone, "+44 20 7946 0958", "442079460958"},
+		{"phone too short", normalizePhone, "12-34", ""},
+
+		{"id number uppercases and strips", normalizeIDNumber, "ab 123-456 c", "AB123456C"},
+		{"id number thai id", normalizeIDNumber, "1-2345-67890-12-3", "1234567890123"},
+		{"id number too short", normalizeIDNumber, "a-1-2", ""},
+
+		{"name lowercases and sorts", normalizePersonName, "Brown Anna", "anna brown"},
+		{"name swapped order", normalizePersonName, "Anna Brown", "anna brown"},
+		{"name drops english title", normalizePersonName, "Mr. John Smith", "john smith"},
+		{"name drops thai title", normalizePersonName, "นาย สมชาย ใจดี", "สมชาย ใจดี"},
+		{"name drops thai abbreviated title", normalizePersonName, "น.ส. สมหญิง ใจดี", "สมหญิง ใจดี"},
+		{"name splits comma and hyphen", normalizePersonName, "Smith-Jones, Anna", "anna jones smith"},
+		{"name empty", normalizePersonName, "   ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(tt.in); got != tt.want {
+				t.Fatalf("normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"anna brown", "anna brown", 1},
+		{"anna brown", "anna browne", 1 - 1.0/11},
+		{"สมชาย ใจดี", "สมชาย ใจดี", 1},
+		{"anna", "", 0},
+		{"abc", "xyz", 0},
+	}
+	for _, tt := range tests {
+		if got := nameSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("nameSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDuplicateScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		reasons []DuplicateReason
+		want    int
+	}{
+		{"none", nil, 0},
+		{"email only", []DuplicateReason{{Field: "email"}}, DuplicateScoreEmail},
+		{"phone and half-similar name", []DuplicateReason{{Field: "phone"}, {Field: "name", Similarity: 0.5}}, DuplicateScorePhone + DuplicateScoreName/2},
+		{"capped at 100", []DuplicateReason{{Field: "idNumber"}, {Field: "email"}, {Field: "phone"}}, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := duplicateScore(tt.reasons); got != tt.want {
+				t.Fatalf("duplicateScore = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,23 +1,281 @@
 package services
 
 import (
-	"gorm.io/gorm"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	"hotel-backend/models"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCustomerNotFound    = NewError(KindNotFound, "error.customerNotFound")
+	ErrCustomerHasBookings = NewError(KindConflict, "error.customerHasBookings")
 )
 
 type CustomerService struct {
-    DB *gorm.DB
+	DB *gorm.DB
 }
 
 // NewCustomerService Constructor สำหรับ Dependency Injection
 func NewCustomerService(db *gorm.DB) *CustomerService {
-    return &CustomerService{DB: db}
+	return &CustomerService{DB: db}
+}
+
+// CustomerInput ค่าที่แก้ได้ของลูกค้า (nil = ไม่เปลี่ยน ใช้กับ PATCH)
+type CustomerInput struct {
+	FullName          *string
+	Email             *string
+	Phone             *string
+	LineUserID        *string
+	PreferredLanguage *string
+	Nationality       *string
+	Address           *string
+	Notes             *string
+	Tags              *[]string
+}
+
+func (in CustomerInput) apply(c *models.Customer) {
+	set := func(dst *string, v *string) {
+		if v != nil {
+			*dst = strings.TrimSpace(*v)
+		}
+	}
+	set(&c.FullName, in.FullName)
+	set(&c.Email, in.Email)
+	set(&c.Phone, in.Phone)
+	set(&c.LineUserID, in.LineUserID)
+	set(&c.Nationality, in.Nationality)
+	set(&c.Address, in.Address)
+	set(&c.Notes, in.Notes)
+	if in.PreferredLanguage != nil {
+		c.PreferredLanguage = strings.ToLower(strings.TrimSpace(*in.PreferredLanguage))
+	}
+	c.Email = strings.ToLower(c.Email)
+	if in.Tags != nil {
+		c.Tags = normalizeTags(*in.Tags)
+	}
+	if c.Tags == nil {
+		c.Tags = datatypes.JSONSlice[string]{}
+	}
+}
+
+// normalizeTags ตัดช่องว่าง ตัวพิมพ์เล็ก ไม่ซ้ำ เรียงตามตัวอักษร
+func normalizeTags(tags []string) datatypes.JSONSlice[string] {
+	seen := map[string]bool{}
+	out := datatypes.JSONSlice[string]{}
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
 }
 
 // Create Customer Record (T0.1)
 // รับ Pointer เพื่อให้ GORM อัปเดต Customer.ID กลับมา
 func (s *CustomerService) Create(customer *models.Customer) error {
-    return s.DB.Create(customer).Error 
+	if customer.Tags == nil {
+		customer.Tags = datatypes.JSONSlice[string]{}
+	}
+	return s.DB.Create(customer).Error
 }
 
-// 💡 คุณสามารถเพิ่มเมธอดอื่น ๆ เช่น GetByID หรือ Update ได้ที่นี่
\ No newline at end of file
+// CreateFrom สร้างลูกค้าจาก input
+func (s *CustomerService) CreateFrom(in CustomerInput) (models.Customer, error) {
+	var c models.Customer
+	in.apply(&c)
+	err := s.Create(&c)
+	return c, err
+}
+
+func (s *CustomerService) GetByID(id uint) (models.Customer, error) {
+	var c models.Customer
+	if err := s.DB.First(&c, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// ลูกค้าที่ถูกรวมแล้ว บอก client ว่าย้ายไปรายไหน
+			var merged models.Customer
+			if s.DB.Unscoped().Select("id", "merged_into_id").First(&merged, id).Error == nil && merged.MergedIntoID != nil {
+				return c, ErrCustomerNotFound.WithDetails(map[string]interface{}{"mergedIntoId": *merged.MergedIntoID})
+			}
+			return c, ErrCustomerNotFound
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+// Update แก้เฉพาะ field ที่ส่งมา
+func (s *CustomerService) Update(id uint, in CustomerInput) (models.Customer, error) {
+	c, err := s.GetByID(id)
+	if err != nil {
+		return c, err
+	}
+	in.apply(&c)
+	if err := s.DB.Save(&c).Error; err != nil {
+		return c, fmt.Errorf("update customer %d: %w", id, err)
+	}
+	return c, nil
+}
+
+// Delete soft delete; ลูกค้าที่ยังมีการจองที่ไม่เช็คเอาท์ลบไม่ได้
+func (s *CustomerService) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	var open int64
+	if err := s.DB.Model(&models.Booking{}).
+		Where("customer_id = ? AND (status IS NULL OR status <> ?)", id, "Checked-Out").
+		Count(&open).Error; err != nil {
+		return err
+	}
+	if open > 0 {
+		return ErrCustomerHasBookings.WithDetails(map[string]interface{}{"openBookings": open})
+	}
+	return s.DB.Delete(&models.Customer{}, id).Error
+}
+
+// CustomerListSpec: GET /api/customers
+var CustomerListSpec = ListSpec{
+	IDColumn: "customers.id",
+	Sorts: map[string]string{
+		"id":        "customers.id",
+		"fullName":  "customers.full_name",
+		"email":     "customers.email",
+		"createdAt": "customers.created_at",
+	},
+	DefaultSort: "-id",
+	DateColumn:  "customers.created_at",
+	Filters: map[string]ListFilter{
+		"q": {
+			Cond: "(customers.full_name LIKE CONCAT('%', @v, '%') OR customers.email LIKE CONCAT('%', @v, '%') OR customers.phone LIKE CONCAT('%', @v, '%'))",
+			Kind: FilterText,
+		},
+		"nationality":       {Cond: "customers.nationality = ?", Kind: FilterText},
+		"preferredLanguage": {Cond: "customers.preferred_language = ?", Kind: FilterText},
+		"tag":               {Cond: "JSON_CONTAINS(customers.tags, JSON_QUOTE(LOWER(?)))", Kind: FilterText},
+	},
+}
+
+func (s *CustomerService) List(q ListQuery) ([]models.Customer, PageInfo, error) {
+	list := []models.Customer{}
+	page, err := FindPage(s.DB, CustomerListSpec, q, &list)
+	if err != nil {
+		return nil, page, fmt.Errorf("failed to retrieve customers: %w", err)
+	}
+	return list, page, nil
+}
+
+// ---------------- detail ----------------
+
+// CustomerStats สรุปการเข้าพักของลูกค้า
+// ยอดใช้จ่ายเป็นค่าประมาณจากราคาห้อง × จำนวนคืน (ยังไม่มีระบบ folio)
+type CustomerStats struct {
+	TotalBookings    int        `json:"totalBookings"`
+	Stays            int        `json:"stays"` // การจองที่เช็คอินแล้ว (รวมที่เช็คเอาท์)
+	Nights           int        `json:"nights"`
+	UpcomingBookings int        `json:"upcomingBookings"`
+	TotalSpend       float64    `json:"totalSpend"`
+	FirstStayAt      *time.Time `json:"firstStayAt,omitempty"`
+	LastStayAt       *time.Time `json:"lastStayAt,omitempty"`
+}
+
+type CustomerBookingSummary struct {
+	ID            uint       `json:"id"`
+	ReferenceCode string     `json:"referenceCode"`
+	Status        string     `json:"status"`
+	CheckIn       *time.Time `json:"checkIn,omitempty"`
+	CheckOut      *time.Time `json:"checkOut,omitempty"`
+	Nights        int        `json:"nights"`
+	Rooms         []string   `json:"rooms"`
+	Amount        float64    `json:"amount"`
+}
+
+type CustomerDetail struct {
+	Customer models.Customer          `json:"customer"`
+	Stats    CustomerStats            `json:"stats"`
+	Bookings []CustomerBookingSummary `json:"bookings"`
+}
+
+// Detail ลูกค้า + สถิติการเข้าพัก + การจองทั้งหมด (ใหม่สุดก่อน)
+func (s *CustomerService) Detail(id uint) (CustomerDetail, error) {
+	out := CustomerDetail{Bookings: []CustomerBookingSummary{}}
+	c, err := s.GetByID(id)
+	if err != nil {
+		return out, err
+	}
+	out.Customer = c
+
+	var bookings []models.Booking
+	if err := s.DB.Preload("Rooms.Room").Preload("Room").
+		Where("customer_id = ?", id).
+		Order("check_in DESC, id DESC").
+		Find(&bookings).Error; err != nil {
+		return out, fmt.Errorf("load bookings of customer %d: %w", id, err)
+	}
+
+	now := time.Now()
+	for _, b := range bookings {
+		sum := CustomerBookingSummary{
+			ID: b.ID, ReferenceCode: b.ReferenceCode, Status: b.Status,
+			CheckIn: b.CheckIn, CheckOut: b.CheckOut, Nights: bookingNights(b), Rooms: []string{},
+		}
+		// ยอดเงินคิดทุก segment ตามคืนที่พักจริง แต่รายชื่อห้องแสดงเฉพาะห้องปัจจุบัน (ไม่รวมห้องที่ย้ายออก)
+		for _, br := range b.Rooms {
+			nights := br.Nights
+			if nights <= 0 && br.Status != BookingRoomStatusMoved {
+				nights = sum.Nights
+			}
+			sum.Amount += br.Room.Price * float64(nights)
+			if br.Status != BookingRoomStatusMoved {
+				sum.Rooms = append(sum.Rooms, br.Room.RoomNumber)
+			}
+		}
+		if len(b.Rooms) == 0 && b.Room.ID != 0 {
+			sum.Amount = b.Room.Price * float64(sum.Nights)
+			sum.Rooms = append(sum.Rooms, b.Room.RoomNumber)
+		}
+		out.Bookings = append(out.Bookings, sum)
+
+		out.Stats.TotalBookings++
+		stayed := b.CheckinCompleted || b.Status == "Checked-In" || b.Status == "Checked-Out"
+		if stayed {
+			out.Stats.Stays++
+			out.Stats.Nights += sum.Nights
+			out.Stats.TotalSpend += sum.Amount
+			if b.CheckIn != nil {
+				if out.Stats.FirstStayAt == nil || b.CheckIn.Before(*out.Stats.FirstStayAt) {
+					out.Stats.FirstStayAt = b.CheckIn
+				}
+				if out.Stats.LastStayAt == nil || b.CheckIn.After(*out.Stats.LastStayAt) {
+					out.Stats.LastStayAt = b.CheckIn
+				}
+			}
+		} else if b.CheckIn != nil && b.CheckIn.After(now) {
+			out.Stats.UpcomingBookings++
+		}
+	}
+	return out, nil
+}
+
+// bookingNights: Nights ที่บันทึกไว้ หรือคำนวณจาก check-in/out
+func bookingNights(b models.Booking) int {
+	if b.Nights > 0 {
+		return b.Nights
+	}
+	if b.CheckIn != nil && b.CheckOut != nil {
+		if n := int(b.CheckOut.Sub(*b.CheckIn).Hours() / 24); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrorKind ประเภทของ error (middleware.ErrorHandler แปลงเป็น HTTP status)
+type ErrorKind int
+
+const (
+	KindInternal        ErrorKind = iota // 500
+	KindInvalid                          // 400
+	KindUnauthorized                     // 401
+	KindForbidden                        // 403
+	KindNotFound                         // 404
+	KindConflict                         // 409
+	KindGone                             // 410
+	KindUnprocessable                    // 422
+	KindTooManyRequests                  // 429
+	KindUpstream                         // 502 (OCR, อีเมล, provider ภายนอก)
+)
+
+// Error domain error ที่มี code คงที่ (error.xxx) ส่งถึง client ได้ตรง ๆ
+// ข้อความที่ client เห็นมาจาก message catalog (utils.Message) ตาม MessageKey, Err คือสาเหตุภายในที่ไม่ส่งให้ client
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Key     string // key ใน message catalog (ว่าง = ใช้ Code)
+	Details interface{}
+	Err     error
+}
+
+// errorKeys message key ของ error ทุกตัวที่ประกาศไว้ (ใช้ตรวจว่า catalog แปลครบ)
+var errorKeys = map[string]bool{}
+
+// NewError สร้าง domain error (ใช้ประกาศ sentinel ระดับ package)
+func NewError(kind ErrorKind, code string) *Error {
+	errorKeys[code] = true
+	return &Error{Kind: kind, Code: code}
+}
+
+// ErrorKeys คืน message key ของ domain error ทั้งหมด
+func ErrorKeys() []string {
+	keys := make([]string, 0, len(errorKeys))
+	for k := range errorKeys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Err.Error()
+	}
+	return e.Code
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is: error ที่ code เดียวกันถือว่าเป็นตัวเดียวกัน (ค่าที่ผ่าน Wrap/WithDetails ยัง errors.Is กับ sentinel ได้)
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// MessageKey key ที่ใช้หาข้อความใน catalog
+func (e *Error) MessageKey() string {
+	if e.Key != "" {
+		return e.Key
+	}
+	return e.Code
+}
+
+// Wrap แนบสาเหตุภายใน (log ได้ แต่ไม่ส่งให้ client)
+func (e *Error) Wrap(err error) *Error {
+	cp := *e
+	cp.Err = err
+	return &cp
+}
+
+// Wrapf เหมือน Wrap แต่สร้างสาเหตุจาก format
+func (e *Error) Wrapf(format string, args ...interface{}) *Error {
+	return e.Wrap(fmt.Errorf(format, args...))
+}
+
+// WithDetails แนบข้อมูลประกอบที่ client ใช้ได้ (เช่น field ที่ผิด, retryAfter)
+func (e *Error) WithDetails(details interface{}) *Error {
+	cp := *e
+	cp.Details = details
+	return &cp
+}
+
+// Variant code เดิมแต่ใช้ข้อความอื่นจาก catalog (เช่น ข้อความเฉพาะหน้า kiosk)
+func (e *Error) Variant(key string) *Error {
+	errorKeys[key] = true
+	cp := *e
+	cp.Key = key
+	return &cp
+}
+
+// AsError ดึง *Error ออกจาก chain (nil ถ้าไม่ใช่ domain error)
+func AsError(err error) *Error {
+	var de *Error
+	if errors.As(err, &de) {
+		return de
+	}
+	return nil
+}
+
+// ---------------------------
+// Generic
+// ---------------------------
+var (
+	ErrInternal        = NewError(KindInternal, "error.internal")
+	ErrInvalidPayload  = NewError(KindInvalid, "error.invalidPayload")
+	ErrValidation      = NewError(KindInvalid, "error.validation")
+	ErrInvalidID       = NewError(KindInvalid, "error.invalidId")
+	ErrNotFound        = NewError(KindNotFound, "error.notFound")
+	ErrTooManyAttempts = NewError(KindTooManyRequests, "error.tooManyAttempts")
+)
+
+// ---------------------------
+// Booking / check-in
+// ---------------------------
+var (
+	ErrBookingNotFound         = NewError(KindNotFound, "error.bookingNotFound")
+	ErrMissingBookingID        = NewError(KindInvalid, "error.missingBookingId")
+	ErrInvalidBookingID        = NewError(KindInvalid, "error.invalidBookingId")
+	ErrInvalidBooking          = NewError(KindInvalid, "error.invalidBooking")
+	ErrBookingMissingCustomer  = NewError(KindUnprocessable, "error.bookingMissingCustomer")
+	ErrBookingMissingRoom      = NewError(KindUnprocessable, "error.bookingMissingRoom")
+	ErrCustomerContactMissing  = NewError(KindUnprocessable, "error.customerContactMissing")
+	ErrAlreadyCheckedIn        = NewError(KindConflict, "error.alreadyCheckedIn")
+	ErrNotCheckedIn            = NewError(KindConflict, "error.notCheckedIn")
+	ErrBookingCheckedOut       = NewError(KindGone, "error.bookingCheckedOut")
+	ErrCheckinAlreadyInitiated = NewError(KindConflict, "error.checkinAlreadyInitiated")
+	ErrBookingHasReferences    = NewError(KindConflict, "error.bookingHasReferences")
+	ErrRoomNotFound            = NewError(KindNotFound, "error.roomNotFound")
+	ErrRoomBlocked             = NewError(KindConflict, "error.roomBlocked")
+
+	ErrMissingToken             = NewError(KindInvalid, "error.missingToken")
+	ErrInvalidOrExpiredToken    = NewError(KindUnauthorized, "error.invalidOrExpiredToken")
+	ErrInvalidOrExpiredCode     = NewError(KindUnauthorized, "error.invalidOrExpiredCode")
+	ErrInvalidCheckinCodeFormat = NewError(KindInvalid, "error.invalidCheckinCodeFormat")
+	ErrCheckinCodeNotFound      = NewError(KindNotFound, "error.checkinCodeNotFound")
+	ErrCheckinCodeExpired       = NewError(KindGone, "error.codeExpired")
+	ErrTokenExpired             = NewError(KindGone, "error.tokenExpired")
+	ErrBookingInfoNotFound      = NewError(KindNotFound, "error.bookingInfoNotFound")
+	ErrBookingInfoDeleteBlocked = NewError(KindForbidden, "error.bookingInfoDeleteDisabled")
+	ErrQRGenerateFailed         = NewError(KindInternal, "error.qrGenerateFailed")
+	ErrCheckinInitiationFailed  = NewError(KindInternal, "error.checkinInitiationFailed")
+	ErrFinalizeFailed           = NewError(KindInternal, "error.finalizeFailed")
+	ErrNotificationSendFailed   = NewError(KindUpstream, "error.notificationSendFailed")
+	ErrNotificationNotSent      = NewError(KindUpstream, "error.notificationNotSent")
+	ErrCheckinQueryRequired     = ErrInvalidPayload.Variant("error.invalidPayload.checkinQuery")
+	ErrInvalidChannel           = NewError(KindInvalid, "error.invalidChannel")
+)
+
+// ---------------------------
+// Guests / consents / documents
+// ---------------------------
+var (
+	ErrGuestNotFound         = NewError(KindNotFound, "error.guestNotFound")
+	ErrGuestDeletionDisabled = NewError(KindForbidden, "error.guestDeletionDisabled")
+	ErrConsentNotFound       = NewError(KindNotFound, "error.consentNotFound")
+	ErrMissingFile           = NewError(KindInvalid, "error.missingFile")
+	ErrDocumentReadFailed    = NewError(KindUpstream, "error.documentReadFailed")
+	ErrUnsupportedDocument   = NewError(KindInvalid, "error.unsupportedDocument")
+	ErrInvalidImage          = NewError(KindInvalid, "error.invalidImage")
+)
+
+// ---------------------------
+// Staff / admin
+// ---------------------------
+var (
+	ErrInvalidCredentials   = NewError(KindUnauthorized, "error.invalidCredentials")
+	ErrEmailExists          = NewError(KindConflict, "error.emailExists")
+	ErrInvalidInvite        = NewError(KindNotFound, "error.invalidInviteToken")
+	ErrInviteExpired        = NewError(KindGone, "error.inviteExpired")
+	ErrEmailSendFailed      = NewError(KindUpstream, "error.emailSendFailed")
+	ErrRoleNotFound         = NewError(KindNotFound, "error.roleNotFound")
+	ErrRoomExists           = NewError(KindConflict, "error.roomExists")
+	ErrUnknownTemplate      = NewError(KindInvalid, "error.unknownTemplate")
+	ErrRenderTemplateFailed = NewError(KindInternal, "error.renderTemplateFailed")
+	ErrMailCaptureOff       = NewError(KindConflict, "error.mailCaptureDisabled")
+	ErrMessageNotFound      = NewError(KindNotFound, "error.messageNotFound")
+)
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownExport           = NewError(KindNotFound, "error.unknownExport")
+	ErrUnsupportedExportFormat = NewError(KindInvalid, "error.unsupportedExportFormat")
+)
+
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+
+	ExportRunning   = "running"
+	ExportCompleted = "completed"
+	ExportFailed    = "failed"
+
+	exportBatchSize = 500
+)
+
+// ExportService export ข้อมูลเป็น CSV / XLSX แบบ stream (อ่านทีละ batch เขียนทีละแถว) และบันทึก ExportLog ทุกครั้ง
+type ExportService struct {
+	DB *gorm.DB
+}
+
+func NewExportService(db *gorm.DB) *ExportService {
+	return &ExportService{DB: db}
+}
+
+// exportDataset ชุดข้อมูลที่ export ได้: filter ใช้ ListSpec เดียวกับ list endpoints (?from= ?to= ?status= ...)
+type exportDataset struct {
+	Spec    ListSpec
+	Columns []string
+	// write อ่านข้อมูลตาม query แล้วส่งทีละแถวให้ emit
+	write func(db *gorm.DB, mask bool, emit func([]string) error) error
+}
+
+var exportDatasets = map[string]exportDataset{
+	"customers": {
+		Spec: ListSpec{
+			IDColumn:   "customers.id",
+			DateColumn: "customers.created_at",
+			Filters: map[string]ListFilter{
+				"nationality":       {Cond: "customers.nationality IN ?", Kind: FilterList},
+				"preferredLanguage": {Cond: "customers.preferred_language IN ?", Kind: FilterList},
+				"tag":               {Cond: "JSON_CONTAINS(customers.tags, JSON_QUOTE(LOWER(?)))", Kind: FilterText},
+			},
+		},
+		Columns: []string{"ID", "Full name", "Email", "Phone", "LINE user ID", "Preferred language", "Nationality", "Address", "Tags", "Notes", "Created at"},
+		write:   exportCustomers,
+	},
+	"guests": {
+		Spec: ListSpec{
+			IDColumn:   "guests.id",
+			DateColumn: "guests.created_at",
+			Filters: map[string]ListFilter{
+				"nationality": {Cond: "guests.nationality IN ?", Kind: FilterList},
+				"status":      {Cond: "guests.booking_id IN (SELECT id FROM bookings WHERE status IN ?)", Kind: FilterList},
+				"bookingId":   {Cond: "guests.booking_id = ?", Kind: FilterID},
+			},
+		},
+		Columns: []string{"ID", "Booking ID", "Booking reference", "Booking status", "Full name", "Main guest", "Date of birth", "Gender", "Nationality", "Address", "ID type", "ID number", "ID issued country", "Email", "Face match", "Created at"},
+		write:   exportGuests,
+	},
+	"bookings": {
+		Spec: ListSpec{
+			IDColumn:   "bookings.id",
+			DateColumn: "bookings.check_in",
+			Filters: map[string]ListFilter{
+				"status":      {Cond: "bookings.status IN ?", Kind: FilterList},
+				"nationality": {Cond: "bookings.customer_id IN (SELECT id FROM customers WHERE nationality IN ?)", Kind: FilterList},
+				"customerId":  {Cond: "bookings.customer_id = ?", Kind: FilterID},
+			},
+		},
+		Columns: []string{"ID", "Reference", "Status", "Customer ID", "Customer name", "Customer email", "Customer phone", "Nationality", "Rooms", "Check-in", "Check-out", "Nights", "Adults", "Children", "Check-in completed", "Checked in at", "Created at"},
+		write:   exportBookings,
+	},
+	"consentLogs": {
+		Spec: ListSpec{
+			IDColumn:   "consent_logs.id",
+			DateColumn: "consent_logs.accepted_at",
+			Filters: map[string]ListFilter{
+				"status":      {Cond: "consent_logs.status IN ?", Kind: FilterList},
+				"action":      {Cond: "consent_logs.action IN ?", Kind: FilterList},
+				"nationality": {Cond: "consent_logs.guest_id IN (SELECT id FROM guests WHERE nationality IN ?)", Kind: FilterList},
+				"consentId":   {Cond: "consent_logs.consent_id = ?", Kind: FilterID},
+			},
+		},
+		Columns: []string{"ID", "Booking ID", "Consent ID", "Consent", "Guest ID", "Guest name", "Status", "Action", "Accepted by", "Accepted at"},
+		write:   exportConsentLogs,
+	},
+}
+
+// ExportDatasets ชื่อชุดข้อมูลที่ export ได้
+func ExportDatasets() []string {
+	names := make([]string, 0, len(exportDatasets))
+	for name := range exportDatasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExportRequest export หนึ่งครั้ง (Query ได้จาก ParseExportQuery)
+type ExportRequest struct {
+	Dataset string
+	Format  string
+	Query   ListQuery
+	Filters url.Values // เก็บลง ExportLog
+	MaskPII bool
+	Actor   AdminActor
+}
+
+// ParseExportQuery ตรวจชื่อชุดข้อมูล / format แล้วอ่าน filter (paging / sort ไม่ใช้กับ export)
+func ParseExportQuery(dataset, format string, values url.Values) (ExportRequest, error) {
+	req := ExportRequest{Dataset: dataset, Format: strings.ToLower(strings.TrimSpace(format))}
+	ds, ok := exportDatasets[dataset]
+	if !ok {
+		return req, ErrUnknownExport.WithDetails(map[string]interface{}{"datasets": ExportDatasets()})
+	}
+	if req.Format == "" {
+		req.Format = ExportCSV
+	}
+	if req.Format != ExportCSV && req.Format != ExportXLSX {
+		return req, ErrUnsupportedExportFormat.WithDetails(map[string]interface{}{"formats": []string{ExportCSV, ExportXLSX}})
+	}
+
+	filters := url.Values{}
+	for k, v := range values {
+		if _, isFilter := ds.Spec.Filters[k]; isFilter || k == "from" || k == "to" {
+			filters[k] = v
+		}
+	}
+	q, err := ParseListQuery(filters, ds.Spec)
+	if err != nil {
+		return req, err
+	}
+	req.Query = q
+	req.Filters = filters
+	return req, nil
+}
+
+// Run เขียนข้อมูลลง tw แล้วปิด tw พร้อมบันทึก ExportLog (สำเร็จ / ล้มกลางทาง)
+func (s *ExportService) Run(req ExportRequest, tw utils.TableWriter) (models.ExportLog, error) {
+	ds, ok := exportDatasets[req.Dataset]
+	if !ok {
+		return models.ExportLog{}, ErrUnknownExport
+	}
+
+	entry := models.ExportLog{
+		AdminName: req.Actor.AdminName,
+		IP:        req.Actor.IP,
+		Dataset:   req.Dataset,
+		Format:    req.Format,
+		MaskPII:   req.MaskPII,
+		Status:    ExportRunning,
+	}
+	if req.Actor.AdminID != 0 {
+		entry.AdminID = &req.Actor.AdminID
+	}
+	if len(req.Filters) > 0 {
+		if b, err := json.Marshal(req.Filters); err == nil {
+			entry.Filters = string(b)
+		}
+	}
+	if err := s.DB.Create(&entry).Error; err != nil {
+		return entry, fmt.Errorf("create export log: %w", err)
+	}
+
+	err := tw.WriteHeader(ds.Columns)
+	if err == nil {
+		err = ds.write(req.Query.Apply(s.DB), req.MaskPII, func(row []string) error {
+			entry.RowCount++
+			return tw.WriteRow(row)
+		})
+	}
+	if cerr := tw.Close(); err == nil {
+		err = cerr
+	}
+
+	now := time.Now().UTC()
+	entry.CompletedAt = &now
+	entry.Status = ExportCompleted
+	if err != nil {
+		entry.Status = ExportFailed
+		entry.Error = err.Error()
+	}
+	if uerr := s.DB.Model(&entry).Updates(map[string]interface{}{
+		"status":       entry.Status,
+		"row_count":    entry.RowCount,
+		"error":        entry.Error,
+		"completed_at": now,
+	}).Error; uerr != nil && err == nil {
+		err = fmt.Errorf("update export log %d: %w", entry.ID, uerr)
+	}
+
+	RecordAudit(s.DB, AuditEntry{
+		ActorType: ActorAdmin,
+		ActorID:   strconv.FormatUint(uint64(req.Actor.AdminID), 10),
+		Action:    "export." + req.Dataset,
+		Target:    fmt.Sprintf("export_log:%d", entry.ID),
+		IP:        req.Actor.IP,
+		Details:   map[string]interface{}{"format": req.Format, "rows": entry.RowCount, "maskPii": req.MaskPII, "status": entry.Status},
+	})
+	return entry, err
+}
+
+// ExportLogListSpec: GET /api/exports/logs
+var ExportLogListSpec = ListSpec{
+	IDColumn: "export_logs.id",
+	Sorts: map[string]string{
+		"id":        "export_logs.id",
+		"createdAt": "export_logs.created_at",
+	},
+	DefaultSort: "-id",
+	DateColumn:  "export_logs.created_at",
+	Filters: map[string]ListFilter{
+		"dataset": {Cond: "export_logs.dataset IN ?", Kind: FilterList},
+		"status":  {Cond: "export_logs.status IN ?", Kind: FilterList},
+		"adminId": {Cond: "export_logs.admin_id = ?", Kind: FilterID},
+	},
+}
+
+func (s *ExportService) ListLogs(q ListQuery) ([]models.ExportLog, PageInfo, error) {
+	list := []models.ExportLog{}
+	page, err := FindPage(s.DB, ExportLogListSpec, q, &list)
+	if err != nil {
+		return nil, page, fmt.Errorf("failed to retrieve export logs: %w", err)
+	}
+	return list, page, nil
+}
+
+// ---------------- datasets ----------------
+
+func exportCustomers(db *gorm.DB, mask bool, emit func([]string) error) error {
+	var batch []models.Customer
+	return db.Model(&models.Customer{}).FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, c := range batch {
+			email, phone, line, address := c.Email, c.Phone, c.LineUserID, c.Address
+			if mask {
+				email, phone, line, address = utils.MaskEmail(email), utils.MaskTail(phone, 3), utils.MaskTail(line, 3), maskedIfSet(address)
+			}
+			if err := emit([]string{
+				uintCell(c.ID), c.FullName, email, phone, line, c.PreferredLanguage, c.Nationality, address,
+				strings.Join(c.Tags, ", "), c.Notes, timeCell(&c.CreatedAt),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func exportGuests(db *gorm.DB, mask bool, emit func([]string) error) error {
+	var batch []models.Guest
+	return db.Model(&models.Guest{}).Preload("Booking").FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, g := range batch {
+			dob, address, idNumber, email := dateCell(g.DateOfBirth), g.CurrentAddress, g.IDNumber, g.Email
+			if mask {
+				if g.DateOfBirth != nil {
+					dob = strconv.Itoa(g.DateOfBirth.Year())
+				}
+				address, idNumber, email = maskedIfSet(address), utils.MaskTail(idNumber, 4), utils.MaskEmail(email)
+			}
+			bookingID := ""
+			if g.BookingID != nil {
+				bookingID = uintCell(*g.BookingID)
+			}
+			if err := emit([]string{
+				uintCell(g.ID), bookingID, g.Booking.ReferenceCode, g.Booking.Status, g.FullName, boolCell(g.IsMainGuest),
+				dob, g.Gender, g.Nationality, address, g.IDType, idNumber, g.IDIssuedCountry, email,
+				g.FaceMatchDecision, timeCell(&g.CreatedAt),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func exportBookings(db *gorm.DB, mask bool, emit func([]string) error) error {
+	var batch []models.Booking
+	return db.Model(&models.Booking{}).Preload("Customer").Preload("Room").Preload("Rooms.Room").
+		FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, b := range batch {
+				email, phone := b.Customer.Email, b.Customer.Phone
+				if mask {
+					email, phone = utils.MaskEmail(email), utils.MaskTail(phone, 3)
+				}
+				rooms := make([]string, 0, len(b.Rooms)+1)
+				for _, br := range CurrentBookingRooms(b.Rooms) {
+					rooms = append(rooms, br.Room.RoomNumber)
+				}
+				if len(rooms) == 0 && b.Room.RoomNumber != "" {
+					rooms = append(rooms, b.Room.RoomNumber)
+				}
+				if err := emit([]string{
+					uintCell(b.ID), b.ReferenceCode, b.Status, uintCell(b.CustomerID), b.Customer.FullName, email, phone,
+					b.Customer.Nationality, strings.Join(rooms, ", "), timeCell(b.CheckIn), timeCell(b.CheckOut),
+					strconv.Itoa(bookingNights(b)), strconv.Itoa(b.Adults), strconv.Itoa(b.Children),
+					boolCell(b.CheckinCompleted), timeCell(b.CheckedInAt), timeCell(&b.CreatedAt),
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+func exportConsentLogs(db *gorm.DB, mask bool, emit func([]string) error) error {
+	var batch []models.ConsentLog
+	consentTitles := map[uint]string{}
+	return db.Model(&models.ConsentLog{}).FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		// ชื่อ consent / ผู้เข้าพักของ batch นี้ (ไม่ preload เพราะ ConsentLog ไม่มี relation)
+		var consentIDs, guestIDs []uint
+		for _, l := range batch {
+			if _, ok := consentTitles[l.ConsentID]; !ok {
+				consentIDs = append(consentIDs, l.ConsentID)
+			}
+			if l.GuestID != nil {
+				guestIDs = append(guestIDs, *l.GuestID)
+			}
+		}
+		if len(consentIDs) > 0 {
+			var consents []models.Consent
+			if err := tx.Session(&gorm.Session{NewDB: true}).Unscoped().Select("consent_id", "title").Find(&consents, consentIDs).Error; err != nil {
+				return err
+			}
+			for _, c := range consents {
+				consentTitles[c.ID] = c.Title
+			}
+		}
+		guestNames := map[uint]string{}
+		if len(guestIDs) > 0 {
+			var guests []models.Guest
+			if err := tx.Session(&gorm.Session{NewDB: true}).Select("id", "full_name").Find(&guests, guestIDs).Error; err != nil {
+				return err
+			}
+			for _, g := range guests {
+				guestNames[g.ID] = g.FullName
+			}
+		}
+
+		for _, l := range batch {
+			bookingID, guestID, guestName := "", "", ""
+			if l.BookingID != nil {
+				bookingID = uintCell(*l.BookingID)
+			}
+			if l.GuestID != nil {
+				guestID, guestName = uintCell(*l.GuestID), guestNames[*l.GuestID]
+			}
+			if err := emit([]string{
+				uintCell(l.ID), bookingID, uintCell(l.ConsentID), consentTitles[l.ConsentID], guestID, guestName,
+				l.Status, l.Action, l.AcceptedBy, timeCell(&l.AcceptedAt),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func uintCell(v uint) string { return strconv.FormatUint(uint64(v), 10) }
+
+func boolCell(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
+
+func timeCell(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func dateCell(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func maskedIfSet(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	return "***"
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// Face match decisions (เก็บใน guests.face_match_decision)
+const (
+	FaceMatchAccepted = "match"
+	FaceMatchLowScore = "low_score" // ส่งเข้าคิว staff
+	FaceMatchSkipped  = "skipped"   // ไม่มีรูปใบหน้าหรือรูปเอกสาร
+	FaceMatchError    = "error"
+
+	CaseSourceFaceMatch      = "face_match"
+	CaseReasonFaceMatch      = "face_mismatch"
+	CaseReasonFaceMatchError = "face_match_error" // provider ล่ม / ตอบ error -> ให้ staff ตรวจเอง
+)
+
+var errFaceImageMissing = errors.New("face or document image missing")
+
+// FaceMatcher เปรียบเทียบรูปใบหน้ากับรูปบนเอกสาร คืนค่าความเหมือน 0..1
+type FaceMatcher interface {
+	Name() string
+	Compare(face, document []byte) (float64, error)
+}
+
+// StubFaceMatcher คืนคะแนนคงที่ (ใช้ตอน dev / ยังไม่มี provider)
+type StubFaceMatcher struct {
+	Score float64
+}
+
+func (m StubFaceMatcher) Name() string { return "stub" }
+
+func (m StubFaceMatcher) Compare(face, document []byte) (float64, error) {
+	if len(face) == 0 || len(document) == 0 {
+		return 0, errFaceImageMissing
+	}
+	return m.Score, nil
+}
+
+// HTTPFaceMatcher เรียก service ภายนอก:
+//
+//	POST {URL}  {"face": "<base64>", "document": "<base64>"}  →  {"score": 0.93}
+type HTTPFaceMatcher struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+func (m *HTTPFaceMatcher) Name() string { return "http" }
+
+func (m *HTTPFaceMatcher) Compare(face, document []byte) (float64, error) {
+	if len(face) == 0 || len(document) == 0 {
+		return 0, errFaceImageMissing
+	}
+	body, _ := json.Marshal(map[string]string{
+		"face":     base64.StdEncoding.EncodeToString(face),
+		"document": base64.StdEncoding.EncodeToString(document),
+	})
+
+	req, err := http.NewRequest(http.MethodPost, m.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("cannot build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.APIKey)
+	}
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+
+	var out struct {
+		Score      *float64 `json:"score"`
+		Similarity *float64 `json:"similarity"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return 0, fmt.Errorf("JSON parse error: %w", err)
+	}
+	switch {
+	case out.Score != nil:
+		return *out.Score, nil
+	case out.Similarity != nil:
+		return *out.Similarity, nil
+	}
+	return 0, fmt.Errorf("no score in response: %s", string(raw))
+}
+
+// NewFaceMatcherFromEnv:
+//
+//	FACE_MATCH_PROVIDER    http | off | stub (default: http ถ้ามี FACE_MATCH_URL ไม่งั้น off; stub ใช้ได้เฉพาะ APP_ENV=dev)
+//	FACE_MATCH_URL         endpoint ของ http provider
+//	FACE_MATCH_API_KEY     ส่งเป็น Bearer token
+//	FACE_MATCH_STUB_SCORE  คะแนนที่ stub คืน (default 1.0)
+func NewFaceMatcherFromEnv() FaceMatcher {
+	url := strings.TrimSpace(os.Getenv("FACE_MATCH_URL"))
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("FACE_MATCH_PROVIDER")))
+	if provider == "" {
+		provider = "off"
+		if url != "" {
+			provider = "http"
+		}
+	}
+
+	switch provider {
+	case "off", "none", "disabled":
+		return nil
+	case "stub":
+		// stub ให้คะแนนตายตัว ห้ามใช้จริง — production ที่ตั้งผิดถือว่าปิดการเทียบหน้า
+		if !utils.IsDevMode() {
+			log.Println("⚠️  FACE_MATCH_PROVIDER=stub is only allowed when APP_ENV=dev; face matching disabled")
+			return nil
+		}
+		return StubFaceMatcher{Score: envFloat("FACE_MATCH_STUB_SCORE", 1.0)}
+	case "http":
+		if url == "" {
+			log.Println("⚠️  FACE_MATCH_PROVIDER=http but FACE_MATCH_URL is empty; face matching disabled")
+			return nil
+		}
+		return &HTTPFaceMatcher{
+			URL:    url,
+			APIKey: strings.TrimSpace(os.Getenv("FACE_MATCH_API_KEY")),
+			Client: &http.Client{Timeout: 15 * time.Second},
+		}
+	}
+	log.Printf("⚠️  unknown FACE_MATCH_PROVIDER %q; face matching disabled", provider)
+	return nil
+}
+
+func envFloat(key string, def float64) float64 {
+	if v, err := strconv.ParseFloat(strings.TrimSpace(utils.EnvOrDefault(key, "")), 64); err == nil {
+		return v
+	}
+	return def
+}
+
+// FaceMatchService เทียบ selfie กับรูปเอกสารของแขกหลังเช็คอิน
+// คะแนนต่ำกว่า Threshold → เปิด VerificationCase ให้ staff ตรวจ
+type FaceMatchService struct {
+	DB        *gorm.DB
+	Matcher   FaceMatcher
+	Threshold float64
+	Cases     *VerificationCaseService
+}
+
+// NewFaceMatchService อ่าน FACE_MATCH_THRESHOLD (default 0.8)
+func NewFaceMatchService(db *gorm.DB, cases *VerificationCaseService) *FaceMatchService {
+	return &FaceMatchService{
+		DB:        db,
+		Matcher:   NewFaceMatcherFromEnv(),
+		Threshold: envFloat("FACE_MATCH_THRESHOLD", 0.8),
+		Cases:     cases,
+	}
+}
+
+// CheckBooking เทียบรูปของแขกทุกคนใน booking ที่ยังไม่เคยเทียบ
+func (s *FaceMatchService) CheckBooking(bookingID, bookingInfoID uint) {
+	if s == nil || s.Matcher == nil {
+		return
+	}
+	var guests []models.Guest
+	if err := s.DB.Where("booking_id = ? AND (face_match_decision IS NULL OR face_match_decision = '')", bookingID).Find(&guests).Error; err != nil {
+		log.Printf("face match: load guests of booking %d failed: %v", bookingID, err)
+		return
+	}
+	for _, g := range guests {
+		if _, err := s.CheckGuest(g, bookingInfoID); err != nil {
+			log.Printf("face match: guest %d: %v", g.ID, err)
+		}
+	}
+}
+
+// CheckGuest เทียบรูปของแขกหนึ่งคน บันทึกคะแนน/ผลลง guest และคืน decision
+func (s *FaceMatchService) CheckGuest(g models.Guest, bookingInfoID uint) (string, error) {
+	updates := map[string]interface{}{
+		"face_match_provider": s.Matcher.Name(),
+		"face_matched_at":     time.Now().UTC(),
+	}
+
+	face, errFace := readUploadedImage(g.FaceImagePath)
+	doc, errDoc := readUploadedImage(g.DocumentImagePath)
+	if errFace != nil || errDoc != nil {
+		updates["face_match_decision"] = FaceMatchSkipped
+		return FaceMatchSkipped, s.DB.Model(&models.Guest{}).Where("id = ?", g.ID).Updates(updates).Error
+	}
+
+	score, err := s.Matcher.Compare(face, doc)
+	if err != nil {
+		// decision "error" ไม่ถูกตรวจซ้ำ จึงต้องเปิด case ให้ staff ตรวจแทน ไม่ให้แขกข้ามการยืนยันตัวตนช่วง provider ล่ม
+		updates["face_match_decision"] = FaceMatchError
+		if uerr := s.DB.Model(&models.Guest{}).Where("id = ?", g.ID).Updates(updates).Error; uerr != nil {
+			return FaceMatchError, uerr
+		}
+		if cerr := s.flag(g, bookingInfoID, CaseReasonFaceMatchError, map[string]interface{}{
+			"error":    err.Error(),
+			"provider": s.Matcher.Name(),
+		}); cerr != nil {
+			return FaceMatchError, cerr
+		}
+		return FaceMatchError, err
+	}
+
+	decision := FaceMatchAccepted
+	if score < s.Threshold {
+		decision = FaceMatchLowScore
+	}
+	updates["face_match_score"] = score
+	updates["face_match_decision"] = decision
+	if err := s.DB.Model(&models.Guest{}).Where("id = ?", g.ID).Updates(updates).Error; err != nil {
+		return decision, err
+	}
+
+	if decision == FaceMatchLowScore {
+		if err := s.flag(g, bookingInfoID, CaseReasonFaceMatch, map[string]interface{}{
+			"score":     score,
+			"threshold": s.Threshold,
+			"provider":  s.Matcher.Name(),
+		}); err != nil {
+			return decision, err
+		}
+	}
+	return decision, nil
+}
+
+// flag เปิด verification case ของแขก (ไม่มี booking = ไม่มีคิวให้เปิด)
+func (s *FaceMatchService) flag(g models.Guest, bookingInfoID uint, reason string, details map[string]interface{}) error {
+	if g.BookingID == nil {
+		return nil
+	}
+	guestID := g.ID
+	vc, err := s.Cases.Open(OpenCaseInput{
+		BookingID:     *g.BookingID,
+		BookingInfoID: bookingInfoID,
+		GuestID:       &guestID,
+		Source:        CaseSourceFaceMatch,
+		Reasons:       []string{reason},
+		Details:       details,
+	})
+	if err != nil {
+		return err
+	}
+	audit := map[string]interface{}{"caseId": vc.ID, "reason": reason}
+	if score, ok := details["score"]; ok {
+		audit["score"] = score
+	}
+	RecordAudit(s.DB, AuditEntry{
+		ActorType: ActorSystem,
+		Action:    "face_match.flagged",
+		Target:    fmt.Sprintf("guest:%d", g.ID),
+		Details:   audit,
+	})
+	return nil
+}
+
+// readUploadedImage อ่านรูปใต้ uploads/ เท่านั้น (path มาจาก client ได้ ห้ามหลุดออกนอกโฟลเดอร์)
+func readUploadedImage(path string) ([]byte, error) {
+	full, ok := UploadedFilePath(path)
+	if !ok {
+		return nil, errFaceImageMissing
+	}
+	return os.ReadFile(full)
+}
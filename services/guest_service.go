@@ -33,26 +33,41 @@ func (s *GuestService) Create(guest *models.Guest) error {
 	return err
 }
 
+// GuestListSpec: GET /api/guests/all
+var GuestListSpec = ListSpec{
+	IDColumn: "guests.id",
+	Sorts: map[string]string{
+		"id":          "guests.id",
+		"createdAt":   "guests.created_at",
+		"fullName":    "guests.full_name",
+		"nationality": "guests.nationality",
+	},
+	DefaultSort: "-id",
+	DateColumn:  "guests.created_at",
+	Filters: map[string]ListFilter{
+		"bookingId":   {Cond: "guests.booking_id = ?", Kind: FilterID},
+		"nationality": {Cond: "guests.nationality = ?", Kind: FilterText},
+		"customerId":  {Cond: "guests.booking_id IN (SELECT id FROM bookings WHERE customer_id = ?)", Kind: FilterID},
+		"roomId": {
+			Cond: "guests.booking_id IN (SELECT id FROM bookings WHERE room_id = @v UNION SELECT booking_id FROM booking_rooms WHERE room_id = @v AND deleted_at IS NULL)",
+			Kind: FilterID,
+		},
+	},
+}
+
 // ----------------------------------------------------
-// ✅ GetAll (Admin view)
+// ✅ List (Admin view)
 // - preload booking/room
 // - เติม RoomNumber
 // ----------------------------------------------------
-func (s *GuestService) GetAll() ([]models.Guest, error) {
-	log.Println("➡️ GuestService.GetAll")
-
-	var guests []models.Guest
-
-	// NOTE: ใส่ Order ให้ consistent กับ /guests/all ที่คุณอยากให้เรียงล่าสุดก่อน
-	err := s.DB.
-		Preload("Booking.Room").
-		Preload("Booking.Rooms.Room").
-		Order("guests.id DESC").
-		Find(&guests).Error
+func (s *GuestService) List(q ListQuery) ([]models.Guest, PageInfo, error) {
+	log.Println("➡️ GuestService.List")
 
+	guests := []models.Guest{}
+	page, err := FindPage(s.DB, GuestListSpec, q, &guests, "Booking.Room", "Booking.Rooms.Room")
 	if err != nil {
-		log.Printf("⬅️ GuestService.GetAll error: %v", err)
-		return nil, err
+		log.Printf("⬅️ GuestService.List error: %v", err)
+		return nil, page, err
 	}
 
 	// เติม roomNumber ให้ guest (ใช้เฉพาะ admin view)
@@ -80,8 +95,8 @@ func (s *GuestService) GetAll() ([]models.Guest, error) {
 		}
 	}
 
-	log.Printf("⬅️ GuestService.GetAll ok: %d guests", len(guests))
-	return guests, nil
+	log.Printf("⬅️ GuestService.List ok: %d/%d guests", len(guests), page.Total)
+	return guests, page, nil
 }
 
 // ----------------------------------------------------
@@ -140,6 +155,28 @@ func (s *GuestService) Update(guest *models.Guest) error {
 	log.Printf("⬅️ GuestService.Update err=%v", err)
 	return err
 }
+
+// ----------------------------------------------------
+// UPDATE FIELDS — แก้เฉพาะ column ที่ระบุ (รองรับการล้างค่าเป็น "" / false)
+// ----------------------------------------------------
+func (s *GuestService) UpdateFields(id uint, updates map[string]interface{}) (*models.Guest, error) {
+	log.Printf("➡️ GuestService.UpdateFields id=%d fields=%d", id, len(updates))
+
+	guest, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(updates) == 0 {
+		return guest, nil
+	}
+
+	if err := s.DB.Model(guest).Updates(updates).Error; err != nil {
+		log.Printf("⬅️ GuestService.UpdateFields err=%v", err)
+		return nil, err
+	}
+	return s.GetByID(id)
+}
+
 // ----------------------------------------------------
 // 🚫 DELETE — ไม่อนุญาตให้ลบ Guest
 // ----------------------------------------------------
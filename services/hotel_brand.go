@@ -0,0 +1,49 @@
+package services
+
+import (
+	"strings"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// LoadHotelBrand อ่าน HotelSetting (แถวแรก) มาใช้เป็น branding ของอีเมล/ข้อความ
+// ถ้ายังไม่มีการตั้งค่า จะ fallback ไปที่ SMTP_FROM_NAME
+func LoadHotelBrand(db *gorm.DB) utils.HotelBrand {
+	brand := utils.HotelBrand{
+		Name: utils.EnvOrDefault("SMTP_FROM_NAME", "Our Hotel"),
+	}
+	if db == nil {
+		return brand
+	}
+
+	var hotel models.HotelSetting
+	if err := db.Order("id").First(&hotel).Error; err != nil {
+		return brand
+	}
+
+	if strings.TrimSpace(hotel.Name) != "" {
+		brand.Name = strings.TrimSpace(hotel.Name)
+	}
+	brand.Address = strings.TrimSpace(hotel.Address)
+	brand.Phone = strings.TrimSpace(hotel.Phone)
+	brand.Email = strings.TrimSpace(hotel.Email)
+	brand.Website = strings.TrimSpace(hotel.Website)
+	brand.LogoURL = absoluteAssetURL(hotel.Logo)
+	return brand
+}
+
+// absoluteAssetURL: logo อาจเก็บเป็น path ใน /uploads ต้องแปลงเป็น URL เต็มสำหรับอีเมล
+func absoluteAssetURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://") || strings.HasPrefix(raw, "data:") {
+		return raw
+	}
+	base := strings.TrimRight(utils.EnvOrDefault("PUBLIC_BASE_URL", "http://localhost:8080"), "/")
+	return base + "/" + strings.TrimLeft(raw, "/")
+}
@@ -0,0 +1,540 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Room.CleanStatus (แยกจาก Room.Status ที่เป็นสถานะการเข้าพัก)
+const (
+	CleanStatusDirty     = "dirty"
+	CleanStatusCleaning  = "cleaning"
+	CleanStatusClean     = "clean"
+	CleanStatusInspected = "inspected"
+)
+
+// HousekeepingTask.Type
+const (
+	TaskTypeCheckout = "checkout"
+	TaskTypeStayover = "stayover"
+	TaskTypeTouchup  = "touchup"
+	TaskTypeDeep     = "deep"
+)
+
+// HousekeepingTask.Status
+const (
+	TaskStatusPending    = "pending"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusDone       = "done"
+	TaskStatusInspected  = "inspected"
+)
+
+// taskOpenStatuses งานที่ยังไม่เสร็จ (ใช้กันสร้างงานซ้ำของห้องเดียวกันในวันเดียวกัน)
+var taskOpenStatuses = []string{TaskStatusPending, TaskStatusInProgress}
+
+var (
+	ErrHousekeepingTaskNotFound = NewError(KindNotFound, "error.housekeepingTaskNotFound")
+	ErrHousekeepingTaskState    = NewError(KindConflict, "error.housekeepingTaskState")
+	ErrHousekeepingNotAssignee  = NewError(KindForbidden, "error.housekeepingNotAssignee")
+	ErrAssigneeNotFound         = NewError(KindNotFound, "error.assigneeNotFound")
+)
+
+// HousekeepingService งานแม่บ้าน: สถานะความสะอาดของห้อง, งานรายวันตามพนักงาน / ชั้น, การตรวจห้อง
+type HousekeepingService struct {
+	DB *gorm.DB
+}
+
+func NewHousekeepingService(db *gorm.DB) *HousekeepingService {
+	return &HousekeepingService{DB: db}
+}
+
+// HousekeepingDate วันที่ของงาน (เที่ยงคืน UTC)
+func HousekeepingDate(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// markRoomsDirty ห้องที่แขกเพิ่งออก (เช็คเอาท์ / ย้ายห้อง) → dirty และมีงาน checkout ของวันนี้ (ใช้ใน transaction)
+// ถ้ามีงานที่ยังไม่เริ่มของห้องนั้นอยู่แล้ว (เช่น stayover) เปลี่ยนเป็นงาน checkout แทนการสร้างใหม่
+func markRoomsDirty(tx *gorm.DB, roomIDs []uint, bookingID uint, source string) error {
+	if len(roomIDs) == 0 {
+		return nil
+	}
+	today := HousekeepingDate(time.Now())
+	for _, roomID := range roomIDs {
+		if err := setCleanStatus(tx, roomID, CleanStatusDirty, source, nil, ""); err != nil {
+			return err
+		}
+		var task models.HousekeepingTask
+		err := tx.Where("room_id = ? AND date = ? AND status IN ?", roomID, today, taskOpenStatuses).First(&task).Error
+		switch {
+		case err == nil:
+			if err := tx.Model(&task).Updates(map[string]interface{}{"type": TaskTypeCheckout, "booking_id": bookingID}).Error; err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			bid := bookingID
+			task = models.HousekeepingTask{
+				RoomID:    roomID,
+				Date:      today,
+				Type:      TaskTypeCheckout,
+				Status:    TaskStatusPending,
+				Priority:  1,
+				BookingID: &bid,
+			}
+			if err := tx.Create(&task).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// ---------------- tasks ----------------
+
+// HousekeepingTaskFilter ตัวกรองรายการงาน (Date บังคับ)
+type HousekeepingTaskFilter struct {
+	Date       time.Time
+	AssigneeID *uint
+	Unassigned bool
+	Floor      string
+	Status     string
+}
+
+// ListTasks งานของวันหนึ่ง เรียงตามชั้น → เลขห้อง → priority
+func (s *HousekeepingService) ListTasks(f HousekeepingTaskFilter) ([]models.HousekeepingTask, error) {
+	tasks := []models.HousekeepingTask{}
+	q := s.DB.Model(&models.HousekeepingTask{}).
+		Joins("JOIN rooms ON rooms.id = housekeeping_tasks.room_id").
+		Where("housekeeping_tasks.date = ?", HousekeepingDate(f.Date))
+	if f.AssigneeID != nil {
+		q = q.Where("housekeeping_tasks.assigned_to_id = ?", *f.AssigneeID)
+	} else if f.Unassigned {
+		q = q.Where("housekeeping_tasks.assigned_to_id IS NULL")
+	}
+	if f.Floor != "" {
+		q = q.Where("rooms.floor = ?", f.Floor)
+	}
+	if f.Status != "" {
+		q = q.Where("housekeeping_tasks.status = ?", f.Status)
+	}
+	err := q.Preload("Room").Preload("AssignedTo", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "full_name", "username")
+	}).
+		Order("rooms.floor, rooms.room_number, housekeeping_tasks.priority DESC, housekeeping_tasks.id").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// HousekeepingTaskCard งานแบบย่อสำหรับหน้าจอมือถือของพนักงาน
+type HousekeepingTaskCard struct {
+	ID          uint       `json:"id"`
+	RoomID      uint       `json:"roomId"`
+	RoomNumber  string     `json:"roomNumber"`
+	Type        string     `json:"type"`
+	Status      string     `json:"status"`
+	Priority    int        `json:"priority"`
+	CleanStatus string     `json:"cleanStatus"`
+	Notes       string     `json:"notes,omitempty"`
+	Inspection  string     `json:"inspectionNote,omitempty"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+}
+
+// HousekeepingFloor งานของชั้นเดียว
+type HousekeepingFloor struct {
+	Floor string                 `json:"floor"`
+	Tasks []HousekeepingTaskCard `json:"tasks"`
+}
+
+// GroupTasksByFloor จัดงาน (ที่เรียงตามชั้นแล้วจาก ListTasks) เป็นกลุ่มรายชั้น
+func GroupTasksByFloor(tasks []models.HousekeepingTask) []HousekeepingFloor {
+	floors := []HousekeepingFloor{}
+	for _, t := range tasks {
+		if len(floors) == 0 || floors[len(floors)-1].Floor != t.Room.Floor {
+			floors = append(floors, HousekeepingFloor{Floor: t.Room.Floor, Tasks: []HousekeepingTaskCard{}})
+		}
+		last := &floors[len(floors)-1]
+		last.Tasks = append(last.Tasks, HousekeepingTaskCard{
+			ID:          t.ID,
+			RoomID:      t.RoomID,
+			RoomNumber:  t.Room.RoomNumber,
+			Type:        t.Type,
+			Status:      t.Status,
+			Priority:    t.Priority,
+			CleanStatus: t.Room.CleanStatus,
+			Notes:       t.Notes,
+			Inspection:  t.InspectionNote,
+			StartedAt:   t.StartedAt,
+			FinishedAt:  t.FinishedAt,
+		})
+	}
+	return floors
+}
+
+// HousekeepingTaskInput ค่าที่สร้าง / แก้งานได้ (nil = ไม่เปลี่ยน)
+type HousekeepingTaskInput struct {
+	RoomID     *uint
+	Date       *time.Time
+	Type       *string
+	Priority   *int
+	AssigneeID *uint // 0 = เลิก assign
+	Notes      *string
+}
+
+func (s *HousekeepingService) CreateTask(in HousekeepingTaskInput, actor AdminActor) (models.HousekeepingTask, error) {
+	task := models.HousekeepingTask{Type: TaskTypeTouchup, Status: TaskStatusPending, Date: HousekeepingDate(time.Now())}
+	if in.RoomID == nil {
+		return task, ErrRoomNotFound
+	}
+	var room models.Room
+	if err := s.DB.Select("id").First(&room, *in.RoomID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return task, ErrRoomNotFound
+		}
+		return task, err
+	}
+	task.RoomID = room.ID
+	if err := s.applyTaskInput(&task, in); err != nil {
+		return task, err
+	}
+	if err := s.DB.Create(&task).Error; err != nil {
+		return task, err
+	}
+	s.audit(actor, "housekeeping.task.create", task.ID, map[string]interface{}{"roomId": task.RoomID, "type": task.Type})
+	return s.GetTask(task.ID)
+}
+
+func (s *HousekeepingService) UpdateTask(id uint, in HousekeepingTaskInput, actor AdminActor) (models.HousekeepingTask, error) {
+	task, err := s.GetTask(id)
+	if err != nil {
+		return task, err
+	}
+	if err := s.applyTaskInput(&task, in); err != nil {
+		return task, err
+	}
+	if err := s.DB.Model(&task).Select("date", "type", "priority", "assigned_to_id", "notes").Updates(&task).Error; err != nil {
+		return task, err
+	}
+	s.audit(actor, "housekeeping.task.update", id, map[string]interface{}{"assignedToId": task.AssignedToID, "type": task.Type})
+	return s.GetTask(id)
+}
+
+func (s *HousekeepingService) applyTaskInput(task *models.HousekeepingTask, in HousekeepingTaskInput) error {
+	if in.Date != nil {
+		task.Date = HousekeepingDate(*in.Date)
+	}
+	if in.Type != nil {
+		task.Type = *in.Type
+	}
+	if in.Priority != nil {
+		task.Priority = *in.Priority
+	}
+	if in.Notes != nil {
+		task.Notes = strings.TrimSpace(*in.Notes)
+	}
+	if in.AssigneeID != nil {
+		if *in.AssigneeID == 0 {
+			task.AssignedToID = nil
+		} else {
+			if err := ensureAssignee(s.DB, *in.AssigneeID); err != nil {
+				return err
+			}
+			aid := *in.AssigneeID
+			task.AssignedToID = &aid
+		}
+		task.AssignedTo = nil
+	}
+	return nil
+}
+
+// ensureAssignee ผู้รับงานต้องเป็น admin ที่มีอยู่จริง
+func ensureAssignee(db *gorm.DB, id uint) error {
+	var n int64
+	if err := db.Model(&models.Admin{}).Where("id = ?", id).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAssigneeNotFound
+	}
+	return nil
+}
+
+func (s *HousekeepingService) DeleteTask(id uint, actor AdminActor) error {
+	res := s.DB.Delete(&models.HousekeepingTask{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrHousekeepingTaskNotFound
+	}
+	s.audit(actor, "housekeeping.task.delete", id, nil)
+	return nil
+}
+
+func (s *HousekeepingService) GetTask(id uint) (models.HousekeepingTask, error) {
+	var task models.HousekeepingTask
+	err := s.DB.Preload("Room").Preload("AssignedTo", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "full_name", "username")
+	}).First(&task, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return task, ErrHousekeepingTaskNotFound
+	}
+	return task, err
+}
+
+// GenerateTasks สร้างงานของวัน date จากการจอง: ห้องที่แขกออกวันนั้น = checkout, ห้องที่แขกพักต่อ = stayover,
+// ห้อง dirty ที่ไม่มีการจอง = touchup; ห้องที่มีงานของวันนั้นอยู่แล้วจะข้าม (เรียกซ้ำได้)
+// floorAssignees: ชั้น → admin ที่รับผิดชอบ (ไม่ระบุ = ยังไม่ assign)
+func (s *HousekeepingService) GenerateTasks(date time.Time, floorAssignees map[string]uint, actor AdminActor) ([]models.HousekeepingTask, error) {
+	day := HousekeepingDate(date)
+	next := day.AddDate(0, 0, 1)
+	for _, id := range floorAssignees {
+		if err := ensureAssignee(s.DB, id); err != nil {
+			return nil, err
+		}
+	}
+
+	var rooms []models.Room
+	if err := s.DB.Select("id", "floor", "clean_status").Order("floor, room_number").Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	var existing []uint
+	if err := s.DB.Model(&models.HousekeepingTask{}).Where("date = ?", day).Distinct().Pluck("room_id", &existing).Error; err != nil {
+		return nil, err
+	}
+	hasTask := map[uint]bool{}
+	for _, id := range existing {
+		hasTask[id] = true
+	}
+
+	// การจองที่คาบเกี่ยววันนั้น: ออกภายในวัน = checkout, ออกหลังจากนั้น (และเข้าพักก่อนวันนั้น) = stayover
+	type stay struct {
+		RoomID   uint
+		CheckIn  time.Time
+		CheckOut time.Time
+	}
+	var stays []stay
+	cols := "bookings.check_in, bookings.check_out"
+	var viaRooms, legacy []stay
+	if err := activeSegmentsIn(s.DB.Model(&models.BookingRoom{}).
+		Joins("JOIN bookings ON bookings.id = booking_rooms.booking_id"), day, next, 0).
+		Select("booking_rooms.room_id, " + segmentStartExpr + " AS check_in, " + segmentEndExpr + " AS check_out").Scan(&viaRooms).Error; err != nil {
+		return nil, err
+	}
+	if err := activeBookingsIn(s.DB.Model(&models.Booking{}).Where("bookings.room_id IS NOT NULL"), day, next, 0).
+		Select("bookings.room_id, " + cols).Scan(&legacy).Error; err != nil {
+		return nil, err
+	}
+	stays = append(viaRooms, legacy...)
+	taskType := map[uint]string{}
+	for _, st := range stays {
+		switch {
+		case st.CheckOut.Before(next):
+			taskType[st.RoomID] = TaskTypeCheckout
+		case st.CheckIn.Before(day) && taskType[st.RoomID] == "":
+			taskType[st.RoomID] = TaskTypeStayover
+		}
+	}
+
+	tasks := []models.HousekeepingTask{}
+	for _, r := range rooms {
+		if hasTask[r.ID] {
+			continue
+		}
+		t := taskType[r.ID]
+		if t == "" && r.CleanStatus == CleanStatusDirty {
+			t = TaskTypeTouchup
+		}
+		if t == "" {
+			continue
+		}
+		task := models.HousekeepingTask{RoomID: r.ID, Date: day, Type: t, Status: TaskStatusPending}
+		if t == TaskTypeCheckout {
+			task.Priority = 1
+		}
+		if aid, ok := floorAssignees[r.Floor]; ok && aid != 0 {
+			id := aid
+			task.AssignedToID = &id
+		}
+		tasks = append(tasks, task)
+	}
+	if len(tasks) > 0 {
+		if err := s.DB.CreateInBatches(&tasks, 200).Error; err != nil {
+			return nil, err
+		}
+		s.audit(actor, "housekeeping.task.generate", 0, map[string]interface{}{"date": day.Format("2006-01-02"), "created": len(tasks)})
+	}
+	return tasks, nil
+}
+
+// ---------------- workflow ----------------
+
+// StartTask พนักงานเริ่มทำงาน: งานที่ยังไม่ assign จะถูก assign ให้คนที่กดเริ่ม
+// canManage = มีสิทธิ์ housekeeping.assign (ทำแทนคนอื่นได้)
+func (s *HousekeepingService) StartTask(id uint, actor AdminActor, canManage bool) (models.HousekeepingTask, error) {
+	return s.transition(id, TaskStatusPending, func(tx *gorm.DB, task *models.HousekeepingTask, now time.Time) error {
+		if task.AssignedToID == nil {
+			aid := actor.AdminID
+			task.AssignedToID = &aid
+		} else if *task.AssignedToID != actor.AdminID && !canManage {
+			return ErrHousekeepingNotAssignee
+		}
+		task.Status = TaskStatusInProgress
+		task.StartedAt = &now
+		task.FinishedAt = nil
+		if err := tx.Model(task).Select("status", "assigned_to_id", "started_at", "finished_at").Updates(task).Error; err != nil {
+			return err
+		}
+		return setCleanStatus(tx, task.RoomID, CleanStatusCleaning, RoomStatusSourceHousekeeping, &actor.AdminID, "")
+	})
+}
+
+// FinishTask ทำความสะอาดเสร็จ → ห้อง clean (รอ supervisor ตรวจ)
+func (s *HousekeepingService) FinishTask(id uint, notes *string, actor AdminActor, canManage bool) (models.HousekeepingTask, error) {
+	return s.transition(id, TaskStatusInProgress, func(tx *gorm.DB, task *models.HousekeepingTask, now time.Time) error {
+		if task.AssignedToID != nil && *task.AssignedToID != actor.AdminID && !canManage {
+			return ErrHousekeepingNotAssignee
+		}
+		task.Status = TaskStatusDone
+		task.FinishedAt = &now
+		if notes != nil {
+			task.Notes = strings.TrimSpace(*notes)
+		}
+		if err := tx.Model(task).Select("status", "finished_at", "notes").Updates(task).Error; err != nil {
+			return err
+		}
+		return setCleanStatus(tx, task.RoomID, CleanStatusClean, RoomStatusSourceHousekeeping, &actor.AdminID, "")
+	})
+}
+
+// InspectTask supervisor ตรวจห้อง: ผ่าน → inspected, ไม่ผ่าน → งานกลับเป็น pending และห้องกลับเป็น dirty
+func (s *HousekeepingService) InspectTask(id uint, passed bool, note string, actor AdminActor) (models.HousekeepingTask, error) {
+	task, err := s.transition(id, TaskStatusDone, func(tx *gorm.DB, task *models.HousekeepingTask, now time.Time) error {
+		inspector := actor.AdminID
+		task.InspectedAt = &now
+		task.InspectedByID = &inspector
+		task.InspectionNote = strings.TrimSpace(note)
+		cols := []string{"status", "inspected_at", "inspected_by_id", "inspection_note"}
+		room := CleanStatusInspected
+		if passed {
+			task.Status = TaskStatusInspected
+		} else {
+			task.Status = TaskStatusPending
+			task.StartedAt, task.FinishedAt = nil, nil
+			cols = append(cols, "started_at", "finished_at")
+			room = CleanStatusDirty
+		}
+		if err := tx.Model(task).Select(cols).Updates(task).Error; err != nil {
+			return err
+		}
+		return setCleanStatus(tx, task.RoomID, room, RoomStatusSourceHousekeeping, &inspector, task.InspectionNote)
+	})
+	if err == nil {
+		s.audit(actor, "housekeeping.task.inspect", id, map[string]interface{}{"passed": passed, "roomId": task.RoomID})
+	}
+	return task, err
+}
+
+// transition ล็อกงานแล้วเปลี่ยนสถานะ (from = สถานะที่ต้องเป็นอยู่ก่อน)
+func (s *HousekeepingService) transition(id uint, from string, apply func(tx *gorm.DB, task *models.HousekeepingTask, now time.Time) error) (models.HousekeepingTask, error) {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var task models.HousekeepingTask
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrHousekeepingTaskNotFound
+			}
+			return err
+		}
+		if task.Status != from {
+			return ErrHousekeepingTaskState.WithDetails(map[string]interface{}{"status": task.Status, "expected": from})
+		}
+		return apply(tx, &task, time.Now().UTC())
+	})
+	if err != nil {
+		return models.HousekeepingTask{}, err
+	}
+	return s.GetTask(id)
+}
+
+// ---------------- rooms ----------------
+
+// HousekeepingRoom แถวของ board สถานะห้อง
+type HousekeepingRoom struct {
+	ID            uint       `json:"id"`
+	RoomNumber    string     `json:"roomNumber"`
+	Floor         string     `json:"floor"`
+	Occupancy     string     `json:"occupancy"`
+	CleanStatus   string     `json:"cleanStatus"`
+	CleanStatusAt *time.Time `json:"cleanStatusAt,omitempty"`
+	ServiceStatus string     `json:"serviceStatus"`
+}
+
+func housekeepingRoom(r models.Room) HousekeepingRoom {
+	return HousekeepingRoom{
+		ID:            r.ID,
+		RoomNumber:    r.RoomNumber,
+		Floor:         r.Floor,
+		Occupancy:     r.Occupancy,
+		CleanStatus:   r.CleanStatus,
+		CleanStatusAt: r.CleanStatusAt,
+		ServiceStatus: r.ServiceStatus,
+	}
+}
+
+// RoomBoard สถานะความสะอาด + การเข้าพักของทุกห้อง (กรองชั้น / สถานะความสะอาดได้)
+func (s *HousekeepingService) RoomBoard(floor, cleanStatus string) ([]HousekeepingRoom, error) {
+	var rooms []models.Room
+	q := s.DB.Select("id", "room_number", "floor", "clean_status", "clean_status_at", "service_status")
+	if floor != "" {
+		q = q.Where("floor = ?", floor)
+	}
+	if cleanStatus != "" {
+		q = q.Where("clean_status = ?", cleanStatus)
+	}
+	if err := q.Order("floor, room_number").Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	if err := FillRoomOccupancy(s.DB, rooms); err != nil {
+		return nil, err
+	}
+	out := make([]HousekeepingRoom, len(rooms))
+	for i, r := range rooms {
+		out[i] = housekeepingRoom(r)
+	}
+	return out, nil
+}
+
+// SetRoomCleanStatus ตั้งสถานะความสะอาดของห้องเอง (เช่น แจ้งห้องสกปรกระหว่างเข้าพัก) ตามกติกาของ RoomStatusService
+func (s *HousekeepingService) SetRoomCleanStatus(roomID uint, status string, actor AdminActor) (HousekeepingRoom, error) {
+	room, err := NewRoomStatusService(s.DB).UpdateStatus(roomID, RoomStatusInput{CleanStatus: &status, Source: RoomStatusSourceHousekeeping}, actor)
+	if err != nil {
+		return HousekeepingRoom{}, err
+	}
+	return housekeepingRoom(room), nil
+}
+
+func (s *HousekeepingService) audit(actor AdminActor, action string, taskID uint, details map[string]interface{}) {
+	target := "housekeeping_task"
+	if taskID != 0 {
+		target = fmt.Sprintf("housekeeping_task:%d", taskID)
+	}
+	RecordAudit(s.DB, AuditEntry{
+		ActorType: ActorAdmin,
+		ActorID:   strconv.FormatUint(uint64(actor.AdminID), 10),
+		Action:    action,
+		Target:    target,
+		IP:        actor.IP,
+		Details:   details,
+	})
+}
@@ -3,12 +3,42 @@ package services
 import (
 	"encoding/base64"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 )
 
+const uploadsDir = "uploads"
+
+// UploadedFilePath แปลง path ที่เก็บใน DB ("faces/x.jpg" หรือ "uploads/faces/x.jpg") เป็น path จริงใต้ uploads/
+// คืน false ถ้าว่างหรือชี้ออกนอก uploads/ (เช่น "../.env", "/etc/passwd")
+func UploadedFilePath(p string) (string, bool) {
+	rel := filepath.ToSlash(strings.TrimSpace(p))
+	if rel == "" || filepath.IsAbs(rel) || strings.HasPrefix(rel, "/") {
+		return "", false
+	}
+	rel = strings.TrimPrefix(strings.TrimPrefix(rel, "./"), uploadsDir+"/")
+	full := filepath.Join(uploadsDir, filepath.FromSlash(rel))
+	if !strings.HasPrefix(full, uploadsDir+string(filepath.Separator)) {
+		return "", false
+	}
+	return full, true
+}
+
+// removeUploadedFiles ลบไฟล์ใต้ uploads/ แบบ best-effort (path นอก uploads/ ถูกข้าม)
+func removeUploadedFiles(paths []string) {
+	for _, p := range paths {
+		full, ok := UploadedFilePath(p)
+		if !ok {
+			continue
+		}
+		if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+			log.Printf("remove upload %s failed: %v", full, err)
+		}
+	}
+}
 
 func SaveBase64Image(b64 string, subdir string) (string, error) {
 	if idx := strings.Index(b64, "base64,"); idx >= 0 {
@@ -20,7 +50,7 @@ func SaveBase64Image(b64 string, subdir string) (string, error) {
 		return "", fmt.Errorf("decode base64: %w", err)
 	}
 
-	dir := filepath.Join("uploads", subdir)
+	dir := filepath.Join(uploadsDir, subdir)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return "", fmt.Errorf("mkdir uploads dir: %w", err)
 	}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrKioskUnauthorized     = NewError(KindUnauthorized, "error.kioskUnauthorized")
+	ErrKioskDeviceNotFound   = NewError(KindNotFound, "error.kioskNotFound")
+	ErrHotelNotFound         = NewError(KindNotFound, "error.hotelNotFound")
+	ErrKioskLookupFailed     = ErrBookingNotFound.Variant("error.bookingNotFound.kiosk") // ไม่พบ หรือ นามสกุลไม่ตรง (ไม่แยกให้รู้)
+	ErrKioskCodeExpired      = ErrCheckinCodeExpired.Variant("error.codeExpired.kiosk")
+	ErrKioskLookupKeyMissing = ErrInvalidPayload.Variant("error.invalidPayload.kioskLookup")
+)
+
+// Kiosk document types
+const (
+	KioskDocIDCard   = "idcard"
+	KioskDocPassport = "passport"
+)
+
+// Flag ที่ kiosk ตั้งเองตอน complete → ส่งเข้าคิว staff
+const (
+	KioskFlagGuestCount  = "guest_count_exceeds_booking"
+	KioskFlagMissingFace = "missing_face_photo"
+	KioskFlagNameMatch   = "name_mismatch"
+)
+
+var kioskFlagPattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// KioskLookupInput ค้นการจองได้ 3 แบบ (อย่างใดอย่างหนึ่ง) + นามสกุลเสมอ
+type KioskLookupInput struct {
+	CheckinCode string
+	QR          string
+	Reference   string
+	Surname     string
+}
+
+// KioskService ขั้นตอนเช็คอินด้วยตนเองที่ lobby; ทุก action ถูกบันทึก audit ในนามของเครื่อง kiosk
+type KioskService struct {
+	DB       *gorm.DB
+	Bookings *BookingService
+	Infos    *BookingInfoService
+	Cases    *VerificationCaseService
+}
+
+func NewKioskService(db *gorm.DB, bookings *BookingService, infos *BookingInfoService, cases *VerificationCaseService) *KioskService {
+	return &KioskService{DB: db, Bookings: bookings, Infos: infos, Cases: cases}
+}
+
+func hashKioskToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ---------------------------
+// Device registration (staff)
+// ---------------------------
+
+// RegisterDevice สร้างเครื่อง kiosk ผูกกับโรงแรม และคืน token จริง (แสดงได้ครั้งเดียว)
+func (s *KioskService) RegisterDevice(hotelSettingID uint, name string) (models.KioskDevice, string, error) {
+	var hotel models.HotelSetting
+	if err := s.DB.First(&hotel, hotelSettingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.KioskDevice{}, "", ErrHotelNotFound
+		}
+		return models.KioskDevice{}, "", err
+	}
+
+	raw, err := utils.GenerateSecureToken(24)
+	if err != nil {
+		return models.KioskDevice{}, "", fmt.Errorf("failed to generate kiosk token: %w", err)
+	}
+	token := "kiosk_" + raw
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Lobby kiosk"
+	}
+	device := models.KioskDevice{
+		HotelSettingID: hotel.ID,
+		Name:           name,
+		TokenHash:      hashKioskToken(token),
+		TokenPrefix:    token[:12],
+		Active:         true,
+	}
+	if err := s.DB.Create(&device).Error; err != nil {
+		return models.KioskDevice{}, "", err
+	}
+	return device, token, nil
+}
+
+func (s *KioskService) ListDevices() ([]models.KioskDevice, error) {
+	var out []models.KioskDevice
+	if err := s.DB.Order("id").Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RevokeDevice ปิดการใช้งาน token ของเครื่อง (ลงทะเบียนใหม่เพื่อออก token ใหม่)
+func (s *KioskService) RevokeDevice(id uint) error {
+	now := time.Now().UTC()
+	res := s.DB.Model(&models.KioskDevice{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"active": false, "revoked_at": now})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrKioskDeviceNotFound
+	}
+	return nil
+}
+
+// Authenticate หาเครื่องจาก token และอัปเดต last seen
+func (s *KioskService) Authenticate(token, ip string) (models.KioskDevice, error) {
+	var device models.KioskDevice
+	if strings.TrimSpace(token) == "" {
+		return device, ErrKioskUnauthorized
+	}
+	if err := s.DB.Where("token_hash = ? AND active = ?", hashKioskToken(token), true).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return device, ErrKioskUnauthorized
+		}
+		return device, err
+	}
+	now := time.Now().UTC()
+	s.DB.Model(&device).Updates(map[string]interface{}{"last_seen_at": now, "last_ip": ip})
+	return device, nil
+}
+
+// ---------------------------
+// Guest flow (kiosk)
+// ---------------------------
+
+// Lookup หา booking จาก check-in code / QR / reference แล้วยืนยันด้วยนามสกุล
+// คืน session (BookingInfo) ที่ใช้ได้ — ถ้าไม่มีจะเปิดใหม่โดยไม่ส่งลิงก์
+func (s *KioskService) Lookup(device models.KioskDevice, ip string, in KioskLookupInput) (models.BookingInfo, models.Booking, error) {
+	// ตรวจนามสกุลก่อนบอกว่า code หมดอายุ — code ผิดกับนามสกุลผิดได้ error เดียวกัน
+	bookingID, expired, err := s.resolveBookingID(in)
+	if err == nil && !s.surnameMatches(bookingID, in.Surname) {
+		err = ErrKioskLookupFailed
+	}
+	if err == nil && expired {
+		err = ErrKioskCodeExpired
+	}
+	if err != nil {
+		s.audit(device, ip, "kiosk.lookup_failed", "", map[string]interface{}{"reason": err.Error()})
+		return models.BookingInfo{}, models.Booking{}, err
+	}
+
+	bi, err := s.Bookings.Sessions.OpenOnSite(bookingID)
+	if err != nil {
+		return models.BookingInfo{}, models.Booking{}, err
+	}
+
+	var booking models.Booking
+	if err := s.DB.Preload("Customer").Preload("Rooms.Room").First(&booking, bookingID).Error; err != nil {
+		return bi, booking, err
+	}
+
+	s.audit(device, ip, "kiosk.lookup", fmt.Sprintf("booking:%d", bookingID), map[string]interface{}{"bookingInfoId": bi.ID})
+	return bi, booking, nil
+}
+
+// resolveBookingID หา booking จาก code / QR / หมายเลขการจอง; expired = check-in code หมดอายุ
+func (s *KioskService) resolveBookingID(in KioskLookupInput) (uint, bool, error) {
+	switch {
+	case strings.TrimSpace(in.CheckinCode) != "":
+		norm := utils.NormalizeCheckinCode(in.CheckinCode)
+		if len(norm) != 8 {
+			return 0, false, ErrKioskLookupFailed
+		}
+		bi, expired, err := s.Infos.FindByCodeWithExpiry(norm[:4]+"-"+norm[4:], norm)
+		if err != nil {
+			return 0, false, lookupErr(err)
+		}
+		return bi.BookingID, expired, nil
+
+	case strings.TrimSpace(in.QR) != "":
+		// QR เป็นลิงก์/token ที่อาจหมดอายุแล้ว — ที่ kiosk ใช้แค่ระบุ booking แล้วเปิด session ใหม่ได้
+		bi, _, err := s.Infos.ResolveScanPayload(in.QR)
+		if err != nil {
+			return 0, false, lookupErr(err)
+		}
+		return bi.BookingID, false, nil
+
+	case strings.TrimSpace(in.Reference) != "":
+		var booking models.Booking
+		if err := s.DB.Where("reference_code = ?", strings.TrimSpace(in.Reference)).First(&booking).Error; err != nil {
+			return 0, false, lookupErr(err)
+		}
+		return booking.ID, false, nil
+	}
+	return 0, false, ErrKioskLookupKeyMissing
+}
+
+func lookupErr(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, ErrInvalidScanPayload) {
+		return ErrKioskLookupFailed
+	}
+	return err
+}
+
+// surnameMatches นามสกุลที่แขกพิมพ์ต้องตรงกับคำในชื่อลูกค้าทั้งคำ (หลายคำได้ เช่น "van der berg")
+// ไม่นับ substring — "an" ต้องไม่ผ่านกับ "Anna Brown"
+func (s *KioskService) surnameMatches(bookingID uint, surname string) bool {
+	var booking models.Booking
+	if err := s.DB.Preload("Customer").First(&booking, bookingID).Error; err != nil {
+		return false
+	}
+	return nameMatches(booking.Customer.FullName, surname)
+}
+
+// nameMatches query ต้องเป็นคำเต็มที่ต่อกันใน fullName (อย่างน้อย 2 ตัวอักษร)
+func nameMatches(fullName, query string) bool {
+	want := nameTokens(query)
+	if len(want) == 0 || len([]rune(strings.Join(want, ""))) < 2 {
+		return false
+	}
+	have := nameTokens(fullName)
+	for i := 0; i+len(want) <= len(have); i++ {
+		if strings.Join(have[i:i+len(want)], " ") == strings.Join(want, " ") {
+			return true
+		}
+	}
+	return false
+}
+
+// nameTokens แยกชื่อเป็นคำตัวพิมพ์เล็ก (ตัดช่องว่าง / จุลภาค / จุด)
+func nameTokens(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == '.'
+	})
+}
+
+// ReadDocument บันทึกรูปเอกสารแล้วอ่านข้อมูลด้วย OCR
+// ถ้า OCR ล้มเหลวยังคืน path ของรูป (kiosk ให้แขกกรอกเองได้ และควรส่ง flag ตอน complete)
+func (s *KioskService) ReadDocument(device models.KioskDevice, ip, token, docType, image string) (map[string]interface{}, string, error) {
+	bi, err := s.Bookings.Drafts.session(token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	docType = strings.ToLower(strings.TrimSpace(docType))
+	if docType != KioskDocIDCard && docType != KioskDocPassport {
+		return nil, "", ErrUnsupportedDocument
+	}
+
+	b64 := stripDataURI(image)
+	path, err := SaveBase64Image(b64, "documents")
+	if err != nil {
+		return nil, "", ErrInvalidImage
+	}
+	if err := s.Bookings.Drafts.RecordUpload(bi, path); err != nil {
+		return nil, "", err
+	}
+
+	var fields map[string]interface{}
+	if docType == KioskDocPassport {
+		fields, err = DoPassportOCR(b64)
+	} else {
+		fields, err = DoOCR(b64)
+	}
+
+	details := map[string]interface{}{"documentType": docType, "ok": err == nil}
+	s.audit(device, ip, "kiosk.document_read", fmt.Sprintf("booking_info:%d", bi.ID), details)
+	if err != nil {
+		return nil, path, fmt.Errorf("%w: %v", ErrDocumentReadFailed, err)
+	}
+	return fields, path, nil
+}
+
+// CaptureFace บันทึกรูปใบหน้าจากกล้อง kiosk คืน path สำหรับใส่ในขั้น documents
+func (s *KioskService) CaptureFace(device models.KioskDevice, ip, token, image string) (string, error) {
+	bi, err := s.Bookings.Drafts.session(token)
+	if err != nil {
+		return "", err
+	}
+	path, err := SaveBase64Image(stripDataURI(image), "faces")
+	if err != nil {
+		return "", ErrInvalidImage
+	}
+	if err := s.Bookings.Drafts.RecordUpload(bi, path); err != nil {
+		return "", err
+	}
+	s.audit(device, ip, "kiosk.face_captured", fmt.Sprintf("booking_info:%d", bi.ID), nil)
+	return path, nil
+}
+
+// SaveStep บันทึกขั้นของ draft (guests / documents / consents / arrival_time) ผ่าน CheckInDraftService
+func (s *KioskService) SaveStep(device models.KioskDevice, ip, token, step string, data json.RawMessage) (CheckInDraftState, error) {
+	state, err := s.Bookings.Drafts.SaveStep(token, step, data)
+	if err != nil {
+		return state, err
+	}
+	action := "kiosk.step_saved"
+	if step == DraftStepConsents {
+		action = "kiosk.consents_accepted"
+	}
+	s.audit(device, ip, action, fmt.Sprintf("booking_info:%d", state.BookingInfoID), map[string]interface{}{"step": step})
+	return state, nil
+}
+
+// Complete จบการเช็คอิน: ถ้ามี flag (จาก kiosk หรือที่ตรวจเอง) จะเปิด case ให้ staff approve ก่อน finalize
+// คืน case != nil เมื่อรอ staff
+func (s *KioskService) Complete(device models.KioskDevice, ip, token string, clientFlags []string) (CheckInDraftState, *models.VerificationCase, error) {
+	bi, err := s.Bookings.Drafts.session(token)
+	if err != nil {
+		return CheckInDraftState{}, nil, err
+	}
+
+	state, err := s.Bookings.Drafts.Get(token)
+	if err != nil {
+		return state, nil, err
+	}
+
+	if pending, err := s.Cases.PendingForBookingInfo(bi.ID); err != nil {
+		return state, nil, err
+	} else if pending != nil {
+		return state, pending, nil
+	}
+
+	if len(state.MissingSteps) > 0 {
+		return state, nil, ErrDraftIncomplete.WithDetails(map[string]interface{}{"missingSteps": state.MissingSteps})
+	}
+
+	var booking models.Booking
+	if err := s.DB.Preload("Customer").First(&booking, bi.BookingID).Error; err != nil {
+		return state, nil, err
+	}
+
+	flags := kioskFlags(booking, state)
+	for _, f := range clientFlags {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if kioskFlagPattern.MatchString(f) && !hasStep(flags, f) {
+			flags = append(flags, f)
+		}
+	}
+
+	target := fmt.Sprintf("booking:%d", booking.ID)
+	if len(flags) > 0 {
+		deviceID := device.ID
+		vc, err := s.Cases.Open(OpenCaseInput{
+			BookingID:     booking.ID,
+			BookingInfoID: bi.ID,
+			KioskDeviceID: &deviceID,
+			Source:        CaseSourceKiosk,
+			Reasons:       flags,
+			Details:       map[string]interface{}{"guests": len(state.Guests)},
+			BlocksCheckIn: true,
+		})
+		if err != nil {
+			return state, nil, err
+		}
+		s.audit(device, ip, "kiosk.flagged", target, map[string]interface{}{"caseId": vc.ID, "reasons": flags})
+		return state, &vc, nil
+	}
+
+	state, err = s.Bookings.Drafts.Submit(token)
+	if err != nil {
+		return state, nil, err
+	}
+	s.audit(device, ip, "kiosk.completed", target, map[string]interface{}{"bookingInfoId": bi.ID, "guests": len(state.Guests)})
+	return state, nil, nil
+}
+
+// GetCase ให้ kiosk ตามสถานะ case ของตัวเองเท่านั้น
+func (s *KioskService) GetCase(device models.KioskDevice, id uint) (models.VerificationCase, error) {
+	vc, err := s.Cases.Get(id)
+	if err != nil {
+		return vc, err
+	}
+	if vc.KioskDeviceID == nil || *vc.KioskDeviceID != device.ID {
+		return models.VerificationCase{}, ErrCaseNotFound
+	}
+	return vc, nil
+}
+
+// kioskFlags ตรวจสิ่งที่ staff ควรดูก่อนปล่อยให้เช็คอินเอง
+func kioskFlags(booking models.Booking, state CheckInDraftState) []string {
+	flags := []string{}
+
+	if expected := booking.Adults + booking.Children; expected > 0 && len(state.Guests) > expected {
+		flags = append(flags, KioskFlagGuestCount)
+	}
+
+	withFace := map[int]bool{}
+	for _, d := range state.Documents {
+		if strings.TrimSpace(d.FaceImagePath) != "" {
+			withFace[d.GuestIndex] = true
+		}
+	}
+	for i, g := range state.Guests {
+		if g.IsMainGuest && !withFace[i] {
+			flags = append(flags, KioskFlagMissingFace)
+		}
+		if g.IsMainGuest && !sharesNamePart(g.FullName, booking.Customer.FullName) {
+			flags = append(flags, KioskFlagNameMatch)
+		}
+	}
+	return flags
+}
+
+func sharesNamePart(a, b string) bool {
+	parts := strings.Fields(strings.ToLower(b))
+	for _, p := range strings.Fields(strings.ToLower(a)) {
+		for _, q := range parts {
+			if len([]rune(p)) >= 2 && p == q {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func stripDataURI(b64 string) string {
+	b64 = strings.TrimSpace(b64)
+	if idx := strings.Index(b64, "base64,"); idx >= 0 {
+		return b64[idx+7:]
+	}
+	return b64
+}
+
+func (s *KioskService) audit(device models.KioskDevice, ip, action, target string, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["hotelSettingId"] = device.HotelSettingID
+	RecordAudit(s.DB, AuditEntry{
+		ActorType: ActorKiosk,
+		ActorID:   fmt.Sprintf("%d", device.ID),
+		Action:    action,
+		Target:    target,
+		IP:        ip,
+		Details:   details,
+	})
+}
@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//
+// ===========================================================
+//  LIST QUERY (paging / filter / sort ร่วมของ list endpoints)
+// ===========================================================
+//
+// query string ที่รองรับ:
+//
+//	?limit=50&offset=100        (หรือ ?page=3)
+//	?cursor=<จาก X-Next-Cursor>  (keyset; ใช้กับ sort ได้ field เดียว)
+//	?sort=-checkIn,referenceCode (ชื่อต้องอยู่ใน ListSpec.Sorts, "-" = มากไปน้อย)
+//	?from=2025-01-01&to=2025-01-31 (กรอง ListSpec.DateColumn, to รวมทั้งวัน)
+//	?status=Confirmed,Checked-In&roomId=3 ... (ตาม ListSpec.Filters)
+//
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 200
+)
+
+type FilterKind int
+
+const (
+	FilterText FilterKind = iota // ค่าเดียว เทียบตรง ๆ
+	FilterList                   // คั่นด้วย comma → IN ?
+	FilterID                     // id (> 0)
+)
+
+// ListFilter เงื่อนไขของ query param หนึ่งตัว
+// Cond มี ? หนึ่งตัว หรือใช้ @v เมื่อต้องอ้างค่าซ้ำหลายที่
+type ListFilter struct {
+	Cond string
+	Kind FilterKind
+}
+
+// ListSpec whitelist ของ endpoint: sort/filter ได้เฉพาะที่ประกาศไว้
+type ListSpec struct {
+	IDColumn    string                // tie-breaker + cursor เช่น "bookings.id"
+	Sorts       map[string]string     // ชื่อใน ?sort= → column
+	DefaultSort string                // เช่น "-createdAt"
+	DateColumn  string                // column ที่ ?from= / ?to= กรอง (ว่าง = ไม่รองรับ)
+	Filters     map[string]ListFilter // ?<name>= → เงื่อนไข
+	MaxLimit    int                   // 0 = MaxListLimit
+}
+
+type SortField struct {
+	Name   string
+	Column string
+	Desc   bool
+}
+
+// ListQuery ผลจาก ParseListQuery (ใช้กับ FindPage)
+type ListQuery struct {
+	Limit  int
+	Offset int
+	Sort   []SortField
+
+	cursor *listCursor
+	where  []listClause
+}
+
+// Cursor true เมื่อ request ใช้ ?cursor= (keyset) แทน offset
+func (q ListQuery) Cursor() bool { return q.cursor != nil }
+
+type listClause struct {
+	cond string
+	arg  any
+}
+
+// listCursor ค่าของแถวสุดท้ายในหน้าก่อน (base64url ของ JSON)
+type listCursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v,omitempty"`
+	ID    json.RawMessage `json:"id"`
+}
+
+// PageInfo ผลการแบ่งหน้า (controller ใช้ทำ X-Total-Count / Link)
+type PageInfo struct {
+	Total      int64
+	Limit      int
+	Offset     int
+	Count      int // จำนวนแถวในหน้านี้
+	Cursor     bool
+	NextCursor string // ว่าง = หน้าสุดท้าย
+}
+
+// ListParamError query param ไม่ถูกต้อง (Rule/Value ใช้ทำข้อความ validation.*)
+type ListParamError struct {
+	Param string
+	Rule  string
+	Value string
+}
+
+func (e *ListParamError) Error() string {
+	return fmt.Sprintf("invalid list parameter %q (%s %s)", e.Param, e.Rule, e.Value)
+}
+
+// ParseListQuery อ่าน paging/sort/filter จาก query string ตาม spec
+func ParseListQuery(values url.Values, spec ListSpec) (ListQuery, error) {
+	maxLimit := spec.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = MaxListLimit
+	}
+	q := ListQuery{Limit: DefaultListLimit}
+	if q.Limit > maxLimit {
+		q.Limit = maxLimit
+	}
+
+	if v := strings.TrimSpace(values.Get("limit")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return q, &ListParamError{Param: "limit", Rule: "gte", Value: "1"}
+		}
+		if n > maxLimit {
+			return q, &ListParamError{Param: "limit", Rule: "lte", Value: strconv.Itoa(maxLimit)}
+		}
+		q.Limit = n
+	}
+	if v := strings.TrimSpace(values.Get("offset")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return q, &ListParamError{Param: "offset", Rule: "gte", Value: "0"}
+		}
+		q.Offset = n
+	} else if v := strings.TrimSpace(values.Get("page")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return q, &ListParamError{Param: "page", Rule: "gte", Value: "1"}
+		}
+		q.Offset = (n - 1) * q.Limit
+	}
+
+	sortParam := strings.TrimSpace(values.Get("sort"))
+	if sortParam == "" {
+		sortParam = spec.DefaultSort
+	}
+	for _, part := range strings.Split(sortParam, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		sf := SortField{Name: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		col, ok := spec.Sorts[sf.Name]
+		if !ok {
+			return q, &ListParamError{Param: "sort", Rule: "oneof", Value: strings.Join(spec.SortNames(), ", ")}
+		}
+		sf.Column = col
+		q.Sort = append(q.Sort, sf)
+	}
+
+	if v := strings.TrimSpace(values.Get("cursor")); v != "" {
+		if q.Offset > 0 {
+			return q, &ListParamError{Param: "cursor", Rule: "invalid"}
+		}
+		if len(q.Sort) > 1 {
+			return q, &ListParamError{Param: "sort", Rule: "max", Value: "1"}
+		}
+		cur, err := decodeListCursor(v)
+		if err != nil || cur.Sort != sortKey(q.Sort) {
+			return q, &ListParamError{Param: "cursor", Rule: "invalid"}
+		}
+		q.cursor = cur
+	}
+
+	if spec.DateColumn != "" {
+		if v := strings.TrimSpace(values.Get("from")); v != "" {
+			t, _, err := parseListDate(v)
+			if err != nil {
+				return q, &ListParamError{Param: "from", Rule: "dateonly"}
+			}
+			q.where = append(q.where, listClause{spec.DateColumn + " >= ?", t})
+		}
+		if v := strings.TrimSpace(values.Get("to")); v != "" {
+			t, dateOnly, err := parseListDate(v)
+			if err != nil {
+				return q, &ListParamError{Param: "to", Rule: "dateonly"}
+			}
+			if dateOnly {
+				q.where = append(q.where, listClause{spec.DateColumn + " < ?", t.AddDate(0, 0, 1)})
+			} else {
+				q.where = append(q.where, listClause{spec.DateColumn + " <= ?", t})
+			}
+		}
+	}
+
+	names := make([]string, 0, len(spec.Filters))
+	for name := range spec.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := spec.Filters[name]
+		v := strings.TrimSpace(values.Get(name))
+		if v == "" {
+			continue
+		}
+		switch f.Kind {
+		case FilterID:
+			id, err := strconv.ParseUint(v, 10, 64)
+			if err != nil || id == 0 {
+				return q, &ListParamError{Param: name, Rule: "gt", Value: "0"}
+			}
+			q.where = append(q.where, listClause{f.Cond, uint(id)})
+		case FilterList:
+			var list []string
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			if len(list) == 0 {
+				continue
+			}
+			q.where = append(q.where, listClause{f.Cond, list})
+		default:
+			q.where = append(q.where, listClause{f.Cond, v})
+		}
+	}
+	return q, nil
+}
+
+// SortNames ชื่อ sort ที่ spec รองรับ (เรียงตามตัวอักษร)
+func (spec ListSpec) SortNames() []string {
+	names := make([]string, 0, len(spec.Sorts))
+	for name := range spec.Sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply ใส่เงื่อนไข filter / from / to ของ q ลงใน db (ไม่รวม sort, limit, cursor)
+func (q ListQuery) Apply(db *gorm.DB) *gorm.DB {
+	for _, w := range q.where {
+		if strings.Contains(w.cond, "@v") {
+			db = db.Where(w.cond, sql.Named("v", w.arg))
+		} else {
+			db = db.Where(w.cond, w.arg)
+		}
+	}
+	return db
+}
+
+// FindPage รัน query ของ db (ที่ scope/Where ไว้แล้ว) ตาม q ใส่ผลลง dest (*[]Model)
+// Count ทำก่อน preload เพื่อไม่ให้ preload ไปโหลดทั้งตาราง
+func FindPage(db *gorm.DB, spec ListSpec, q ListQuery, dest any, preloads ...string) (PageInfo, error) {
+	page := PageInfo{Limit: q.Limit, Offset: q.Offset, Cursor: q.cursor != nil}
+
+	base := q.Apply(db.Model(dest)).Session(&gorm.Session{})
+
+	if err := base.Count(&page.Total).Error; err != nil {
+		return page, err
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(dest); err != nil {
+		return page, err
+	}
+
+	find := base
+	for _, p := range preloads {
+		find = find.Preload(p)
+	}
+	if q.cursor != nil {
+		cond, args, err := cursorCondition(stmt, spec, q, q.cursor)
+		if err != nil {
+			return page, err
+		}
+		find = find.Where(cond, args...)
+	} else if q.Offset > 0 {
+		find = find.Offset(q.Offset)
+	}
+	idDesc := false
+	for _, s := range q.Sort {
+		find = find.Order(orderExpr(s.Column, s.Desc))
+		idDesc = s.Desc
+	}
+	if !sortsByID(spec, q.Sort) {
+		find = find.Order(orderExpr(spec.IDColumn, idDesc))
+	}
+
+	// ขอเกินมา 1 แถวเพื่อรู้ว่ามีหน้าถัดไปไหม
+	if err := find.Limit(q.Limit + 1).Find(dest).Error; err != nil {
+		return page, err
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	hasNext := rows.Len() > q.Limit
+	if hasNext {
+		rows.Set(rows.Slice(0, q.Limit))
+	}
+	page.Count = rows.Len()
+	if hasNext && page.Count > 0 {
+		cur, err := cursorFor(stmt, spec, q.Sort, rows.Index(page.Count-1))
+		if err != nil {
+			return page, err
+		}
+		page.NextCursor = cur
+	}
+	return page, nil
+}
+
+func orderExpr(column string, desc bool) string {
+	if desc {
+		return column + " DESC"
+	}
+	return column + " ASC"
+}
+
+func sortsByID(spec ListSpec, sorts []SortField) bool {
+	return len(sorts) > 0 && sorts[len(sorts)-1].Column == spec.IDColumn
+}
+
+func sortKey(sorts []SortField) string {
+	if len(sorts) == 0 {
+		return ""
+	}
+	if sorts[0].Desc {
+		return "-" + sorts[0].Name
+	}
+	return sorts[0].Name
+}
+
+// cursorCondition: แถวที่อยู่ "หลัง" cursor ตามลำดับ sort (MySQL: NULL มาก่อนใน ASC, หลังสุดใน DESC)
+func cursorCondition(stmt *gorm.Statement, spec ListSpec, q ListQuery, cur *listCursor) (string, []any, error) {
+	invalid := &ListParamError{Param: "cursor", Rule: "invalid"}
+	id, _, err := decodeCursorValue(stmt, spec.IDColumn, cur.ID)
+	if err != nil {
+		return "", nil, invalid
+	}
+
+	idCol := spec.IDColumn
+	if len(q.Sort) == 0 || sortsByID(spec, q.Sort) {
+		desc := len(q.Sort) > 0 && q.Sort[0].Desc
+		if desc {
+			return idCol + " < ?", []any{id}, nil
+		}
+		return idCol + " > ?", []any{id}, nil
+	}
+
+	s := q.Sort[0]
+	v, isNull, err := decodeCursorValue(stmt, s.Column, cur.Value)
+	if err != nil {
+		return "", nil, invalid
+	}
+	col := s.Column
+	switch {
+	case !s.Desc && !isNull:
+		return fmt.Sprintf("(%s > ? OR (%s = ? AND %s > ?))", col, col, idCol), []any{v, v, id}, nil
+	case !s.Desc && isNull:
+		return fmt.Sprintf("(%s IS NOT NULL OR %s > ?)", col, idCol), []any{id}, nil
+	case s.Desc && !isNull:
+		return fmt.Sprintf("(%s < ? OR %s IS NULL OR (%s = ? AND %s < ?))", col, col, col, idCol), []any{v, v, id}, nil
+	default:
+		return fmt.Sprintf("(%s IS NULL AND %s < ?)", col, idCol), []any{id}, nil
+	}
+}
+
+func cursorFor(stmt *gorm.Statement, spec ListSpec, sorts []SortField, row reflect.Value) (string, error) {
+	cur := listCursor{Sort: sortKey(sorts)}
+	var err error
+	if cur.ID, err = fieldJSON(stmt, spec.IDColumn, row); err != nil {
+		return "", err
+	}
+	if len(sorts) > 0 && !sortsByID(spec, sorts) {
+		if cur.Value, err = fieldJSON(stmt, sorts[0].Column, row); err != nil {
+			return "", err
+		}
+	}
+	raw, err := json.Marshal(cur)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeListCursor(s string) (*listCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var cur listCursor
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		return nil, err
+	}
+	if len(cur.ID) == 0 {
+		return nil, fmt.Errorf("cursor without id")
+	}
+	return &cur, nil
+}
+
+// fieldJSON ค่าของ column ในแถว (ตาม schema ของ gorm) เป็น JSON
+func fieldJSON(stmt *gorm.Statement, column string, row reflect.Value) (json.RawMessage, error) {
+	field := stmt.Schema.LookUpField(columnName(column))
+	if field == nil {
+		return nil, fmt.Errorf("list: column %q is not a field of %s", column, stmt.Schema.Name)
+	}
+	v, zero := field.ValueOf(context.Background(), reflect.Indirect(row))
+	if zero && field.FieldType.Kind() == reflect.Ptr {
+		return json.RawMessage("null"), nil
+	}
+	return json.Marshal(v)
+}
+
+// decodeCursorValue แปลง JSON กลับเป็นชนิดของ field (เช่น time.Time) เพื่อใช้เป็น argument ของ query
+func decodeCursorValue(stmt *gorm.Statement, column string, raw json.RawMessage) (any, bool, error) {
+	field := stmt.Schema.LookUpField(columnName(column))
+	if field == nil {
+		return nil, false, fmt.Errorf("list: column %q is not a field of %s", column, stmt.Schema.Name)
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, true, nil
+	}
+	ptr := reflect.New(field.IndirectFieldType)
+	if err := json.Unmarshal(raw, ptr.Interface()); err != nil {
+		return nil, false, err
+	}
+	return ptr.Elem().Interface(), false, nil
+}
+
+func columnName(column string) string {
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		return column[i+1:]
+	}
+	return column
+}
+
+// parseListDate: YYYY-MM-DD (dateOnly=true) หรือ RFC3339
+func parseListDate(v string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", v, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
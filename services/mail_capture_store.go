@@ -0,0 +1,99 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// DBMailCaptureStore เก็บอีเมลที่ capture ลงตาราง captured_emails
+// ใช้เมื่อมีหลาย instance หรืออยากให้ mailbox อยู่รอดหลัง restart
+type DBMailCaptureStore struct {
+	DB *gorm.DB
+}
+
+func NewDBMailCaptureStore(db *gorm.DB) *DBMailCaptureStore {
+	return &DBMailCaptureStore{DB: db}
+}
+
+func (s *DBMailCaptureStore) Save(msg *utils.CapturedEmail) error {
+	row := models.CapturedEmail{
+		To:        msg.To,
+		Subject:   msg.Subject,
+		Text:      msg.Text,
+		HTML:      msg.HTML,
+		CreatedAt: msg.CreatedAt,
+	}
+	if err := s.DB.Create(&row).Error; err != nil {
+		return err
+	}
+	msg.ID = row.ID
+	return nil
+}
+
+func (s *DBMailCaptureStore) List(to string, limit int) ([]utils.CapturedEmail, error) {
+	q := s.DB.Model(&models.CapturedEmail{}).Order("id DESC")
+	if to = strings.TrimSpace(to); to != "" {
+		q = q.Where("LOWER(to_address) = ?", strings.ToLower(to))
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	var rows []models.CapturedEmail
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]utils.CapturedEmail, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, capturedFromModel(r))
+	}
+	return out, nil
+}
+
+func (s *DBMailCaptureStore) Get(id uint) (utils.CapturedEmail, error) {
+	var row models.CapturedEmail
+	if err := s.DB.First(&row, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.CapturedEmail{}, utils.ErrCapturedEmailNotFound
+		}
+		return utils.CapturedEmail{}, err
+	}
+	return capturedFromModel(row), nil
+}
+
+func (s *DBMailCaptureStore) Clear() error {
+	return s.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.CapturedEmail{}).Error
+}
+
+func capturedFromModel(r models.CapturedEmail) utils.CapturedEmail {
+	return utils.CapturedEmail{
+		ID:        r.ID,
+		To:        r.To,
+		Subject:   r.Subject,
+		Text:      r.Text,
+		HTML:      r.HTML,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// ConfigureMailer เลือก mailer จาก env แล้วตั้งเป็น mailer หลักของระบบ
+// MAIL_CAPTURE_STORE=db -> capture ลง DB, อื่น ๆ -> memory
+func ConfigureMailer(db *gorm.DB) utils.Mailer {
+	var store utils.MailCaptureStore
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("MAIL_CAPTURE_STORE")), "db") && db != nil {
+		store = NewDBMailCaptureStore(db)
+	} else {
+		store = utils.NewMemoryMailStore(200)
+	}
+
+	mailer := utils.MailerFromEnv(store)
+	utils.SetMailer(mailer)
+	log.Printf("✉️  Mailer configured: %s", mailer.Name())
+	return mailer
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// ---------------------------
+// Channels
+// ---------------------------
+
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelLINE  = "line"
+)
+
+// AllChannels ช่องทางที่ระบบรองรับ
+var AllChannels = []string{ChannelEmail, ChannelSMS, ChannelLINE}
+
+// Delivery statuses stored on BookingInfo (email_status / sms_status / line_status)
+const (
+	DeliveryPending = "PENDING"
+	DeliverySent    = "SENT"
+	DeliveryFailed  = "FAILED"
+	DeliverySkipped = "SKIPPED"
+)
+
+// Recipient คือปลายทางของแต่ละช่องทาง
+type Recipient struct {
+	Email      string
+	Phone      string
+	LineUserID string
+}
+
+// Notification is a message type rendered per channel (email template or short text).
+type Notification struct {
+	Kind      string // utils.EmailCheckInInvite, ...
+	Locale    string
+	Recipient Recipient
+	Data      interface{}
+}
+
+// NotificationChannel delivers a notification through one medium.
+type NotificationChannel interface {
+	Name() string
+	// Configured reports whether the provider has enough config to send.
+	Configured() bool
+	Send(n Notification) error
+}
+
+var errNoRecipient = errors.New("no recipient for channel")
+
+// ParseChannels แปลง "email, SMS ,line" -> []string{"email","sms","line"} (ตัดตัวที่ไม่รู้จัก/ซ้ำ)
+func ParseChannels(raw string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, part := range strings.Split(raw, ",") {
+		ch := strings.ToLower(strings.TrimSpace(part))
+		if !IsKnownChannel(ch) || seen[ch] {
+			continue
+		}
+		seen[ch] = true
+		out = append(out, ch)
+	}
+	if len(out) == 0 {
+		out = []string{ChannelEmail}
+	}
+	return out
+}
+
+// IsKnownChannel checks the channel name against AllChannels.
+func IsKnownChannel(ch string) bool {
+	for _, c := range AllChannels {
+		if c == ch {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------------------------
+// Email
+// ---------------------------
+
+// EmailChannel ส่งผ่าน mailer ปัจจุบัน (smtp / capture)
+type EmailChannel struct{}
+
+func (EmailChannel) Name() string     { return ChannelEmail }
+func (EmailChannel) Configured() bool { return true }
+
+func (EmailChannel) Send(n Notification) error {
+	if strings.TrimSpace(n.Recipient.Email) == "" {
+		return errNoRecipient
+	}
+	// ข้อความที่มีลิงก์เช็คอิน -> แนบ QR inline
+	if d, ok := n.Data.(utils.CheckInEmailData); ok {
+		return utils.SendCheckInEmail(n.Recipient.Email, n.Kind, n.Locale, d)
+	}
+	return utils.SendTemplatedEmail(n.Recipient.Email, n.Kind, n.Locale, n.Data)
+}
+
+// ---------------------------
+// SMS (generic HTTP/JSON provider)
+// ---------------------------
+
+// SMSChannel posts {to, from, text} to SMS_API_URL.
+// ใช้ได้ทั้ง gateway จริงและ stub server ในเครื่อง
+type SMSChannel struct {
+	BaseURL string
+	APIKey  string
+	Sender  string
+	Client  *http.Client
+}
+
+func NewSMSChannelFromEnv() *SMSChannel {
+	return &SMSChannel{
+		BaseURL: strings.TrimRight(strings.TrimSpace(os.Getenv("SMS_API_URL")), "/"),
+		APIKey:  strings.TrimSpace(os.Getenv("SMS_API_KEY")),
+		Sender:  utils.EnvOrDefault("SMS_SENDER", "HOTEL"),
+		Client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (c *SMSChannel) Name() string     { return ChannelSMS }
+func (c *SMSChannel) Configured() bool { return c.BaseURL != "" }
+
+func (c *SMSChannel) Send(n Notification) error {
+	to := NormalizePhoneE164(n.Recipient.Phone)
+	if to == "" {
+		return errNoRecipient
+	}
+	text, err := utils.RenderShortMessage(n.Kind, n.Locale, n.Data)
+	if err != nil {
+		return err
+	}
+
+	body, _ := json.Marshal(map[string]string{
+		"to":   to,
+		"from": c.Sender,
+		"text": text,
+	})
+	headers := map[string]string{}
+	if c.APIKey != "" {
+		headers["Authorization"] = "Bearer " + c.APIKey
+	}
+	return postJSON(c.Client, c.BaseURL+"/messages", body, headers)
+}
+
+// ---------------------------
+// LINE Messaging API
+// ---------------------------
+
+// LINEChannel ส่ง push message ผ่าน LINE Messaging API
+// LINE_API_BASE_URL ชี้ไป stub ในเครื่องได้ (default https://api.line.me)
+type LINEChannel struct {
+	BaseURL     string
+	AccessToken string
+	Client      *http.Client
+}
+
+func NewLINEChannelFromEnv() *LINEChannel {
+	return &LINEChannel{
+		BaseURL:     strings.TrimRight(utils.EnvOrDefault("LINE_API_BASE_URL", "https://api.line.me"), "/"),
+		AccessToken: strings.TrimSpace(os.Getenv("LINE_CHANNEL_ACCESS_TOKEN")),
+		Client:      &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (c *LINEChannel) Name() string     { return ChannelLINE }
+func (c *LINEChannel) Configured() bool { return c.AccessToken != "" }
+
+func (c *LINEChannel) Send(n Notification) error {
+	to := strings.TrimSpace(n.Recipient.LineUserID)
+	if to == "" {
+		return errNoRecipient
+	}
+	text, err := utils.RenderShortMessage(n.Kind, n.Locale, n.Data)
+	if err != nil {
+		return err
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"to": to,
+		"messages": []map[string]string{
+			{"type": "text", "text": text},
+		},
+	})
+	return postJSON(c.Client, c.BaseURL+"/v2/bot/message/push", body, map[string]string{
+		"Authorization": "Bearer " + c.AccessToken,
+	})
+}
+
+func postJSON(client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("cannot build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return fmt.Errorf("HTTP error %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+var nonDigit = regexp.MustCompile(`[^0-9]`)
+
+// NormalizePhoneE164 แปลงเบอร์ไทย "081-234-5678" -> "+66812345678"
+// เบอร์ที่ขึ้นต้นด้วย + จะคงรหัสประเทศเดิมไว้
+func NormalizePhoneE164(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	plus := strings.HasPrefix(raw, "+")
+	digits := nonDigit.ReplaceAllString(raw, "")
+	if digits == "" {
+		return ""
+	}
+	if plus {
+		return "+" + digits
+	}
+	if strings.HasPrefix(digits, "00") {
+		return "+" + digits[2:]
+	}
+	if strings.HasPrefix(digits, "0") {
+		cc := utils.EnvOrDefault("SMS_DEFAULT_COUNTRY_CODE", "66")
+		return "+" + cc + digits[1:]
+	}
+	return "+" + digits
+}
+
+// ---------------------------
+// NotificationService
+// ---------------------------
+
+// NotificationService ส่ง notification ตามช่องทางที่ booking เลือก และบันทึกสถานะต่อช่องทางบน BookingInfo
+type NotificationService struct {
+	DB       *gorm.DB
+	Channels map[string]NotificationChannel
+}
+
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{
+		DB: db,
+		Channels: map[string]NotificationChannel{
+			ChannelEmail: EmailChannel{},
+			ChannelSMS:   NewSMSChannelFromEnv(),
+			ChannelLINE:  NewLINEChannelFromEnv(),
+		},
+	}
+}
+
+// statusColumns: channel -> (status column, error column) ใน booking_infos
+var statusColumns = map[string][2]string{
+	ChannelEmail: {"email_status", "email_error"},
+	ChannelSMS:   {"sms_status", "sms_error"},
+	ChannelLINE:  {"line_status", "line_error"},
+}
+
+// DeliveryResult สรุปผลต่อช่องทาง
+type DeliveryResult struct {
+	Channel string `json:"channel"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// SendCheckInInvite ส่งลิงก์เช็คอินตาม booking.NotificationChannels
+// คืน error "notification_send_failed" ถ้ามีช่องทางที่เลือกไว้ส่งไม่สำเร็จ (bookingInfo ยังใช้ได้)
+func (s *NotificationService) SendCheckInInvite(
+	booking models.Booking,
+	bookingInfo models.BookingInfo,
+	data utils.CheckInEmailData,
+) ([]DeliveryResult, error) {
+	n := Notification{
+		Kind:   utils.EmailCheckInInvite,
+		Locale: booking.Customer.PreferredLanguage,
+		Recipient: Recipient{
+			Email:      firstNonEmpty(bookingInfo.GuestEmail, booking.Customer.Email),
+			Phone:      booking.Customer.Phone,
+			LineUserID: booking.Customer.LineUserID,
+		},
+		Data: data.Sanitized(),
+	}
+	return s.Dispatch(bookingInfo.ID, ParseChannels(booking.NotificationChannels), n)
+}
+
+// Dispatch ส่ง n ไปทุกช่องทางใน channels แล้วอัปเดตสถานะลง booking_infos (ถ้า bookingInfoID != 0)
+//   - บางช่องทางล้มเหลว -> ErrNotificationSendFailed
+//   - ไม่ล้มเหลวแต่ไม่มีช่องทางไหนส่งได้เลย (ทุกช่องทาง SKIPPED / ไม่มีช่องทาง) -> ErrNotificationNotSent
+func (s *NotificationService) Dispatch(bookingInfoID uint, channels []string, n Notification) ([]DeliveryResult, error) {
+	results := make([]DeliveryResult, 0, len(channels))
+	var failed []string
+
+	for _, name := range channels {
+		res := DeliveryResult{Channel: name}
+		ch, ok := s.Channels[name]
+
+		switch {
+		case !ok || !ch.Configured():
+			res.Status = DeliverySkipped
+			res.Error = "channel not configured"
+		default:
+			err := ch.Send(n)
+			switch {
+			case errors.Is(err, errNoRecipient):
+				res.Status = DeliverySkipped
+				res.Error = err.Error()
+			case err != nil:
+				res.Status = DeliveryFailed
+				res.Error = err.Error()
+				failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+				log.Printf("notification %s via %s failed (booking_info=%d): %v", n.Kind, name, bookingInfoID, err)
+			default:
+				res.Status = DeliverySent
+			}
+		}
+
+		results = append(results, res)
+		s.recordStatus(bookingInfoID, res)
+	}
+
+	if len(failed) > 0 {
+		return results, fmt.Errorf("%w: %s", ErrNotificationSendFailed, strings.Join(failed, "; "))
+	}
+	if !anySent(results) {
+		return results, ErrNotificationNotSent
+	}
+	return results, nil
+}
+
+func (s *NotificationService) recordStatus(bookingInfoID uint, res DeliveryResult) {
+	cols, ok := statusColumns[res.Channel]
+	if !ok || bookingInfoID == 0 || s.DB == nil {
+		return
+	}
+	if err := s.DB.Model(&models.BookingInfo{}).Where("id = ?", bookingInfoID).
+		Updates(map[string]interface{}{cols[0]: res.Status, cols[1]: res.Error}).Error; err != nil {
+		log.Printf("failed to record %s delivery status for booking_info %d: %v", res.Channel, bookingInfoID, err)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"hotel-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//
+// ===========================================================
+//  RATE LIMIT (sliding window + progressive lockout)
+// ===========================================================
+//
+
+// RateLimitLock สถานะ lockout ของ key
+type RateLimitLock struct {
+	Until time.Time
+	Level int
+}
+
+// RateLimitStore เก็บความพยายามที่ล้มเหลวและสถานะ lock (memory หรือ DB)
+type RateLimitStore interface {
+	// AddFailure บันทึกความล้มเหลวที่เวลา now แล้วคืนจำนวนครั้งภายใน window
+	AddFailure(key string, now time.Time, window time.Duration) (int, error)
+	// ResetFailures ลบประวัติความล้มเหลวของ key
+	ResetFailures(key string) error
+	GetLock(key string) (RateLimitLock, error)
+	SetLock(key string, lock RateLimitLock) error
+}
+
+// RateLimitPolicy กำหนดเกณฑ์ของ limiter หนึ่งตัว
+type RateLimitPolicy struct {
+	Name string
+
+	// ต่อ IP
+	IPMaxFailures int
+	// ต่อเป้าหมาย (code / username)
+	TargetMaxFailures int
+
+	Window      time.Duration
+	BaseLockout time.Duration // lock ครั้งแรก; ครั้งต่อไปคูณ 2
+	MaxLockout  time.Duration
+
+	// SensitiveTarget = true จะ hash ค่าเป้าหมายก่อนเก็บ (เช่น check-in code)
+	SensitiveTarget bool
+}
+
+// ระยะที่ไม่มีการโดน lock แล้ว level จะกลับเป็น 0
+const lockLevelDecay = 24 * time.Hour
+
+// Policies ที่ใช้กับ endpoint จริง
+var (
+	CheckinCodePolicy = RateLimitPolicy{
+		Name:              "checkin",
+		IPMaxFailures:     20,
+		TargetMaxFailures: 5,
+		Window:            15 * time.Minute,
+		BaseLockout:       time.Minute,
+		MaxLockout:        time.Hour,
+		SensitiveTarget:   true,
+	}
+	LoginPolicy = RateLimitPolicy{
+		Name:              "login",
+		IPMaxFailures:     20,
+		TargetMaxFailures: 5,
+		Window:            15 * time.Minute,
+		BaseLockout:       time.Minute,
+		MaxLockout:        time.Hour,
+	}
+)
+
+var (
+	rateLimitMu      sync.RWMutex
+	rateLimitStore   RateLimitStore
+	rateLimitAuditDB *gorm.DB
+)
+
+// ConfigureRateLimiting เลือก store ตาม RATE_LIMIT_STORE (memory | db) และตั้ง DB สำหรับ audit
+func ConfigureRateLimiting(db *gorm.DB) RateLimitStore {
+	var store RateLimitStore
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("RATE_LIMIT_STORE")), "db") && db != nil {
+		store = NewDBRateLimitStore(db)
+	} else {
+		store = NewMemoryRateLimitStore()
+	}
+
+	rateLimitMu.Lock()
+	rateLimitStore = store
+	rateLimitAuditDB = db
+	rateLimitMu.Unlock()
+
+	log.Printf("🛡️  Rate limit store: %T", store)
+	return store
+}
+
+func currentRateLimitStore() (RateLimitStore, *gorm.DB) {
+	rateLimitMu.RLock()
+	store, db := rateLimitStore, rateLimitAuditDB
+	rateLimitMu.RUnlock()
+	if store != nil {
+		return store, db
+	}
+
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+	if rateLimitStore == nil {
+		rateLimitStore = NewMemoryRateLimitStore()
+	}
+	return rateLimitStore, rateLimitAuditDB
+}
+
+// RateLimiter ใช้ policy หนึ่งกับ key ต่อ IP และต่อเป้าหมาย
+type RateLimiter struct {
+	Policy RateLimitPolicy
+	Store  RateLimitStore // nil = ใช้ store ที่ configure ไว้
+	Now    func() time.Time
+}
+
+func NewRateLimiter(policy RateLimitPolicy) *RateLimiter {
+	return &RateLimiter{Policy: policy}
+}
+
+func (l *RateLimiter) store() (RateLimitStore, *gorm.DB) {
+	store, db := currentRateLimitStore()
+	if l.Store != nil {
+		store = l.Store
+	}
+	return store, db
+}
+
+func (l *RateLimiter) now() time.Time {
+	if l.Now != nil {
+		return l.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+func (l *RateLimiter) ipKey(ip string) string {
+	return l.Policy.Name + ":ip:" + ip
+}
+
+func (l *RateLimiter) targetKey(target string) string {
+	target = strings.ToLower(strings.TrimSpace(target))
+	if l.Policy.SensitiveTarget {
+		sum := sha256.Sum256([]byte(target))
+		target = hex.EncodeToString(sum[:12])
+	}
+	return l.Policy.Name + ":target:" + target
+}
+
+// Check คืนเวลาที่ต้องรอถ้า IP หรือเป้าหมายถูก lock อยู่ (0 = ผ่าน)
+func (l *RateLimiter) Check(ip, target string) time.Duration {
+	store, _ := l.store()
+	now := l.now()
+
+	var wait time.Duration
+	for _, key := range l.keys(ip, target) {
+		lock, err := store.GetLock(key)
+		if err != nil {
+			log.Printf("rate limit: get lock %s failed: %v", key, err)
+			continue
+		}
+		if d := lock.Until.Sub(now); d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// Fail บันทึกความล้มเหลว และ lock key ที่เกินเกณฑ์ (คืนระยะ lock ที่ยาวที่สุดที่เพิ่งเกิด)
+func (l *RateLimiter) Fail(ip, target string) time.Duration {
+	store, db := l.store()
+	now := l.now()
+
+	var lockedFor time.Duration
+	check := func(key string, max int, kind string) {
+		if key == "" || max <= 0 {
+			return
+		}
+		count, err := store.AddFailure(key, now, l.Policy.Window)
+		if err != nil {
+			log.Printf("rate limit: add failure %s failed: %v", key, err)
+			return
+		}
+		if count < max {
+			return
+		}
+
+		prev, _ := store.GetLock(key)
+		level := prev.Level
+		if !prev.Until.IsZero() && now.Sub(prev.Until) > lockLevelDecay {
+			level = 0
+		}
+		level++
+
+		d := l.Policy.BaseLockout << (level - 1)
+		if d <= 0 || d > l.Policy.MaxLockout {
+			d = l.Policy.MaxLockout
+		}
+		lock := RateLimitLock{Until: now.Add(d), Level: level}
+		if err := store.SetLock(key, lock); err != nil {
+			log.Printf("rate limit: set lock %s failed: %v", key, err)
+			return
+		}
+		// เริ่มนับใหม่หลัง lock
+		_ = store.ResetFailures(key)
+		if d > lockedFor {
+			lockedFor = d
+		}
+
+		RecordAudit(db, AuditEntry{
+			ActorType: ActorSystem,
+			Action:    "rate_limit.lockout",
+			Target:    key,
+			IP:        ip,
+			Details: map[string]interface{}{
+				"policy":   l.Policy.Name,
+				"scope":    kind,
+				"failures": count,
+				"level":    level,
+				"until":    lock.Until.Format(time.RFC3339),
+			},
+		})
+		log.Printf("🛡️  rate limit lockout %s level=%d for %s", key, level, d)
+	}
+
+	check(l.ipKey(ip), l.Policy.IPMaxFailures, "ip")
+	if strings.TrimSpace(target) != "" {
+		check(l.targetKey(target), l.Policy.TargetMaxFailures, "target")
+	}
+	return lockedFor
+}
+
+// Succeed ล้างตัวนับของเป้าหมาย (ไม่ล้างของ IP เพื่อกันการสลับเดาหลายเป้าหมาย)
+func (l *RateLimiter) Succeed(ip, target string) {
+	if strings.TrimSpace(target) == "" {
+		return
+	}
+	store, _ := l.store()
+	if err := store.ResetFailures(l.targetKey(target)); err != nil {
+		log.Printf("rate limit: reset failures failed: %v", err)
+	}
+}
+
+func (l *RateLimiter) keys(ip, target string) []string {
+	keys := []string{l.ipKey(ip)}
+	if strings.TrimSpace(target) != "" {
+		keys = append(keys, l.targetKey(target))
+	}
+	return keys
+}
+
+// ---------------------------
+// Memory store (instance เดียว)
+// ---------------------------
+
+// memoryStoreSweepEvery ความถี่ที่ล้าง key หมดอายุ (ทำใน AddFailure ด้วยเวลาของ limiter ไม่มี goroutine แยก)
+const memoryStoreSweepEvery = time.Minute
+
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	failures  map[string][]time.Time
+	windows   map[string]time.Duration // window ล่าสุดของแต่ละ key ใช้ตัดสินว่า key หมดอายุหรือยัง
+	locks     map[string]RateLimitLock
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		failures: map[string][]time.Time{},
+		windows:  map[string]time.Duration{},
+		locks:    map[string]RateLimitLock{},
+	}
+}
+
+func (s *MemoryRateLimitStore) AddFailure(key string, now time.Time, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	cutoff := now.Add(-window)
+	kept := s.failures[key][:0]
+	for _, t := range s.failures[key] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	kept = append(kept, now)
+	s.failures[key] = kept
+	s.windows[key] = window
+	return len(kept), nil
+}
+
+func (s *MemoryRateLimitStore) ResetFailures(key string) error {
+	s.mu.Lock()
+	delete(s.failures, key)
+	delete(s.windows, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// sweep ลบ key ที่ไม่มีผลแล้ว: failure ที่เก่ากว่า window ทั้งหมด และ lock ที่หมดเวลาเกิน lockLevelDecay
+// (key เป็นต่อ IP / ต่อ code ถ้าไม่ลบ map จะโตไม่จำกัดเมื่อผู้โจมตีสลับค่า) — เรียกขณะถือ mu
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memoryStoreSweepEvery {
+		return
+	}
+	s.lastSweep = now
+	for key, times := range s.failures {
+		if len(times) == 0 || !times[len(times)-1].After(now.Add(-s.windows[key])) {
+			delete(s.failures, key)
+			delete(s.windows, key)
+		}
+	}
+	for key, lock := range s.locks {
+		if now.Sub(lock.Until) > lockLevelDecay {
+			delete(s.locks, key)
+		}
+	}
+}
+
+func (s *MemoryRateLimitStore) GetLock(key string) (RateLimitLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.locks[key], nil
+}
+
+func (s *MemoryRateLimitStore) SetLock(key string, lock RateLimitLock) error {
+	s.mu.Lock()
+	s.locks[key] = lock
+	s.mu.Unlock()
+	return nil
+}
+
+// ---------------------------
+// DB store (หลาย instance ใช้ร่วมกัน)
+// ---------------------------
+
+type DBRateLimitStore struct {
+	DB *gorm.DB
+}
+
+func NewDBRateLimitStore(db *gorm.DB) *DBRateLimitStore {
+	return &DBRateLimitStore{DB: db}
+}
+
+func (s *DBRateLimitStore) AddFailure(key string, now time.Time, window time.Duration) (int, error) {
+	cutoff := now.Add(-window)
+	if err := s.DB.Where("`key` = ? AND created_at <= ?", key, cutoff).Delete(&models.RateLimitEvent{}).Error; err != nil {
+		return 0, err
+	}
+	if err := s.DB.Create(&models.RateLimitEvent{Key: key, CreatedAt: now}).Error; err != nil {
+		return 0, err
+	}
+	var count int64
+	err := s.DB.Model(&models.RateLimitEvent{}).Where("`key` = ? AND created_at > ?", key, cutoff).Count(&count).Error
+	return int(count), err
+}
+
+func (s *DBRateLimitStore) ResetFailures(key string) error {
+	return s.DB.Where("`key` = ?", key).Delete(&models.RateLimitEvent{}).Error
+}
+
+func (s *DBRateLimitStore) GetLock(key string) (RateLimitLock, error) {
+	var row models.RateLimitLock
+	err := s.DB.Where("`key` = ?", key).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return RateLimitLock{}, nil
+	}
+	if err != nil {
+		return RateLimitLock{}, err
+	}
+	return RateLimitLock{Until: row.Until, Level: row.Level}, nil
+}
+
+func (s *DBRateLimitStore) SetLock(key string, lock RateLimitLock) error {
+	row := models.RateLimitLock{Key: key, Until: lock.Until, Level: lock.Level}
+	return s.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"until", "level", "updated_at"}),
+	}).Create(&row).Error
+}
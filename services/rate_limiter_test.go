@@ -0,0 +1,185 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// testClock เวลาที่เลื่อนเองได้สำหรับ RateLimiter.Now
+type testClock struct{ t time.Time }
+
+func (c *testClock) Now() time.Time          { return c.t }
+func (c *testClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func testPolicy() RateLimitPolicy {
+	return RateLimitPolicy{
+		Name:              "test",
+		IPMaxFailures:     10,
+		TargetMaxFailures: 3,
+		Window:            15 * time.Minute,
+		BaseLockout:       time.Minute,
+		MaxLockout:        4 * time.Minute,
+		SensitiveTarget:   true,
+	}
+}
+
+func newTestLimiter() (*RateLimiter, *testClock) {
+	clock := &testClock{t: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	l := NewRateLimiter(testPolicy())
+	l.Store = NewMemoryRateLimitStore()
+	l.Now = clock.Now
+	return l, clock
+}
+
+// lock ยาวขึ้นทีละเท่าตัวจนถึง MaxLockout
+func TestRateLimiterProgressiveLockout(t *testing.T) {
+	l, clock := newTestLimiter()
+
+	steps := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute}
+	for i, want := range steps {
+		ip := fmt.Sprintf("10.0.1.%d", i) // แยก IP ทุกขั้น ให้ทดสอบเฉพาะ lock ของเป้าหมาย
+		var got time.Duration
+		for n := 1; n <= 3; n++ {
+			got = l.Fail(ip, "ABCD1234")
+			if n < 3 && got != 0 {
+				t.Fatalf("step %d: locked after %d failures", i, n)
+			}
+		}
+		if got != want {
+			t.Fatalf("step %d: lockout = %s, want %s", i, got, want)
+		}
+		if wait := l.Check(ip, "ABCD1234"); wait != want {
+			t.Fatalf("step %d: Check = %s, want %s", i, wait, want)
+		}
+		clock.Advance(want)
+		if wait := l.Check(ip, "ABCD1234"); wait != 0 {
+			t.Fatalf("step %d: still locked after lockout: %s", i, wait)
+		}
+	}
+}
+
+// ไม่โดน lock นานกว่า lockLevelDecay -> level กลับไปเริ่มที่ BaseLockout
+func TestRateLimiterLockLevelDecay(t *testing.T) {
+	l, clock := newTestLimiter()
+
+	for i := 0; i < 3; i++ {
+		l.Fail("10.0.0.1", "ABCD1234")
+	}
+	clock.Advance(time.Minute)
+	for i := 0; i < 3; i++ {
+		l.Fail("10.0.0.1", "ABCD1234")
+	}
+	clock.Advance(2*time.Minute + lockLevelDecay + time.Second)
+
+	var got time.Duration
+	for i := 0; i < 3; i++ {
+		got = l.Fail("10.0.0.1", "ABCD1234")
+	}
+	if got != time.Minute {
+		t.Fatalf("lockout after decay = %s, want %s", got, time.Minute)
+	}
+}
+
+func TestRateLimiterScopes(t *testing.T) {
+	tests := []struct {
+		name      string
+		fail      func(l *RateLimiter, clock *testClock)
+		ip        string
+		target    string
+		wantBlock bool
+	}{
+		{
+			name: "failures outside the window do not add up",
+			fail: func(l *RateLimiter, clock *testClock) {
+				for i := 0; i < 3; i++ {
+					l.Fail("10.0.0.1", "ABCD1234")
+					clock.Advance(8 * time.Minute)
+				}
+			},
+			ip:        "10.0.0.1",
+			target:    "ABCD1234",
+			wantBlock: false,
+		},
+		{
+			name: "target lock applies from another IP",
+			fail: func(l *RateLimiter, _ *testClock) {
+				for i := 0; i < 3; i++ {
+					l.Fail("10.0.0.1", "ABCD1234")
+				}
+			},
+			ip:        "10.0.0.2",
+			target:    "abcd1234",
+			wantBlock: true,
+		},
+		{
+			name: "IP lock applies to every target",
+			fail: func(l *RateLimiter, _ *testClock) {
+				for i := 0; i < 10; i++ {
+					l.Fail("10.0.0.1", fmt.Sprintf("CODE%04d", i))
+				}
+			},
+			ip:        "10.0.0.1",
+			target:    "ZZZZ9999",
+			wantBlock: true,
+		},
+		{
+			name: "success resets the target counter",
+			fail: func(l *RateLimiter, _ *testClock) {
+				l.Fail("10.0.0.1", "ABCD1234")
+				l.Fail("10.0.0.1", "ABCD1234")
+				l.Succeed("10.0.0.1", "ABCD1234")
+				l.Fail("10.0.0.1", "ABCD1234")
+			},
+			ip:        "10.0.0.1",
+			target:    "ABCD1234",
+			wantBlock: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, clock := newTestLimiter()
+			tt.fail(l, clock)
+			if blocked := l.Check(tt.ip, tt.target) > 0; blocked != tt.wantBlock {
+				t.Fatalf("blocked = %v, want %v", blocked, tt.wantBlock)
+			}
+		})
+	}
+}
+
+// key ของเป้าหมายที่เป็นความลับต้องไม่เก็บค่าจริง
+func TestRateLimiterSensitiveTargetKey(t *testing.T) {
+	l, _ := newTestLimiter()
+	key := l.targetKey("ABCD1234")
+	if key == "test:target:abcd1234" {
+		t.Fatalf("sensitive target stored in clear: %s", key)
+	}
+	if key != l.targetKey("  abcd1234 ") {
+		t.Fatalf("target key is not normalized")
+	}
+}
+
+func TestMemoryRateLimitStoreSweep(t *testing.T) {
+	s := NewMemoryRateLimitStore()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s.AddFailure("old", now, time.Minute)
+	s.SetLock("old-lock", RateLimitLock{Until: now, Level: 1})
+	s.SetLock("new-lock", RateLimitLock{Until: now.Add(lockLevelDecay), Level: 1})
+
+	later := now.Add(lockLevelDecay + memoryStoreSweepEvery + time.Second)
+	s.AddFailure("fresh", later, time.Minute)
+
+	if _, ok := s.failures["old"]; ok {
+		t.Errorf("expired failures were not swept")
+	}
+	if _, ok := s.locks["old-lock"]; ok {
+		t.Errorf("decayed lock was not swept")
+	}
+	if _, ok := s.locks["new-lock"]; !ok {
+		t.Errorf("lock still within decay was swept")
+	}
+	if n := len(s.failures["fresh"]); n != 1 {
+		t.Errorf("fresh failures = %d, want 1", n)
+	}
+}
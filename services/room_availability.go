@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"hotel-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// BookingReleasedStatuses สถานะ booking ที่ไม่กันห้องแล้ว
+var BookingReleasedStatuses = []string{"Cancelled", "Checked-Out", "No-Show"}
+
+// roomInterval ช่วงที่ห้องถูกจอง [from, to)
+type roomInterval struct{ from, to time.Time }
+
+func overlapsAny(list []roomInterval, from, to time.Time) bool {
+	for _, iv := range list {
+		if iv.from.Before(to) && iv.to.After(from) {
+			return true
+		}
+	}
+	return false
+}
+
+// activeBookingsIn booking ที่ยังกันห้องอยู่และคาบเกี่ยวช่วง [from, to)
+func activeBookingsIn(q *gorm.DB, from, to time.Time, excludeBookingID uint) *gorm.DB {
+	q = q.Where("bookings.deleted_at IS NULL").
+		Where("(bookings.status IS NULL OR bookings.status NOT IN ?)", BookingReleasedStatuses).
+		Where("bookings.check_in < ? AND bookings.check_out > ?", to, from)
+	if excludeBookingID != 0 {
+		q = q.Where("bookings.id <> ?", excludeBookingID)
+	}
+	return q
+}
+
+// ช่วงที่ BookingRoom ใช้ห้อง (start_date / end_date ว่าง = ตามวันเข้าพักของ booking)
+// booking ที่ย้ายห้องระหว่างพักมีหลาย segment ต่อกัน
+const (
+	segmentStartExpr = "COALESCE(booking_rooms.start_date, bookings.check_in)"
+	segmentEndExpr   = "COALESCE(booking_rooms.end_date, bookings.check_out)"
+)
+
+// activeSegmentsIn booking_rooms (join bookings แล้ว) ของ booking ที่ยังกันห้องอยู่ ซึ่ง segment คาบเกี่ยวช่วง [from, to)
+func activeSegmentsIn(q *gorm.DB, from, to time.Time, excludeBookingID uint) *gorm.DB {
+	q = q.Where("booking_rooms.deleted_at IS NULL AND bookings.deleted_at IS NULL").
+		Where("(bookings.status IS NULL OR bookings.status NOT IN ?)", BookingReleasedStatuses).
+		Where(segmentStartExpr+" < ? AND "+segmentEndExpr+" > ?", to, from).
+		Where(segmentEndExpr + " > " + segmentStartExpr) // segment ที่ย้ายออกในวันแรกไม่กันห้อง
+	if excludeBookingID != 0 {
+		q = q.Where("bookings.id <> ?", excludeBookingID)
+	}
+	return q
+}
+
+// BookedRoomIDs คืน room ใน roomIDs ที่มี booking (ยังไม่ยกเลิก / เช็คเอาท์), RoomBlock หรือปิดใช้งานคาบเกี่ยวช่วง [from, to)
+// ดูทั้ง booking_rooms และ bookings.room_id แบบเดิม; excludeBookingID ใช้ตอนแก้ booking เดิม (0 = ไม่ยกเว้น)
+func BookedRoomIDs(db *gorm.DB, roomIDs []uint, from, to time.Time, excludeBookingID uint) (map[uint]bool, error) {
+	booked := map[uint]bool{}
+	if len(roomIDs) == 0 {
+		return booked, nil
+	}
+
+	var ids []uint
+	if err := activeSegmentsIn(db.Model(&models.BookingRoom{}).
+		Joins("JOIN bookings ON bookings.id = booking_rooms.booking_id").
+		Where("booking_rooms.room_id IN ?", roomIDs), from, to, excludeBookingID).
+		Distinct().Pluck("booking_rooms.room_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	var legacy []uint
+	if err := activeBookingsIn(db.Model(&models.Booking{}).Where("bookings.room_id IN ?", roomIDs), from, to, excludeBookingID).
+		Distinct().Pluck("bookings.room_id", &legacy).Error; err != nil {
+		return nil, err
+	}
+	var blocked []uint
+	if err := roomBlocksIn(db, roomIDs, from, to).Distinct().Pluck("room_id", &blocked).Error; err != nil {
+		return nil, err
+	}
+	var closed []uint
+	if err := roomsOutOfServiceIn(db, roomIDs, from).Pluck("id", &closed).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range append(append(append(ids, legacy...), blocked...), closed...) {
+		booked[id] = true
+	}
+	return booked, nil
+}
+
+// roomsOutOfServiceIn ห้องใน roomIDs ที่ปิดใช้งาน (out_of_order / out_of_service) ต่อเนื่องถึงหลัง from
+func roomsOutOfServiceIn(db *gorm.DB, roomIDs []uint, from time.Time) *gorm.DB {
+	return db.Model(&models.Room{}).
+		Where("id IN ? AND service_status <> ?", roomIDs, ServiceStatusInService).
+		Where("(service_until IS NULL OR service_until > ?)", from)
+}
+
+// roomBlocksIn RoomBlock ของ roomIDs ที่คาบเกี่ยวช่วง [from, to)
+func roomBlocksIn(db *gorm.DB, roomIDs []uint, from, to time.Time) *gorm.DB {
+	return db.Model(&models.RoomBlock{}).
+		Where("room_id IN ? AND start_date < ? AND end_date > ?", roomIDs, to, from)
+}
+
+// FirstRoomBlock block แรกของ roomIDs ที่ทับช่วง [from, to) (nil = ไม่มี)
+func FirstRoomBlock(db *gorm.DB, roomIDs []uint, from, to time.Time) (*models.RoomBlock, error) {
+	var block models.RoomBlock
+	err := roomBlocksIn(db, roomIDs, from, to).Order("start_date").First(&block).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// roomBookingIntervals ช่วงที่แต่ละห้องใน roomIDs ถูกจอง, block หรือปิดใช้งานภายใน [from, to) (ใช้คำนวณห้องว่างรายวัน)
+func roomBookingIntervals(db *gorm.DB, roomIDs []uint, from, to time.Time) (map[uint][]roomInterval, error) {
+	out := map[uint][]roomInterval{}
+	if len(roomIDs) == 0 {
+		return out, nil
+	}
+	type row struct {
+		RoomID   uint
+		CheckIn  time.Time
+		CheckOut time.Time
+	}
+	var rows, legacy []row
+	if err := activeSegmentsIn(db.Model(&models.BookingRoom{}).
+		Joins("JOIN bookings ON bookings.id = booking_rooms.booking_id").
+		Where("booking_rooms.room_id IN ?", roomIDs), from, to, 0).
+		Select("booking_rooms.room_id, " + segmentStartExpr + " AS check_in, " + segmentEndExpr + " AS check_out").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if err := activeBookingsIn(db.Model(&models.Booking{}).Where("bookings.room_id IN ?", roomIDs), from, to, 0).
+		Select("bookings.room_id, bookings.check_in, bookings.check_out").Scan(&legacy).Error; err != nil {
+		return nil, err
+	}
+	var blocks []models.RoomBlock
+	if err := roomBlocksIn(db, roomIDs, from, to).Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range append(rows, legacy...) {
+		out[r.RoomID] = append(out[r.RoomID], roomInterval{r.CheckIn, r.CheckOut})
+	}
+	for _, b := range blocks {
+		out[b.RoomID] = append(out[b.RoomID], roomInterval{b.StartDate, b.EndDate})
+	}
+	var closed []models.Room
+	if err := roomsOutOfServiceIn(db, roomIDs, from).Select("id", "service_until").Find(&closed).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range closed {
+		until := to
+		if r.ServiceUntil != nil && r.ServiceUntil.Before(to) {
+			until = *r.ServiceUntil
+		}
+		out[r.ID] = append(out[r.ID], roomInterval{from, until})
+	}
+	return out, nil
+}
+
+// firstFreeRoomOfType ห้องแรก (เรียงตามเลขห้อง) ของ roomTypeID ที่ว่างตลอดช่วง [from, to) (0 = ไม่มีห้องว่าง)
+func firstFreeRoomOfType(db *gorm.DB, roomTypeID uint, from, to time.Time, excludeBookingID uint) (uint, error) {
+	var ids []uint
+	if err := db.Model(&models.Room{}).Where("room_type_id = ?", roomTypeID).
+		Order("room_number").Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	booked, err := BookedRoomIDs(db, ids, from, to, excludeBookingID)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if !booked[id] {
+			return id, nil
+		}
+	}
+	return 0, nil
+}
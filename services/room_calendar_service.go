@@ -0,0 +1,430 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// Calendar feed scopes (RoomCalendarFeed.Scope)
+const (
+	CalendarScopeRoom     = "room"
+	CalendarScopeRoomType = "roomType"
+)
+
+const (
+	calendarFeedPastDays   = 30  // feed แสดงการจองย้อนหลัง
+	calendarFeedFutureDays = 365 // และล่วงหน้า
+	maxICalImportSize      = 5 << 20
+)
+
+var (
+	ErrCalendarFeedNotFound   = NewError(KindNotFound, "error.calendarFeedNotFound")
+	ErrCalendarImportNotFound = NewError(KindNotFound, "error.calendarImportNotFound")
+	ErrCalendarImportFailed   = NewError(KindUpstream, "error.calendarImportFailed")
+	ErrInvalidCalendarURL     = NewError(KindInvalid, "error.invalidCalendarUrl")
+)
+
+// RoomCalendarService iCal feed ของห้อง / ประเภทห้อง และ import ปฏิทินภายนอกเป็น RoomBlock
+type RoomCalendarService struct {
+	DB     *gorm.DB
+	Client *http.Client
+}
+
+func NewRoomCalendarService(db *gorm.DB) *RoomCalendarService {
+	return &RoomCalendarService{DB: db, Client: &http.Client{Timeout: 20 * time.Second}}
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ---------------- feeds ----------------
+
+// CreateFeed ออก token ของ feed ใหม่ และคืน token จริง (แสดงได้ครั้งเดียว)
+func (s *RoomCalendarService) CreateFeed(scope string, targetID uint, name string, actor AdminActor) (models.RoomCalendarFeed, string, error) {
+	defaultName, err := s.feedTargetName(scope, targetID)
+	if err != nil {
+		return models.RoomCalendarFeed{}, "", err
+	}
+	raw, err := utils.GenerateSecureToken(24)
+	if err != nil {
+		return models.RoomCalendarFeed{}, "", fmt.Errorf("failed to generate feed token: %w", err)
+	}
+	token := "ical_" + raw
+
+	feed := models.RoomCalendarFeed{
+		Scope:       scope,
+		TargetID:    targetID,
+		Name:        firstNonEmpty(name, defaultName),
+		TokenHash:   hashCalendarToken(token),
+		TokenPrefix: token[:12],
+		CreatedByID: actor.AdminID,
+	}
+	if err := s.DB.Create(&feed).Error; err != nil {
+		return feed, "", err
+	}
+	s.audit(actor, "calendar.feed.create", fmt.Sprintf("calendar_feed:%d", feed.ID), map[string]interface{}{"scope": scope, "targetId": targetID})
+	return feed, token, nil
+}
+
+// feedTargetName ชื่อเริ่มต้นของ feed (และตรวจว่าห้อง / ประเภทห้องมีอยู่)
+func (s *RoomCalendarService) feedTargetName(scope string, targetID uint) (string, error) {
+	switch scope {
+	case CalendarScopeRoom:
+		var room models.Room
+		if err := s.DB.Select("id", "room_number").First(&room, targetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", ErrRoomNotFound
+			}
+			return "", err
+		}
+		return "Room " + room.RoomNumber, nil
+	case CalendarScopeRoomType:
+		var rt models.RoomType
+		if err := s.DB.Select("id", "type_name").First(&rt, targetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", ErrRoomTypeNotFound
+			}
+			return "", err
+		}
+		return rt.TypeName, nil
+	}
+	return "", ErrValidation.WithDetails(map[string]interface{}{"scope": scope})
+}
+
+func (s *RoomCalendarService) ListFeeds() ([]models.RoomCalendarFeed, error) {
+	feeds := []models.RoomCalendarFeed{}
+	return feeds, s.DB.Order("id").Find(&feeds).Error
+}
+
+// RevokeFeed ปิด token ของ feed (สร้างใหม่เพื่อออก URL ใหม่)
+func (s *RoomCalendarService) RevokeFeed(id uint, actor AdminActor) error {
+	res := s.DB.Model(&models.RoomCalendarFeed{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now().UTC())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCalendarFeedNotFound
+	}
+	s.audit(actor, "calendar.feed.revoke", fmt.Sprintf("calendar_feed:%d", id), nil)
+	return nil
+}
+
+// FeedByToken หา feed ที่ยังไม่ถูก revoke จาก token และบันทึกเวลาที่ถูกเรียกล่าสุด
+func (s *RoomCalendarService) FeedByToken(token string) (models.RoomCalendarFeed, error) {
+	var feed models.RoomCalendarFeed
+	if strings.TrimSpace(token) == "" {
+		return feed, ErrCalendarFeedNotFound
+	}
+	if err := s.DB.Where("token_hash = ? AND revoked_at IS NULL", hashCalendarToken(token)).First(&feed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return feed, ErrCalendarFeedNotFound
+		}
+		return feed, err
+	}
+	now := time.Now().UTC()
+	s.DB.Model(&feed).Update("last_access_at", now)
+	return feed, nil
+}
+
+// FeedEvents การจองของห้องใน feed (ย้อนหลัง 30 วัน ถึงล่วงหน้า 1 ปี) เป็น event ทั้งวัน
+// ไม่มีชื่อแขกใน feed; block ที่ import มาไม่ถูกส่งออก (กันวนกลับไปปฏิทินต้นทาง)
+func (s *RoomCalendarService) FeedEvents(feed models.RoomCalendarFeed) ([]utils.ICalEvent, error) {
+	var rooms []models.Room
+	q := s.DB.Select("id", "room_number")
+	if feed.Scope == CalendarScopeRoom {
+		q = q.Where("id = ?", feed.TargetID)
+	} else {
+		q = q.Where("room_type_id = ?", feed.TargetID)
+	}
+	if err := q.Order("room_number").Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	if len(rooms) == 0 {
+		return []utils.ICalEvent{}, nil
+	}
+	roomIDs := make([]uint, len(rooms))
+	roomNumbers := map[uint]string{}
+	for i, r := range rooms {
+		roomIDs[i] = r.ID
+		roomNumbers[r.ID] = r.RoomNumber
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from, to := today.AddDate(0, 0, -calendarFeedPastDays), today.AddDate(0, 0, calendarFeedFutureDays)
+
+	type row struct {
+		RoomID        uint
+		BookingID     uint
+		CheckIn       time.Time
+		CheckOut      time.Time
+		Status        string
+		Adults        int
+		Children      int
+		ReferenceCode string
+		Source        string
+		UpdatedAt     time.Time
+	}
+	cols := "bookings.id AS booking_id, bookings.status, bookings.adults, bookings.children, bookings.reference_code, bookings.source, bookings.updated_at"
+	var rows, legacy []row
+	if err := activeSegmentsIn(s.DB.Model(&models.BookingRoom{}).
+		Joins("JOIN bookings ON bookings.id = booking_rooms.booking_id").
+		Where("booking_rooms.room_id IN ?", roomIDs), from, to, 0).
+		Select("booking_rooms.room_id, " + segmentStartExpr + " AS check_in, " + segmentEndExpr + " AS check_out, " + cols).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if err := activeBookingsIn(s.DB.Model(&models.Booking{}).Where("bookings.room_id IN ?", roomIDs), from, to, 0).
+		Select("bookings.room_id, bookings.check_in, bookings.check_out, " + cols).Scan(&legacy).Error; err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	events := []utils.ICalEvent{}
+	for _, r := range append(rows, legacy...) {
+		uid := fmt.Sprintf("booking-%d-room-%d@hotel-backend", r.BookingID, r.RoomID)
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+
+		start := time.Date(r.CheckIn.Year(), r.CheckIn.Month(), r.CheckIn.Day(), 0, 0, 0, 0, time.UTC)
+		end := time.Date(r.CheckOut.Year(), r.CheckOut.Month(), r.CheckOut.Day(), 0, 0, 0, 0, time.UTC)
+		if !end.After(start) {
+			end = start.AddDate(0, 0, 1)
+		}
+		summary := "Booked"
+		if r.Status == "Checked-In" {
+			summary = "Occupied"
+		}
+		if feed.Scope == CalendarScopeRoomType {
+			summary = "Room " + roomNumbers[r.RoomID] + " · " + summary
+		}
+		desc := fmt.Sprintf("Booking #%d · %d adult(s)", r.BookingID, r.Adults)
+		if r.Children > 0 {
+			desc += fmt.Sprintf(", %d child(ren)", r.Children)
+		}
+		if r.Source != "" {
+			desc += " · " + strings.TrimSpace(r.Source+" "+r.ReferenceCode)
+		}
+		events = append(events, utils.ICalEvent{
+			UID:         uid,
+			Start:       start,
+			End:         end,
+			Summary:     summary,
+			Description: desc,
+			Status:      "CONFIRMED",
+			Stamp:       r.UpdatedAt,
+		})
+	}
+	return events, nil
+}
+
+// ---------------- imports ----------------
+
+// normalizeCalendarURL รับ http(s) และ webcal:// (แปลงเป็น https)
+func normalizeCalendarURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(strings.ToLower(raw), "webcal://") {
+		raw = "https://" + raw[len("webcal://"):]
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", ErrInvalidCalendarURL
+	}
+	return u.String(), nil
+}
+
+func (s *RoomCalendarService) ListImports(roomID uint) ([]models.RoomCalendarImport, error) {
+	imports := []models.RoomCalendarImport{}
+	q := s.DB.Order("id")
+	if roomID != 0 {
+		q = q.Where("room_id = ?", roomID)
+	}
+	return imports, q.Find(&imports).Error
+}
+
+// CreateImport เพิ่มปฏิทินภายนอกของห้องแล้ว sync ทันที (sync ไม่สำเร็จยังบันทึกไว้ ดู lastError)
+func (s *RoomCalendarService) CreateImport(roomID uint, name, rawURL string, actor AdminActor) (models.RoomCalendarImport, error) {
+	var imp models.RoomCalendarImport
+	u, err := normalizeCalendarURL(rawURL)
+	if err != nil {
+		return imp, err
+	}
+	if _, err := s.feedTargetName(CalendarScopeRoom, roomID); err != nil {
+		return imp, err
+	}
+	imp = models.RoomCalendarImport{RoomID: roomID, Name: strings.TrimSpace(name), URL: u, Active: true}
+	if err := s.DB.Create(&imp).Error; err != nil {
+		return imp, err
+	}
+	s.audit(actor, "calendar.import.create", fmt.Sprintf("calendar_import:%d", imp.ID), map[string]interface{}{"roomId": roomID, "host": hostOf(u)})
+
+	synced, _ := s.SyncImport(imp.ID)
+	return synced, nil
+}
+
+// DeleteImport ลบปฏิทินภายนอกและ block ทั้งหมดที่มาจากปฏิทินนั้น
+func (s *RoomCalendarService) DeleteImport(id uint, actor AdminActor) error {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.RoomCalendarImport{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrCalendarImportNotFound
+		}
+		return tx.Where("import_id = ?", id).Delete(&models.RoomBlock{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.audit(actor, "calendar.import.delete", fmt.Sprintf("calendar_import:%d", id), nil)
+	return nil
+}
+
+// SyncImport ดึง .ics แล้วแทน block เดิมของปฏิทินนี้ทั้งชุด (ดึงไม่สำเร็จ = คง block เดิมไว้)
+func (s *RoomCalendarService) SyncImport(id uint) (models.RoomCalendarImport, error) {
+	var imp models.RoomCalendarImport
+	if err := s.DB.First(&imp, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return imp, ErrCalendarImportNotFound
+		}
+		return imp, err
+	}
+
+	now := time.Now().UTC()
+	imp.LastSyncedAt = &now
+	events, err := s.fetchEvents(imp.URL)
+	if err != nil {
+		imp.LastError = err.Error()
+		s.DB.Model(&imp).Select("last_synced_at", "last_error").Updates(&imp)
+		return imp, ErrCalendarImportFailed.Wrap(err)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	blocks := make([]models.RoomBlock, 0, len(events))
+	for _, e := range events {
+		if e.Status == "CANCELLED" || !e.End.After(today) {
+			continue
+		}
+		blocks = append(blocks, models.RoomBlock{
+			RoomID:      imp.RoomID,
+			Kind:        RoomBlockKindExternal,
+			StartDate:   e.Start,
+			EndDate:     e.End,
+			Summary:     truncateRunes(firstNonEmpty(e.Summary, imp.Name), 255),
+			ImportID:    &imp.ID,
+			ExternalUID: truncateRunes(e.UID, 255),
+		})
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("import_id = ?", imp.ID).Delete(&models.RoomBlock{}).Error; err != nil {
+			return err
+		}
+		if len(blocks) > 0 {
+			if err := tx.CreateInBatches(&blocks, 200).Error; err != nil {
+				return err
+			}
+		}
+		imp.LastError = ""
+		imp.EventCount = len(blocks)
+		return tx.Model(&imp).Select("last_synced_at", "last_error", "event_count").Updates(&imp).Error
+	})
+	if err != nil {
+		return imp, fmt.Errorf("save calendar blocks: %w", err)
+	}
+	return imp, nil
+}
+
+func (s *RoomCalendarService) fetchEvents(rawURL string) ([]utils.ICalEvent, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build request: %w", err)
+	}
+	req.Header.Set("Accept", "text/calendar")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP error %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxICalImportSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read calendar: %w", err)
+	}
+	if len(data) > maxICalImportSize {
+		return nil, fmt.Errorf("calendar larger than %d MB", maxICalImportSize>>20)
+	}
+	return utils.ParseICalendar(data)
+}
+
+// syncImports sync ปฏิทินภายนอกที่เปิดใช้ทั้งหมด (งาน ical_import ของ scheduler)
+func (s *RoomCalendarService) syncImports(res *jobResult) error {
+	var ids []uint
+	if err := s.DB.Model(&models.RoomCalendarImport{}).Where("active = ?", true).Order("id").Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("failed to query calendar imports: %w", err)
+	}
+	for _, id := range ids {
+		res.processed++
+		if _, err := s.SyncImport(id); err != nil {
+			res.fail("calendar import %d: %v", id, err)
+			continue
+		}
+		res.succeeded++
+	}
+	return nil
+}
+
+// ListBlocks block ของห้อง (0 = ทุกห้อง) ที่ยังไม่สิ้นสุดก่อน from
+func (s *RoomCalendarService) ListBlocks(roomID uint, from time.Time) ([]models.RoomBlock, error) {
+	blocks := []models.RoomBlock{}
+	q := s.DB.Where("end_date > ?", from).Order("start_date, room_id")
+	if roomID != 0 {
+		q = q.Where("room_id = ?", roomID)
+	}
+	return blocks, q.Find(&blocks).Error
+}
+
+func (s *RoomCalendarService) audit(actor AdminActor, action, target string, details map[string]interface{}) {
+	RecordAudit(s.DB, AuditEntry{
+		ActorType: ActorAdmin,
+		ActorID:   strconv.FormatUint(uint64(actor.AdminID), 10),
+		Action:    action,
+		Target:    target,
+		IP:        actor.IP,
+		Details:   details,
+	})
+}
+
+// hostOf host ของ URL (ไม่บันทึก path / query ลง audit เพราะปฏิทินภายนอกมักฝัง secret ไว้)
+func hostOf(raw string) string {
+	if u, err := url.Parse(raw); err == nil {
+		return u.Host
+	}
+	return ""
+}
+
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hotel-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Room.Occupancy (คำนวณจาก booking)
+const (
+	OccupancyVacant   = "vacant"
+	OccupancyReserved = "reserved" // มีแขกจะเข้าพักวันนี้ / ค้างเช็คอิน
+	OccupancyOccupied = "occupied" // แขกเช็คอินแล้ว
+)
+
+// Room.ServiceStatus
+const (
+	ServiceStatusInService    = "in_service"
+	ServiceStatusOutOfOrder   = "out_of_order"
+	ServiceStatusOutOfService = "out_of_service"
+)
+
+// RoomStatusChange.Field / Source
+const (
+	RoomStatusFieldClean   = "clean"
+	RoomStatusFieldService = "service"
+
+	RoomStatusSourceManual       = "manual"
+	RoomStatusSourceCheckout     = "checkout"
+	RoomStatusSourceHousekeeping = "housekeeping"
+	RoomStatusSourceRoomMove     = "room_move"
+)
+
+// cleanTransitions การเปลี่ยนสถานะความสะอาดที่ตั้งเองได้ (ขั้นตอนของงานแม่บ้านเปลี่ยนผ่าน HousekeepingService)
+var cleanTransitions = map[string][]string{
+	CleanStatusDirty:     {CleanStatusCleaning, CleanStatusClean},
+	CleanStatusCleaning:  {CleanStatusDirty, CleanStatusClean},
+	CleanStatusClean:     {CleanStatusDirty, CleanStatusCleaning, CleanStatusInspected},
+	CleanStatusInspected: {CleanStatusDirty, CleanStatusCleaning},
+}
+
+var (
+	ErrInvalidRoomTransition = NewError(KindConflict, "error.invalidRoomTransition")
+	ErrRoomOccupied          = NewError(KindConflict, "error.roomOccupied")
+	ErrRoomOutOfService      = NewError(KindConflict, "error.roomOutOfService")
+	ErrServiceReasonRequired = NewError(KindInvalid, "error.serviceReasonRequired")
+)
+
+// RoomStatusService เปลี่ยนสถานะห้องตามกติกา และเก็บประวัติใน room_status_changes
+type RoomStatusService struct {
+	DB *gorm.DB
+}
+
+func NewRoomStatusService(db *gorm.DB) *RoomStatusService {
+	return &RoomStatusService{DB: db}
+}
+
+// RoomStatusInput สถานะที่ต้องการ (nil = ไม่เปลี่ยน)
+type RoomStatusInput struct {
+	CleanStatus   *string
+	ServiceStatus *string
+	Reason        string
+	ServiceUntil  *time.Time
+	Source        string // ว่าง = manual
+}
+
+// UpdateStatus ตั้งสถานะความสะอาด / การใช้งานของห้องเอง
+// - ความสะอาดเปลี่ยนได้ตาม cleanTransitions เท่านั้น
+// - ปิดห้อง (out_of_order / out_of_service) ต้องมีเหตุผล และห้องต้องไม่มีแขกพักอยู่
+func (s *RoomStatusService) UpdateStatus(roomID uint, in RoomStatusInput, actor AdminActor) (models.Room, error) {
+	var room models.Room
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&room, roomID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoomNotFound
+			}
+			return err
+		}
+		actorID := actor.AdminID
+		reason := strings.TrimSpace(in.Reason)
+		source := firstNonEmpty(in.Source, RoomStatusSourceManual)
+
+		if in.CleanStatus != nil && *in.CleanStatus != room.CleanStatus {
+			if !containsStatus(cleanTransitions[room.CleanStatus], *in.CleanStatus) {
+				return ErrInvalidRoomTransition.WithDetails(map[string]interface{}{
+					"field": RoomStatusFieldClean, "from": room.CleanStatus, "to": *in.CleanStatus,
+					"allowed": cleanTransitions[room.CleanStatus],
+				})
+			}
+			if err := setCleanStatus(tx, roomID, *in.CleanStatus, source, &actorID, reason); err != nil {
+				return err
+			}
+		}
+
+		if in.ServiceStatus != nil {
+			to := *in.ServiceStatus
+			updates := map[string]interface{}{"service_status": to}
+			if to == ServiceStatusInService {
+				updates["service_reason"], updates["service_until"] = "", nil
+			} else {
+				if reason == "" {
+					return ErrServiceReasonRequired
+				}
+				occ, err := RoomOccupancy(tx, []uint{roomID}, time.Now())
+				if err != nil {
+					return err
+				}
+				if occ[roomID] == OccupancyOccupied {
+					return ErrRoomOccupied.WithDetails(map[string]interface{}{"roomId": roomID})
+				}
+				updates["service_reason"], updates["service_until"] = reason, in.ServiceUntil
+			}
+			if to == room.ServiceStatus && to == ServiceStatusInService {
+				return nil
+			}
+			if err := tx.Model(&models.Room{}).Where("id = ?", roomID).Updates(updates).Error; err != nil {
+				return err
+			}
+			if to != room.ServiceStatus {
+				if err := recordRoomStatus(tx, roomID, RoomStatusFieldService, room.ServiceStatus, to, source, &actorID, reason); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return room, err
+	}
+	RecordAudit(s.DB, AuditEntry{
+		ActorType: ActorAdmin,
+		ActorID:   strconv.FormatUint(uint64(actor.AdminID), 10),
+		Action:    "room.status",
+		Target:    fmt.Sprintf("room:%d", roomID),
+		IP:        actor.IP,
+		Details:   map[string]interface{}{"cleanStatus": in.CleanStatus, "serviceStatus": in.ServiceStatus, "reason": in.Reason},
+	})
+	return s.GetRoom(roomID)
+}
+
+// GetRoom ห้องพร้อม occupancy
+func (s *RoomStatusService) GetRoom(roomID uint) (models.Room, error) {
+	var room models.Room
+	if err := s.DB.Preload("RoomType").First(&room, roomID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return room, ErrRoomNotFound
+		}
+		return room, err
+	}
+	rooms := []models.Room{room}
+	if err := FillRoomOccupancy(s.DB, rooms); err != nil {
+		return room, err
+	}
+	return rooms[0], nil
+}
+
+// History ประวัติสถานะของห้อง ใหม่สุดก่อน
+func (s *RoomStatusService) History(roomID uint, limit int) ([]models.RoomStatusChange, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	changes := []models.RoomStatusChange{}
+	return changes, s.DB.Where("room_id = ?", roomID).Order("id DESC").Limit(limit).Find(&changes).Error
+}
+
+// setCleanStatus เปลี่ยนสถานะความสะอาดและบันทึกประวัติ (ไม่ตรวจ cleanTransitions)
+func setCleanStatus(tx *gorm.DB, roomID uint, status, source string, actorID *uint, reason string) error {
+	var room models.Room
+	if err := tx.Select("id", "clean_status").First(&room, roomID).Error; err != nil {
+		return err
+	}
+	if room.CleanStatus == status {
+		return nil
+	}
+	if err := tx.Model(&models.Room{}).Where("id = ?", roomID).
+		Updates(map[string]interface{}{"clean_status": status, "clean_status_at": time.Now().UTC()}).Error; err != nil {
+		return err
+	}
+	return recordRoomStatus(tx, roomID, RoomStatusFieldClean, room.CleanStatus, status, source, actorID, reason)
+}
+
+func recordRoomStatus(tx *gorm.DB, roomID uint, field, from, to, source string, actorID *uint, reason string) error {
+	if actorID != nil && *actorID == 0 {
+		actorID = nil
+	}
+	return tx.Create(&models.RoomStatusChange{
+		RoomID:      roomID,
+		Field:       field,
+		FromStatus:  from,
+		ToStatus:    to,
+		Reason:      truncateRunes(reason, 255),
+		Source:      source,
+		ChangedByID: actorID,
+	}).Error
+}
+
+// RoomOccupancy สถานะการเข้าพักของ roomIDs ณ เวลา at:
+// occupied = มี booking ที่ Checked-In, reserved = มี booking ที่ยังไม่เข้าพักซึ่งเริ่มภายในวันนี้และยังไม่สิ้นสุด
+// booking ที่ย้ายห้องแล้วนับเฉพาะ segment ที่ครอบคลุม at
+func RoomOccupancy(db *gorm.DB, roomIDs []uint, at time.Time) (map[uint]string, error) {
+	out := map[uint]string{}
+	for _, id := range roomIDs {
+		out[id] = OccupancyVacant
+	}
+	if len(roomIDs) == 0 {
+		return out, nil
+	}
+	endOfDay := HousekeepingDate(at).AddDate(0, 0, 1)
+	type row struct {
+		RoomID uint
+		Status string
+	}
+	released := func(q *gorm.DB) *gorm.DB {
+		return q.Where("bookings.deleted_at IS NULL").
+			Where("(bookings.status IS NULL OR bookings.status NOT IN ?)", BookingReleasedStatuses)
+	}
+	var rows, legacy []row
+	if err := released(db.Model(&models.BookingRoom{}).
+		Joins("JOIN bookings ON bookings.id = booking_rooms.booking_id").
+		Where("booking_rooms.room_id IN ? AND booking_rooms.deleted_at IS NULL", roomIDs)).
+		Where("(booking_rooms.start_date IS NULL OR booking_rooms.start_date <= ?) AND (booking_rooms.end_date IS NULL OR booking_rooms.end_date > ?)", at, at).
+		Where("(bookings.status = ? OR ("+segmentStartExpr+" < ? AND "+segmentEndExpr+" > ?))", "Checked-In", endOfDay, at).
+		Select("booking_rooms.room_id, bookings.status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if err := released(db.Model(&models.Booking{}).Where("bookings.room_id IN ?", roomIDs)).
+		Where("(bookings.status = ? OR (bookings.check_in < ? AND bookings.check_out > ?))", "Checked-In", endOfDay, at).
+		Select("bookings.room_id, bookings.status").Scan(&legacy).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range append(rows, legacy...) {
+		if r.Status == "Checked-In" {
+			out[r.RoomID] = OccupancyOccupied
+		} else if out[r.RoomID] == OccupancyVacant {
+			out[r.RoomID] = OccupancyReserved
+		}
+	}
+	return out, nil
+}
+
+// FillRoomOccupancy เติม Occupancy ของ rooms ณ ตอนนี้
+func FillRoomOccupancy(db *gorm.DB, rooms []models.Room) error {
+	ids := make([]uint, len(rooms))
+	for i, r := range rooms {
+		ids[i] = r.ID
+	}
+	occ, err := RoomOccupancy(db, ids, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	for i := range rooms {
+		rooms[i].Occupancy = occ[rooms[i].ID]
+	}
+	return nil
+}
+
+// roomOutOfServiceOn ห้องปิดใช้งานอยู่ ณ วัน from หรือไม่ (ServiceUntil ผ่านไปแล้ว = กลับมาขายได้)
+func roomOutOfServiceOn(room models.Room, from time.Time) bool {
+	if room.ServiceStatus == "" || room.ServiceStatus == ServiceStatusInService {
+		return false
+	}
+	return room.ServiceUntil == nil || room.ServiceUntil.After(from)
+}
+
+func containsStatus(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,523 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// Scheduler job names
+const (
+	JobAutoInitiate    = "auto_initiate_checkin"
+	JobCheckInReminder = "checkin_reminder"
+	JobThankYou        = "post_checkout_thanks"
+	JobICalImport      = "ical_import"
+)
+
+// SchedulerJobs ลำดับการรันในแต่ละรอบ
+var SchedulerJobs = []string{JobAutoInitiate, JobCheckInReminder, JobThankYou, JobICalImport}
+
+// JobRun statuses
+const (
+	JobStatusRunning = "RUNNING"
+	JobStatusSuccess = "SUCCESS"
+	JobStatusPartial = "PARTIAL"
+	JobStatusFailed  = "FAILED"
+)
+
+// thank-you จะไม่ย้อนส่งให้ booking ที่เช็คเอาท์นานกว่านี้ (กันส่งย้อนหลังทั้งหมดตอนเปิดใช้ครั้งแรก)
+const thankYouLookback = 7 * 24 * time.Hour
+
+var (
+	ErrSchedulerBusy   = NewError(KindConflict, "error.schedulerBusy")
+	ErrUnknownJob      = NewError(KindInvalid, "error.unknownJob").WithDetails(map[string]interface{}{"jobs": SchedulerJobs})
+	ErrInvalidSchedule = NewError(KindInvalid, "error.invalidSchedule")
+)
+
+// SchedulerService รันงานอัตโนมัติ: ส่งลิงก์เช็คอินล่วงหน้า, เตือนเช็คอิน, ขอบคุณหลังเช็คเอาท์, sync ปฏิทินภายนอก
+type SchedulerService struct {
+	DB        *gorm.DB
+	Bookings  *BookingService
+	Notifier  *NotificationService
+	Calendars *RoomCalendarService
+
+	running sync.Mutex
+}
+
+func NewSchedulerService(db *gorm.DB, bookings *BookingService) *SchedulerService {
+	return &SchedulerService{DB: db, Bookings: bookings, Notifier: bookings.Notifier, Calendars: NewRoomCalendarService(db)}
+}
+
+// DefaultScheduleSetting ค่าเริ่มต้นเมื่อโรงแรมยังไม่เคยตั้งค่า
+func DefaultScheduleSetting(hotelID uint) models.ScheduleSetting {
+	return models.ScheduleSetting{
+		HotelSettingID:         hotelID,
+		Enabled:                true,
+		IntervalMinutes:        15,
+		AutoInitiateEnabled:    true,
+		AutoInitiateDaysBefore: 3,
+		ReminderEnabled:        true,
+		ReminderHoursBefore:    24,
+		ThankYouEnabled:        true,
+		ThankYouHoursAfter:     2,
+	}
+}
+
+// currentHotelID: ระบบปัจจุบันมีโรงแรมเดียว -> ใช้ HotelSetting แถวแรก (0 ถ้ายังไม่มี)
+func (s *SchedulerService) currentHotelID() uint {
+	var hs models.HotelSetting
+	if err := s.DB.Select("id").Order("id asc").First(&hs).Error; err != nil {
+		return 0
+	}
+	return hs.ID
+}
+
+// GetSettings คืน ScheduleSetting ของโรงแรม (สร้างค่าเริ่มต้นถ้ายังไม่มี)
+func (s *SchedulerService) GetSettings() (models.ScheduleSetting, error) {
+	hotelID := s.currentHotelID()
+
+	var setting models.ScheduleSetting
+	err := s.DB.Where("hotel_setting_id = ?", hotelID).First(&setting).Error
+	if err == nil {
+		return setting, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return setting, err
+	}
+
+	setting = DefaultScheduleSetting(hotelID)
+	if err := s.DB.Create(&setting).Error; err != nil {
+		return setting, fmt.Errorf("failed to create schedule setting: %w", err)
+	}
+	return setting, nil
+}
+
+// UpdateSettings บันทึกค่าใหม่ (ตรวจช่วงค่าที่ยอมรับได้ก่อน)
+func (s *SchedulerService) UpdateSettings(in models.ScheduleSetting) (models.ScheduleSetting, error) {
+	current, err := s.GetSettings()
+	if err != nil {
+		return current, err
+	}
+
+	switch {
+	case in.IntervalMinutes < 1 || in.IntervalMinutes > 24*60:
+		return current, ErrInvalidSchedule.WithDetails("intervalMinutes must be between 1 and 1440")
+	case in.AutoInitiateDaysBefore < 0 || in.AutoInitiateDaysBefore > 60:
+		return current, ErrInvalidSchedule.WithDetails("autoInitiateDaysBefore must be between 0 and 60")
+	case in.ReminderHoursBefore < 1 || in.ReminderHoursBefore > 14*24:
+		return current, ErrInvalidSchedule.WithDetails("reminderHoursBefore must be between 1 and 336")
+	case in.ThankYouHoursAfter < 0 || in.ThankYouHoursAfter > 7*24:
+		return current, ErrInvalidSchedule.WithDetails("thankYouHoursAfter must be between 0 and 168")
+	}
+
+	in.ID = current.ID
+	in.HotelSettingID = current.HotelSettingID
+	in.CreatedAt = current.CreatedAt
+	if err := s.DB.Save(&in).Error; err != nil {
+		return current, fmt.Errorf("failed to save schedule setting: %w", err)
+	}
+	return in, nil
+}
+
+// JobRunListSpec: GET /api/scheduler/runs
+var JobRunListSpec = ListSpec{
+	IDColumn: "id",
+	Sorts: map[string]string{
+		"id":        "id",
+		"startedAt": "started_at",
+	},
+	DefaultSort: "-id",
+	DateColumn:  "started_at",
+	Filters: map[string]ListFilter{
+		"job":    {Cond: "job = ?", Kind: FilterText},
+		"status": {Cond: "status IN ?", Kind: FilterList},
+	},
+	MaxLimit: 500,
+}
+
+// ListRuns คืนประวัติการรัน (filter ตาม job/status ได้) เรียงใหม่สุดก่อน
+func (s *SchedulerService) ListRuns(q ListQuery) ([]models.JobRun, PageInfo, error) {
+	runs := []models.JobRun{}
+	page, err := FindPage(s.DB, JobRunListSpec, q, &runs)
+	return runs, page, err
+}
+
+// Start วน tick ตาม IntervalMinutes จนกว่า ctx ถูก cancel
+func (s *SchedulerService) Start(ctx context.Context) {
+	log.Println("⏰ Scheduler started")
+	for {
+		interval := 15 * time.Minute
+
+		setting, err := s.GetSettings()
+		if err != nil {
+			log.Printf("scheduler: failed to load settings: %v", err)
+		} else {
+			if setting.IntervalMinutes > 0 {
+				interval = time.Duration(setting.IntervalMinutes) * time.Minute
+			}
+			if setting.Enabled {
+				if _, err := s.RunAll("scheduler"); err != nil && !errors.Is(err, ErrSchedulerBusy) {
+					log.Printf("scheduler: run failed: %v", err)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("⏰ Scheduler stopped")
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// RunAll รันทุกงานที่เปิดใช้งานอยู่ (ไม่รันซ้อนกัน)
+func (s *SchedulerService) RunAll(trigger string) ([]models.JobRun, error) {
+	if !s.running.TryLock() {
+		return nil, ErrSchedulerBusy
+	}
+	defer s.running.Unlock()
+
+	setting, err := s.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []models.JobRun{}
+	for _, job := range SchedulerJobs {
+		if !jobEnabled(setting, job) {
+			continue
+		}
+		runs = append(runs, s.runJob(job, trigger, setting))
+	}
+	return runs, nil
+}
+
+// RunJob รันงานเดียว (manual) โดยไม่สนใจว่าปิดอยู่หรือไม่
+func (s *SchedulerService) RunJob(job, trigger string) (models.JobRun, error) {
+	if !isSchedulerJob(job) {
+		return models.JobRun{}, ErrUnknownJob
+	}
+	if !s.running.TryLock() {
+		return models.JobRun{}, ErrSchedulerBusy
+	}
+	defer s.running.Unlock()
+
+	setting, err := s.GetSettings()
+	if err != nil {
+		return models.JobRun{}, err
+	}
+	return s.runJob(job, trigger, setting), nil
+}
+
+func isSchedulerJob(job string) bool {
+	for _, j := range SchedulerJobs {
+		if j == job {
+			return true
+		}
+	}
+	return false
+}
+
+func jobEnabled(setting models.ScheduleSetting, job string) bool {
+	switch job {
+	case JobAutoInitiate:
+		return setting.AutoInitiateEnabled
+	case JobCheckInReminder:
+		return setting.ReminderEnabled
+	case JobThankYou:
+		return setting.ThankYouEnabled
+	case JobICalImport:
+		return true // เปิด / ปิดรายปฏิทินที่ RoomCalendarImport.Active
+	}
+	return false
+}
+
+// jobResult ผลรวมของงานหนึ่งรอบ
+type jobResult struct {
+	processed, succeeded, failed, skipped int
+	errs                                  []string
+}
+
+func (r *jobResult) fail(format string, args ...interface{}) {
+	r.failed++
+	r.warn(format, args...)
+}
+
+// warn บันทึกข้อความลง run โดยไม่นับเป็นรายการที่ล้มเหลว (เช่น ส่งได้บางช่องทาง)
+func (r *jobResult) warn(format string, args ...interface{}) {
+	if len(r.errs) < 20 {
+		r.errs = append(r.errs, fmt.Sprintf(format, args...))
+	}
+}
+
+func (s *SchedulerService) runJob(job, trigger string, setting models.ScheduleSetting) models.JobRun {
+	run := models.JobRun{
+		Job:       job,
+		Trigger:   trigger,
+		Status:    JobStatusRunning,
+		StartedAt: time.Now().UTC(),
+	}
+	if err := s.DB.Create(&run).Error; err != nil {
+		log.Printf("scheduler: failed to record job run %s: %v", job, err)
+	}
+
+	var res jobResult
+	var runErr error
+	switch job {
+	case JobAutoInitiate:
+		runErr = s.autoInitiate(setting, &res)
+	case JobCheckInReminder:
+		runErr = s.sendReminders(setting, &res)
+	case JobThankYou:
+		runErr = s.sendThankYous(setting, &res)
+	case JobICalImport:
+		runErr = s.Calendars.syncImports(&res)
+	}
+
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+	run.Processed = res.processed
+	run.Succeeded = res.succeeded
+	run.Failed = res.failed
+	run.Skipped = res.skipped
+
+	switch {
+	case runErr != nil:
+		run.Status = JobStatusFailed
+		res.errs = append([]string{runErr.Error()}, res.errs...)
+	case res.failed > 0 && res.succeeded == 0:
+		run.Status = JobStatusFailed
+	case res.failed > 0:
+		run.Status = JobStatusPartial
+	default:
+		run.Status = JobStatusSuccess
+	}
+	run.Error = strings.Join(res.errs, "\n")
+
+	if run.ID != 0 {
+		if err := s.DB.Save(&run).Error; err != nil {
+			log.Printf("scheduler: failed to update job run %d: %v", run.ID, err)
+		}
+	}
+	if res.processed > 0 || runErr != nil {
+		log.Printf("scheduler: %s done status=%s processed=%d ok=%d failed=%d skipped=%d",
+			job, run.Status, res.processed, res.succeeded, res.failed, res.skipped)
+	}
+	return run
+}
+
+// activeBookingStatuses: booking ที่ยังไม่เช็คอิน/เช็คเอาท์/ยกเลิก
+func activeBookingScope(db *gorm.DB) *gorm.DB {
+	return db.Where("bookings.checked_in_at IS NULL").
+		Where("LOWER(COALESCE(bookings.status, '')) NOT IN ?", []string{"checked-in", "checkedin", "checked in", "checked-out", "cancelled", "canceled"})
+}
+
+// autoInitiate: booking ที่จะเข้าพักภายใน N วันและยังไม่เคยมี booking_info -> Sessions.InitiateOnce
+func (s *SchedulerService) autoInitiate(setting models.ScheduleSetting, res *jobResult) error {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	until := today.AddDate(0, 0, setting.AutoInitiateDaysBefore+1)
+
+	var ids []uint
+	err := activeBookingScope(s.DB.Model(&models.Booking{})).
+		Where("bookings.check_in_date >= ? AND bookings.check_in_date < ?", today, until).
+		Where("NOT EXISTS (SELECT 1 FROM booking_infos bi WHERE bi.booking_id = bookings.id AND bi.deleted_at IS NULL)").
+		Pluck("bookings.id", &ids).Error
+	if err != nil {
+		return fmt.Errorf("failed to query bookings: %w", err)
+	}
+
+	for _, id := range ids {
+		res.processed++
+		_, err := s.Bookings.Sessions.InitiateOnce(id)
+		switch {
+		case err == nil:
+			res.succeeded++
+		case errors.Is(err, ErrCheckinAlreadyInitiated),
+			errors.Is(err, ErrNotificationNotSent), // session สร้างแล้ว งานเตือนจะลองส่งอีกครั้ง
+			errors.Is(err, ErrCustomerContactMissing),
+			errors.Is(err, ErrAlreadyCheckedIn):
+			res.skipped++
+		default:
+			res.fail("booking %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// sendReminders: BookingInfo ที่ยัง INITIATED และเข้าพักภายใน N ชั่วโมง -> ส่งเตือน (ครั้งเดียว)
+func (s *SchedulerService) sendReminders(setting models.ScheduleSetting, res *jobResult) error {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	until := now.Add(time.Duration(setting.ReminderHoursBefore) * time.Hour)
+
+	var infos []models.BookingInfo
+	err := s.DB.
+		Joins("JOIN bookings ON bookings.id = booking_infos.booking_id AND bookings.deleted_at IS NULL").
+		Where("booking_infos.status = ? AND booking_infos.reminder_sent_at IS NULL", "INITIATED").
+		Where("bookings.check_in_date >= ? AND bookings.check_in_date <= ?", today, until).
+		Scopes(activeBookingScope).
+		Find(&infos).Error
+	if err != nil {
+		return fmt.Errorf("failed to query booking infos: %w", err)
+	}
+
+	for _, bi := range infos {
+		res.processed++
+
+		// จองสิทธิ์ส่งก่อน (reminder_sent_at IS NULL -> now) กัน instance อื่นส่งซ้ำ
+		claimed, err := claimSentStamp(s.DB, &models.BookingInfo{}, bi.ID, "reminder_sent_at", now)
+		if err != nil {
+			res.fail("booking_info %d: claim: %v", bi.ID, err)
+			continue
+		}
+		if !claimed {
+			res.skipped++
+			continue
+		}
+
+		var booking models.Booking
+		if err := s.DB.Preload("Rooms.Room.RoomType").Preload("Room.RoomType").Preload("Customer").
+			First(&booking, bi.BookingID).Error; err != nil {
+			res.fail("booking_info %d: %v", bi.ID, err)
+			releaseSentStamp(s.DB, &models.BookingInfo{}, bi.ID, "reminder_sent_at")
+			continue
+		}
+
+		// token หมดอายุก่อนวันเข้าพัก -> ต่ออายุถึงสิ้นวันเช็คอินเพื่อให้ลิงก์ในข้อความยังใช้ได้
+		if bi.ExpiresAt != nil && bi.ExpiresAt.Before(now) && booking.CheckInDate != nil {
+			newExp := booking.CheckInDate.UTC().Add(24 * time.Hour)
+			if err := s.DB.Model(&models.BookingInfo{}).Where("id = ?", bi.ID).
+				Update("expires_at", newExp).Error; err != nil {
+				res.fail("booking_info %d: extend expiry: %v", bi.ID, err)
+				releaseSentStamp(s.DB, &models.BookingInfo{}, bi.ID, "reminder_sent_at")
+				continue
+			}
+			bi.ExpiresAt = &newExp
+		}
+
+		data := checkInInviteData(s.DB, booking, bi).Sanitized()
+		results, sendErr := s.Notifier.Dispatch(0, ParseChannels(booking.NotificationChannels), Notification{
+			Kind:      utils.EmailCheckInReminder,
+			Locale:    booking.Customer.PreferredLanguage,
+			Recipient: recipientFor(booking, bi.GuestEmail),
+			Data:      data,
+		})
+		// ส่งได้อย่างน้อยหนึ่งช่องทาง = ส่งแล้ว ไม่งั้นช่องทางที่สำเร็จจะได้ข้อความซ้ำทุกรอบ
+		if !anySent(results) {
+			if errors.Is(sendErr, ErrNotificationNotSent) {
+				res.skipped++
+			} else {
+				res.fail("booking_info %d: %v", bi.ID, sendErr)
+			}
+			releaseSentStamp(s.DB, &models.BookingInfo{}, bi.ID, "reminder_sent_at")
+			continue
+		}
+		if sendErr != nil {
+			res.warn("booking_info %d: partial delivery: %v", bi.ID, sendErr)
+		}
+		res.succeeded++
+	}
+	return nil
+}
+
+// sendThankYous: booking ที่เช็คเอาท์แล้วเกิน N ชั่วโมงและยังไม่เคยส่งขอบคุณ
+func (s *SchedulerService) sendThankYous(setting models.ScheduleSetting, res *jobResult) error {
+	now := time.Now().UTC()
+	dueBefore := now.Add(-time.Duration(setting.ThankYouHoursAfter) * time.Hour)
+	notBefore := now.Add(-thankYouLookback)
+
+	var bookings []models.Booking
+	err := s.DB.Preload("Rooms.Room.RoomType").Preload("Room.RoomType").Preload("Customer").
+		Where("status = ? AND thank_you_sent_at IS NULL", "Checked-Out").
+		Where("check_out <= ? AND check_out >= ?", dueBefore, notBefore).
+		Find(&bookings).Error
+	if err != nil {
+		return fmt.Errorf("failed to query bookings: %w", err)
+	}
+
+	for _, booking := range bookings {
+		res.processed++
+
+		claimed, err := claimSentStamp(s.DB, &models.Booking{}, booking.ID, "thank_you_sent_at", now)
+		if err != nil {
+			res.fail("booking %d: claim: %v", booking.ID, err)
+			continue
+		}
+		if !claimed {
+			res.skipped++
+			continue
+		}
+
+		data := checkInInviteData(s.DB, booking, models.BookingInfo{})
+		data.CheckinLink = ""
+		data.CheckInDate = formatDatePtr(booking.CheckInDate)
+		data.CheckOutDate = formatDatePtr(booking.CheckOut)
+
+		results, sendErr := s.Notifier.Dispatch(0, ParseChannels(booking.NotificationChannels), Notification{
+			Kind:      utils.EmailThankYou,
+			Locale:    booking.Customer.PreferredLanguage,
+			Recipient: recipientFor(booking, ""),
+			Data:      data,
+		})
+		if !anySent(results) {
+			if !errors.Is(sendErr, ErrNotificationNotSent) {
+				res.fail("booking %d: %v", booking.ID, sendErr)
+				releaseSentStamp(s.DB, &models.Booking{}, booking.ID, "thank_you_sent_at")
+				continue
+			}
+			// ไม่มีช่องทางส่งได้ -> คง stamp ไว้เพื่อไม่ต้องลองซ้ำทุกรอบ
+			res.skipped++
+			continue
+		}
+		if sendErr != nil {
+			res.warn("booking %d: partial delivery: %v", booking.ID, sendErr)
+		}
+		res.succeeded++
+	}
+	return nil
+}
+
+// claimSentStamp ตั้ง column = now เฉพาะแถวที่ยังเป็น NULL (atomic UPDATE)
+// true = รอบนี้ได้สิทธิ์ส่ง; running.TryLock กันได้แค่ใน process เดียว ส่วนนี้กันหลาย instance
+func claimSentStamp(db *gorm.DB, model interface{}, id uint, column string, now time.Time) (bool, error) {
+	tx := db.Model(model).Where("id = ? AND "+column+" IS NULL", id).Update(column, now)
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	return tx.RowsAffected == 1, nil
+}
+
+// releaseSentStamp คืนสิทธิ์ที่ claim ไว้เมื่อส่งไม่สำเร็จ (รอบถัดไปลองใหม่)
+func releaseSentStamp(db *gorm.DB, model interface{}, id uint, column string) {
+	if err := db.Model(model).Where("id = ?", id).Update(column, nil).Error; err != nil {
+		log.Printf("scheduler: release %s for %d failed: %v", column, id, err)
+	}
+}
+
+func recipientFor(booking models.Booking, guestEmail string) Recipient {
+	return Recipient{
+		Email:      firstNonEmpty(guestEmail, booking.Customer.Email),
+		Phone:      booking.Customer.Phone,
+		LineUserID: booking.Customer.LineUserID,
+	}
+}
+
+func anySent(results []DeliveryResult) bool {
+	for _, r := range results {
+		if r.Status == DeliverySent {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,466 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"hotel-backend/models"
+	"hotel-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+//
+// ===========================================================
+//  FRONT-DESK SEARCH (ช่องค้นหาเดียว: การจอง / ลูกค้า / แขก / ห้อง)
+// ===========================================================
+//
+
+var ErrSearchQueryTooShort = NewError(KindInvalid, "error.searchQueryTooShort")
+
+// ลำดับความเกี่ยวข้อง: เลขอ้างอิงตรง > เลขบัตร/ติดต่อ/ห้องตรง > ชื่อขึ้นต้น > มีคำค้นอยู่ข้างใน
+const (
+	RankExactReference = 400
+	RankIdentifier     = 300
+	RankNamePrefix     = 200
+	RankSubstring      = 100
+)
+
+const (
+	SearchMinQueryLen   = 2
+	SearchDefaultLimit  = 10
+	SearchMaxLimit      = 50
+	searchCandidateMult = 3 // ดึงเกิน limit ไว้ก่อน แล้วค่อยจัดอันดับ/ตัด
+)
+
+// FULLTEXT index ที่ config.ensureSearchIndexes สร้าง (MySQL เท่านั้น)
+const (
+	SearchIndexCustomers = "ft_customers_search"
+	SearchIndexGuests    = "ft_guests_search"
+)
+
+type BookingSearchResult struct {
+	ID            uint       `json:"id"`
+	ReferenceCode string     `json:"referenceCode"`
+	Status        string     `json:"status"`
+	CheckIn       *time.Time `json:"checkIn,omitempty"`
+	CheckOut      *time.Time `json:"checkOut,omitempty"`
+	CustomerName  string     `json:"customerName"`
+	Rooms         []string   `json:"rooms"`
+	Rank          int        `json:"rank"`
+	MatchedOn     string     `json:"matchedOn"`
+}
+
+type CustomerSearchResult struct {
+	ID        uint   `json:"id"`
+	FullName  string `json:"fullName"`
+	Email     string `json:"email,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+	Rank      int    `json:"rank"`
+	MatchedOn string `json:"matchedOn"`
+}
+
+type GuestSearchResult struct {
+	ID          uint   `json:"id"`
+	BookingID   *uint  `json:"bookingId,omitempty"`
+	FullName    string `json:"fullName"`
+	Nationality string `json:"nationality,omitempty"`
+	IDType      string `json:"idType,omitempty"`
+	IDNumber    string `json:"idNumber,omitempty"`
+	Rank        int    `json:"rank"`
+	MatchedOn   string `json:"matchedOn"`
+}
+
+type RoomSearchResult struct {
+	ID            uint   `json:"id"`
+	RoomNumber    string `json:"roomNumber"`
+	RoomCode      string `json:"roomCode,omitempty"`
+	Occupancy     string `json:"occupancy,omitempty"`
+	CleanStatus   string `json:"cleanStatus,omitempty"`
+	ServiceStatus string `json:"serviceStatus,omitempty"`
+	Floor         string `json:"floor,omitempty"`
+	Rank          int    `json:"rank"`
+	MatchedOn     string `json:"matchedOn"`
+}
+
+// SearchResults ผลค้นหาแยกตามชนิด (แต่ละกลุ่มเรียง rank มากไปน้อย)
+type SearchResults struct {
+	Query     string                 `json:"query"`
+	FullText  bool                   `json:"fullText"`
+	Bookings  []BookingSearchResult  `json:"bookings"`
+	Customers []CustomerSearchResult `json:"customers"`
+	Guests    []GuestSearchResult    `json:"guests"`
+	Rooms     []RoomSearchResult     `json:"rooms"`
+}
+
+type SearchService struct {
+	DB *gorm.DB
+
+	ftOnce      sync.Once
+	ftAvailable bool
+}
+
+func NewSearchService(db *gorm.DB) *SearchService {
+	return &SearchService{DB: db}
+}
+
+// fullTextAvailable: MySQL และมี FULLTEXT index ครบ (ตรวจครั้งเดียว)
+func (s *SearchService) fullTextAvailable() bool {
+	s.ftOnce.Do(func() {
+		if s.DB.Dialector.Name() != "mysql" {
+			return
+		}
+		m := s.DB.Migrator()
+		s.ftAvailable = m.HasIndex(&models.Customer{}, SearchIndexCustomers) && m.HasIndex(&models.Guest{}, SearchIndexGuests)
+		if !s.ftAvailable {
+			log.Println("search: FULLTEXT indexes missing, using LIKE fallback")
+		}
+	})
+	return s.ftAvailable
+}
+
+// SearchOptions สิ่งที่ผู้ค้นเห็นได้ตามสิทธิ์
+type SearchOptions struct {
+	// ShowIDNumbers ค้น / แสดงเลขเอกสารของแขกเต็ม (tm30Verification.view); ไม่งั้นไม่ค้นด้วยเลขเอกสารและ mask ผลลัพธ์
+	ShowIDNumbers bool
+}
+
+// Search ค้นหาจากคำเดียวในทุกชนิด; การจองรวมผลจากลูกค้า/แขก/ห้องที่ตรงด้วย
+func (s *SearchService) Search(query string, limit int, opts SearchOptions) (*SearchResults, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	if len([]rune(query)) < SearchMinQueryLen {
+		return nil, ErrSearchQueryTooShort
+	}
+	if limit <= 0 {
+		limit = SearchDefaultLimit
+	}
+	if limit > SearchMaxLimit {
+		limit = SearchMaxLimit
+	}
+	candidates := limit * searchCandidateMult
+
+	out := &SearchResults{Query: query, FullText: s.fullTextAvailable()}
+	like := "%" + escapeLike(query) + "%"
+	boolean := fullTextTerms(query)
+	useFT := out.FullText && boolean != ""
+
+	// --- customers ---
+	var customers []models.Customer
+	cq := s.DB.Model(&models.Customer{})
+	cond, args := "full_name LIKE ? OR email LIKE ? OR phone LIKE ?", []interface{}{like, like, like}
+	if useFT {
+		// FULLTEXT (parser ปกติ) ไม่ตัดคำภาษาไทย / คำสั้นกว่า innodb_ft_min_token_size / กลางคำ -> คง LIKE ไว้ด้วย
+		cond, args = "MATCH(full_name, email, phone) AGAINST (? IN BOOLEAN MODE) OR full_name LIKE ? OR email LIKE ? OR phone LIKE ?", []interface{}{boolean, like, like, like}
+	}
+	if digits, ok := phoneQuery(query); ok {
+		// เบอร์ที่เก็บไว้มีขีด/ช่องว่างได้ เทียบเฉพาะตัวเลข
+		cond += " OR REPLACE(REPLACE(REPLACE(phone, '-', ''), ' ', ''), '+', '') LIKE ?"
+		args = append(args, "%"+digits+"%")
+	}
+	cq = cq.Where(cond, args...)
+	if err := cq.Order("id DESC").Limit(candidates).Find(&customers).Error; err != nil {
+		return nil, err
+	}
+	customerRank := map[uint]searchMatch{}
+	for _, c := range customers {
+		m := bestMatch(
+			matchName(query, c.FullName),
+			matchIdentifier(query, c.Email, "email"),
+			matchPhone(query, c.Phone),
+		)
+		customerRank[c.ID] = m
+		out.Customers = append(out.Customers, CustomerSearchResult{
+			ID: c.ID, FullName: c.FullName, Email: c.Email, Phone: c.Phone, Rank: m.rank, MatchedOn: m.field,
+		})
+	}
+
+	// --- guests ---
+	var guests []models.Guest
+	gq := s.DB.Model(&models.Guest{})
+	switch {
+	case !opts.ShowIDNumbers:
+		// FULLTEXT index รวม id_number ไว้ด้วย — ไม่มีสิทธิ์ดูเลขเอกสารจึงค้นแบบ LIKE เฉพาะชื่อ / อีเมล
+		gq = gq.Where("full_name LIKE ? OR email LIKE ?", like, like)
+	case useFT:
+		gq = gq.Where("MATCH(full_name, id_number, email) AGAINST (? IN BOOLEAN MODE) OR full_name LIKE ? OR id_number LIKE ? OR email LIKE ?", boolean, like, like, like)
+	default:
+		gq = gq.Where("full_name LIKE ? OR id_number LIKE ? OR email LIKE ?", like, like, like)
+	}
+	if err := gq.Order("id DESC").Limit(candidates).Find(&guests).Error; err != nil {
+		return nil, err
+	}
+	for _, g := range guests {
+		idNumber := g.IDNumber
+		idMatch := matchIdentifier(query, g.IDNumber, "idNumber")
+		if !opts.ShowIDNumbers {
+			idNumber, idMatch = utils.MaskTail(idNumber, 4), searchMatch{}
+		}
+		m := bestMatch(
+			matchName(query, g.FullName),
+			idMatch,
+			matchIdentifier(query, g.Email, "email"),
+		)
+		out.Guests = append(out.Guests, GuestSearchResult{
+			ID: g.ID, BookingID: g.BookingID, FullName: g.FullName, Nationality: g.Nationality,
+			IDType: g.IDType, IDNumber: idNumber, Rank: m.rank, MatchedOn: m.field,
+		})
+	}
+
+	// --- rooms ---
+	var rooms []models.Room
+	if err := s.DB.Where("room_number LIKE ? OR room_code LIKE ?", like, like).
+		Order("room_number ASC").Limit(candidates).Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	if err := FillRoomOccupancy(s.DB, rooms); err != nil {
+		return nil, err
+	}
+	roomRank := map[uint]searchMatch{}
+	for _, r := range rooms {
+		m := bestMatch(matchIdentifier(query, r.RoomNumber, "roomNumber"), matchIdentifier(query, r.RoomCode, "roomCode"))
+		roomRank[r.ID] = m
+		out.Rooms = append(out.Rooms, RoomSearchResult{
+			ID: r.ID, RoomNumber: r.RoomNumber, RoomCode: r.RoomCode, Occupancy: r.Occupancy, CleanStatus: r.CleanStatus,
+			ServiceStatus: r.ServiceStatus, Floor: r.Floor, Rank: m.rank, MatchedOn: m.field,
+		})
+	}
+
+	// --- bookings: เลขอ้างอิง + ที่โยงมาจากลูกค้า/แขก/ห้อง ---
+	bookingRank := map[uint]searchMatch{}
+	raise := func(id uint, m searchMatch) {
+		if cur, ok := bookingRank[id]; !ok || m.rank > cur.rank {
+			bookingRank[id] = m
+		}
+	}
+
+	var refHits []models.Booking
+	if err := s.DB.Select("id", "reference_code").
+		Where("reference_code LIKE ?", like).
+		Order("id DESC").Limit(candidates).Find(&refHits).Error; err != nil {
+		return nil, err
+	}
+	for _, b := range refHits {
+		raise(b.ID, matchReference(query, b.ReferenceCode))
+	}
+
+	if len(customerRank) > 0 {
+		var linked []models.Booking
+		if err := s.DB.Select("id", "customer_id").Where("customer_id IN ?", mapKeys(customerRank)).
+			Order("id DESC").Limit(candidates).Find(&linked).Error; err != nil {
+			return nil, err
+		}
+		for _, b := range linked {
+			m := customerRank[b.CustomerID]
+			raise(b.ID, searchMatch{m.rank, "customer." + m.field})
+		}
+	}
+	for i, g := range guests {
+		if g.BookingID != nil {
+			raise(*g.BookingID, searchMatch{out.Guests[i].Rank, "guest." + out.Guests[i].MatchedOn})
+		}
+	}
+	if len(roomRank) > 0 {
+		var linked []models.BookingRoom
+		if err := s.DB.Select("booking_id", "room_id").Where("room_id IN ?", mapKeys(roomRank)).
+			Order("booking_id DESC").Limit(candidates).Find(&linked).Error; err != nil {
+			return nil, err
+		}
+		for _, br := range linked {
+			m := roomRank[br.RoomID]
+			raise(br.BookingID, searchMatch{m.rank, "room." + m.field})
+		}
+	}
+
+	if len(bookingRank) > 0 {
+		var bookings []models.Booking
+		if err := s.DB.Preload("Customer").Preload("Rooms.Room").
+			Where("id IN ?", mapKeys(bookingRank)).Find(&bookings).Error; err != nil {
+			return nil, err
+		}
+		for _, b := range bookings {
+			m := bookingRank[b.ID]
+			res := BookingSearchResult{
+				ID: b.ID, ReferenceCode: b.ReferenceCode, Status: b.Status, CheckIn: b.CheckIn, CheckOut: b.CheckOut,
+				CustomerName: b.Customer.FullName, Rooms: []string{}, Rank: m.rank, MatchedOn: m.field,
+			}
+			for _, br := range CurrentBookingRooms(b.Rooms) {
+				if num := strings.TrimSpace(br.Room.RoomNumber); num != "" {
+					res.Rooms = append(res.Rooms, num)
+				}
+			}
+			out.Bookings = append(out.Bookings, res)
+		}
+	}
+
+	sort.SliceStable(out.Bookings, func(i, j int) bool {
+		return rankedBefore(out.Bookings[i].Rank, out.Bookings[j].Rank, out.Bookings[i].ID, out.Bookings[j].ID)
+	})
+	sort.SliceStable(out.Customers, func(i, j int) bool {
+		return rankedBefore(out.Customers[i].Rank, out.Customers[j].Rank, out.Customers[i].ID, out.Customers[j].ID)
+	})
+	sort.SliceStable(out.Guests, func(i, j int) bool {
+		return rankedBefore(out.Guests[i].Rank, out.Guests[j].Rank, out.Guests[i].ID, out.Guests[j].ID)
+	})
+	sort.SliceStable(out.Rooms, func(i, j int) bool {
+		return rankedBefore(out.Rooms[i].Rank, out.Rooms[j].Rank, out.Rooms[i].ID, out.Rooms[j].ID)
+	})
+
+	out.Bookings = truncate(out.Bookings, limit)
+	out.Customers = truncate(out.Customers, limit)
+	out.Guests = truncate(out.Guests, limit)
+	out.Rooms = truncate(out.Rooms, limit)
+	return out, nil
+}
+
+// ---------------- ranking ----------------
+
+type searchMatch struct {
+	rank  int
+	field string
+}
+
+// bestMatch rank สูงสุด; ไม่มี field ไหนมีคำค้นทั้งก้อน (FULLTEXT ตรงทีละคำ) = substring
+func bestMatch(ms ...searchMatch) searchMatch {
+	var best searchMatch
+	for _, m := range ms {
+		if m.field != "" && m.rank > best.rank {
+			best = m
+		}
+	}
+	if best.field == "" {
+		best = searchMatch{RankSubstring, "fullText"}
+	}
+	return best
+}
+
+func matchReference(q, ref string) searchMatch {
+	if ref != "" && strings.EqualFold(strings.TrimSpace(ref), q) {
+		return searchMatch{RankExactReference, "referenceCode"}
+	}
+	return searchMatch{RankSubstring, "referenceCode"}
+}
+
+func matchIdentifier(q, value, field string) searchMatch {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return searchMatch{}
+	case strings.EqualFold(value, q):
+		return searchMatch{RankIdentifier, field}
+	case containsFold(value, q):
+		return searchMatch{RankSubstring, field}
+	}
+	return searchMatch{}
+}
+
+// matchPhone เทียบเฉพาะตัวเลข ("081-234 5678" = "0812345678")
+func matchPhone(q, phone string) searchMatch {
+	dq, dp := digitsOnly(q), digitsOnly(phone)
+	switch {
+	case dp == "" || len(dq) < SearchMinQueryLen:
+		return searchMatch{}
+	case dq == dp:
+		return searchMatch{RankIdentifier, "phone"}
+	case strings.Contains(dp, dq):
+		return searchMatch{RankSubstring, "phone"}
+	}
+	return searchMatch{}
+}
+
+// matchName: ชื่อเต็มหรือคำใดคำหนึ่งในชื่อขึ้นต้นด้วยคำค้น = prefix
+func matchName(q, name string) searchMatch {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return searchMatch{}
+	}
+	lq, ln := strings.ToLower(q), strings.ToLower(name)
+	if strings.HasPrefix(ln, lq) {
+		return searchMatch{RankNamePrefix, "name"}
+	}
+	for _, word := range strings.Fields(ln) {
+		if strings.HasPrefix(word, lq) {
+			return searchMatch{RankNamePrefix, "name"}
+		}
+	}
+	if strings.Contains(ln, lq) {
+		return searchMatch{RankSubstring, "name"}
+	}
+	return searchMatch{}
+}
+
+func rankedBefore(ri, rj int, idi, idj uint) bool {
+	if ri != rj {
+		return ri > rj
+	}
+	return idi > idj
+}
+
+func truncate[T any](list []T, n int) []T {
+	if list == nil {
+		return []T{}
+	}
+	if len(list) > n {
+		return list[:n]
+	}
+	return list
+}
+
+func mapKeys(m map[uint]searchMatch) []uint {
+	keys := make([]uint, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func containsFold(s, sub string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(sub))
+}
+
+// phoneQuery: คำค้นเป็นเบอร์โทร (ตัวเลขกับ + - ช่องว่าง วงเล็บ) → ตัวเลขล้วน
+func phoneQuery(q string) (string, bool) {
+	for _, r := range q {
+		if !unicode.IsDigit(r) && !strings.ContainsRune("+-() ", r) {
+			return "", false
+		}
+	}
+	digits := digitsOnly(q)
+	return digits, len(digits) >= 3
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// escapeLike กัน % และ _ ในคำค้นไม่ให้กลายเป็น wildcard
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// fullTextTerms แปลงคำค้นเป็น BOOLEAN MODE: ทุกคำต้องมี และให้ตรงแบบขึ้นต้น ("+som* +chai*")
+func fullTextTerms(q string) string {
+	var terms []string
+	for _, word := range strings.Fields(q) {
+		word = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		word = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`+-<>()~*"@`, r) {
+				return -1
+			}
+			return r
+		}, word)
+		if len([]rune(word)) >= SearchMinQueryLen {
+			terms = append(terms, "+"+word+"*")
+		}
+	}
+	return strings.Join(terms, " ")
+}
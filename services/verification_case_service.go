@@ -0,0 +1,186 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"hotel-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Verification case statuses / sources
+const (
+	CasePending  = "PENDING"
+	CaseApproved = "APPROVED"
+	CaseRejected = "REJECTED"
+
+	CaseSourceKiosk  = "kiosk"
+	CaseSourceOnline = "online"
+)
+
+var (
+	ErrCaseNotFound        = NewError(KindNotFound, "error.caseNotFound")
+	ErrCaseAlreadyReviewed = NewError(KindConflict, "error.caseAlreadyReviewed")
+)
+
+// OpenCaseInput ข้อมูลสำหรับเปิด case ใหม่
+type OpenCaseInput struct {
+	BookingID     uint
+	BookingInfoID uint
+	GuestID       *uint
+	KioskDeviceID *uint
+	Source        string
+	Reasons       []string
+	Details       map[string]interface{}
+	BlocksCheckIn bool
+}
+
+// VerificationCaseService คิวตรวจสอบของ staff
+type VerificationCaseService struct {
+	DB       *gorm.DB
+	Bookings *BookingService
+}
+
+func NewVerificationCaseService(db *gorm.DB, bookings *BookingService) *VerificationCaseService {
+	return &VerificationCaseService{DB: db, Bookings: bookings}
+}
+
+// Open สร้าง case สถานะ PENDING
+func (s *VerificationCaseService) Open(in OpenCaseInput) (models.VerificationCase, error) {
+	vc := models.VerificationCase{
+		BookingID:     in.BookingID,
+		BookingInfoID: in.BookingInfoID,
+		GuestID:       in.GuestID,
+		KioskDeviceID: in.KioskDeviceID,
+		Source:        in.Source,
+		Reasons:       strings.Join(in.Reasons, ","),
+		BlocksCheckIn: in.BlocksCheckIn,
+		Status:        CasePending,
+	}
+	if len(in.Details) > 0 {
+		if b, err := json.Marshal(in.Details); err == nil {
+			vc.Details = string(b)
+		}
+	}
+	if err := s.DB.Create(&vc).Error; err != nil {
+		return vc, fmt.Errorf("failed to open verification case: %w", err)
+	}
+	return vc, nil
+}
+
+// PendingForBookingInfo คืน case ที่ยังรอตรวจของ session นี้ (ถ้ามี)
+func (s *VerificationCaseService) PendingForBookingInfo(bookingInfoID uint) (*models.VerificationCase, error) {
+	var vc models.VerificationCase
+	err := s.DB.Where("booking_info_id = ? AND status = ?", bookingInfoID, CasePending).
+		Order("id DESC").First(&vc).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &vc, nil
+}
+
+// VerificationCaseListSpec: GET /api/verification-cases
+var VerificationCaseListSpec = ListSpec{
+	IDColumn: "id",
+	Sorts: map[string]string{
+		"id":         "id",
+		"createdAt":  "created_at",
+		"reviewedAt": "reviewed_at",
+	},
+	DefaultSort: "-id",
+	DateColumn:  "created_at",
+	Filters: map[string]ListFilter{
+		"status":    {Cond: "status IN ?", Kind: FilterList},
+		"source":    {Cond: "source IN ?", Kind: FilterList},
+		"bookingId": {Cond: "booking_id = ?", Kind: FilterID},
+	},
+}
+
+// List คืน case ตาม filter (status ว่าง = ทั้งหมด) เรียงใหม่สุดก่อน
+func (s *VerificationCaseService) List(q ListQuery) ([]models.VerificationCase, PageInfo, error) {
+	out := []models.VerificationCase{}
+	page, err := FindPage(s.DB, VerificationCaseListSpec, q, &out)
+	if err != nil {
+		return nil, page, err
+	}
+	return out, page, nil
+}
+
+func (s *VerificationCaseService) Get(id uint) (models.VerificationCase, error) {
+	var vc models.VerificationCase
+	if err := s.DB.First(&vc, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return vc, ErrCaseNotFound
+		}
+		return vc, err
+	}
+	return vc, nil
+}
+
+// Approve อนุมัติ case; ถ้า case กันการเช็คอินไว้จะ submit draft ของ session นั้นต่อให้
+func (s *VerificationCaseService) Approve(id uint, reviewer, note string) (models.VerificationCase, error) {
+	vc, err := s.pending(id)
+	if err != nil {
+		return vc, err
+	}
+
+	if vc.BlocksCheckIn {
+		var bi models.BookingInfo
+		if err := s.DB.First(&bi, vc.BookingInfoID).Error; err != nil {
+			return vc, fmt.Errorf("failed to load booking info: %w", err)
+		}
+		if _, err := s.Bookings.Drafts.Submit(bi.Token); err != nil && !errors.Is(err, ErrDraftSubmitted) {
+			return vc, err
+		}
+	}
+
+	return s.review(vc, CaseApproved, reviewer, note)
+}
+
+// Reject ปฏิเสธ case (การเช็คอินที่ถูกกันไว้จะไม่ถูก finalize — แขกต้องติดต่อเคาน์เตอร์)
+func (s *VerificationCaseService) Reject(id uint, reviewer, note string) (models.VerificationCase, error) {
+	vc, err := s.pending(id)
+	if err != nil {
+		return vc, err
+	}
+	return s.review(vc, CaseRejected, reviewer, note)
+}
+
+func (s *VerificationCaseService) pending(id uint) (models.VerificationCase, error) {
+	vc, err := s.Get(id)
+	if err != nil {
+		return vc, err
+	}
+	if vc.Status != CasePending {
+		return vc, ErrCaseAlreadyReviewed
+	}
+	return vc, nil
+}
+
+func (s *VerificationCaseService) review(vc models.VerificationCase, status, reviewer, note string) (models.VerificationCase, error) {
+	now := time.Now().UTC()
+	if err := s.DB.Model(&vc).Updates(map[string]interface{}{
+		"status":      status,
+		"reviewed_by": strings.TrimSpace(reviewer),
+		"review_note": strings.TrimSpace(note),
+		"reviewed_at": now,
+	}).Error; err != nil {
+		return vc, err
+	}
+
+	RecordAudit(s.DB, AuditEntry{
+		ActorType: ActorAdmin,
+		ActorID:   strings.TrimSpace(reviewer),
+		Action:    "verification." + strings.ToLower(status),
+		Target:    fmt.Sprintf("verification_case:%d", vc.ID),
+		Details:   map[string]interface{}{"bookingId": vc.BookingID, "note": note},
+	})
+	return s.Get(vc.ID)
+}
@@ -6,10 +6,10 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	htmltemplate "html/template"
 	"io/ioutil"
 	"log"
 	"math/big"
-	"net/smtp"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -164,226 +164,46 @@ func MaskEmail(email string) string {
 	return maskedLocal + "@" + strings.Join(domainParts, ".")
 }
 
+// MaskTail แสดงเฉพาะ keep ตัวท้าย (เลขเอกสาร / เบอร์โทร) ที่เหลือเป็น *
+func MaskTail(s string, keep int) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= keep {
+		return strings.Repeat("*", len(r))
+	}
+	return strings.Repeat("*", len(r)-keep) + string(r[len(r)-keep:])
+}
+
 //
 // ===========================================================
 //  EMAIL SENDER (CHECK-IN LINK + CONFIRM CODE)
 // ===========================================================
 //
 
-// SendCheckInLinkEmail — send HTML + plain text email including confirmation code
-// NOTE: changed to accept rooms []RoomInfo so email can include number+type for every room.
-func SendCheckInLinkEmail(
-	recipientEmail,
-	bookingRef,
-	checkinLink,
-	guestName string,
-	rooms []RoomInfo,
-	checkInDate,
-	checkOutDate,
-	confirmationCode string,
-) error {
-
-	// SMTP config
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPort := os.Getenv("SMTP_PORT")
-	smtpUser := os.Getenv("SMTP_USERNAME")
-	smtpPass := os.Getenv("SMTP_PASSWORD")
-	fromName := os.Getenv("SMTP_FROM_NAME")
-
-	// DEV fallback -> mock send (log) when SMTP not configured
-	if smtpUser == "" || smtpPass == "" || smtpHost == "" || smtpPort == "" {
-		// Build readable rooms text for logs
-		roomsText := roomsListText(rooms)
-		log.Printf("[MOCK EMAIL] to:%s booking:%s code:%s link:%s rooms:%s",
-			recipientEmail, bookingRef, confirmationCode, checkinLink, roomsText)
-		return nil
-	}
-
-	// sanitize strings
-	safe := func(s string) string {
-		return strings.ReplaceAll(strings.TrimSpace(s), "\r\n", " ")
-	}
-
-	guestName = safe(guestName)
-	bookingRef = safe(bookingRef)
-	checkInDate = safe(checkInDate)
-	checkOutDate = safe(checkOutDate)
-	checkinLink = safe(checkinLink)
-	confirmationCode = safe(confirmationCode)
+// SendCheckInLinkEmail — render template checkin_invite ตาม locale ของแขก แล้วส่ง (HTML + plain text + QR)
+func SendCheckInLinkEmail(recipientEmail, locale string, data CheckInEmailData) error {
+	data = data.Sanitized()
 
-	// Ensure scheme
-	if !(strings.HasPrefix(checkinLink, "http://") || strings.HasPrefix(checkinLink, "https://")) {
-		checkinLink = "https://" + strings.TrimLeft(checkinLink, "/")
-	}
-
-	// Build rooms textual & HTML representation
-	roomsText := roomsListText(rooms)    // plain text list
-	roomsHTML := roomsListHTML(rooms)    // html list
-
-	from := fmt.Sprintf("%s <%s>", fromName, smtpUser)
-	to := []string{recipientEmail}
-	auth := smtp.PlainAuth("", smtpUser, smtpPass, smtpHost)
-	addr := fmt.Sprintf("%s:%s", smtpHost, smtpPort)
-
-	subject := fmt.Sprintf("Booking Confirmation and Pre-Check-in — %s", bookingRef)
-	boundary := "----=_CLOUD9_EMAIL_BOUNDARY"
-
-	//
-	// PLAIN TEXT
-	//
-	plainBody := fmt.Sprintf(
-		"Dear %s,\n\n"+ 
-			"Thank you for booking with us! Here are your booking details:\n\n"+
-			"Booking Reference: %s\n"+
-			"Confirmation Code: %s\n"+
-			"Rooms:\n%s\n"+
-			"Check-In: %s\n"+
-			"Check-Out: %s\n\n"+
-			"Complete your pre-check-in here: %s\n\n"+
-			"If you have any questions, feel free to contact us.\n\n"+
-			"Best regards,\n%s",
-		guestName,
-		bookingRef,
-		confirmationCode,
-		roomsText,
-		checkInDate,
-		checkOutDate,
-		checkinLink,
-		fromName,
-	)
-
-	//
-	// HTML EMAIL BODY
-	//
-	htmlBody := fmt.Sprintf(`<!doctype html>
-<html>
-<head>
-<meta charset="utf-8">
-<title>Pre Check-in</title>
-<style>
-body { background:#f5f7fb; font-family:Arial, Helvetica, sans-serif; color:#222; }
-.container { max-width:700px; margin:20px auto; }
-.card { background:#fff; border:1px solid #e6eef6; padding:24px; border-radius:8px; }
-.label { font-weight:700; width:160px; display:inline-block; vertical-align:top; }
-.btn { display:inline-block; padding:12px 20px; background:#0b74ff; color:#fff;
-       text-decoration:none; border-radius:6px; margin-top:18px; }
-.room-list { margin:12px 0 18px 0; padding-left:18px; }
-.room-item { margin:6px 0; }
-</style>
-</head>
-<body>
-<div class="container">
-  <div class="card">
-    <h2>Booking Confirmation & Pre-Check-in</h2>
-    <p>Dear %s,</p>
-    <p>Thank you for choosing our hotel. Below are your booking details:</p>
-
-    <p><span class="label">Booking Reference:</span> %s</p>
-    <p><span class="label">Confirmation Code:</span> %s</p>
-    <p><span class="label">Rooms:</span> %s</p>
-    <p><span class="label">Check-In:</span> %s</p>
-    <p><span class="label">Check-Out:</span> %s</p>
-
-    <a class="btn" href="%s" target="_blank">Complete Pre-Check-in</a>
-    <p>If you have any questions, feel free to contact us.</p>
-    <p>Best regards,<br>%s</p>
-  </div>
-</div>
-</body>
-</html>`,
-		guestName,
-		bookingRef,
-		confirmationCode,
-		roomsHTML,
-		checkInDate,
-		checkOutDate,
-		checkinLink,
-		fromName,
-	)
-
-	//
-	// MIME MULTIPART MESSAGE
-	//
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("From: %s\r\n", from))
-	sb.WriteString(fmt.Sprintf("To: %s\r\n", recipientEmail))
-	sb.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
-	sb.WriteString("MIME-Version: 1.0\r\n")
-	sb.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=\"%s\"\r\n\r\n", boundary))
-
-	// plain
-	sb.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-	sb.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
-	sb.WriteString(plainBody + "\r\n")
-
-	// html
-	sb.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-	sb.WriteString("Content-Type: text/html; charset=utf-8\r\n\r\n")
-	sb.WriteString(htmlBody + "\r\n")
-
-	// end
-	sb.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
-
-	msg := []byte(sb.String())
-
-	// SEND EMAIL
-	if err := smtp.SendMail(addr, auth, smtpUser, to, msg); err != nil {
-		log.Printf("❌ Failed to send email to %s: %v", recipientEmail, err)
+	if err := SendCheckInEmail(recipientEmail, EmailCheckInInvite, locale, data); err != nil {
 		return err
 	}
 
-	log.Printf("📨 Email sent to %s (Confirmation Code: %s)", recipientEmail, confirmationCode)
+	log.Printf("📨 Check-in email for booking %s (Confirmation Code: %s)", data.BookingRef, data.ConfirmationCode)
 	return nil
 }
 
-// helper: produce plain text list for rooms
-func roomsListText(rooms []RoomInfo) string {
-	if len(rooms) == 0 {
-		return "N/A"
-	}
-	var b strings.Builder
-	for _, r := range rooms {
-		num := strings.TrimSpace(r.Number)
-		typ := strings.TrimSpace(r.Type)
-		if typ != "" {
-			b.WriteString(fmt.Sprintf(" - %s (%s)\n", num, typ))
+// SendCheckInEmail ส่งอีเมลประเภทที่มีลิงก์เช็คอิน (invite / reminder) พร้อมแนบ QR ของลิงก์แบบ inline
+// ถ้าสร้าง QR ไม่ได้จะส่งอีเมลต่อโดยไม่มีรูป
+func SendCheckInEmail(recipientEmail, kind, locale string, data CheckInEmailData) error {
+	var inline []InlineAttachment
+	if data.CheckinLink != "" {
+		if att, err := CheckInQRAttachment(data.CheckinLink); err == nil {
+			inline = append(inline, att)
+			data.QRCodeSrc = htmltemplate.URL("cid:" + att.ContentID)
 		} else {
-			b.WriteString(fmt.Sprintf(" - %s\n", num))
+			log.Printf("⚠️ cannot build check-in QR: %v", err)
 		}
 	}
-	return b.String()
-}
-
-// helper: produce HTML list for rooms
-func roomsListHTML(rooms []RoomInfo) string {
-	if len(rooms) == 0 {
-		return "<em>N/A</em>"
-	}
-	var b strings.Builder
-	b.WriteString("<ul class=\"room-list\">")
-	for _, r := range rooms {
-		num := strings.TrimSpace(r.Number)
-		typ := strings.TrimSpace(r.Type)
-		if typ != "" {
-			b.WriteString(fmt.Sprintf("<li class=\"room-item\">%s (%s)</li>", htmlEscape(num), htmlEscape(typ)))
-		} else {
-			b.WriteString(fmt.Sprintf("<li class=\"room-item\">%s</li>", htmlEscape(num)))
-		}
-	}
-	b.WriteString("</ul>")
-	return b.String()
-}
-
-// minimal html escaper for the small strings we use
-func htmlEscape(s string) string {
-	replacer := strings.NewReplacer(
-		"&", "&amp;",
-		"<", "&lt;",
-		">", "&gt;",
-		`"`, "&quot;",
-		"'", "&#39;",
-	)
-	return replacer.Replace(s)
+	return SendTemplatedEmail(recipientEmail, kind, locale, data, inline...)
 }
 
 //
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"log"
+)
+
+// sendRenderedEmail ส่งอีเมลที่ render แล้วผ่าน mailer ปัจจุบัน (smtp หรือ capture)
+func sendRenderedEmail(recipientEmail string, email RenderedEmail, inline ...InlineAttachment) error {
+	mailer := CurrentMailer()
+	if err := mailer.Send(EmailMessage{
+		To:      recipientEmail,
+		Subject: email.Subject,
+		Text:    email.Text,
+		HTML:    email.HTML,
+		Inline:  inline,
+	}); err != nil {
+		log.Printf("❌ Failed to send email to %s: %v", recipientEmail, err)
+		return err
+	}
+
+	log.Printf("📨 Email sent to %s via %s (%s)", recipientEmail, mailer.Name(), email.Subject)
+	return nil
+}
@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//
+// ===========================================================
+//  EMAIL TEMPLATES (html/template ต่อ message type + locale)
+// ===========================================================
+//
+
+// Message types
+const (
+	EmailCheckInInvite   = "checkin_invite"
+	EmailAdminInvite     = "admin_invite"
+	EmailCheckInReminder = "checkin_reminder"     // ใช้ CheckInEmailData
+	EmailThankYou        = "post_checkout_thanks" // ใช้ CheckInEmailData (ไม่ใช้ CheckinLink/ConfirmationCode)
+)
+
+// EmailTypes lists every message type that has templates.
+var EmailTypes = []string{EmailCheckInInvite, EmailAdminInvite, EmailCheckInReminder, EmailThankYou}
+
+// SupportedLocales ภาษาที่มี template ครบทุก message type
+var SupportedLocales = []string{"th", "en", "zh"}
+
+//go:embed templates/email/*.tmpl templates/sms/*.tmpl
+var emailTemplateFS embed.FS
+
+// HotelBrand คือข้อมูลโรงแรมที่ใช้แสดงในอีเมล (มาจาก HotelSetting)
+type HotelBrand struct {
+	Name    string
+	Address string
+	Phone   string
+	Email   string
+	Website string
+	LogoURL string
+}
+
+// CheckInEmailData is the data passed to the checkin_invite templates.
+type CheckInEmailData struct {
+	Hotel            HotelBrand
+	GuestName        string
+	BookingRef       string
+	CheckinLink      string
+	ConfirmationCode string
+	CheckInDate      string
+	CheckOutDate     string
+	Rooms            []RoomInfo
+
+	// QRCodeSrc คือ src ของรูป QR (cid:... ในอีเมลจริง, data:... ใน preview) ว่าง = ไม่แสดง
+	QRCodeSrc htmltemplate.URL
+}
+
+// Sanitized trims values, fills N/A dates and makes sure the link has a scheme.
+func (d CheckInEmailData) Sanitized() CheckInEmailData {
+	safe := func(s string) string {
+		return strings.ReplaceAll(strings.TrimSpace(s), "\r\n", " ")
+	}
+
+	d.GuestName = safe(d.GuestName)
+	d.BookingRef = safe(d.BookingRef)
+	d.CheckInDate = safe(d.CheckInDate)
+	d.CheckOutDate = safe(d.CheckOutDate)
+	d.CheckinLink = safe(d.CheckinLink)
+	d.ConfirmationCode = safe(d.ConfirmationCode)
+	if d.CheckInDate == "" {
+		d.CheckInDate = "N/A"
+	}
+	if d.CheckOutDate == "" {
+		d.CheckOutDate = "N/A"
+	}
+
+	// Ensure scheme
+	if !(strings.HasPrefix(d.CheckinLink, "http://") || strings.HasPrefix(d.CheckinLink, "https://")) {
+		d.CheckinLink = "https://" + strings.TrimLeft(d.CheckinLink, "/")
+	}
+	return d
+}
+
+// AdminInviteEmailData is the data passed to the admin_invite templates.
+type AdminInviteEmailData struct {
+	Hotel      HotelBrand
+	Name       string
+	Role       string
+	InviteLink string
+}
+
+// RenderedEmail ผลลัพธ์หลัง render template
+type RenderedEmail struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// DefaultLocale returns EMAIL_DEFAULT_LOCALE (fallback "en").
+func DefaultLocale() string {
+	l := strings.ToLower(strings.TrimSpace(EnvOrDefault("EMAIL_DEFAULT_LOCALE", "en")))
+	if IsSupportedLocale(l) {
+		return l
+	}
+	return "en"
+}
+
+// IsSupportedLocale checks an already-normalized locale.
+func IsSupportedLocale(locale string) bool {
+	for _, l := range SupportedLocales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeLocale แปลง "th-TH", "zh_CN", "EN" ฯลฯ ให้เป็น locale ที่รองรับ
+// ถ้าไม่รองรับจะคืน DefaultLocale()
+func NormalizeLocale(raw string) string {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	s = strings.ReplaceAll(s, "_", "-")
+	if i := strings.Index(s, "-"); i > 0 {
+		s = s[:i]
+	}
+	switch s {
+	case "thai":
+		s = "th"
+	case "english":
+		s = "en"
+	case "chinese", "cn":
+		s = "zh"
+	}
+	if IsSupportedLocale(s) {
+		return s
+	}
+	return DefaultLocale()
+}
+
+// RenderEmail renders subject, plain text and HTML for a message type in the given locale.
+// Each template file defines three blocks: "subject", "text" and "html".
+func RenderEmail(kind, locale string, data interface{}) (RenderedEmail, error) {
+	locale = NormalizeLocale(locale)
+	name := fmt.Sprintf("templates/email/%s.%s.tmpl", kind, locale)
+
+	if _, err := emailTemplateFS.ReadFile(name); err != nil {
+		return RenderedEmail{}, fmt.Errorf("email template not found: %s (%s)", kind, locale)
+	}
+
+	funcs := map[string]interface{}{
+		"roomLabel": roomLabel,
+	}
+
+	// subject + plain text ใช้ text/template (ไม่ต้อง escape HTML)
+	tt, err := texttemplate.New(kind).Funcs(funcs).ParseFS(emailTemplateFS, "templates/email/layout.tmpl", name)
+	if err != nil {
+		return RenderedEmail{}, fmt.Errorf("parse email template %s: %w", name, err)
+	}
+	// html ใช้ html/template เพื่อ escape ค่าที่มาจากผู้ใช้
+	ht, err := htmltemplate.New(kind).Funcs(funcs).ParseFS(emailTemplateFS, "templates/email/layout.tmpl", name)
+	if err != nil {
+		return RenderedEmail{}, fmt.Errorf("parse email template %s: %w", name, err)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tt.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return RenderedEmail{}, fmt.Errorf("render subject: %w", err)
+	}
+	if err := tt.ExecuteTemplate(&text, "text", data); err != nil {
+		return RenderedEmail{}, fmt.Errorf("render text: %w", err)
+	}
+	if err := ht.ExecuteTemplate(&html, "html", data); err != nil {
+		return RenderedEmail{}, fmt.Errorf("render html: %w", err)
+	}
+
+	return RenderedEmail{
+		Subject: strings.TrimSpace(strings.ReplaceAll(subject.String(), "\n", " ")),
+		Text:    strings.TrimSpace(text.String()),
+		HTML:    html.String(),
+	}, nil
+}
+
+// RenderShortMessage renders the short text (SMS / LINE) version of a message type.
+// Templates live in templates/sms/<kind>.<locale>.tmpl and define a "text" block.
+func RenderShortMessage(kind, locale string, data interface{}) (string, error) {
+	locale = NormalizeLocale(locale)
+	name := fmt.Sprintf("templates/sms/%s.%s.tmpl", kind, locale)
+
+	tt, err := texttemplate.New(kind).ParseFS(emailTemplateFS, name)
+	if err != nil {
+		return "", fmt.Errorf("short message template not found: %s (%s)", kind, locale)
+	}
+	var out bytes.Buffer
+	if err := tt.ExecuteTemplate(&out, "text", data); err != nil {
+		return "", fmt.Errorf("render short message: %w", err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// SendTemplatedEmail renders a message type in the given locale and delivers it via the current mailer.
+func SendTemplatedEmail(recipientEmail, kind, locale string, data interface{}, inline ...InlineAttachment) error {
+	email, err := RenderEmail(kind, locale, data)
+	if err != nil {
+		return err
+	}
+	return sendRenderedEmail(recipientEmail, email, inline...)
+}
+
+// roomLabel: "101 (Deluxe)" หรือ "101"
+func roomLabel(r RoomInfo) string {
+	num := strings.TrimSpace(r.Number)
+	typ := strings.TrimSpace(r.Type)
+	if typ != "" {
+		return fmt.Sprintf("%s (%s)", num, typ)
+	}
+	return num
+}
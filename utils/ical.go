@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+//
+// ===========================================================
+//  iCALENDAR (RFC 5545) สำหรับ feed ห้องพักและ import ปฏิทินภายนอก
+// ===========================================================
+//
+
+// ICalEvent event หนึ่งรายการ (ทั้งวัน: Start รวม, End ไม่รวม เหมือน DTEND;VALUE=DATE)
+type ICalEvent struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Status      string // CONFIRMED | TENTATIVE | CANCELLED
+	Stamp       time.Time
+}
+
+// WriteICalendar เขียน VCALENDAR ที่มี event ทั้งวัน (บรรทัด CRLF, พับบรรทัดที่ 75 octets)
+func WriteICalendar(w io.Writer, name string, events []ICalEvent) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) { bw.WriteString(foldICalLine(s) + "\r\n") }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//hotel-backend//Room occupancy//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	if name != "" {
+		line("X-WR-CALNAME:" + escapeICalText(name))
+	}
+	for _, e := range events {
+		stamp := e.Stamp
+		if stamp.IsZero() {
+			stamp = time.Now()
+		}
+		line("BEGIN:VEVENT")
+		line("UID:" + escapeICalText(e.UID))
+		line("DTSTAMP:" + stamp.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE:" + e.Start.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + e.End.Format("20060102"))
+		line("SUMMARY:" + escapeICalText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + escapeICalText(e.Description))
+		}
+		if e.Status != "" {
+			line("STATUS:" + e.Status)
+		}
+		line("TRANSP:OPAQUE")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// foldICalLine พับบรรทัดที่ยาวเกิน 75 octets (ไม่ตัดกลางตัวอักษร UTF-8)
+func foldICalLine(s string) string {
+	if len(s) <= 75 {
+		return s
+	}
+	var b strings.Builder
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // บรรทัดต่อมีช่องว่างนำหน้าหนึ่ง octet
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+var icalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+var icalTextUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func escapeICalText(s string) string { return icalTextEscaper.Replace(s) }
+
+// ParseICalendar อ่าน VEVENT ทั้งหมด วันที่คืนเป็นเที่ยงคืน UTC ของวันนั้น
+// DTSTART/DTEND แบบมีเวลาใช้วันตามเวลาที่เขียนในไฟล์ (ไม่แปลง TZID); ไม่มี DTEND = หนึ่งวัน
+func ParseICalendar(data []byte) ([]ICalEvent, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	// unfold: บรรทัดที่ขึ้นต้นด้วย space / tab ต่อจากบรรทัดก่อนหน้า
+	raw := strings.ReplaceAll(string(data), "\r\n", "\n")
+	raw = strings.NewReplacer("\n ", "", "\n\t", "").Replace(raw)
+	if !strings.Contains(raw, "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("not an iCalendar file")
+	}
+
+	var events []ICalEvent
+	var cur *ICalEvent
+	var endSet bool
+	for _, l := range strings.Split(raw, "\n") {
+		l = strings.TrimRight(l, "\r")
+		name, value := splitICalLine(l)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			cur, endSet = &ICalEvent{}, false
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if cur != nil && !cur.Start.IsZero() {
+				if !endSet || !cur.End.After(cur.Start) {
+					cur.End = cur.Start.AddDate(0, 0, 1)
+				}
+				events = append(events, *cur)
+			}
+			cur = nil
+		case cur == nil:
+			continue
+		case name == "UID":
+			cur.UID = value
+		case name == "SUMMARY":
+			cur.Summary = icalTextUnescaper.Replace(value)
+		case name == "DESCRIPTION":
+			cur.Description = icalTextUnescaper.Replace(value)
+		case name == "STATUS":
+			cur.Status = strings.ToUpper(value)
+		case name == "DTSTART":
+			if t, ok := parseICalDate(value); ok {
+				cur.Start = t
+			}
+		case name == "DTEND":
+			if t, ok := parseICalDate(value); ok {
+				cur.End, endSet = t, true
+			}
+		}
+	}
+	return events, nil
+}
+
+// splitICalLine "DTSTART;VALUE=DATE:20260101" → ("DTSTART", "20260101") (ไม่สนใจ parameter)
+func splitICalLine(l string) (string, string) {
+	i := strings.IndexByte(l, ':')
+	if i < 0 {
+		return "", ""
+	}
+	head := l[:i]
+	if j := strings.IndexByte(head, ';'); j >= 0 {
+		head = head[:j]
+	}
+	return strings.ToUpper(head), strings.TrimSpace(l[i+1:])
+}
+
+// parseICalDate "20260101" หรือ "20260101T140000Z" → วันที่ (ตัดเวลาทิ้ง)
+func parseICalDate(value string) (time.Time, bool) {
+	if len(value) < 8 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("20060102", value[:8])
+	return t, err == nil
+}
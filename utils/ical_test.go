@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFoldICalLine(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"short", "SUMMARY:Room 101"},
+		{"exactly 75", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"long ascii", "DESCRIPTION:" + strings.Repeat("x", 200)},
+		{"long thai", "SUMMARY:" + strings.Repeat("ห้องพัก", 20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := foldICalLine(tt.in)
+			for i, l := range strings.Split(got, "\r\n") {
+				if len(l) > 75 {
+					t.Fatalf("line %d is %d octets: %q", i, len(l), l)
+				}
+				if i > 0 && !strings.HasPrefix(l, " ") {
+					t.Fatalf("continuation line %d does not start with a space: %q", i, l)
+				}
+			}
+			if unfolded := strings.ReplaceAll(got, "\r\n ", ""); unfolded != tt.in {
+				t.Fatalf("unfold(fold(x)) = %q, want %q", unfolded, tt.in)
+			}
+		})
+	}
+}
+
+func TestEscapeICalText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Room 101", "Room 101"},
+		{"Brown, Anna; VIP", `Brown\, Anna\; VIP`},
+		{`C:\temp`, `C:\\temp`},
+		{"line1\nline2\r\nline3", `line1\nline2\nline3`},
+	}
+	for _, tt := range tests {
+		got := escapeICalText(tt.in)
+		if got != tt.want {
+			t.Errorf("escapeICalText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if back := icalTextUnescaper.Replace(got); back != strings.ReplaceAll(tt.in, "\r\n", "\n") {
+			t.Errorf("unescape(%q) = %q", got, back)
+		}
+	}
+}
+
+func TestWriteICalendar(t *testing.T) {
+	stamp := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name    string
+		calName string
+		events  []ICalEvent
+		want    []string // บรรทัดที่ต้องมี
+		notWant []string // บรรทัดที่ต้องไม่มี
+	}{
+		{
+			name:    "empty calendar",
+			calName: "",
+			want:    []string{"BEGIN:VCALENDAR", "VERSION:2.0", "END:VCALENDAR"},
+			notWant: []string{"BEGIN:VEVENT", "X-WR-CALNAME:"},
+		},
+		{
+			name:    "all-day event",
+			calName: "Room 101, Deluxe",
+			events: []ICalEvent{{
+				UID: "booking-1@hotel", Start: day(10), End: day(12),
+				Summary: "Occupied", Status: "CONFIRMED", Stamp: stamp,
+			}},
+			want: []string{
+				`X-WR-CALNAME:Room 101\, Deluxe`,
+				"BEGIN:VEVENT",
+				"UID:booking-1@hotel",
+				"DTSTAMP:20260301T093000Z",
+				"DTSTART;VALUE=DATE:20260310",
+				"DTEND;VALUE=DATE:20260312",
+				"SUMMARY:Occupied",
+				"STATUS:CONFIRMED",
+				"END:VEVENT",
+			},
+			notWant: []string{"DESCRIPTION:"},
+		},
+		{
+			name: "description escaped",
+			events: []ICalEvent{{
+				UID: "block-7", Start: day(1), End: day(2), Summary: "Blocked",
+				Description: "Maintenance; aircon\nRoom 205", Stamp: stamp,
+			}},
+			want:    []string{`DESCRIPTION:Maintenance\; aircon\nRoom 205`},
+			notWant: []string{"STATUS:"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteICalendar(&buf, tt.calName, tt.events); err != nil {
+				t.Fatalf("WriteICalendar: %v", err)
+			}
+			out := buf.String()
+			if !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+				t.Fatalf("output does not end with CRLF END:VCALENDAR: %q", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			has := func(s string) bool {
+				for _, l := range lines {
+					if l == s || strings.HasPrefix(l, s) && strings.HasSuffix(s, ":") {
+						return true
+					}
+				}
+				return false
+			}
+			for _, w := range tt.want {
+				if !has(w) {
+					t.Errorf("missing line %q in:\n%s", w, out)
+				}
+			}
+			for _, w := range tt.notWant {
+				if has(w) {
+					t.Errorf("unexpected line %q in:\n%s", w, out)
+				}
+			}
+		})
+	}
+}
+
+// สิ่งที่เขียนออกต้องอ่านกลับได้ค่าเดิม (รวมข้อความยาวที่ถูกพับบรรทัด)
+func TestWriteICalendarRoundTrip(t *testing.T) {
+	in := []ICalEvent{
+		{UID: "a", Start: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 5, 3, 0, 0, 0, 0, time.UTC),
+			Summary: "Brown, Anna", Status: "CONFIRMED"},
+		{UID: "b", Start: time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 5, 5, 0, 0, 0, 0, time.UTC),
+			Summary: "ปิดปรับปรุง", Description: strings.Repeat("ซ่อมแอร์; ", 15) + "\nห้อง 205"},
+	}
+	var buf bytes.Buffer
+	if err := WriteICalendar(&buf, "rooms", in); err != nil {
+		t.Fatalf("WriteICalendar: %v", err)
+	}
+	out, err := ParseICalendar(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseICalendar: %v", err)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("got %d events, want %d", len(out), len(in))
+	}
+	for i := range in {
+		got, want := out[i], in[i]
+		if got.UID != want.UID || got.Summary != want.Summary || got.Description != want.Description ||
+			got.Status != want.Status || !got.Start.Equal(want.Start) || !got.End.Equal(want.End) {
+			t.Errorf("event %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
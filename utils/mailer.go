@@ -0,0 +1,429 @@
+package utils
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//
+// ===========================================================
+//  MAILER (SMTP จริง / capture สำหรับ dev + test)
+// ===========================================================
+//
+
+// EmailMessage is a fully rendered message ready to be delivered.
+type EmailMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Inline  []InlineAttachment // รูปที่อ้างอิงใน HTML ด้วย cid:<ContentID>
+}
+
+// InlineAttachment is a related part referenced from the HTML body (e.g. a QR code image).
+type InlineAttachment struct {
+	ContentID   string
+	ContentType string
+	Filename    string
+	Data        []byte
+}
+
+// Mailer delivers rendered email messages.
+type Mailer interface {
+	Send(msg EmailMessage) error
+	Name() string
+}
+
+var (
+	mailerMu      sync.RWMutex
+	currentMailer Mailer
+)
+
+// SetMailer เปลี่ยน mailer ที่ใช้ทั้งระบบ (เรียกจาก main หรือ test)
+func SetMailer(m Mailer) {
+	mailerMu.Lock()
+	defer mailerMu.Unlock()
+	currentMailer = m
+}
+
+// CurrentMailer returns the configured mailer, building one from env on first use.
+func CurrentMailer() Mailer {
+	mailerMu.RLock()
+	m := currentMailer
+	mailerMu.RUnlock()
+	if m != nil {
+		return m
+	}
+
+	mailerMu.Lock()
+	defer mailerMu.Unlock()
+	if currentMailer == nil {
+		currentMailer = MailerFromEnv(NewMemoryMailStore(200))
+	}
+	return currentMailer
+}
+
+// MailerFromEnv เลือก backend จาก MAILER (smtp | capture)
+// ถ้าไม่ได้ระบุ: มีค่า SMTP ครบ -> smtp, ไม่ครบ -> capture เฉพาะ dev mode
+// นอก dev ใช้ smtp ต่อ (ส่งไม่สำเร็จ = error ให้เห็น) ไม่เก็บอีเมลลูกค้าเงียบ ๆ ใน capture
+func MailerFromEnv(store MailCaptureStore) Mailer {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("MAILER")))
+	smtpMailer, smtpOK := NewSMTPMailerFromEnv()
+
+	switch mode {
+	case "smtp":
+		return smtpMailer
+	case "capture":
+		return NewCaptureMailer(store)
+	}
+	if smtpOK {
+		return smtpMailer
+	}
+	if IsDevMode() {
+		return NewCaptureMailer(store)
+	}
+	log.Printf("❌❌ SMTP is not fully configured (SMTP_HOST / SMTP_PORT / SMTP_FROM_ADDRESS / SMTP_PASSWORD) and APP_ENV is not dev: outgoing email will FAIL. Set MAILER=capture explicitly to capture mail instead.")
+	return smtpMailer
+}
+
+// IsDevMode: APP_ENV=dev|development|local
+func IsDevMode() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))) {
+	case "dev", "development", "local":
+		return true
+	}
+	return false
+}
+
+// ---------------------------
+// SMTP
+// ---------------------------
+
+// SMTP TLS modes
+const (
+	SMTPTLSNone     = "none"     // plain connection
+	SMTPTLSStartTLS = "starttls" // upgrade with STARTTLS (required)
+	SMTPTLSImplicit = "tls"      // implicit TLS (SMTPS, usually port 465)
+	SMTPTLSAuto     = "auto"     // STARTTLS if the server offers it
+)
+
+// SMTPMailer sends mail through a real SMTP server.
+type SMTPMailer struct {
+	Host        string
+	Port        string
+	Username    string
+	Password    string
+	FromName    string
+	FromAddress string
+	TLSMode     string
+	SkipVerify  bool
+	Timeout     time.Duration
+}
+
+// NewSMTPMailerFromEnv reads SMTP_* env vars. The bool reports whether the config is complete.
+func NewSMTPMailerFromEnv() (*SMTPMailer, bool) {
+	m := &SMTPMailer{
+		Host:        strings.TrimSpace(os.Getenv("SMTP_HOST")),
+		Port:        strings.TrimSpace(os.Getenv("SMTP_PORT")),
+		Username:    strings.TrimSpace(os.Getenv("SMTP_USERNAME")),
+		Password:    os.Getenv("SMTP_PASSWORD"),
+		FromName:    os.Getenv("SMTP_FROM_NAME"),
+		FromAddress: strings.TrimSpace(os.Getenv("SMTP_FROM_ADDRESS")),
+		TLSMode:     strings.ToLower(strings.TrimSpace(EnvOrDefault("SMTP_TLS_MODE", ""))),
+		SkipVerify:  strings.EqualFold(os.Getenv("SMTP_TLS_SKIP_VERIFY"), "true"),
+		Timeout:     15 * time.Second,
+	}
+	if m.FromAddress == "" {
+		m.FromAddress = m.Username
+	}
+	if m.TLSMode == "" {
+		if m.Port == "465" {
+			m.TLSMode = SMTPTLSImplicit
+		} else {
+			m.TLSMode = SMTPTLSAuto
+		}
+	}
+
+	ok := m.Host != "" && m.Port != "" && m.FromAddress != ""
+	if m.Username != "" && m.Password == "" {
+		ok = false
+	}
+	return m, ok
+}
+
+func (m *SMTPMailer) Name() string { return "smtp" }
+
+// Send dials the server, negotiates TLS according to TLSMode, authenticates and delivers.
+func (m *SMTPMailer) Send(msg EmailMessage) error {
+	if m.Host == "" || m.Port == "" {
+		return errors.New("smtp not configured")
+	}
+	addr := net.JoinHostPort(m.Host, m.Port)
+	tlsConfig := &tls.Config{ServerName: m.Host, InsecureSkipVerify: m.SkipVerify}
+
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: m.Timeout}
+	if m.TLSMode == SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("smtp dial %s: %w", addr, err)
+	}
+
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp client: %w", err)
+	}
+	defer client.Close()
+
+	if m.TLSMode == SMTPTLSStartTLS || m.TLSMode == SMTPTLSAuto {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("smtp starttls: %w", err)
+			}
+		} else if m.TLSMode == SMTPTLSStartTLS {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+	}
+
+	if m.Username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+				return fmt.Errorf("smtp auth: %w", err)
+			}
+		}
+	}
+
+	if err := client.Mail(m.FromAddress); err != nil {
+		return fmt.Errorf("smtp MAIL FROM: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("smtp RCPT TO: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	from := m.FromAddress
+	if strings.TrimSpace(m.FromName) != "" {
+		from = fmt.Sprintf("%s <%s>", encodeHeader(m.FromName), m.FromAddress)
+	}
+	if _, err := w.Write(BuildMIMEMessage(from, msg)); err != nil {
+		w.Close()
+		return fmt.Errorf("smtp write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp close data: %w", err)
+	}
+	return client.Quit()
+}
+
+// BuildMIMEMessage builds a multipart/alternative (plain + html) message.
+// ถ้ามี Inline จะห่อเป็น multipart/related (alternative + รูป) เพื่อให้ HTML อ้างอิง cid: ได้
+func BuildMIMEMessage(from string, msg EmailMessage) []byte {
+	boundary := "----=_HOTEL_" + randomBoundary()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("From: %s\r\n", from))
+	sb.WriteString(fmt.Sprintf("To: %s\r\n", msg.To))
+	sb.WriteString(fmt.Sprintf("Subject: %s\r\n", encodeHeader(msg.Subject)))
+	sb.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+
+	if len(msg.Inline) == 0 {
+		sb.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=\"%s\"\r\n\r\n", boundary))
+		writeAlternativeParts(&sb, boundary, msg)
+		return []byte(sb.String())
+	}
+
+	related := "----=_HOTEL_REL_" + randomBoundary()
+	sb.WriteString(fmt.Sprintf("Content-Type: multipart/related; type=\"multipart/alternative\"; boundary=\"%s\"\r\n\r\n", related))
+
+	sb.WriteString(fmt.Sprintf("--%s\r\n", related))
+	sb.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=\"%s\"\r\n\r\n", boundary))
+	writeAlternativeParts(&sb, boundary, msg)
+
+	for _, att := range msg.Inline {
+		sb.WriteString(fmt.Sprintf("--%s\r\n", related))
+		sb.WriteString(fmt.Sprintf("Content-Type: %s; name=\"%s\"\r\n", att.ContentType, att.Filename))
+		sb.WriteString("Content-Transfer-Encoding: base64\r\n")
+		sb.WriteString(fmt.Sprintf("Content-ID: <%s>\r\n", att.ContentID))
+		sb.WriteString(fmt.Sprintf("Content-Disposition: inline; filename=\"%s\"\r\n\r\n", att.Filename))
+		writeBase64Lines(&sb, att.Data)
+	}
+	sb.WriteString(fmt.Sprintf("--%s--\r\n", related))
+	return []byte(sb.String())
+}
+
+func writeAlternativeParts(sb *strings.Builder, boundary string, msg EmailMessage) {
+	sb.WriteString(fmt.Sprintf("--%s\r\n", boundary))
+	sb.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	sb.WriteString(msg.Text + "\r\n")
+
+	sb.WriteString(fmt.Sprintf("--%s\r\n", boundary))
+	sb.WriteString("Content-Type: text/html; charset=utf-8\r\n\r\n")
+	sb.WriteString(msg.HTML + "\r\n")
+
+	sb.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
+}
+
+// writeBase64Lines: base64 ตัดบรรทัดละ 76 ตัวอักษร (RFC 2045)
+func writeBase64Lines(sb *strings.Builder, data []byte) {
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		sb.WriteString(enc[:76] + "\r\n")
+		enc = enc[76:]
+	}
+	sb.WriteString(enc + "\r\n")
+}
+
+func randomBoundary() string {
+	if s, err := GenerateSecureToken(12); err == nil {
+		return s
+	}
+	return fmt.Sprintf("%d", time.Now().UnixNano())
+}
+
+// encodeHeader: subject ภาษาไทย/จีนต้อง encode แบบ RFC 2047
+func encodeHeader(s string) string {
+	for _, r := range s {
+		if r > 127 {
+			return mime.QEncoding.Encode("utf-8", s)
+		}
+	}
+	return s
+}
+
+// ---------------------------
+// CAPTURE (dev / test)
+// ---------------------------
+
+// CapturedEmail is a message stored by the capture mailer.
+type CapturedEmail struct {
+	ID        uint      `json:"id"`
+	To        string    `json:"to"`
+	Subject   string    `json:"subject"`
+	Text      string    `json:"text"`
+	HTML      string    `json:"html"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// MailCaptureStore persists captured messages (memory or DB).
+type MailCaptureStore interface {
+	Save(msg *CapturedEmail) error
+	List(to string, limit int) ([]CapturedEmail, error)
+	Get(id uint) (CapturedEmail, error)
+	Clear() error
+}
+
+// ErrCapturedEmailNotFound is returned by stores when the id does not exist.
+var ErrCapturedEmailNotFound = errors.New("captured_email_not_found")
+
+// CaptureMailer keeps messages instead of sending them.
+type CaptureMailer struct {
+	Store MailCaptureStore
+}
+
+func NewCaptureMailer(store MailCaptureStore) *CaptureMailer {
+	if store == nil {
+		store = NewMemoryMailStore(200)
+	}
+	return &CaptureMailer{Store: store}
+}
+
+func (m *CaptureMailer) Name() string { return "capture" }
+
+func (m *CaptureMailer) Send(msg EmailMessage) error {
+	// แทน cid: ด้วย data URL เพื่อให้ dev mailbox แสดงรูป inline ได้
+	html := msg.HTML
+	for _, att := range msg.Inline {
+		dataURL := "data:" + att.ContentType + ";base64," + base64.StdEncoding.EncodeToString(att.Data)
+		html = strings.ReplaceAll(html, "cid:"+att.ContentID, dataURL)
+	}
+	return m.Store.Save(&CapturedEmail{
+		To:        msg.To,
+		Subject:   msg.Subject,
+		Text:      msg.Text,
+		HTML:      html,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// MemoryMailStore keeps the last N captured messages in memory.
+type MemoryMailStore struct {
+	mu     sync.Mutex
+	max    int
+	nextID uint
+	items  []CapturedEmail
+}
+
+func NewMemoryMailStore(max int) *MemoryMailStore {
+	if max <= 0 {
+		max = 200
+	}
+	return &MemoryMailStore{max: max}
+}
+
+func (s *MemoryMailStore) Save(msg *CapturedEmail) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	msg.ID = s.nextID
+	s.items = append(s.items, *msg)
+	if len(s.items) > s.max {
+		s.items = s.items[len(s.items)-s.max:]
+	}
+	return nil
+}
+
+func (s *MemoryMailStore) List(to string, limit int) ([]CapturedEmail, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	to = strings.ToLower(strings.TrimSpace(to))
+	out := make([]CapturedEmail, 0, len(s.items))
+	for _, m := range s.items {
+		if to != "" && strings.ToLower(m.To) != to {
+			continue
+		}
+		out = append(out, m)
+	}
+	// ล่าสุดก่อน
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *MemoryMailStore) Get(id uint) (CapturedEmail, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.items {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return CapturedEmail{}, ErrCapturedEmailNotFound
+}
+
+func (s *MemoryMailStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = nil
+	return nil
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//
+// ===========================================================
+//  API MESSAGE CATALOG (ข้อความใน response ต่อ code + locale)
+// ===========================================================
+//
+
+// APILocales ภาษาที่ข้อความ API ต้องมีครบทุก code (อีเมลรองรับมากกว่านี้ ดู SupportedLocales)
+var APILocales = []string{"th", "en"}
+
+// Success codes (error codes อยู่ใน services/errors.go)
+const (
+	MsgCheckinCompleted        = "success.checkinCompleted"
+	MsgCheckinPendingReview    = "success.checkinPendingReview"
+	MsgCheckinNotifyPartial    = "success.checkinNotificationPartial"
+	MsgCheckinCodeResent       = "success.checkinCodeResent"
+	MsgBookingCreated          = "success.bookingCreated"
+	MsgBookingDeleted          = "success.bookingDeleted"
+	MsgBookingCheckedOut       = "success.bookingCheckedOut"
+	MsgIDCardRead              = "success.idCardRead"
+	MsgPassportRead            = "success.passportRead"
+	MsgConsentDeleted          = "success.consentDeleted"
+	MsgConsentLogDeleted       = "success.consentLogDeleted"
+	MsgConsentLogsAttached     = "success.consentLogsAttached"
+	MsgConsentLogsNoneMatched  = "success.consentLogsNoneMatched"
+	MsgCustomerDeleted         = "success.customerDeleted"
+	MsgDuplicateDismissed      = "success.customerDuplicateDismissed"
+	MsgChannelMappingDeleted   = "success.channelMappingDeleted"
+	MsgCalendarFeedRevoked     = "success.calendarFeedRevoked"
+	MsgCalendarImportDeleted   = "success.calendarImportDeleted"
+	MsgHousekeepingTaskDeleted = "success.housekeepingTaskDeleted"
+	MsgRoomBlockConflicts      = "success.roomBlockConflicts"
+	MsgRoomBlockDeleted        = "success.roomBlockDeleted"
+)
+
+// SuccessCodes success code ทั้งหมดที่ controller ใช้ (ต้องมีคำแปลครบทุก APILocales)
+var SuccessCodes = []string{
+	MsgCheckinCompleted, MsgCheckinPendingReview, MsgCheckinNotifyPartial, MsgCheckinCodeResent,
+	MsgBookingCreated, MsgBookingDeleted, MsgBookingCheckedOut,
+	MsgIDCardRead, MsgPassportRead,
+	MsgConsentDeleted, MsgConsentLogDeleted, MsgConsentLogsAttached, MsgConsentLogsNoneMatched,
+	MsgCustomerDeleted, MsgDuplicateDismissed,
+	MsgChannelMappingDeleted,
+	MsgCalendarFeedRevoked, MsgCalendarImportDeleted,
+	MsgHousekeepingTaskDeleted,
+	MsgRoomBlockConflicts, MsgRoomBlockDeleted,
+}
+
+//go:embed messages/*.json
+var messageFS embed.FS
+
+// messageCatalog[locale][code] = ข้อความ (โหลดจาก messages/<locale>.json ตอน init)
+var messageCatalog = loadMessageCatalog()
+
+func loadMessageCatalog() map[string]map[string]string {
+	catalog := make(map[string]map[string]string, len(APILocales))
+	for _, locale := range APILocales {
+		raw, err := messageFS.ReadFile("messages/" + locale + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("message catalog missing for locale %q: %v", locale, err))
+		}
+		entries := map[string]string{}
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			panic(fmt.Sprintf("message catalog %q is not valid JSON: %v", locale, err))
+		}
+		catalog[locale] = entries
+	}
+	return catalog
+}
+
+// MessageCodes คืน code ทั้งหมดใน catalog ของ locale (เรียงตามตัวอักษร)
+func MessageCodes(locale string) []string {
+	codes := make([]string, 0, len(messageCatalog[locale]))
+	for code := range messageCatalog[locale] {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// LookupMessage หาข้อความของ code ใน locale นั้นตรง ๆ (ไม่ fallback)
+func LookupMessage(locale, code string) (string, bool) {
+	msg, ok := messageCatalog[locale][code]
+	return msg, ok
+}
+
+// Message คืนข้อความของ code ตาม locale
+// ไม่มีใน locale → ใช้ DefaultAPILocale() → ไม่มีเลยคืน code
+// params เป็นคู่ชื่อ/ค่า แทนที่ {ชื่อ} ในข้อความ เช่น Message("en", "validation.min", "param", "8")
+func Message(locale, code string, params ...string) string {
+	msg, ok := LookupMessage(locale, code)
+	if !ok {
+		if msg, ok = LookupMessage(DefaultAPILocale(), code); !ok {
+			return code
+		}
+	}
+	if len(params) >= 2 {
+		pairs := make([]string, 0, len(params))
+		for i := 0; i+1 < len(params); i += 2 {
+			pairs = append(pairs, "{"+params[i]+"}", params[i+1])
+		}
+		msg = strings.NewReplacer(pairs...).Replace(msg)
+	}
+	return msg
+}
+
+// DefaultAPILocale returns API_DEFAULT_LOCALE (fallback "th").
+func DefaultAPILocale() string {
+	l := strings.ToLower(strings.TrimSpace(EnvOrDefault("API_DEFAULT_LOCALE", "th")))
+	if IsAPILocale(l) {
+		return l
+	}
+	return "th"
+}
+
+// IsAPILocale checks an already-normalized locale.
+func IsAPILocale(locale string) bool {
+	for _, l := range APILocales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchAPILocale เลือก locale ของ API จากค่าแบบ Accept-Language ("en-US,en;q=0.9,th;q=0.8")
+// หรือค่าเดี่ยว ("th-TH", "english") ตาม q มากไปน้อย; ไม่มีตัวที่รองรับคืน false
+func MatchAPILocale(header string) (string, bool) {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				if v, err := strconv.ParseFloat(strings.TrimPrefix(f, "q="), 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{tag, q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, cand := range candidates {
+		s := strings.ToLower(strings.ReplaceAll(cand.tag, "_", "-"))
+		if i := strings.Index(s, "-"); i > 0 {
+			s = s[:i]
+		}
+		switch s {
+		case "thai":
+			s = "th"
+		case "english":
+			s = "en"
+		}
+		if IsAPILocale(s) {
+			return s, true
+		}
+	}
+	return "", false
+}
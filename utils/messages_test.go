@@ -0,0 +1,100 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"hotel-backend/services"
+	"hotel-backend/utils"
+)
+
+// ทุก error code / variant ที่ services ประกาศต้องมีคำแปลครบทุกภาษา
+func TestErrorCodesTranslated(t *testing.T) {
+	for _, locale := range utils.APILocales {
+		for _, key := range services.ErrorKeys() {
+			if _, ok := utils.LookupMessage(locale, key); !ok {
+				t.Errorf("%s: missing translation for %q", locale, key)
+			}
+		}
+	}
+}
+
+func TestSuccessCodesTranslated(t *testing.T) {
+	for _, locale := range utils.APILocales {
+		for _, code := range utils.SuccessCodes {
+			if _, ok := utils.LookupMessage(locale, code); !ok {
+				t.Errorf("%s: missing translation for %q", locale, code)
+			}
+		}
+	}
+}
+
+// validation.* ที่ controllers อ้างถึง (ทั้งจาก validator tag และ fieldError) ต้องมีใน catalog
+func TestValidationKeysTranslated(t *testing.T) {
+	keys := map[string]bool{
+		// validator tags ที่ struct ใช้ + key พิเศษใน request_binding.go
+		"body": true, "json": true, "type": true, "invalid": true, "unknown": true,
+		"required": true, "requiredOneOf": true, "email": true, "dateonly": true, "datetime": true,
+		"oneof": true, "gt": true, "gte": true, "lte": true,
+		"min": true, "max": true, "minLen": true, "maxLen": true,
+	}
+	files, _ := filepath.Glob(filepath.Join("..", "controllers", "*.go"))
+	call := regexp.MustCompile(`fieldError\("[^"]*", "([A-Za-z]+)"`)
+	for _, f := range files {
+		src, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range call.FindAllStringSubmatch(string(src), -1) {
+			keys[m[1]] = true
+		}
+	}
+	for _, locale := range utils.APILocales {
+		for key := range keys {
+			if _, ok := utils.LookupMessage(locale, "validation."+key); !ok {
+				t.Errorf("%s: missing translation for validation.%s", locale, key)
+			}
+		}
+	}
+}
+
+// ทุกภาษามีชุด key เดียวกัน (กันลืมเพิ่มคำแปลฝั่งใดฝั่งหนึ่ง)
+func TestCatalogLocalesInSync(t *testing.T) {
+	base := utils.APILocales[0]
+	want := map[string]bool{}
+	for _, code := range utils.MessageCodes(base) {
+		want[code] = true
+	}
+	for _, locale := range utils.APILocales[1:] {
+		got := map[string]bool{}
+		for _, code := range utils.MessageCodes(locale) {
+			got[code] = true
+			if !want[code] {
+				t.Errorf("%s has %q but %s does not", locale, code, base)
+			}
+		}
+		for code := range want {
+			if !got[code] {
+				t.Errorf("%s has %q but %s does not", base, code, locale)
+			}
+		}
+	}
+}
+
+func TestMatchAPILocale(t *testing.T) {
+	cases := map[string]string{
+		"en-US,en;q=0.9,th;q=0.8": "en",
+		"fr-FR, th;q=0.5":         "th",
+		"th_TH":                   "th",
+		"english":                 "en",
+		"de, en;q=0":              "",
+	}
+	for header, want := range cases {
+		got, _ := utils.MatchAPILocale(header)
+		if got != want {
+			t.Errorf("MatchAPILocale(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+//
+// ===========================================================
+//  QR CODE (ลิงก์เช็คอิน) — PNG / SVG
+// ===========================================================
+//
+
+// CheckInQRContentID คือ Content-ID ของรูป QR ที่แนบ inline ในอีเมลเช็คอิน
+const CheckInQRContentID = "checkin-qr@hotel"
+
+const (
+	qrDefaultSize = 256
+	qrMinSize     = 64
+	qrMaxSize     = 1024
+)
+
+func clampQRSize(size int) int {
+	switch {
+	case size <= 0:
+		return qrDefaultSize
+	case size < qrMinSize:
+		return qrMinSize
+	case size > qrMaxSize:
+		return qrMaxSize
+	}
+	return size
+}
+
+// QRCodePNG encodes content as a PNG QR code of size x size pixels.
+func QRCodePNG(content string, size int) ([]byte, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("qr content is empty")
+	}
+	return qrcode.Encode(content, qrcode.Medium, clampQRSize(size))
+}
+
+// QRCodeSVG encodes content as an SVG QR code (1 rect ต่อ 1 แถวที่ต่อกัน เพื่อให้ไฟล์เล็ก).
+func QRCodeSVG(content string, size int) (string, error) {
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("qr content is empty")
+	}
+	q, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return "", err
+	}
+	bitmap := q.Bitmap() // รวม quiet zone แล้ว
+	n := len(bitmap)
+	size = clampQRSize(size)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, n, n))
+	sb.WriteString(fmt.Sprintf(`<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n))
+	for y, row := range bitmap {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			sb.WriteString(fmt.Sprintf("M%d %dh%dv1h-%dz", start, y, x-start, x-start))
+		}
+	}
+	sb.WriteString(`"/></svg>`)
+	return sb.String(), nil
+}
+
+// CheckInQRAttachment สร้างรูป QR ของลิงก์เช็คอินสำหรับแนบ inline (cid:) ในอีเมล
+func CheckInQRAttachment(link string) (InlineAttachment, error) {
+	png, err := QRCodePNG(link, qrDefaultSize)
+	if err != nil {
+		return InlineAttachment{}, err
+	}
+	return InlineAttachment{
+		ContentID:   CheckInQRContentID,
+		ContentType: "image/png",
+		Filename:    "checkin-qr.png",
+		Data:        png,
+	}, nil
+}
+
+// QRCodeDataURL คืน data:image/png;base64,... (ใช้ใน preview / dev mailbox)
+func QRCodeDataURL(content string, size int) (htmltemplate.URL, error) {
+	png, err := QRCodePNG(content, size)
+	if err != nil {
+		return "", err
+	}
+	return htmltemplate.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)), nil
+}
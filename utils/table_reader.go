@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// ===========================================================
+//  TABLE READER (CSV / XLSX สำหรับ import)
+// ===========================================================
+//
+
+// ErrEmptyTable ไฟล์ไม่มีแถวข้อมูล (มีแต่หัวตารางหรือว่าง)
+var ErrEmptyTable = errors.New("table has no data rows")
+
+// ReadCSVTable อ่าน CSV ทั้งไฟล์ (ตัด BOM, เดาตัวคั่น , ; หรือ tab จากบรรทัดแรก)
+func ReadCSVTable(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	first := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		first = data[:i]
+	}
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	best := 0
+	for _, sep := range []rune{',', ';', '\t'} {
+		if n := bytes.Count(first, []byte(string(sep))); n > best {
+			best, r.Comma = n, sep
+		}
+	}
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return trimTable(rows)
+}
+
+// ReadXLSXTable อ่าน sheet แรกของไฟล์ XLSX เป็นข้อความทุก cell
+// (วันที่ที่ Excel เก็บเป็นตัวเลขจะได้เป็น serial number ใช้ ExcelSerialTime แปลง)
+func ReadXLSXTable(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not an xlsx file: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := xlsxFirstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = xlsxSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("xlsx sheet %s not found", sheetPath)
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Num   int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string       `xml:"r,attr"`
+				Type   string       `xml:"t,attr"`
+				Value  string       `xml:"v"`
+				Inline xlsxRichText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeZipXML(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, xr := range sheet.Rows {
+		// แถวว่างไม่ถูกเขียนลงไฟล์ เติมกลับให้เลขแถวตรงกับที่เห็นใน Excel
+		for xr.Num > 0 && len(rows) < xr.Num-1 {
+			rows = append(rows, nil)
+		}
+		var row []string
+		for i, c := range xr.Cells {
+			col := i
+			if c.Ref != "" {
+				col = xlsxColumnIndex(c.Ref)
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			switch c.Type {
+			case "s":
+				if n, err := strconv.Atoi(c.Value); err == nil && n >= 0 && n < len(shared) {
+					row[col] = shared[n]
+				}
+			case "inlineStr":
+				row[col] = c.Inline.String()
+			case "b":
+				row[col] = map[string]string{"1": "TRUE", "0": "FALSE"}[c.Value]
+			default:
+				row[col] = c.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return trimTable(rows)
+}
+
+// ExcelSerialTime แปลง serial number วันที่ของ Excel (เช่น "45292" หรือ "45292.5") เป็นเวลา (UTC)
+func ExcelSerialTime(v string) (time.Time, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || f < 1 || f > 2958465 {
+		return time.Time{}, false
+	}
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return epoch.Add(time.Duration(f * float64(24*time.Hour))).Round(time.Second), true
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+func xlsxFirstSheetPath(files map[string]*zip.File) (string, error) {
+	wb, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("xlsx workbook not found")
+	}
+	var workbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeZipXML(wb, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("xlsx has no sheets")
+	}
+	if rels, ok := files["xl/_rels/workbook.xml.rels"]; ok {
+		var r struct {
+			Items []struct {
+				ID     string `xml:"Id,attr"`
+				Target string `xml:"Target,attr"`
+			} `xml:"Relationship"`
+		}
+		if err := decodeZipXML(rels, &r); err != nil {
+			return "", err
+		}
+		for _, it := range r.Items {
+			if it.ID == workbook.Sheets[0].RID {
+				if strings.HasPrefix(it.Target, "/") {
+					return strings.TrimPrefix(it.Target, "/"), nil
+				}
+				return path.Join("xl", it.Target), nil
+			}
+		}
+	}
+	return "xl/worksheets/sheet1.xml", nil
+}
+
+func xlsxSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []xlsxRichText `xml:"si"`
+	}
+	if err := decodeZipXML(f, &sst); err != nil {
+		return nil, err
+	}
+	out := make([]string, len(sst.Items))
+	for i, it := range sst.Items {
+		out[i] = it.String()
+	}
+	return out, nil
+}
+
+func decodeZipXML(f *zip.File, dst interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, 64<<20)).Decode(dst); err != nil {
+		return fmt.Errorf("read %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// xlsxColumnIndex "C12" → 2
+func xlsxColumnIndex(ref string) int {
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		n = n*26 + int(r-'A'+1)
+	}
+	return n - 1
+}
+
+// trimTable ตัดช่องว่างทุก cell และทิ้งแถวว่างท้ายไฟล์ (แถวว่างตรงกลางคงไว้ให้เลขแถวตรงกับไฟล์)
+func trimTable(rows [][]string) ([][]string, error) {
+	last := -1
+	for i, row := range rows {
+		for j := range row {
+			row[j] = strings.TrimSpace(row[j])
+			if row[j] != "" {
+				last = i
+			}
+		}
+	}
+	rows = rows[:last+1]
+	if len(rows) < 2 {
+		return nil, ErrEmptyTable
+	}
+	return rows, nil
+}
@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//
+// ===========================================================
+//  TABLE WRITER (CSV / XLSX แบบ stream ใช้กับ export)
+// ===========================================================
+//
+
+// TableWriter เขียนตารางทีละแถว (แถวแรกคือหัวตาราง) ต้องเรียก Close เพื่อปิดไฟล์
+type TableWriter interface {
+	WriteHeader(cells []string) error
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// ---------------- CSV ----------------
+
+type csvTableWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVTableWriter CSV แบบ UTF-8 มี BOM (Excel เปิดภาษาไทยได้ถูก)
+func NewCSVTableWriter(w io.Writer) (TableWriter, error) {
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return nil, err
+	}
+	return &csvTableWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (t *csvTableWriter) WriteHeader(cells []string) error { return t.w.Write(cells) }
+
+func (t *csvTableWriter) WriteRow(cells []string) error {
+	safe := make([]string, len(cells))
+	for i, c := range cells {
+		safe[i] = csvSafeCell(c)
+	}
+	return t.w.Write(safe)
+}
+
+func (t *csvTableWriter) Close() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+// csvSafeCell กัน CSV/formula injection: ค่าที่ขึ้นต้นด้วย = + - @ จะถูกเติม ' ข้างหน้า
+// ยกเว้นตัวเลข / เบอร์โทร เช่น "+66 81-234-5678", "-50"
+func csvSafeCell(s string) string {
+	if s == "" || !strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return s
+	}
+	if (s[0] == '+' || s[0] == '-') && len(s) > 1 && strings.Trim(s[1:], "0123456789 -().") == "" {
+		return s
+	}
+	return "'" + s
+}
+
+// ---------------- XLSX ----------------
+
+// xlsxMaxCell จำนวนตัวอักษรสูงสุดต่อ cell ที่ Excel รับได้
+const xlsxMaxCell = 32767
+
+type xlsxTableWriter struct {
+	zw  *zip.Writer
+	buf *bufio.Writer
+	row int
+}
+
+// NewXLSXTableWriter XLSX หนึ่ง sheet เขียนแถวลง zip ตรง ๆ (ไม่เก็บทั้งไฟล์ไว้ในหน่วยความจำ)
+// ทุก cell เป็นข้อความ (inline string) หัวตารางเป็นตัวหนา
+func NewXLSXTableWriter(w io.Writer, sheetName string) (TableWriter, error) {
+	zw := zip.NewWriter(w)
+	static := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlEscape(xlsxSheetName(sheetName)))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, f := range static {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(fw, f.body); err != nil {
+			return nil, err
+		}
+	}
+
+	// sheet ต้องเป็นไฟล์สุดท้ายใน zip เพราะเขียนต่อไปเรื่อย ๆ จนกว่าจะ Close
+	sw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	t := &xlsxTableWriter{zw: zw, buf: bufio.NewWriter(sw)}
+	_, err = t.buf.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return t, err
+}
+
+func (t *xlsxTableWriter) WriteHeader(cells []string) error { return t.write(cells, true) }
+
+func (t *xlsxTableWriter) WriteRow(cells []string) error { return t.write(cells, false) }
+
+func (t *xlsxTableWriter) write(cells []string, header bool) error {
+	t.row++
+	fmt.Fprintf(t.buf, `<row r="%d">`, t.row)
+	for i, c := range cells {
+		if r := []rune(c); len(r) > xlsxMaxCell {
+			c = string(r[:xlsxMaxCell])
+		}
+		style := ""
+		if header {
+			style = ` s="1"`
+		}
+		fmt.Fprintf(t.buf, `<c r="%s%d" t="inlineStr"%s><is><t xml:space="preserve">%s</t></is></c>`,
+			xlsxColumn(i), t.row, style, xmlEscape(c))
+	}
+	_, err := t.buf.WriteString("</row>")
+	return err
+}
+
+func (t *xlsxTableWriter) Close() error {
+	if _, err := t.buf.WriteString("</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	if err := t.buf.Flush(); err != nil {
+		return err
+	}
+	return t.zw.Close()
+}
+
+// xlsxColumn 0 → A, 25 → Z, 26 → AA
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxSheetName ชื่อ sheet ห้ามมี []:*?/\ และยาวไม่เกิน 31 ตัว
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		name = "Sheet1"
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// style 0 = ปกติ, 1 = ตัวหนา (หัวตาราง)
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`